@@ -15,6 +15,8 @@
 package config
 
 import (
+	"time"
+
 	"losh/internal/infra/dgraph"
 	"losh/internal/lib/log"
 )
@@ -34,9 +36,229 @@ func DefaultConfig() Config {
 }
 
 type CrawlerConfig struct {
-	UserAgent string `json:"userAgent" filter:"trim" validate:"required"`
+	// UserAgent is the default user agent of all platform crawlers.
+	UserAgent  string           `json:"userAgent" filter:"trim" validate:"required"`
+	Platforms  PlatformsConfig  `json:"platforms"`
+	State      StateConfig      `json:"state"`
+	Update     UpdateConfig     `json:"update"`
+	Reconcile  ReconcileConfig  `json:"reconcile"`
+	Popularity PopularityConfig `json:"popularity"`
 }
 
 func DefaultCrawlerConfig() CrawlerConfig {
-	return CrawlerConfig{}
+	return CrawlerConfig{
+		Platforms:  DefaultPlatformsConfig(),
+		State:      DefaultStateConfig(),
+		Update:     DefaultUpdateConfig(),
+		Reconcile:  DefaultReconcileConfig(),
+		Popularity: DefaultPopularityConfig(),
+	}
+}
+
+// PlatformsConfig contains the configuration of the crawlers of the individual
+// platforms.
+type PlatformsConfig struct {
+	Wikifactory WikifactoryConfig `json:"wikifactory"`
+	GitHub      GitHubConfig      `json:"github"`
+	GitLab      []GitLabConfig    `json:"gitlab"`
+	OSHWA       OSHWAConfig       `json:"oshwa"`
+}
+
+func DefaultPlatformsConfig() PlatformsConfig {
+	return PlatformsConfig{
+		Wikifactory: DefaultWikifactoryConfig(),
+		GitHub:      DefaultGitHubConfig(),
+		GitLab:      []GitLabConfig{},
+		OSHWA:       DefaultOSHWAConfig(),
+	}
+}
+
+// RateLimitConfig limits the rate of requests sent to a platform.
+type RateLimitConfig struct {
+	// Interval is the minimum time between two requests. If zero, the default
+	// of the platform crawler is used.
+	Interval time.Duration `json:"interval"`
+	// Jitter is the random variation of the interval in percent.
+	Jitter uint64 `json:"jitter" validate:"max:100"`
+}
+
+// OrDefault returns the given default, if no interval is configured.
+func (c RateLimitConfig) OrDefault(interval time.Duration, jitter uint64) RateLimitConfig {
+	if c.Interval <= 0 {
+		return RateLimitConfig{Interval: interval, Jitter: jitter}
+	}
+	return c
+}
+
+// WikifactoryConfig contains the configuration of the Wikifactory crawler.
+type WikifactoryConfig struct {
+	// Enabled indicates whether the platform is crawled. Defaults to true.
+	Enabled *bool `json:"enabled"`
+	// UserAgent overrides the default user agent.
+	UserAgent string `json:"userAgent" filter:"trim"`
+	// RateLimit limits the requests to the GraphQL API.
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	// Concurrency is the number of products fetched concurrently.
+	Concurrency int `json:"concurrency" validate:"min:1"`
+}
+
+func DefaultWikifactoryConfig() WikifactoryConfig {
+	return WikifactoryConfig{
+		Concurrency: 4,
+	}
+}
+
+// IsEnabled returns true, if the platform is crawled.
+func (c WikifactoryConfig) IsEnabled() bool {
+	return isEnabled(c.Enabled)
+}
+
+// GitHubConfig contains the configuration of the GitHub crawler.
+type GitHubConfig struct {
+	// Enabled indicates whether the platform is crawled. Defaults to true.
+	Enabled *bool `json:"enabled"`
+	// UserAgent overrides the default user agent.
+	UserAgent string `json:"userAgent" filter:"trim"`
+	// RateLimit limits the requests to the REST API and file downloads.
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	// SearchRateLimit limits the requests to the code search API.
+	SearchRateLimit RateLimitConfig `json:"searchRateLimit"`
+	// APIURL is the base URL of the GitHub REST API.
+	APIURL string `json:"apiUrl" filter:"trim" validate:"fullUrl"`
+	// Token is the personal access token used to authenticate against the
+	// API. Products can only be discovered, if a token is provided.
+	Token string `json:"token" filter:"trim"`
+}
+
+func DefaultGitHubConfig() GitHubConfig {
+	return GitHubConfig{
+		APIURL: "https://api.github.com",
+	}
+}
+
+// IsEnabled returns true, if the platform is crawled.
+func (c GitHubConfig) IsEnabled() bool {
+	return isEnabled(c.Enabled)
+}
+
+// GitLabConfig contains the configuration of a GitLab instance to crawl.
+type GitLabConfig struct {
+	// Enabled indicates whether the instance is crawled. Defaults to true.
+	Enabled *bool `json:"enabled"`
+	// UserAgent overrides the default user agent.
+	UserAgent string `json:"userAgent" filter:"trim"`
+	// RateLimit limits the requests to the REST API and file downloads.
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	// Name is the display name of the instance. Defaults to the domain.
+	Name string `json:"name" filter:"trim"`
+	// BaseURL is the URL of the instance, e.g. https://gitlab.com.
+	BaseURL string `json:"baseUrl" filter:"trim" validate:"required|fullUrl"`
+	// Token is the personal access token used to authenticate against the
+	// API. It is required to access internal or private projects.
+	Token string `json:"token" filter:"trim"`
+}
+
+// IsEnabled returns true, if the instance is crawled.
+func (c GitLabConfig) IsEnabled() bool {
+	return isEnabled(c.Enabled)
+}
+
+// OSHWAConfig contains the configuration of the OSHWA certification crawler.
+type OSHWAConfig struct {
+	// Enabled indicates whether the platform is crawled. Defaults to true.
+	Enabled *bool `json:"enabled"`
+	// UserAgent overrides the default user agent.
+	UserAgent string `json:"userAgent" filter:"trim"`
+	// RateLimit limits the requests to the API.
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	// APIURL is the base URL of the OSHWA certification API.
+	APIURL string `json:"apiUrl" filter:"trim" validate:"fullUrl"`
+	// Token is the API token used to authenticate against the API. Products
+	// can only be crawled, if a token or a fixture file is provided.
+	Token string `json:"token" filter:"trim"`
+	// FixtureFile is the path to a recorded response of the project list
+	// endpoint. If set, it is used instead of the API.
+	FixtureFile string `json:"fixtureFile" filter:"trim"`
+}
+
+func DefaultOSHWAConfig() OSHWAConfig {
+	return OSHWAConfig{
+		APIURL: "https://certificationapi.oshwa.org/api",
+	}
+}
+
+// IsEnabled returns true, if the platform is crawled.
+func (c OSHWAConfig) IsEnabled() bool {
+	return isEnabled(c.Enabled)
+}
+
+// isEnabled treats an unset enabled flag as true.
+func isEnabled(enabled *bool) bool {
+	return enabled == nil || *enabled
+}
+
+// StateConfig contains the configuration of the store for the crawler states,
+// which are used to resume interrupted runs.
+type StateConfig struct {
+	// Store is the kind of the state store, either `file` or `database`.
+	Store string `json:"store" filter:"trim,lower" validate:"required|in:file,database"`
+	// Directory is the directory of the state files of the `file` store.
+	Directory string `json:"directory" filter:"trim"`
+	// Archive indicates whether the states of completed runs are kept.
+	Archive bool `json:"archive"`
+}
+
+func DefaultStateConfig() StateConfig {
+	return StateConfig{
+		Store:     "file",
+		Directory: ".",
+	}
+}
+
+// UpdateConfig contains the configuration of the update mode, which re-indexes
+// already known products.
+type UpdateConfig struct {
+	// MaxAge is the age of the last indexing after which a product is
+	// re-indexed.
+	MaxAge time.Duration `json:"maxAge"`
+}
+
+func DefaultUpdateConfig() UpdateConfig {
+	return UpdateConfig{
+		MaxAge: 7 * 24 * time.Hour,
+	}
+}
+
+// ReconcileConfig contains the configuration of the detection of products,
+// that have been removed from their platform.
+type ReconcileConfig struct {
+	// Enabled indicates whether removed products are detected after a complete
+	// discovery run.
+	Enabled bool `json:"enabled"`
+	// PurgeAfter is the time after which missing products are deleted. If
+	// zero, missing products are kept forever.
+	PurgeAfter time.Duration `json:"purgeAfter"`
+}
+
+func DefaultReconcileConfig() ReconcileConfig {
+	return ReconcileConfig{
+		Enabled: true,
+	}
+}
+
+// PopularityConfig contains the configuration of the popularity snapshots,
+// which are used to compute the growth of the star and fork counts.
+type PopularityConfig struct {
+	// SnapshotInterval is the minimum time between two snapshots of a product.
+	SnapshotInterval time.Duration `json:"snapshotInterval"`
+	// GrowthWindows are the time windows over which the growth is computed.
+	// The growth over the first window is used to rank trending products.
+	GrowthWindows []time.Duration `json:"growthWindows"`
+}
+
+func DefaultPopularityConfig() PopularityConfig {
+	return PopularityConfig{
+		SnapshotInterval: 24 * time.Hour,
+		GrowthWindows:    []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour},
+	}
 }
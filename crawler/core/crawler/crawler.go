@@ -0,0 +1,117 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crawler defines the common interface of the platform crawlers and a
+// registry to look them up by the domain of the platform they crawl.
+package crawler
+
+import (
+	"context"
+	"strings"
+
+	"losh/internal/core/product/models"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+// ErrProductNotFound is returned by `Crawler.GetProduct`, if the product
+// doesn't exist (anymore) on the platform.
+var ErrProductNotFound = errors.New("product not found")
+
+// Crawler is a product crawler for a single platform.
+type Crawler interface {
+	// Domain returns the domain of the crawled platform, e.g. github.com.
+	Domain() string
+	// DiscoverProducts searches the platform for new products and saves them
+	// to the database.
+	DiscoverProducts(ctx context.Context) error
+	// UpdateProducts re-indexes products of the platform, that are already
+	// stored in the database.
+	UpdateProducts(ctx context.Context) error
+	// GetProduct retrieves the product with the given ID from the platform.
+	GetProduct(ctx context.Context, id models.ProductID) (*models.Product, error)
+}
+
+// Registry holds the crawlers keyed by the domain of their platform.
+type Registry struct {
+	crawlers map[string]Crawler
+	// domains in order of registration
+	domains []string
+}
+
+// NewRegistry creates a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		crawlers: make(map[string]Crawler),
+		domains:  []string{},
+	}
+}
+
+// Register adds a crawler to the registry. Only a single crawler can be
+// registered per domain.
+func (r *Registry) Register(crawler Crawler) error {
+	domain := strings.ToLower(crawler.Domain())
+	if _, ok := r.crawlers[domain]; ok {
+		return errors.Errorf("crawler for platform '%s' already registered", domain)
+	}
+	r.crawlers[domain] = crawler
+	r.domains = append(r.domains, domain)
+	return nil
+}
+
+// Has returns true, if a crawler is registered for the given domain.
+func (r *Registry) Has(domain string) bool {
+	_, ok := r.crawlers[strings.ToLower(domain)]
+	return ok
+}
+
+// Get returns the crawler registered for the given domain.
+func (r *Registry) Get(domain string) (Crawler, error) {
+	crawler, ok := r.crawlers[strings.ToLower(domain)]
+	if !ok {
+		return nil, errors.Errorf("no crawler registered for platform '%s'", domain)
+	}
+	return crawler, nil
+}
+
+// GetByURL returns the crawler responsible for the product with the given URL
+// together with the ID of the product.
+func (r *Registry) GetByURL(url string) (Crawler, models.ProductID, error) {
+	id, err := models.NewProductIDFromURL(url)
+	if err != nil {
+		return nil, models.ProductID{}, errors.Wrap(err, "invalid or unsupported URL")
+	}
+	crawler, err := r.Get(id.Platform)
+	if err != nil {
+		return nil, models.ProductID{}, err
+	}
+	return crawler, id, nil
+}
+
+// Domains returns the domains of all registered crawlers in order of
+// registration.
+func (r *Registry) Domains() []string {
+	domains := make([]string, len(r.domains))
+	copy(domains, r.domains)
+	return domains
+}
+
+// All returns all registered crawlers in order of registration.
+func (r *Registry) All() []Crawler {
+	crawlers := make([]Crawler, 0, len(r.domains))
+	for _, domain := range r.domains {
+		crawlers = append(crawlers, r.crawlers[domain])
+	}
+	return crawlers
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawler
+
+import (
+	"context"
+	"time"
+
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/infra/dgraph/dgclient"
+	lerrors "losh/internal/lib/errors"
+	"losh/internal/lib/log"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// reconcileBatchSize is the number of stored products checked at once.
+const reconcileBatchSize = 50
+
+// reconcileResult is the outcome of the reconciliation of a single product.
+type reconcileResult int
+
+const (
+	reconcileKept reconcileResult = iota
+	reconcileReindexed
+	reconcileMissing
+	reconcilePurged
+)
+
+// Reconciler detects products, that have disappeared from their platform.
+// After a complete discovery run, it re-checks each stored product of the
+// platform, that was not seen during the run. Products, that no longer exist,
+// are moved into the state MISSING and can be purged after a grace period.
+type Reconciler struct {
+	productService *services.Service
+	log            *zap.SugaredLogger
+
+	// missing products are deleted after this period; zero disables purging
+	purgeAfter time.Duration
+}
+
+// NewReconciler creates a new Reconciler.
+func NewReconciler(productService *services.Service) *Reconciler {
+	return &Reconciler{
+		productService: productService,
+		log:            log.NewLogger("crawler-reconciler"),
+	}
+}
+
+// SetPurgeAfter sets the grace period after which missing products are
+// deleted together with their releases, components, revisions and popularity
+// snapshots. A period of zero disables purging.
+func (r *Reconciler) SetPurgeAfter(purgeAfter time.Duration) *Reconciler {
+	r.purgeAfter = purgeAfter
+	return r
+}
+
+// Reconcile checks the stored products of the crawler's platform, that were
+// not seen during the discovery run started at the given time.
+func (r *Reconciler) Reconcile(ctx context.Context, crawler Crawler, runStartedAt time.Time) error {
+	domain := crawler.Domain()
+	r.log.Infof("reconciling products on %s not seen since %s", domain, runStartedAt.Format(time.RFC3339))
+
+	var numChecked, numReindexed, numMissing, numPurged int64
+	// products that are kept remain in the result set, therefore they need to
+	// be skipped
+	var offset int64
+	for {
+		storedPrds, err := r.productService.GetProductsIndexedBefore(ctx, domain, runStartedAt, reconcileBatchSize, offset)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to get products to reconcile").Add("platform", domain)
+		}
+		if len(storedPrds) == 0 {
+			break
+		}
+
+		for _, storedPrd := range storedPrds {
+			numChecked++
+			result, err := r.reconcileProduct(ctx, crawler, storedPrd)
+			if err != nil {
+				return err
+			}
+			switch result {
+			case reconcileKept:
+				offset++
+			case reconcileReindexed:
+				numReindexed++
+			case reconcileMissing:
+				numMissing++
+				offset++
+			case reconcilePurged:
+				numPurged++
+			}
+		}
+	}
+
+	r.log.Infof("reconciled %d products on %s: %d re-indexed, %d newly missing, %d purged", numChecked, domain, numReindexed, numMissing, numPurged)
+	return nil
+}
+
+// reconcileProduct re-checks a single stored product, that was not seen during
+// the discovery run.
+func (r *Reconciler) reconcileProduct(ctx context.Context, crawler Crawler, storedPrd *models.Product) (reconcileResult, error) {
+	xid := ""
+	if storedPrd.Xid != nil {
+		xid = *storedPrd.Xid
+	}
+	if storedPrd.DataSource == nil || storedPrd.DataSource.URL == nil {
+		r.log.Debugf("skipping (%s): missing data source", xid)
+		return reconcileKept, nil
+	}
+	productID, err := models.NewProductIDFromURL(*storedPrd.DataSource.URL)
+	if err != nil {
+		r.log.Debugf("skipping (%s): %s", xid, err.Error())
+		return reconcileKept, nil
+	}
+
+	r.log.Debugf("re-checking product (%s)", productID.String())
+	prd, err := crawler.GetProduct(ctx, productID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return reconcileKept, ctx.Err()
+		}
+		if !errors.Is(err, ErrProductNotFound) {
+			// the product might still exist; it is checked again next time
+			r.log.Warnf("failed to re-check product (%s): %s", productID.String(), err.Error())
+			return reconcileKept, nil
+		}
+
+		// placeholders of fork origins are kept, they link to the origin anyway
+		if storedPrd.IsPlaceholder() {
+			return reconcileKept, nil
+		}
+
+		now := time.Now()
+		if storedPrd.State == nil || *storedPrd.State != dgclient.ProductStateMissing || storedPrd.MissingSince == nil {
+			r.log.Infof("product is missing (%s)", productID.String())
+			if err = r.productService.MarkProductMissing(ctx, storedPrd, now); err != nil {
+				return reconcileKept, lerrors.NewAppErrorWrap(err, "failed to mark product as missing").Add("crawlerProductID", productID.String())
+			}
+			return reconcileMissing, nil
+		}
+		if r.purgeAfter > 0 && now.Sub(*storedPrd.MissingSince) > r.purgeAfter {
+			r.log.Infof("purging product missing since %s (%s)", storedPrd.MissingSince.Format(time.RFC3339), productID.String())
+			if err = r.productService.DeleteProduct(ctx, storedPrd); err != nil {
+				return reconcileKept, lerrors.NewAppErrorWrap(err, "failed to purge product").Add("crawlerProductID", productID.String())
+			}
+			return reconcilePurged, nil
+		}
+		return reconcileKept, nil
+	}
+
+	// the product still exists, but wasn't discovered
+	r.log.Debugf("re-indexing product (%s)", productID.String())
+	prd.DiscoveredAt = storedPrd.DiscoveredAt
+	diffs, err := r.productService.SaveNodeWithDiff(ctx, prd)
+	if err != nil {
+		return reconcileKept, lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+	}
+	for _, diff := range diffs {
+		r.log.Debugf("changed %s", diff)
+	}
+
+	// the product was saved under a different identity, e.g. because it was
+	// renamed, therefore the stored product is marked as indexed as well
+	if prd.ID == nil || storedPrd.ID == nil || *prd.ID != *storedPrd.ID {
+		if err = r.productService.TouchProduct(ctx, storedPrd, time.Now()); err != nil {
+			return reconcileKept, lerrors.NewAppErrorWrap(err, "failed to update product").Add("crawlerProductID", productID.String())
+		}
+	}
+	return reconcileReindexed, nil
+}
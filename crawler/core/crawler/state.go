@@ -0,0 +1,70 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// RunDiscover is the name of the discovery run used in the state namespaces.
+const RunDiscover = "discover"
+
+// archiveTimeFormat is the format of the timestamp appended to the namespace
+// of an archived state.
+const archiveTimeFormat = "20060102T150405Z"
+
+// StateStore persists the state of crawler runs, so that an interrupted run
+// can be resumed. The states are kept under a namespace, which identifies the
+// crawler and the run, e.g. `wikifactory.com/discover`.
+type StateStore interface {
+	// Load loads the state of the given namespace into the given value. It
+	// returns false, if no state is stored.
+	Load(ctx context.Context, namespace string, state interface{}) (bool, error)
+	// Save saves the state under the given namespace.
+	Save(ctx context.Context, namespace string, state interface{}) error
+	// Complete marks the run as completed. The state is either cleared or
+	// archived under a timestamped namespace.
+	Complete(ctx context.Context, namespace string) error
+	// Reset removes the state of the given namespace.
+	Reset(ctx context.Context, namespace string) error
+	// List returns all stored states, including archived ones.
+	List(ctx context.Context) ([]StateEntry, error)
+}
+
+// StateEntry is a stored crawler state.
+type StateEntry struct {
+	Namespace string          `json:"namespace"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	State     json.RawMessage `json:"state"`
+}
+
+// IsArchived returns true, if the state belongs to a completed run.
+func (e StateEntry) IsArchived() bool {
+	return strings.Contains(e.Namespace, "@")
+}
+
+// StateNamespace returns the namespace of the state of a crawler run.
+func StateNamespace(domain, run string) string {
+	return strings.ToLower(domain) + "/" + run
+}
+
+// archivedNamespace returns the namespace under which the state of a
+// completed run is archived.
+func archivedNamespace(namespace string, completedAt time.Time) string {
+	return namespace + "@" + completedAt.UTC().Format(archiveTimeFormat)
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+// StateRepository is the repository used by the `DatabaseStateStore`.
+type StateRepository interface {
+	GetCrawlerState(ctx context.Context, namespace string) (*models.CrawlerState, error)
+	GetAllCrawlerStates(ctx context.Context) ([]*models.CrawlerState, error)
+	SaveCrawlerState(ctx context.Context, state *models.CrawlerState) error
+	DeleteCrawlerState(ctx context.Context, namespace string) error
+}
+
+// DatabaseStateStore is a `StateStore` that keeps the states in the database
+// as part of the `Database` node.
+type DatabaseStateStore struct {
+	repo    StateRepository
+	archive bool
+}
+
+var _ StateStore = (*DatabaseStateStore)(nil)
+
+// NewDatabaseStateStore creates a new DatabaseStateStore. If archive is true,
+// the states of completed runs are kept instead of being removed.
+func NewDatabaseStateStore(repo StateRepository, archive bool) *DatabaseStateStore {
+	return &DatabaseStateStore{
+		repo:    repo,
+		archive: archive,
+	}
+}
+
+// Load implements `StateStore`.
+func (s *DatabaseStateStore) Load(ctx context.Context, namespace string, state interface{}) (bool, error) {
+	stored, err := s.repo.GetCrawlerState(ctx, namespace)
+	if err != nil || stored == nil {
+		return false, err
+	}
+	if err = json.Unmarshal([]byte(*stored.State), state); err != nil {
+		return false, errors.Wrapf(err, "failed to unmarshal state '%s'", namespace)
+	}
+	return true, nil
+}
+
+// Save implements `StateStore`.
+func (s *DatabaseStateStore) Save(ctx context.Context, namespace string, state interface{}) error {
+	rawState, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal state '%s'", namespace)
+	}
+	now := time.Now().UTC()
+	return s.repo.SaveCrawlerState(ctx, &models.CrawlerState{
+		Namespace: &namespace,
+		State:     p(string(rawState)),
+		UpdatedAt: &now,
+	})
+}
+
+// Complete implements `StateStore`.
+func (s *DatabaseStateStore) Complete(ctx context.Context, namespace string) error {
+	if s.archive {
+		stored, err := s.repo.GetCrawlerState(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			stored.Namespace = p(archivedNamespace(namespace, time.Now()))
+			if err = s.repo.SaveCrawlerState(ctx, stored); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Reset(ctx, namespace)
+}
+
+// Reset implements `StateStore`.
+func (s *DatabaseStateStore) Reset(ctx context.Context, namespace string) error {
+	return s.repo.DeleteCrawlerState(ctx, namespace)
+}
+
+// List implements `StateStore`.
+func (s *DatabaseStateStore) List(ctx context.Context) ([]StateEntry, error) {
+	stored, err := s.repo.GetAllCrawlerStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StateEntry, 0, len(stored))
+	for _, state := range stored {
+		entries = append(entries, StateEntry{
+			Namespace: *state.Namespace,
+			UpdatedAt: *state.UpdatedAt,
+			State:     json.RawMessage(*state.State),
+		})
+	}
+	return entries, nil
+}
+
+func p[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,166 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._@-]+`)
+
+// FileStateStore is a `StateStore` that keeps each state in a JSON file inside
+// a directory.
+type FileStateStore struct {
+	dir     pathlib.Path
+	archive bool
+}
+
+var _ StateStore = (*FileStateStore)(nil)
+
+// NewFileStateStore creates a new FileStateStore, that stores the states in
+// the given directory. If archive is true, the states of completed runs are
+// kept instead of being removed.
+func NewFileStateStore(dir pathlib.Path, archive bool) *FileStateStore {
+	return &FileStateStore{
+		dir:     dir,
+		archive: archive,
+	}
+}
+
+// Load implements `StateStore`.
+func (s *FileStateStore) Load(_ context.Context, namespace string, state interface{}) (bool, error) {
+	entry, err := s.readEntry(s.path(namespace))
+	if err != nil || entry == nil {
+		return false, err
+	}
+	if err = json.Unmarshal(entry.State, state); err != nil {
+		return false, errors.Wrapf(err, "failed to unmarshal state '%s'", namespace)
+	}
+	return true, nil
+}
+
+// Save implements `StateStore`.
+func (s *FileStateStore) Save(_ context.Context, namespace string, state interface{}) error {
+	rawState, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal state '%s'", namespace)
+	}
+	return s.writeEntry(StateEntry{
+		Namespace: namespace,
+		UpdatedAt: time.Now().UTC(),
+		State:     rawState,
+	})
+}
+
+// Complete implements `StateStore`.
+func (s *FileStateStore) Complete(ctx context.Context, namespace string) error {
+	if s.archive {
+		entry, err := s.readEntry(s.path(namespace))
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			entry.Namespace = archivedNamespace(namespace, time.Now())
+			if err = s.writeEntry(*entry); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Reset(ctx, namespace)
+}
+
+// Reset implements `StateStore`.
+func (s *FileStateStore) Reset(_ context.Context, namespace string) error {
+	path := s.path(namespace)
+	exists, err := path.Exists()
+	if err != nil {
+		return errors.Wrap(err, "failed to check file existence")
+	}
+	if !exists {
+		return nil
+	}
+	if err = path.Remove(); err != nil {
+		return errors.Wrapf(err, "failed to remove state file: %s", path.String())
+	}
+	return nil
+}
+
+// List implements `StateStore`.
+func (s *FileStateStore) List(_ context.Context) ([]StateEntry, error) {
+	paths, err := s.dir.Glob("crawler-state-*.json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list state files in %s", s.dir.String())
+	}
+	entries := make([]StateEntry, 0, len(paths))
+	for _, path := range paths {
+		entry, err := s.readEntry(path)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Namespace < entries[j].Namespace })
+	return entries, nil
+}
+
+// path returns the path of the state file of the given namespace.
+func (s *FileStateStore) path(namespace string) pathlib.Path {
+	return s.dir.Join("crawler-state-" + unsafeFileNameChars.ReplaceAllString(namespace, "_") + ".json")
+}
+
+// readEntry reads a state file. It returns nil, if the file doesn't exist.
+func (s *FileStateStore) readEntry(path pathlib.Path) (*StateEntry, error) {
+	exists, err := path.Exists()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check file existence")
+	}
+	if !exists {
+		return nil, nil
+	}
+	fileContent, err := path.ReadFile()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read state file: %s", path.String())
+	}
+	entry := &StateEntry{}
+	if err = json.Unmarshal(fileContent, entry); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal state file: %s", path.String())
+	}
+	return entry, nil
+}
+
+// writeEntry writes a state file.
+func (s *FileStateStore) writeEntry(entry StateEntry) error {
+	fileContent, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal state '%s'", entry.Namespace)
+	}
+	if err = s.dir.MkdirAll(); err != nil {
+		return errors.Wrapf(err, "failed to create state directory: %s", s.dir.String())
+	}
+	path := s.path(entry.Namespace)
+	if err = path.WriteFile(fileContent); err != nil {
+		return errors.Wrapf(err, "failed to write state file: %s", path.String())
+	}
+	return nil
+}
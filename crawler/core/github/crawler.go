@@ -0,0 +1,626 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/github/ghclient"
+	"losh/crawler/core/validator"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	lerrors "losh/internal/lib/errors"
+	"losh/internal/lib/log"
+	"losh/internal/lib/net/download"
+	"losh/internal/lib/net/ratelimit"
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/aisbergg/go-retry/pkg/retry"
+	"github.com/go-resty/resty/v2"
+
+	"go.uber.org/zap"
+
+	n "losh/internal/lib/net"
+	"losh/internal/lib/unit"
+)
+
+// Constants that define the crawler behavior.
+const (
+	crawlerName         = "github.com"
+	timeout             = time.Duration(5) * time.Minute
+	perPage             = 100
+	maxSearchResults    = 1000 // GitHub doesn't return more results per query
+	retries             = 5
+	maxFileSizeManifest = 10 * unit.MiB
+	maxFileSizeLicense  = 1 * unit.MiB
+	maxWaitTime         = 61 * time.Minute // primary rate limit resets every hour
+	maxRedirects        = 5
+	updateBatchSize     = 100
+	defaultUpdateMaxAge = 7 * 24 * time.Hour
+)
+
+// searchQueries are the code search queries used to discover manifest files.
+var searchQueries = []string{
+	"filename:okh extension:toml",
+	"filename:okh extension:yml",
+	"filename:okh extension:yaml",
+}
+
+type CrawlerState struct {
+	StartTime   time.Time     `json:"startTime"`
+	ElapsedTime time.Duration `json:"elapsedTime"`
+	NumCrawled  int64         `json:"numCrawled"`
+	NumIndexed  int64         `json:"numIndexed"`
+	Query       int           `json:"query"`
+	Page        int           `json:"page"`
+}
+
+// GitHubCrawler is a product crawler for GitHub.
+type GitHubCrawler struct {
+	productService *services.Service
+	validator      *validator.Validator
+
+	fileDownloader *download.Downloader
+	ghClient       *ghclient.Client
+	log            *zap.SugaredLogger
+
+	stateStore crawler.StateStore
+	reconciler *crawler.Reconciler
+
+	// code search requires authentication
+	authenticated bool
+	updateMaxAge  time.Duration
+}
+
+// NewGitHubCrawler creates a new GitHubCrawler. The API URL defaults to the
+// public GitHub API if empty. Code search is only available for authenticated
+// requests, therefore a token should be provided for discovering products.
+func NewGitHubCrawler(productService *services.Service, cfg config.GitHubConfig) *GitHubCrawler {
+	log := log.NewLogger("crawler-github")
+	userAgent, apiURL, token := cfg.UserAgent, cfg.APIURL, cfg.Token
+	rateLimit := cfg.RateLimit.OrDefault(500*time.Millisecond, 5)
+	searchRateLimit := cfg.SearchRateLimit.OrDefault(2*time.Second, 5)
+	if apiURL == "" {
+		apiURL = ghclient.DefaultBaseURL
+	}
+	apiURL = strings.TrimRight(apiURL, "/")
+
+	// clients for external requests
+	httpClient := &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: n.NewRedirectHandler(maxRedirects),
+	}
+
+	// GitHub reports the rate limits of the core and search API separately
+	coreRateLimiter := ratelimit.NewHeaderRateLimiter(n.HdrXRateLimitRemainingKey, n.HdrXRateLimitResetKey)
+	searchRateLimiter := ratelimit.NewHeaderRateLimiter(n.HdrXRateLimitRemainingKey, n.HdrXRateLimitResetKey)
+
+	// file downloader
+	fileRequester := request.NewHTTPRequester(httpClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryCalcWaitTimeFromResponseFunc(calcWaitTime).
+		AddRateLimiter(coreRateLimiter).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
+	fileDownloader := download.NewDownloaderWithRequester(fileRequester).
+		SetUserAgent(userAgent).
+		AddHeader(n.HdrAcceptKey, "application/vnd.github.raw")
+
+	// GitHub REST client
+	restClient := resty.NewWithClient(httpClient).
+		SetBaseURL(apiURL).
+		SetHeader(n.HdrUserAgentKey, userAgent).
+		SetHeader(n.HdrAcceptKey, "application/vnd.github+json")
+	if token != "" {
+		restClient.SetAuthToken(token)
+		fileDownloader.AddHeader(n.HdrAuthorizationKey, "Bearer "+token)
+	}
+	coreRequester := request.NewRESTRequester(restClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryCalcWaitTimeFromResponseFunc(calcWaitTime).
+		AddRateLimiter(coreRateLimiter).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
+	searchRequester := request.NewRESTRequester(restClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryCalcWaitTimeFromResponseFunc(calcWaitTime).
+		AddRateLimiter(searchRateLimiter).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(searchRateLimit.Interval, searchRateLimit.Jitter))
+	ghClient := ghclient.NewClient(restClient, coreRequester, searchRequester)
+
+	validator := validator.NewValidator(productService)
+
+	return &GitHubCrawler{
+		productService: productService,
+		validator:      validator,
+
+		fileDownloader: fileDownloader,
+		ghClient:       ghClient,
+
+		log:        log,
+		stateStore: crawler.NewFileStateStore(pathlib.NewPath("."), false),
+
+		authenticated: token != "",
+		updateMaxAge:  defaultUpdateMaxAge,
+	}
+}
+
+// Domain returns the domain of the crawled platform.
+func (c *GitHubCrawler) Domain() string {
+	return crawlerName
+}
+
+// SetReconciler sets the reconciler, that detects removed products after a
+// complete discovery run. No reconciliation takes place, if it is nil.
+func (c *GitHubCrawler) SetReconciler(reconciler *crawler.Reconciler) *GitHubCrawler {
+	c.reconciler = reconciler
+	return c
+}
+
+// SetUpdateMaxAge sets the age of the last indexing after which a product is
+// re-indexed by `UpdateProducts`.
+func (c *GitHubCrawler) SetUpdateMaxAge(maxAge time.Duration) *GitHubCrawler {
+	c.updateMaxAge = maxAge
+	return c
+}
+
+// SetStateStore sets the store, that keeps the state of the crawler runs.
+func (c *GitHubCrawler) SetStateStore(store crawler.StateStore) *GitHubCrawler {
+	c.stateStore = store
+	return c
+}
+
+func (c *GitHubCrawler) DiscoverProducts(ctx context.Context) error {
+	if !c.authenticated {
+		c.log.Infof("no GitHub token configured, skipping discovery on %s", crawlerName)
+		return nil
+	}
+	c.log.Infof("discovering products on %s", crawlerName)
+
+	state, err := c.loadState(ctx)
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to load state")
+	}
+
+	runStartedAt := time.Now()
+	prevElapsedTime := state.ElapsedTime
+	for state.Query < len(searchQueries) {
+		query := searchQueries[state.Query]
+		hasNextPage := true
+		for hasNextPage {
+			c.log.Debugf("getting %d results from page %d (query: %s)", perPage, state.Page, query)
+
+			// search for manifest files
+			result, err := c.ghClient.SearchCode(ctx, query, state.Page, perPage)
+			if err != nil {
+				return lerrors.NewAppErrorWrap(err, "failed to search for manifest files")
+			}
+			discoveredAt := time.Now()
+
+			// check each manifest file and index if compliant
+			for _, item := range result.Items {
+				if item.Repository == nil || item.Repository.Owner == nil {
+					continue
+				}
+				productID := models.NewProductID(crawlerName, item.Repository.Owner.Login, item.Repository.Name, item.Path)
+
+				// only consider accepted manifest file names
+				if !manifest.IsManifestFile(item.Path) {
+					c.log.Debugf("skipping (%s): not a manifest file", productID.String())
+					continue
+				}
+
+				// get full product information
+				c.log.Infof("indexing product (%s)", productID.String())
+				prd, err := c.getProduct(ctx, productID, discoveredAt)
+				if err != nil {
+					if isSkippable(err) {
+						c.log.Debugf("skipping (%s): %s", productID.String(), err.Error())
+						continue
+					}
+					return lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+				}
+
+				// save product
+				c.log.Debugf("saving product (%s)", productID.String())
+				diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+				if err != nil {
+					return lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+				}
+				for _, diff := range diffs {
+					c.log.Debugf("changed %s", diff)
+				}
+
+				state.NumIndexed++
+			}
+
+			// GitHub returns at most 1000 results per query
+			total := result.TotalCount
+			if total > maxSearchResults {
+				total = maxSearchResults
+			}
+			hasNextPage = len(result.Items) == perPage && int64(state.Page*perPage) < total
+			state.Page++
+
+			// save state
+			state.NumCrawled += int64(len(result.Items))
+			state.ElapsedTime = prevElapsedTime + time.Now().Sub(runStartedAt)
+			if !hasNextPage {
+				state.Query++
+				state.Page = 1
+			}
+			err = c.saveState(ctx, state)
+			if err != nil {
+				return lerrors.NewAppErrorWrap(err, "failed to save state")
+			}
+			c.log.Debugf("indexed %d of %d products on %s this far", state.NumIndexed, state.NumCrawled, crawlerName)
+		}
+	}
+
+	// detect products, that have been removed from the platform
+	if c.reconciler != nil {
+		err = c.reconciler.Reconcile(ctx, c, state.StartTime)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to reconcile products")
+		}
+	}
+
+	// the run is completed
+	err = c.stateStore.Complete(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover))
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to complete state")
+	}
+
+	return nil
+}
+
+// UpdateProducts re-indexes the stored products, that were indexed more than
+// the configured max age ago. Unlike the discovery, it works without a token,
+// but is then subject to much lower rate limits.
+func (c *GitHubCrawler) UpdateProducts(ctx context.Context) error {
+	c.log.Infof("updating products on %s indexed more than %s ago", crawlerName, c.updateMaxAge)
+
+	indexedBefore := time.Now().Add(-c.updateMaxAge)
+	var numChecked, numUpdated int64
+	// products that are not re-indexed remain in the result set, therefore
+	// they need to be skipped
+	var offset int64
+	for {
+		storedPrds, err := c.productService.GetProductsIndexedBefore(ctx, crawlerName, indexedBefore, updateBatchSize, offset)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to get products to update")
+		}
+		if len(storedPrds) == 0 {
+			break
+		}
+
+		for _, storedPrd := range storedPrds {
+			numChecked++
+			updated, err := c.updateProduct(ctx, storedPrd)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				offset++
+				continue
+			}
+			numUpdated++
+		}
+		c.log.Debugf("updated %d of %d checked products on %s this far", numUpdated, numChecked, crawlerName)
+	}
+
+	c.log.Infof("updated %d of %d checked products on %s", numUpdated, numChecked, crawlerName)
+	return nil
+}
+
+// updateProduct re-indexes a single stored product. It returns false, if the
+// product was skipped. The product is always saved, because its star and fork
+// counts change without a push; only the changed fields are written though.
+func (c *GitHubCrawler) updateProduct(ctx context.Context, storedPrd *models.Product) (bool, error) {
+	productID, err := storedProductID(storedPrd)
+	if err != nil {
+		c.log.Debugf("skipping (%s): %s", stringOrEmpty(storedPrd.Xid), err.Error())
+		return false, nil
+	}
+
+	c.log.Infof("re-indexing product (%s)", productID.String())
+	indexedAt := time.Now()
+	prd, err := c.getProduct(ctx, productID, indexedAt)
+	if err != nil {
+		if isSkippable(err) {
+			// removed products are detected by the reconciliation
+			c.log.Debugf("skipping (%s): %s", productID.String(), err.Error())
+			return false, nil
+		}
+		return false, lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+	}
+
+	// save product
+	c.log.Debugf("saving product (%s)", productID.String())
+	prd.DiscoveredAt = storedPrd.DiscoveredAt
+	diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+	if err != nil {
+		return false, lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+	}
+	for _, diff := range diffs {
+		c.log.Debugf("changed %s", diff)
+	}
+
+	// the product was saved under a different identity, e.g. because the
+	// repository was renamed, therefore the stored product is marked as
+	// indexed as well
+	if prd.ID == nil || storedPrd.ID == nil || *prd.ID != *storedPrd.ID {
+		err = c.productService.TouchProduct(ctx, storedPrd, indexedAt)
+		if err != nil {
+			return false, lerrors.NewAppErrorWrap(err, "failed to update product").Add("crawlerProductID", productID.String())
+		}
+	}
+	return true, nil
+}
+
+// storedProductID returns the ID of a stored product, which is derived from
+// the repository and the path of the manifest file of its data source.
+func storedProductID(storedPrd *models.Product) (models.ProductID, error) {
+	dataSource := storedPrd.DataSource
+	if dataSource == nil || dataSource.URL == nil || dataSource.Path == nil {
+		return models.ProductID{}, errors.New("missing data source")
+	}
+	repoID, err := models.NewProductIDFromURL(*dataSource.URL)
+	if err != nil {
+		return models.ProductID{}, err
+	}
+	return models.NewProductID(crawlerName, repoID.Owner, repoID.Repo, *dataSource.Path), nil
+}
+
+// getProduct retrieves and normalizes the product information of the manifest
+// file identified by the given product ID.
+func (c *GitHubCrawler) getProduct(ctx context.Context, prdID models.ProductID, discoveredAt time.Time) (*models.Product, error) {
+	raw, err := c.getRawData(ctx, prdID, discoveredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// check mandatory fields
+	err = c.checkMandatory(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// get owner information
+	raw.owner, err = c.ghClient.GetUser(ctx, raw.repository.Owner.Login)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitHub owner information")
+	}
+
+	product, err := c.NormalizeProduct(ctx, raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize product information")
+	}
+
+	// validate product
+	err = c.validator.ValidateProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	// return normalized product
+	return product, nil
+}
+
+// GetProduct retrieves the product information of the manifest file
+// identified by the given product ID. If the path of the product ID is empty,
+// the first manifest file in the root directory of the repository is used.
+func (c *GitHubCrawler) GetProduct(ctx context.Context, productID models.ProductID) (*models.Product, error) {
+	product, err := c.getProduct(ctx, productID, time.Now())
+	if err != nil {
+		if vldErr, ok := err.(*validator.ValidationError); ok {
+			return nil, errors.Wrap(vldErr, "invalid product")
+		}
+		if errors.Is(err, ghclient.ErrNotFound) {
+			return nil, errors.Wrapf(crawler.ErrProductNotFound, "failed to get product '%s'", productID.String())
+		}
+		return nil, errors.Wrap(err, "failed to get product information")
+	}
+	return product, nil
+}
+
+// getRawData retrieves the repository information and the manifest file.
+func (c *GitHubCrawler) getRawData(ctx context.Context, prdID models.ProductID, discoveredAt time.Time) (*rawData, error) {
+	// get repository information
+	repo, err := c.ghClient.GetRepository(ctx, prdID.Owner, prdID.Repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitHub repository information")
+	}
+	commit, err := c.ghClient.GetCommit(ctx, prdID.Owner, prdID.Repo, repo.DefaultBranch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitHub commit information")
+	}
+	tree, err := c.ghClient.GetTree(ctx, prdID.Owner, prdID.Repo, commit.SHA)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitHub file tree")
+	}
+	files := make(map[string]struct{}, len(tree.Tree))
+	for _, entry := range tree.Tree {
+		if entry.IsFile() {
+			files[entry.Path] = struct{}{}
+		}
+	}
+
+	// find manifest file, if not specified
+	manifestPath := strings.Trim(prdID.Path, "/")
+	if manifestPath == "" {
+		for _, entry := range tree.Tree {
+			if !entry.IsFile() || strings.Contains(entry.Path, "/") {
+				continue
+			}
+			if manifest.IsManifestFile(entry.Path) {
+				manifestPath = entry.Path
+				break
+			}
+		}
+		if manifestPath == "" {
+			return nil, &manifest.ErrInvalidManifest{Path: "", Err: errors.New("no manifest file found")}
+		}
+	}
+
+	// download and parse manifest file
+	manifestURL := c.ghClient.ContentURL(prdID.Owner, prdID.Repo, commit.SHA, manifestPath)
+	content, err := c.fileDownloader.DownloadContentWithMaxSize(ctx, manifestURL, maxFileSizeManifest)
+	if err != nil {
+		if errors.Is(err, download.ErrNotFound) {
+			return nil, errors.Wrapf(crawler.ErrProductNotFound, "manifest file '%s' not found", manifestPath)
+		}
+		return nil, errors.Wrap(err, "failed to download manifest file")
+	}
+	mnf, err := manifest.Parse(manifestPath, content)
+	if err != nil {
+		return nil, &manifest.ErrInvalidManifest{Path: manifestPath, Err: err}
+	}
+	if mnf.Conversion != nil && len(mnf.Conversion.Lost) > 0 {
+		c.log.Debugf("converted legacy manifest '%s', lost fields: %s", manifestPath, strings.Join(mnf.Conversion.LostFieldNames(), ", "))
+	}
+
+	// download license file, if the manifest declares no license
+	licenseText := ""
+	if strings.TrimSpace(mnf.License) == "" {
+		if licensePath := services.FindLicenseFile(files, manifestDir(manifestPath)); licensePath != "" {
+			licenseURL := c.ghClient.ContentURL(prdID.Owner, prdID.Repo, commit.SHA, licensePath)
+			content, err := c.fileDownloader.DownloadContentWithMaxSize(ctx, licenseURL, maxFileSizeLicense)
+			if err != nil {
+				c.log.Debugw("failed to download license file", "url", licenseURL, "error", err)
+			} else {
+				licenseText = string(content)
+			}
+		}
+	}
+
+	return &rawData{
+		timestamp:    discoveredAt,
+		repository:   repo,
+		commit:       commit,
+		files:        files,
+		manifestPath: manifestPath,
+		manifest:     mnf,
+		licenseText:  licenseText,
+	}, nil
+}
+
+// loadState loads the state of the discovery run from the state store. A new
+// state is returned, if none is stored.
+func (c *GitHubCrawler) loadState(ctx context.Context) (CrawlerState, error) {
+	// new state
+	state := CrawlerState{
+		StartTime: time.Now(),
+		Query:     0,
+		Page:      1,
+	}
+	_, err := c.stateStore.Load(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), &state)
+	if err != nil {
+		return CrawlerState{}, err
+	}
+	return state, nil
+}
+
+// saveState saves the state of the discovery run to the state store.
+func (c *GitHubCrawler) saveState(ctx context.Context, state CrawlerState) error {
+	return c.stateStore.Save(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), state)
+}
+
+// checkMandatory checks if mandatory fields are present.
+func (c *GitHubCrawler) checkMandatory(raw *rawData) error {
+	mnf := raw.manifest
+	filePaths := make([]string, 0, len(raw.files))
+	dir := manifestDir(raw.manifestPath)
+	for path := range raw.files {
+		// only files next to the manifest are considered
+		rel := strings.TrimPrefix(path, dir)
+		if dir != "" && rel == path {
+			continue
+		}
+		filePaths = append(filePaths, rel)
+	}
+
+	// quick check if mandatory fields are present
+	mdtFlds := validator.MandatoryFields{
+		OKHV:                  mnf.OKHV,
+		Name:                  mnf.Name,
+		Description:           mnf.Function,
+		Version:               mnf.Version,
+		Repository:            mnf.Repo,
+		License:               strings.TrimSpace(mnf.License),
+		Licensor:              mnf.Licensor,
+		DocumentationLanguage: mnf.DocumentationLanguage,
+		FilePaths:             filePaths,
+	}
+	return c.validator.ValidateMandatory(mdtFlds)
+}
+
+// isSkippable returns true if the product can be skipped during discovery,
+// because the error is caused by the product itself and not by the crawler.
+func isSkippable(err error) bool {
+	var (
+		vldErr      *validator.ValidationError
+		mnfErr      *manifest.ErrInvalidManifest
+		tooLargeErr *download.ErrTooLarge
+	)
+	return errors.As(err, &vldErr) ||
+		errors.As(err, &mnfErr) ||
+		errors.As(err, &tooLargeErr) ||
+		errors.Is(err, ghclient.ErrNotFound) ||
+		errors.Is(err, crawler.ErrProductNotFound)
+}
+
+// calcWaitTime calculates the wait time before retrying a failed request. It
+// takes the primary and secondary rate limits of GitHub into account.
+func calcWaitTime(resp *http.Response, delay time.Duration) time.Duration {
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// primary rate limit exceeded -> wait until reset
+		if resp.Header.Get(n.HdrXRateLimitRemainingKey) == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get(n.HdrXRateLimitResetKey), 10, 64)
+			if err == nil {
+				if wait := time.Unix(reset, 0).Sub(time.Now()); wait > 0 {
+					return wait + 1*time.Second
+				}
+				return delay
+			}
+		}
+		// secondary rate limit exceeded -> respect Retry-After
+		if resp.Header.Get(n.HdrRetryAfterKey) != "" {
+			return request.RetryAfter(resp, delay)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return delay
+		}
+		return retry.Stop
+
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return delay
+	}
+	return retry.Stop
+}
@@ -0,0 +1,235 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log/logtest"
+
+	"github.com/aisbergg/go-retry/pkg/retry"
+
+	n "losh/internal/lib/net"
+)
+
+const (
+	testToken = "secret"
+	testSHA   = "0123456789abcdef"
+)
+
+const testManifest = `okhv = "OKH-LOSHv1.0"
+name = "Motor Driver"
+repo = "https://github.com/example/motor-driver"
+version = "1.2.0"
+license = "CERN-OHL-S-2.0"
+licensor = "Jane Doe"
+function = "Drives two brushed DC motors."
+documentation-language = "en"
+technology-readiness-level = "OTRL-4"
+documentation-readiness-level = "ODRL-3"
+source = ["board.kicad_pcb"]
+`
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+// newTestServer creates a server, that serves the repository
+// `example/motor-driver` like the GitHub API. All requests, including file
+// downloads, must be authenticated.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set(n.HdrContentTypeKey, "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	owner := map[string]interface{}{"login": "example", "type": "Organization", "html_url": "https://github.com/example"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/example/motor-driver", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name":             "motor-driver",
+			"full_name":        "example/motor-driver",
+			"owner":            owner,
+			"html_url":         "https://github.com/example/motor-driver",
+			"default_branch":   "main",
+			"stargazers_count": 42,
+			"forks_count":      3,
+			"created_at":       "2022-01-01T00:00:00Z",
+		})
+	})
+	mux.HandleFunc("/repos/example/motor-driver/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"sha":    testSHA,
+			"commit": map[string]interface{}{"committer": map[string]interface{}{"date": "2022-05-01T00:00:00Z"}},
+		})
+	})
+	mux.HandleFunc("/repos/example/motor-driver/git/trees/"+testSHA, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"sha": testSHA, "tree": []map[string]interface{}{
+			{"path": "okh.toml", "type": "blob"},
+			{"path": "README.md", "type": "blob"},
+			{"path": "board.kicad_pcb", "type": "blob"},
+			{"path": "docs", "type": "tree"},
+		}})
+	})
+	mux.HandleFunc("/repos/example/motor-driver/contents/okh.toml", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != testSHA {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testManifest))
+	})
+	mux.HandleFunc("/users/example", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, owner)
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(n.HdrAuthorizationKey) != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set(n.HdrXRateLimitRemainingKey, "4999")
+		w.Header().Set(n.HdrXRateLimitResetKey, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+// newTestCrawler creates a crawler, that uses the given server as GitHub API.
+func newTestCrawler(srv *httptest.Server, repo services.Repository) *GitHubCrawler {
+	svc := services.NewService(repo)
+	xid, name := "CERN-OHL-S-2.0", "CERN Open Hardware Licence Version 2 - Strongly Reciprocal"
+	svc.LoadLicenses([]*models.License{{ID: p("0x1"), Xid: &xid, Name: &name}}, nil)
+	return NewGitHubCrawler(svc, config.GitHubConfig{
+		APIURL:          srv.URL,
+		Token:           testToken,
+		RateLimit:       config.RateLimitConfig{Interval: time.Millisecond},
+		SearchRateLimit: config.RateLimitConfig{Interval: time.Millisecond},
+	})
+}
+
+func TestGetProduct(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	c := newTestCrawler(srv, nil)
+
+	prd, err := c.GetProduct(context.Background(), models.NewProductID(crawlerName, "example", "motor-driver", "okh.toml"))
+	if err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+	if *prd.Xid != "github.com/example/motor-driver/okh.toml" {
+		t.Errorf("Xid = %s", *prd.Xid)
+	}
+	if prd.StarCount == nil || *prd.StarCount != 42 {
+		t.Errorf("StarCount = %v, want 42", prd.StarCount)
+	}
+	if prd.License == nil || *prd.License.Xid != "CERN-OHL-S-2.0" {
+		t.Errorf("License = %v, want CERN-OHL-S-2.0", prd.License)
+	}
+
+	// the stored product is identified by its data source
+	prdID, err := storedProductID(prd)
+	if err != nil {
+		t.Fatalf("storedProductID() error = %v", err)
+	}
+	if prdID.String() != "github.com/example/motor-driver/okh.toml" {
+		t.Errorf("storedProductID() = %s", prdID.String())
+	}
+}
+
+func TestGetProductUnauthenticated(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	c := newTestCrawler(srv, nil)
+	c.ghClient = NewGitHubCrawler(c.productService, config.GitHubConfig{APIURL: srv.URL}).ghClient
+
+	_, err := c.GetProduct(context.Background(), models.NewProductID(crawlerName, "example", "motor-driver", "okh.toml"))
+	if err == nil || isSkippable(err) {
+		t.Errorf("GetProduct() error = %v, want a request error", err)
+	}
+}
+
+// indexedRepo is a repository, that returns the stored products to update.
+type indexedRepo struct {
+	services.Repository
+	products []*models.Product
+	offsets  []int64
+}
+
+func (r *indexedRepo) GetProductsIndexedBefore(ctx context.Context, hostDomain string, indexedBefore time.Time, first, offset int64) ([]*models.Product, error) {
+	r.offsets = append(r.offsets, offset)
+	if offset >= int64(len(r.products)) {
+		return nil, nil
+	}
+	return r.products[offset:], nil
+}
+
+// TestUpdateProductsSkipsRemoved checks that products, that cannot be
+// re-indexed, are skipped instead of being requested again.
+func TestUpdateProductsSkipsRemoved(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	url, path := "https://github.com/example/removed", "okh.toml"
+	repo := &indexedRepo{products: []*models.Product{
+		{ID: p("0x1"), Xid: p("github.com/example/removed/okh.toml"), DataSource: &models.Repository{URL: &url, Path: &path}},
+		{ID: p("0x2"), Xid: p("github.com/example/unknown")},
+	}}
+
+	if err := newTestCrawler(srv, repo).UpdateProducts(context.Background()); err != nil {
+		t.Fatalf("UpdateProducts() error = %v", err)
+	}
+	if len(repo.offsets) != 2 || repo.offsets[0] != 0 || repo.offsets[1] != 2 {
+		t.Errorf("requested offsets %v, want [0 2]", repo.offsets)
+	}
+}
+
+func TestCalcWaitTime(t *testing.T) {
+	delay := 3 * time.Second
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	tests := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{"primary rate limit", http.StatusForbidden, map[string]string{n.HdrXRateLimitRemainingKey: "0", n.HdrXRateLimitResetKey: reset}, 55 * time.Second, 62 * time.Second},
+		{"secondary rate limit", http.StatusForbidden, map[string]string{n.HdrRetryAfterKey: "10"}, 10 * time.Second, 10 * time.Second},
+		{"too many requests", http.StatusTooManyRequests, nil, delay, delay},
+		{"server error", http.StatusBadGateway, nil, delay, delay},
+		{"forbidden", http.StatusForbidden, map[string]string{n.HdrXRateLimitRemainingKey: "10", n.HdrXRateLimitResetKey: reset}, retry.Stop, retry.Stop},
+		{"not found", http.StatusNotFound, nil, retry.Stop, retry.Stop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+			if got := calcWaitTime(resp, delay); got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("calcWaitTime() = %s, want between %s and %s", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
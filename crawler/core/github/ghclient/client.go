@@ -0,0 +1,140 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghclient
+
+import (
+	"context"
+	"net/http"
+	gourl "net/url"
+	"strconv"
+
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultBaseURL is the base URL of the public GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// ErrNotFound is returned if the requested resource does not exist.
+var ErrNotFound = errors.New("resource not found")
+
+// Client is a minimal client for the GitHub REST API.
+type Client struct {
+	restClient      *resty.Client
+	requester       *request.RESTRequester
+	searchRequester *request.RESTRequester
+}
+
+// NewClient creates a new GitHub REST API client. The REST client is expected
+// to be configured with the base URL and authentication of the API. Search
+// requests are executed with a separate requester, because GitHub applies a
+// different rate limit to them.
+func NewClient(restClient *resty.Client, requester, searchRequester *request.RESTRequester) *Client {
+	return &Client{
+		restClient:      restClient,
+		requester:       requester,
+		searchRequester: searchRequester,
+	}
+}
+
+// SearchCode searches for files matching the given query.
+func (c *Client) SearchCode(ctx context.Context, query string, page, perPage int) (*SearchCodeResult, error) {
+	result := &SearchCodeResult{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetQueryParam("q", query).
+		SetQueryParam("page", strconv.Itoa(page)).
+		SetQueryParam("per_page", strconv.Itoa(perPage)).
+		SetResult(result)
+	if err := c.do(c.searchRequester, req, "/search/code"); err != nil {
+		return nil, errors.Wrap(err, "failed to search code")
+	}
+	return result, nil
+}
+
+// GetRepository returns the repository with the given owner and name.
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	result := &Repository{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetResult(result)
+	if err := c.do(c.requester, req, "/repos/"+gourl.PathEscape(owner)+"/"+gourl.PathEscape(repo)); err != nil {
+		return nil, errors.Wrap(err, "failed to get repository")
+	}
+	return result, nil
+}
+
+// GetCommit returns the commit that the given reference points to.
+func (c *Client) GetCommit(ctx context.Context, owner, repo, ref string) (*Commit, error) {
+	result := &Commit{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetResult(result)
+	if err := c.do(c.requester, req, "/repos/"+gourl.PathEscape(owner)+"/"+gourl.PathEscape(repo)+"/commits/"+gourl.PathEscape(ref)); err != nil {
+		return nil, errors.Wrap(err, "failed to get commit")
+	}
+	return result, nil
+}
+
+// GetTree returns the recursive file tree of the given reference.
+func (c *Client) GetTree(ctx context.Context, owner, repo, ref string) (*Tree, error) {
+	result := &Tree{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetQueryParam("recursive", "1").
+		SetResult(result)
+	if err := c.do(c.requester, req, "/repos/"+gourl.PathEscape(owner)+"/"+gourl.PathEscape(repo)+"/git/trees/"+gourl.PathEscape(ref)); err != nil {
+		return nil, errors.Wrap(err, "failed to get file tree")
+	}
+	return result, nil
+}
+
+// GetUser returns the user or organization with the given login.
+func (c *Client) GetUser(ctx context.Context, login string) (*User, error) {
+	result := &User{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetResult(result)
+	if err := c.do(c.requester, req, "/users/"+gourl.PathEscape(login)); err != nil {
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+	return result, nil
+}
+
+// ContentURL returns the API URL to download the raw content of a file.
+func (c *Client) ContentURL(owner, repo, ref, path string) string {
+	u := c.restClient.BaseURL + "/repos/" + gourl.PathEscape(owner) + "/" + gourl.PathEscape(repo) + "/contents/" + (&gourl.URL{Path: path}).EscapedPath()
+	if ref != "" {
+		u += "?ref=" + gourl.QueryEscape(ref)
+	}
+	return u
+}
+
+// do executes a GET request and checks the response status.
+func (c *Client) do(requester *request.RESTRequester, req *resty.Request, path string) error {
+	resp, err := requester.Do(request.NewRESTRequest(req, http.MethodGet, path))
+	if resp != nil && resp.StatusCode() == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return errors.Errorf("request failed with status code %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"losh/internal/lib/net/ratelimit"
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/go-resty/resty/v2"
+
+	n "losh/internal/lib/net"
+)
+
+const testToken = "secret"
+
+// newTestClient creates a client for the given test server, that
+// authenticates with a token and respects the reported rate limits.
+func newTestClient(srv *httptest.Server) *Client {
+	restClient := resty.NewWithClient(srv.Client()).
+		SetBaseURL(srv.URL).
+		SetAuthToken(testToken)
+	requester := request.NewRESTRequester(restClient).
+		SetRetryCount(0).
+		SetMaxWaitTime(time.Minute).
+		AddRateLimiter(ratelimit.NewHeaderRateLimiter(n.HdrXRateLimitRemainingKey, n.HdrXRateLimitResetKey))
+	return NewClient(restClient, requester, requester)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set(n.HdrContentTypeKey, "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(n.HdrAuthorizationKey) != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{"login": "octocat", "type": "Organization"})
+	}))
+	defer srv.Close()
+
+	user, err := newTestClient(srv).GetUser(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.Login != "octocat" || !user.IsOrganization() {
+		t.Errorf("GetUser() = %+v", user)
+	}
+}
+
+func TestSearchCodePagination(t *testing.T) {
+	const total, perPage = 5, 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/code" || r.URL.Query().Get("q") != "filename:okh" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		items := []map[string]interface{}{}
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			items = append(items, map[string]interface{}{"path": strconv.Itoa(i) + "/okh.toml"})
+		}
+		writeJSON(t, w, map[string]interface{}{"total_count": total, "items": items})
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv)
+	paths := []string{}
+	for page := 1; ; page++ {
+		result, err := client.SearchCode(context.Background(), "filename:okh", page, perPage)
+		if err != nil {
+			t.Fatalf("SearchCode() error = %v", err)
+		}
+		for _, item := range result.Items {
+			paths = append(paths, item.Path)
+		}
+		if len(result.Items) < perPage {
+			break
+		}
+	}
+	if len(paths) != total || paths[0] != "0/okh.toml" || paths[total-1] != "4/okh.toml" {
+		t.Errorf("SearchCode() found %v, want %d files", paths, total)
+	}
+}
+
+func TestGetRepositoryNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := newTestClient(srv).GetRepository(context.Background(), "octocat", "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRepository() error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestRateLimitHeaders checks that no further requests are sent after the
+// server reported an exhausted rate limit.
+func TestRateLimitHeaders(t *testing.T) {
+	var numRequests int32
+	reset := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&numRequests, 1)
+		w.Header().Set(n.HdrXRateLimitRemainingKey, "0")
+		w.Header().Set(n.HdrXRateLimitResetKey, strconv.FormatInt(reset, 10))
+		writeJSON(t, w, map[string]interface{}{"sha": "abc"})
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv)
+	if _, err := client.GetCommit(context.Background(), "octocat", "hello", "main"); err != nil {
+		t.Fatalf("GetCommit() error = %v", err)
+	}
+	// the wait until the reset exceeds the max wait time of the requester
+	if _, err := client.GetCommit(context.Background(), "octocat", "hello", "main"); err == nil {
+		t.Errorf("GetCommit() succeeded despite exhausted rate limit")
+	}
+	if got := atomic.LoadInt32(&numRequests); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+func TestContentURL(t *testing.T) {
+	client := NewClient(resty.New().SetBaseURL(DefaultBaseURL), nil, nil)
+	got := client.ContentURL("octocat", "hello", "abc", "docs/read me.md")
+	want := DefaultBaseURL + "/repos/octocat/hello/contents/docs/read%20me.md?ref=abc"
+	if got != want {
+		t.Errorf("ContentURL() = %s, want %s", got, want)
+	}
+}
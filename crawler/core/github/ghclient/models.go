@@ -0,0 +1,107 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghclient
+
+import "time"
+
+// SearchCodeResult is the result of a code search.
+type SearchCodeResult struct {
+	TotalCount        int64             `json:"total_count"`
+	IncompleteResults bool              `json:"incomplete_results"`
+	Items             []*SearchCodeItem `json:"items"`
+}
+
+// SearchCodeItem is a single file found by the code search.
+type SearchCodeItem struct {
+	Name       string      `json:"name"`
+	Path       string      `json:"path"`
+	SHA        string      `json:"sha"`
+	HTMLURL    string      `json:"html_url"`
+	Repository *Repository `json:"repository"`
+}
+
+// Repository contains the information about a GitHub repository.
+type Repository struct {
+	ID              int64       `json:"id"`
+	NodeID          string      `json:"node_id"`
+	Name            string      `json:"name"`
+	FullName        string      `json:"full_name"`
+	Owner           *User       `json:"owner"`
+	HTMLURL         string      `json:"html_url"`
+	Description     *string     `json:"description"`
+	Homepage        *string     `json:"homepage"`
+	Fork            bool        `json:"fork"`
+	Archived        bool        `json:"archived"`
+	Disabled        bool        `json:"disabled"`
+	DefaultBranch   string      `json:"default_branch"`
+	Topics          []string    `json:"topics"`
+	StargazersCount int64       `json:"stargazers_count"`
+	ForksCount      int64       `json:"forks_count"`
+	CreatedAt       *time.Time  `json:"created_at"`
+	UpdatedAt       *time.Time  `json:"updated_at"`
+	PushedAt        *time.Time  `json:"pushed_at"`
+	Parent          *Repository `json:"parent"`
+}
+
+// User contains the information about a GitHub user or organization.
+type User struct {
+	ID        int64   `json:"id"`
+	Login     string  `json:"login"`
+	Type      string  `json:"type"`
+	Name      *string `json:"name"`
+	Email     *string `json:"email"`
+	Bio       *string `json:"bio"`
+	Blog      *string `json:"blog"`
+	AvatarURL string  `json:"avatar_url"`
+	HTMLURL   string  `json:"html_url"`
+}
+
+// IsOrganization returns true if the user is an organization.
+func (u *User) IsOrganization() bool {
+	return u.Type == "Organization"
+}
+
+// Commit contains the information about a single commit.
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Date *time.Time `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Date *time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// Tree contains the files of a repository at a specific commit.
+type Tree struct {
+	SHA       string       `json:"sha"`
+	Truncated bool         `json:"truncated"`
+	Tree      []*TreeEntry `json:"tree"`
+}
+
+// TreeEntry is a single file or directory of a tree.
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+	Size int64  `json:"size"`
+}
+
+// IsFile returns true if the entry is a regular file.
+func (e *TreeEntry) IsFile() bool {
+	return e.Type == "blob"
+}
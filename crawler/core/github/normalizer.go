@@ -0,0 +1,464 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/crawler/core/github/ghclient"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+
+	"github.com/abadojack/whatlanggo"
+)
+
+var host = &models.Host{
+	Domain: p("github.com"),
+	Name:   p("GitHub"),
+}
+var activeTimeThreshold = 2 * 365 * 24 * time.Hour // 2 years
+
+type rawData struct {
+	timestamp  time.Time
+	repository *ghclient.Repository
+	commit     *ghclient.Commit
+	owner      *ghclient.User
+	// paths of all files in the repository
+	files        map[string]struct{}
+	manifestPath string
+	manifest     *manifest.Manifest
+	// content of the license file, if the manifest declares no license
+	licenseText string
+}
+
+// NormalizeProduct creates a normalized product from the given GitHub
+// repository information and manifest file.
+func (c *GitHubCrawler) NormalizeProduct(ctx context.Context, raw *rawData) (*models.Product, error) {
+	product := &models.Product{}
+	repo := raw.repository
+
+	// release
+	licensor := c.normLicensor(raw)
+	crawlerMeta := &models.CrawlerMetaImpl{
+		DiscoveredAt:  &raw.timestamp,
+		LastIndexedAt: &raw.timestamp,
+		DataSource:    c.normRepository(raw, licensor),
+	}
+	release := c.normRelease(ctx, raw, licensor, crawlerMeta)
+
+	// crawler info
+	product.DiscoveredAt = release.DiscoveredAt
+	product.LastIndexedAt = release.LastIndexedAt
+	product.DataSource = release.DataSource
+
+	// product info
+	// Xid format: domain.tld/owner/repo/file-path
+	product.Xid = asXid(*host.Domain, repo.Owner.Login, repo.Name, raw.manifestPath)
+	// PlatformID format: domain.tld/repo-id/file-path
+	product.PlatformID = asXid(*host.Domain, strconv.FormatInt(repo.ID, 10), raw.manifestPath)
+	product.Name = release.Name
+	product.Description = release.Description
+	product.DocumentationLanguage = release.DocumentationLanguage
+	product.Version = release.Version
+	product.License = release.License
+	product.Licensor = licensor
+	product.Website = stringOrNil(sp(repo.Homepage))
+	if product.Website == nil {
+		product.Website = product.DataSource.URL
+	}
+	product.State = c.normState(repo)
+	product.LastUpdatedAt = repo.PushedAt
+	product.Release = release
+	product.Releases = []*models.Component{release}
+	release.Product = product
+
+	// forks are linked to their origin through the inverse of ForkOf
+	product.ForkOf = c.normForkOf(raw)
+	product.ForkCount = &repo.ForksCount
+	product.StarCount = &repo.StargazersCount
+	product.Tags = normTags(repo.Topics)
+
+	return product, nil
+}
+
+func (c *GitHubCrawler) normState(repo *ghclient.Repository) *dgclient.ProductState {
+	state := dgclient.ProductStateUndetermined
+	if repo.Archived {
+		state = dgclient.ProductStateArchived
+	} else if repo.PushedAt != nil && time.Now().Sub(*repo.PushedAt) < activeTimeThreshold {
+		state = dgclient.ProductStateActive
+	} else {
+		state = dgclient.ProductStateInactive
+	}
+	return &state
+}
+
+// normForkOf returns the product, that the repository was forked from. As the
+// origin might not be indexed yet, a placeholder is returned. It refers to the
+// manifest file at the same path as in the fork.
+func (c *GitHubCrawler) normForkOf(raw *rawData) *models.Product {
+	parent := raw.repository.Parent
+	if !raw.repository.Fork || parent == nil || parent.Owner == nil {
+		return nil
+	}
+	productURL := &models.ProductURL{
+		Domain: *host.Domain,
+		Owner:  parent.Owner.Login,
+		Repo:   parent.Name,
+		Ref:    parent.DefaultBranch,
+		Path:   raw.manifestPath,
+	}
+	dataSource := &models.Repository{
+		// Xid format: domain.tld/owner/repo/ref/file-path
+		Xid:       asXid(productURL.Domain, productURL.Owner, productURL.Repo, productURL.Ref, productURL.Path),
+		URL:       p(productURL.RepositoryURL()),
+		PermaURL:  p(productURL.PermaURL()),
+		Host:      host,
+		Name:      p(parent.Name),
+		Reference: p(parent.DefaultBranch),
+		Path:      p(raw.manifestPath),
+	}
+	// Xid format: domain.tld/owner/repo/file-path
+	xid := asXid(productURL.Domain, productURL.Owner, productURL.Repo, productURL.Path)
+	return models.NewProductPlaceholder(*xid, parent.Name, dataSource, raw.timestamp)
+}
+
+// normRelease returns the release described by the manifest file.
+func (c *GitHubCrawler) normRelease(ctx context.Context, raw *rawData, licensor models.UserOrGroup, crawlerMeta *models.CrawlerMetaImpl) *models.Component {
+	mnf := raw.manifest
+	repo := raw.repository
+
+	fileResolver := func(ref string) *models.File {
+		return c.normFile(raw, ref, crawlerMeta)
+	}
+	release := mnf.ToComponent(fileResolver, c.productService.ResolveLicenseExpression)
+	release.DiscoveredAt = crawlerMeta.DiscoveredAt
+	release.LastIndexedAt = crawlerMeta.LastIndexedAt
+	release.DataSource = crawlerMeta.DataSource
+	if release.License == nil && raw.licenseText != "" {
+		// fall back to the license file, if the manifest declares no license
+		if license := c.productService.DetectLicense(ctx, raw.licenseText); license != nil {
+			release.License = license
+			release.LicenseExpression = license.Xid
+		}
+	}
+
+	// Xid format: domain.tld/owner/repo/ref/file-path/component-name
+	release.Xid = asXid(*host.Domain, repo.Owner.Login, repo.Name, s(mnf.Version), raw.manifestPath, s(mnf.Name))
+	if release.Description == nil {
+		release.Description = stringOrNil(sp(repo.Description))
+	}
+	release.CreatedAt = raw.commit.Commit.Committer.Date
+	release.Releases = []*models.Component{release}
+	release.IsLatest = p(true)
+	release.Repository = crawlerMeta.DataSource
+	release.Licensor = licensor
+	release.DocumentationLanguage = c.normDocumentationLanguage(mnf.DocumentationLanguage, release.Description)
+	for _, sw := range release.Software {
+		sw.DiscoveredAt = crawlerMeta.DiscoveredAt
+		sw.LastIndexedAt = crawlerMeta.LastIndexedAt
+		sw.DataSource = crawlerMeta.DataSource
+	}
+
+	// info files that are not referenced in the manifest are looked up by name
+	release.Readme = c.normInfoFile(raw, mnf.Readme, []string{"README"}, crawlerMeta)
+	release.ContributionGuide = c.normInfoFile(raw, mnf.ContributionGuide, []string{"CONTRIBUTING"}, crawlerMeta)
+	release.Bom = c.normInfoFile(raw, mnf.Bom, []string{"BOM", "BILLOFMATERIALS"}, crawlerMeta)
+	release.ManufacturingInstructions = c.normInfoFile(raw, mnf.ManufacturingInstructions, []string{"MANUFACTURINGINSTRUCTIONS", "MANUFACTURING"}, crawlerMeta)
+	release.UserManual = c.normInfoFile(raw, mnf.UserManual, []string{"USERGUIDE", "USERMANUAL"}, crawlerMeta)
+
+	normSubComponents(release, crawlerMeta)
+
+	return release
+}
+
+// normSubComponents completes the information of the sub-components described
+// by the parts of the manifest. The information not contained in the manifest
+// is inherited from the parent component.
+func normSubComponents(parent *models.Component, crawlerMeta *models.CrawlerMetaImpl) {
+	for _, cmp := range parent.Components {
+		cmp.DiscoveredAt = crawlerMeta.DiscoveredAt
+		cmp.LastIndexedAt = crawlerMeta.LastIndexedAt
+		cmp.DataSource = crawlerMeta.DataSource
+
+		// Xid format: domain.tld/owner/repo/ref/file-path/component-name/part-name
+		cmp.Xid = p(*parent.Xid + "/" + *asXid(s(*cmp.Name)))
+		if cmp.Description == nil {
+			cmp.Description = parent.Description
+		}
+		if cmp.DocumentationLanguage == nil {
+			cmp.DocumentationLanguage = parent.DocumentationLanguage
+		}
+		cmp.CreatedAt = parent.CreatedAt
+		cmp.IsLatest = parent.IsLatest
+		cmp.Repository = parent.Repository
+		if cmp.License == nil {
+			cmp.License = parent.License
+			cmp.LicenseExpression = parent.LicenseExpression
+		}
+		cmp.Licensor = parent.Licensor
+
+		normSubComponents(cmp, crawlerMeta)
+	}
+}
+
+// normRepository returns the source of the component.
+func (c *GitHubCrawler) normRepository(raw *rawData, owner models.UserOrGroup) *models.Repository {
+	repo := raw.repository
+	productURL := &models.ProductURL{
+		Domain: *host.Domain,
+		Owner:  repo.Owner.Login,
+		Repo:   repo.Name,
+		Ref:    raw.commit.SHA,
+		Path:   raw.manifestPath,
+	}
+	return &models.Repository{
+		// Xid format: domain.tld/owner/repo/ref/file-path
+		Xid:       asXid(productURL.Domain, productURL.Owner, productURL.Repo, productURL.Ref, productURL.Path),
+		URL:       p(productURL.RepositoryURL()),
+		PermaURL:  p(productURL.PermaURL()),
+		Host:      host,
+		Owner:     owner,
+		Name:      p(repo.Name),
+		Reference: p(raw.commit.SHA),
+		Path:      p(raw.manifestPath),
+	}
+}
+
+// normDocumentationLanguage returns the documentation language of the
+// product. If not specified in the manifest, the language is guessed from the
+// description.
+func (c *GitHubCrawler) normDocumentationLanguage(lang string, description *string) *string {
+	lang = strings.TrimSpace(lang)
+	if lang != "" {
+		return &lang
+	}
+	defaultLang := "en"
+	if description == nil || *description == "" {
+		return &defaultLang
+	}
+	lang = whatlanggo.DetectLang(*description).Iso6391()
+	if lang == "" {
+		return &defaultLang
+	}
+	return &lang
+}
+
+// normFile returns the file referenced in the manifest. References are either
+// paths relative to the manifest file or URLs pointing into the same
+// repository. Files that don't exist in the repository are ignored.
+func (c *GitHubCrawler) normFile(raw *rawData, ref string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	repo := raw.repository
+
+	var filePath string
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		prdURL, err := models.NewProductURLFromURL(ref)
+		if err != nil || prdURL.Domain != *host.Domain ||
+			!strings.EqualFold(prdURL.Owner, repo.Owner.Login) ||
+			!strings.EqualFold(prdURL.Repo, repo.Name) {
+			return nil
+		}
+		filePath = prdURL.Path
+	} else if strings.HasPrefix(ref, "/") {
+		filePath = path.Clean(strings.TrimLeft(ref, "/"))
+	} else {
+		filePath = path.Clean(path.Join(manifestDir(raw.manifestPath), ref))
+	}
+	if _, ok := raw.files[filePath]; !ok {
+		return nil
+	}
+
+	fileURL := &models.ProductURL{
+		Domain: *host.Domain,
+		Owner:  repo.Owner.Login,
+		Repo:   repo.Name,
+		Ref:    raw.commit.SHA,
+		Path:   filePath,
+	}
+	file := &models.File{}
+	file.Path = p(filePath)
+	file.Name = p(path.Base(filePath))
+	file.MimeType = stringOrNil(p(mime.TypeByExtension(path.Ext(filePath))))
+	file.URL = p(fileURL.PermaURL())
+	file.DiscoveredAt = crawlerMeta.DiscoveredAt
+	file.LastIndexedAt = crawlerMeta.LastIndexedAt
+	file.DataSource = crawlerMeta.DataSource
+	// Xid format: domain.tld/owner/repo/ref/file-path
+	file.Xid = asXid(*host.Domain, repo.Owner.Login, repo.Name, s(raw.manifest.Version), filePath)
+
+	return file
+}
+
+// normInfoFile returns the info file of the product. If the file is not
+// referenced in the manifest, then it is looked up by name in the directory
+// of the manifest file.
+func (c *GitHubCrawler) normInfoFile(raw *rawData, ref string, names []string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	if file := c.normFile(raw, ref, crawlerMeta); file != nil {
+		return file
+	}
+	dir := manifestDir(raw.manifestPath)
+	for filePath := range raw.files {
+		if manifestDir(filePath) != dir {
+			continue
+		}
+		filename := path.Base(filePath)
+		if pos := strings.LastIndexByte(filename, '.'); pos != -1 {
+			filename = filename[:pos]
+		}
+		filename = strings.TrimSpace(filename)
+		filename = strings.Replace(filename, " ", "", -1)
+		filename = strings.Replace(filename, "-", "", -1)
+		filename = strings.Replace(filename, "_", "", -1)
+		filename = strings.ToUpper(filename)
+		for _, name := range names {
+			if filename == name {
+				return c.normFile(raw, "/"+filePath, crawlerMeta)
+			}
+		}
+	}
+	return nil
+}
+
+// normLicensor returns the owner of the repository.
+func (c *GitHubCrawler) normLicensor(raw *rawData) models.UserOrGroup {
+	owner := raw.owner
+	if owner == nil {
+		owner = raw.repository.Owner
+	}
+	xid := asXid(*host.Domain, owner.Login)
+	url := "https://" + *xid
+
+	if owner.IsOrganization() {
+		return &models.Group{
+			Xid:         xid,
+			Host:        host,
+			Name:        p(owner.Login),
+			FullName:    stringOrNil(sp(owner.Name)),
+			Email:       stringOrNil(sp(owner.Email)),
+			Description: stringOrNil(sp(owner.Bio)),
+			Members:     []models.UserOrGroup{}, //TODO
+			URL:         &url,
+		}
+	}
+	return &models.User{
+		Xid:         xid,
+		Host:        host,
+		Name:        p(owner.Login),
+		FullName:    stringOrNil(sp(owner.Name)),
+		Email:       stringOrNil(sp(owner.Email)),
+		Description: stringOrNil(sp(owner.Bio)),
+		URL:         &url,
+	}
+}
+
+// TODO: make this better, e.g. https://github.com/abhinav/goldmark-hashtag
+// https://meta.stackexchange.com/a/298981
+var validTagPattern = regexp.MustCompile(`^[a-z0-9_.+-]+$`)
+
+// normTags parses the repository topics into a slice of tags.
+func normTags(topics []string) []*models.Tag {
+	if len(topics) == 0 {
+		return nil
+	}
+	tags := make([]*models.Tag, 0, len(topics))
+	for _, topic := range topics {
+		name := strings.ToLower(strings.TrimSpace(topic))
+		if len(name) == 0 ||
+			len(name) > 128 ||
+			!validTagPattern.MatchString(name) {
+			continue
+		}
+		tags = append(tags, &models.Tag{
+			Name: &name,
+		})
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// manifestDir returns the directory of the manifest file including a trailing
+// slash or an empty string if the manifest is located in the root directory.
+func manifestDir(manifestPath string) string {
+	dir := path.Dir(manifestPath)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// stringOrNil returns the string pointer if it is non nil and contains a string
+// else returns nil.
+func stringOrNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sp(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	*s = strings.TrimSpace(*s)
+	return s
+}
+
+func s(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func p[T any](v T) *T {
+	return &v
+}
+
+func asXid(args ...string) *string {
+	if args == nil || len(args) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(args); i++ {
+		if i > 0 {
+			b.WriteString("/")
+		}
+		if args[i] == "" {
+			b.WriteString("-")
+		} else {
+			b.WriteString(url.PathEscape(args[i]))
+		}
+	}
+	xid := b.String()
+	return &xid
+}
@@ -0,0 +1,676 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	gourl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/gitlab/glclient"
+	"losh/crawler/core/validator"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	lerrors "losh/internal/lib/errors"
+	"losh/internal/lib/log"
+	"losh/internal/lib/net/download"
+	"losh/internal/lib/net/ratelimit"
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/aisbergg/go-retry/pkg/retry"
+	"github.com/go-resty/resty/v2"
+
+	"go.uber.org/zap"
+
+	n "losh/internal/lib/net"
+	"losh/internal/lib/unit"
+)
+
+// Constants that define the crawler behavior.
+const (
+	timeout             = time.Duration(5) * time.Minute
+	perPage             = 100
+	maxTags             = 10 // number of most recent tags turned into releases
+	maxTreePages        = 50 // limits the file tree to 5000 entries
+	retries             = 5
+	maxFileSizeManifest = 10 * unit.MiB
+	maxFileSizeLicense  = 1 * unit.MiB
+	maxWaitTime         = 5 * time.Minute
+	maxRedirects        = 5
+	updateBatchSize     = 100
+	defaultUpdateMaxAge = 7 * 24 * time.Hour
+)
+
+type CrawlerState struct {
+	StartTime   time.Time     `json:"startTime"`
+	ElapsedTime time.Duration `json:"elapsedTime"`
+	NumCrawled  int64         `json:"numCrawled"`
+	NumIndexed  int64         `json:"numIndexed"`
+	IDAfter     int64         `json:"idAfter"`
+}
+
+// GitLabCrawler is a product crawler for a GitLab instance. Besides gitlab.com
+// it can be used for self-hosted instances as well.
+type GitLabCrawler struct {
+	productService *services.Service
+	validator      *validator.Validator
+
+	host           *models.Host
+	baseURL        string
+	fileDownloader *download.Downloader
+	glClient       *glclient.Client
+	log            *zap.SugaredLogger
+
+	stateStore   crawler.StateStore
+	reconciler   *crawler.Reconciler
+	updateMaxAge time.Duration
+}
+
+// NewGitLabCrawler creates a new GitLabCrawler for the instance with the given
+// base URL (e.g. https://gitlab.com). The name is used as the display name of
+// the host and defaults to the domain. The token is optional, but required to
+// access internal or private projects.
+func NewGitLabCrawler(productService *services.Service, cfg config.GitLabConfig) (*GitLabCrawler, error) {
+	userAgent, name, token := cfg.UserAgent, cfg.Name, cfg.Token
+	rateLimit := cfg.RateLimit.OrDefault(500*time.Millisecond, 5)
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	parsedURL, err := gourl.ParseRequestURI(baseURL)
+	if err != nil || parsedURL.Host == "" {
+		return nil, errors.Errorf("invalid GitLab base URL '%s'", baseURL)
+	}
+	domain := strings.ToLower(parsedURL.Host)
+	if name == "" {
+		name = domain
+	}
+	log := log.NewLogger("crawler-gitlab").With("host", domain)
+
+	// clients for external requests
+	httpClient := &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: n.NewRedirectHandler(maxRedirects),
+	}
+	rateLimiter := ratelimit.NewHeaderRateLimiter(n.HdrRateLimitRemainingKey, n.HdrRateLimitResetKey)
+
+	// file downloader
+	fileRequester := request.NewHTTPRequester(httpClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryCalcWaitTimeFromResponseFunc(calcWaitTime).
+		AddRateLimiter(rateLimiter).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
+	fileDownloader := download.NewDownloaderWithRequester(fileRequester).
+		SetUserAgent(userAgent)
+
+	// GitLab REST client
+	restClient := resty.NewWithClient(httpClient).
+		SetBaseURL(baseURL+glclient.APIPath).
+		SetHeader(n.HdrUserAgentKey, userAgent).
+		SetHeader(n.HdrAcceptKey, "application/json")
+	if token != "" {
+		restClient.SetAuthToken(token)
+		fileDownloader.AddHeader(n.HdrAuthorizationKey, "Bearer "+token)
+	}
+	requester := request.NewRESTRequester(restClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryCalcWaitTimeFromResponseFunc(calcWaitTime).
+		AddRateLimiter(rateLimiter).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
+	glClient := glclient.NewClient(restClient, requester)
+
+	validator := validator.NewValidator(productService)
+
+	return &GitLabCrawler{
+		productService: productService,
+		validator:      validator,
+
+		host: &models.Host{
+			Domain: p(domain),
+			Name:   p(name),
+		},
+		baseURL:        baseURL,
+		fileDownloader: fileDownloader,
+		glClient:       glClient,
+
+		log:          log,
+		stateStore:   crawler.NewFileStateStore(pathlib.NewPath("."), false),
+		updateMaxAge: defaultUpdateMaxAge,
+	}, nil
+}
+
+// Domain returns the domain of the crawled GitLab instance.
+func (c *GitLabCrawler) Domain() string {
+	return *c.host.Domain
+}
+
+// SetReconciler sets the reconciler, that detects removed products after a
+// complete discovery run. No reconciliation takes place, if it is nil.
+func (c *GitLabCrawler) SetReconciler(reconciler *crawler.Reconciler) *GitLabCrawler {
+	c.reconciler = reconciler
+	return c
+}
+
+// SetUpdateMaxAge sets the age of the last indexing after which a product is
+// re-indexed by `UpdateProducts`.
+func (c *GitLabCrawler) SetUpdateMaxAge(maxAge time.Duration) *GitLabCrawler {
+	c.updateMaxAge = maxAge
+	return c
+}
+
+// SetStateStore sets the store, that keeps the state of the crawler runs.
+func (c *GitLabCrawler) SetStateStore(store crawler.StateStore) *GitLabCrawler {
+	c.stateStore = store
+	return c
+}
+
+func (c *GitLabCrawler) DiscoverProducts(ctx context.Context) error {
+	c.log.Infof("discovering products on %s", c.Domain())
+
+	state, err := c.loadState(ctx)
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to load state")
+	}
+
+	runStartedAt := time.Now()
+	prevElapsedTime := state.ElapsedTime
+	hasNextPage := true
+	for hasNextPage {
+		c.log.Debugf("getting %d projects with an ID greater than %d", perPage, state.IDAfter)
+
+		projects, err := c.glClient.ListProjects(ctx, state.IDAfter, perPage)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to list projects")
+		}
+		discoveredAt := time.Now()
+
+		// check each project for manifest files and index if compliant
+		for _, project := range projects {
+			if project.EmptyRepo || project.DefaultBranch == "" || project.Namespace == nil {
+				continue
+			}
+			manifestPaths, err := c.findManifestFiles(ctx, project)
+			if err != nil {
+				if isSkippable(err) {
+					c.log.Debugf("skipping project (%s): %s", project.PathWithNamespace, err.Error())
+					continue
+				}
+				return lerrors.NewAppErrorWrap(err, "failed to find manifest files").Add("project", project.PathWithNamespace)
+			}
+
+			for _, manifestPath := range manifestPaths {
+				productID := models.NewProductID(c.Domain(), project.Namespace.FullPath, project.Path, manifestPath)
+
+				// get full product information
+				c.log.Infof("indexing product (%s)", productID.String())
+				prd, err := c.getProduct(ctx, productID, project, discoveredAt)
+				if err != nil {
+					if isSkippable(err) {
+						c.log.Debugf("skipping (%s): %s", productID.String(), err.Error())
+						continue
+					}
+					return lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+				}
+
+				// save product
+				c.log.Debugf("saving product (%s)", productID.String())
+				diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+				if err != nil {
+					return lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+				}
+				for _, diff := range diffs {
+					c.log.Debugf("changed %s", diff)
+				}
+
+				state.NumIndexed++
+			}
+		}
+
+		hasNextPage = len(projects) == perPage
+		if len(projects) > 0 {
+			state.IDAfter = projects[len(projects)-1].ID
+		}
+
+		// save state
+		state.NumCrawled += int64(len(projects))
+		state.ElapsedTime = prevElapsedTime + time.Now().Sub(runStartedAt)
+		err = c.saveState(ctx, state)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to save state")
+		}
+		c.log.Debugf("indexed %d products of %d projects on %s this far", state.NumIndexed, state.NumCrawled, c.Domain())
+	}
+
+	// detect products, that have been removed from the platform
+	if c.reconciler != nil {
+		err = c.reconciler.Reconcile(ctx, c, state.StartTime)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to reconcile products")
+		}
+	}
+
+	// the run is completed
+	err = c.stateStore.Complete(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover))
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to complete state")
+	}
+
+	return nil
+}
+
+// UpdateProducts re-indexes the stored products of the instance, that were
+// indexed more than the configured max age ago.
+func (c *GitLabCrawler) UpdateProducts(ctx context.Context) error {
+	c.log.Infof("updating products on %s indexed more than %s ago", c.Domain(), c.updateMaxAge)
+
+	indexedBefore := time.Now().Add(-c.updateMaxAge)
+	var numChecked, numUpdated int64
+	// products that are not re-indexed remain in the result set, therefore
+	// they need to be skipped
+	var offset int64
+	for {
+		storedPrds, err := c.productService.GetProductsIndexedBefore(ctx, c.Domain(), indexedBefore, updateBatchSize, offset)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to get products to update")
+		}
+		if len(storedPrds) == 0 {
+			break
+		}
+
+		for _, storedPrd := range storedPrds {
+			numChecked++
+			updated, err := c.updateProduct(ctx, storedPrd)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				offset++
+				continue
+			}
+			numUpdated++
+		}
+		c.log.Debugf("updated %d of %d checked products on %s this far", numUpdated, numChecked, c.Domain())
+	}
+
+	c.log.Infof("updated %d of %d checked products on %s", numUpdated, numChecked, c.Domain())
+	return nil
+}
+
+// updateProduct re-indexes a single stored product. It returns false, if the
+// product was skipped. The product is always saved, because its star and fork
+// counts change without a push; only the changed fields are written though.
+func (c *GitLabCrawler) updateProduct(ctx context.Context, storedPrd *models.Product) (bool, error) {
+	productID, err := c.storedProductID(storedPrd)
+	if err != nil {
+		c.log.Debugf("skipping (%s): %s", stringOrEmpty(storedPrd.Xid), err.Error())
+		return false, nil
+	}
+
+	c.log.Infof("re-indexing product (%s)", productID.String())
+	indexedAt := time.Now()
+	prd, err := c.getProduct(ctx, productID, nil, indexedAt)
+	if err != nil {
+		if isSkippable(err) {
+			// removed products are detected by the reconciliation
+			c.log.Debugf("skipping (%s): %s", productID.String(), err.Error())
+			return false, nil
+		}
+		return false, lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+	}
+
+	// save product
+	c.log.Debugf("saving product (%s)", productID.String())
+	prd.DiscoveredAt = storedPrd.DiscoveredAt
+	diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+	if err != nil {
+		return false, lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+	}
+	for _, diff := range diffs {
+		c.log.Debugf("changed %s", diff)
+	}
+
+	// the product was saved under a different identity, e.g. because the
+	// project was moved, therefore the stored product is marked as indexed as
+	// well
+	if prd.ID == nil || storedPrd.ID == nil || *prd.ID != *storedPrd.ID {
+		err = c.productService.TouchProduct(ctx, storedPrd, indexedAt)
+		if err != nil {
+			return false, lerrors.NewAppErrorWrap(err, "failed to update product").Add("crawlerProductID", productID.String())
+		}
+	}
+	return true, nil
+}
+
+// storedProductID returns the ID of a stored product, which is derived from
+// the project URL and the path of the manifest file of its data source. The
+// project URL is relative to the base URL of the instance and may contain
+// nested groups, e.g. `https://gitlab.example.org/group/subgroup/project`.
+func (c *GitLabCrawler) storedProductID(storedPrd *models.Product) (models.ProductID, error) {
+	dataSource := storedPrd.DataSource
+	if dataSource == nil || dataSource.URL == nil || dataSource.Path == nil {
+		return models.ProductID{}, errors.New("missing data source")
+	}
+	projectPath := strings.TrimPrefix(*dataSource.URL, c.baseURL+"/")
+	if projectPath == *dataSource.URL {
+		return models.ProductID{}, errors.Errorf("project URL '%s' is not part of the instance", *dataSource.URL)
+	}
+	projectPath, err := gourl.PathUnescape(strings.Trim(projectPath, "/"))
+	if err != nil {
+		return models.ProductID{}, errors.Wrap(err, "invalid project URL")
+	}
+	pos := strings.LastIndexByte(projectPath, '/')
+	if pos == -1 {
+		return models.ProductID{}, errors.Errorf("project URL '%s' lacks a namespace", *dataSource.URL)
+	}
+	return models.NewProductID(c.Domain(), projectPath[:pos], projectPath[pos+1:], *dataSource.Path), nil
+}
+
+// findManifestFiles returns the paths of the manifest files located in the
+// root directory of the project's default branch.
+func (c *GitLabCrawler) findManifestFiles(ctx context.Context, project *glclient.Project) ([]string, error) {
+	entries, err := c.glClient.ListTree(ctx, project.ID, project.DefaultBranch, false, 1)
+	if err != nil {
+		return nil, err
+	}
+	manifestPaths := []string{}
+	for _, entry := range entries {
+		if entry.IsFile() && manifest.IsManifestFile(entry.Path) {
+			manifestPaths = append(manifestPaths, entry.Path)
+		}
+	}
+	return manifestPaths, nil
+}
+
+// getProduct retrieves and normalizes the product information of the manifest
+// file identified by the given product ID. If the project is nil, it will be
+// retrieved from the API.
+func (c *GitLabCrawler) getProduct(ctx context.Context, prdID models.ProductID, project *glclient.Project, discoveredAt time.Time) (*models.Product, error) {
+	if project == nil {
+		var err error
+		project, err = c.glClient.GetProject(ctx, prdID.Owner+"/"+prdID.Repo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab project information")
+		}
+		if project.Namespace == nil {
+			return nil, errors.New("GitLab project has no namespace")
+		}
+	}
+
+	raw, err := c.getRawData(ctx, prdID, project, discoveredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := c.NormalizeProduct(ctx, raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize product information")
+	}
+
+	// validate product
+	err = c.validator.ValidateProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	// return normalized product
+	return product, nil
+}
+
+// GetProduct retrieves the product information of the manifest file
+// identified by the given product ID. If the path of the product ID is empty,
+// the first manifest file in the root directory of the project is used.
+func (c *GitLabCrawler) GetProduct(ctx context.Context, productID models.ProductID) (*models.Product, error) {
+	product, err := c.getProduct(ctx, productID, nil, time.Now())
+	if err != nil {
+		if vldErr, ok := err.(*validator.ValidationError); ok {
+			return nil, errors.Wrap(vldErr, "invalid product")
+		}
+		if errors.Is(err, glclient.ErrNotFound) {
+			return nil, errors.Wrapf(crawler.ErrProductNotFound, "failed to get product '%s'", productID.String())
+		}
+		return nil, errors.Wrap(err, "failed to get product information")
+	}
+	return product, nil
+}
+
+// getRawData retrieves the manifest file and the file tree for each release of
+// the project. A release is created for each of the most recent tags that
+// contain a valid manifest file. If there is none, the default branch is used
+// instead.
+func (c *GitLabCrawler) getRawData(ctx context.Context, prdID models.ProductID, project *glclient.Project, discoveredAt time.Time) (*rawData, error) {
+	if project.EmptyRepo || project.DefaultBranch == "" {
+		return nil, &manifest.ErrInvalidManifest{Path: "", Err: errors.New("repository is empty")}
+	}
+
+	// find manifest file, if not specified
+	manifestPath := strings.Trim(prdID.Path, "/")
+	if manifestPath == "" {
+		manifestPaths, err := c.findManifestFiles(ctx, project)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab file tree")
+		}
+		if len(manifestPaths) == 0 {
+			return nil, &manifest.ErrInvalidManifest{Path: "", Err: errors.New("no manifest file found")}
+		}
+		manifestPath = manifestPaths[0]
+	}
+
+	raw := &rawData{
+		timestamp:    discoveredAt,
+		project:      project,
+		manifestPath: manifestPath,
+	}
+
+	// one release per tag
+	tags, err := c.glClient.ListTags(ctx, project.ID, maxTags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitLab tags")
+	}
+	var firstErr error
+	for _, tag := range tags {
+		if tag.Commit == nil {
+			continue
+		}
+		release, err := c.getRawRelease(ctx, raw, tag.Name, tag.Commit)
+		if err != nil {
+			if !isSkippable(err) {
+				return nil, err
+			}
+			c.log.Debugf("skipping tag '%s' (%s): %s", tag.Name, prdID.String(), err.Error())
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		raw.releases = append(raw.releases, release)
+	}
+
+	// fall back to default branch
+	if len(raw.releases) == 0 {
+		branch, err := c.glClient.GetBranch(ctx, project.ID, project.DefaultBranch)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab branch information")
+		}
+		release, err := c.getRawRelease(ctx, raw, branch.Name, branch.Commit)
+		if err != nil {
+			if firstErr != nil && isSkippable(err) {
+				return nil, firstErr
+			}
+			return nil, err
+		}
+		raw.releases = append(raw.releases, release)
+	}
+
+	return raw, nil
+}
+
+// getRawRelease retrieves the file tree and the manifest file at the given
+// reference.
+func (c *GitLabCrawler) getRawRelease(ctx context.Context, raw *rawData, ref string, commit *glclient.Commit) (*rawRelease, error) {
+	if commit == nil {
+		return nil, errors.Errorf("reference '%s' has no commit", ref)
+	}
+	entries, err := c.glClient.ListTree(ctx, raw.project.ID, commit.ID, true, maxTreePages)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GitLab file tree")
+	}
+	files := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsFile() {
+			files[entry.Path] = struct{}{}
+		}
+	}
+	if _, ok := files[raw.manifestPath]; !ok {
+		return nil, &manifest.ErrInvalidManifest{Path: raw.manifestPath, Err: errors.Errorf("manifest file not found at '%s'", ref)}
+	}
+
+	// download and parse manifest file
+	manifestURL := c.glClient.RawFileURL(raw.project.ID, commit.ID, raw.manifestPath)
+	content, err := c.fileDownloader.DownloadContentWithMaxSize(ctx, manifestURL, maxFileSizeManifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download manifest file")
+	}
+	mnf, err := manifest.Parse(raw.manifestPath, content)
+	if err != nil {
+		return nil, &manifest.ErrInvalidManifest{Path: raw.manifestPath, Err: err}
+	}
+	if mnf.Conversion != nil && len(mnf.Conversion.Lost) > 0 {
+		c.log.Debugf("converted legacy manifest '%s', lost fields: %s", raw.manifestPath, strings.Join(mnf.Conversion.LostFieldNames(), ", "))
+	}
+
+	// download license file, if the manifest declares no license
+	licenseText := ""
+	if strings.TrimSpace(mnf.License) == "" {
+		if licensePath := services.FindLicenseFile(files, manifestDir(raw.manifestPath)); licensePath != "" {
+			licenseURL := c.glClient.RawFileURL(raw.project.ID, commit.ID, licensePath)
+			content, err := c.fileDownloader.DownloadContentWithMaxSize(ctx, licenseURL, maxFileSizeLicense)
+			if err != nil {
+				c.log.Debugw("failed to download license file", "url", licenseURL, "error", err)
+			} else {
+				licenseText = string(content)
+			}
+		}
+	}
+
+	release := &rawRelease{
+		ref:         ref,
+		commit:      commit,
+		files:       files,
+		manifest:    mnf,
+		licenseText: licenseText,
+	}
+
+	// check mandatory fields
+	err = c.checkMandatory(raw, release)
+	if err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// loadState loads the state of the discovery run from the state store. A new
+// state is returned, if none is stored.
+func (c *GitLabCrawler) loadState(ctx context.Context) (CrawlerState, error) {
+	// new state
+	state := CrawlerState{
+		StartTime: time.Now(),
+		IDAfter:   0,
+	}
+	_, err := c.stateStore.Load(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), &state)
+	if err != nil {
+		return CrawlerState{}, err
+	}
+	return state, nil
+}
+
+// saveState saves the state of the discovery run to the state store.
+func (c *GitLabCrawler) saveState(ctx context.Context, state CrawlerState) error {
+	return c.stateStore.Save(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), state)
+}
+
+// checkMandatory checks if mandatory fields are present.
+func (c *GitLabCrawler) checkMandatory(raw *rawData, release *rawRelease) error {
+	mnf := release.manifest
+	filePaths := make([]string, 0, len(release.files))
+	dir := manifestDir(raw.manifestPath)
+	for path := range release.files {
+		// only files next to the manifest are considered
+		rel := strings.TrimPrefix(path, dir)
+		if dir != "" && rel == path {
+			continue
+		}
+		filePaths = append(filePaths, rel)
+	}
+
+	// quick check if mandatory fields are present
+	mdtFlds := validator.MandatoryFields{
+		OKHV:                  mnf.OKHV,
+		Name:                  mnf.Name,
+		Description:           mnf.Function,
+		Version:               mnf.Version,
+		Repository:            mnf.Repo,
+		License:               strings.TrimSpace(mnf.License),
+		Licensor:              mnf.Licensor,
+		DocumentationLanguage: mnf.DocumentationLanguage,
+		FilePaths:             filePaths,
+	}
+	return c.validator.ValidateMandatory(mdtFlds)
+}
+
+// isSkippable returns true if the product can be skipped during discovery,
+// because the error is caused by the product itself and not by the crawler.
+func isSkippable(err error) bool {
+	var (
+		vldErr      *validator.ValidationError
+		mnfErr      *manifest.ErrInvalidManifest
+		tooLargeErr *download.ErrTooLarge
+	)
+	return errors.As(err, &vldErr) ||
+		errors.As(err, &mnfErr) ||
+		errors.As(err, &tooLargeErr) ||
+		errors.Is(err, glclient.ErrNotFound)
+}
+
+// calcWaitTime calculates the wait time before retrying a failed request. It
+// takes the rate limits of GitLab into account.
+func calcWaitTime(resp *http.Response, delay time.Duration) time.Duration {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		if resp.Header.Get(n.HdrRetryAfterKey) != "" {
+			return request.RetryAfter(resp, delay)
+		}
+		reset, err := strconv.ParseInt(resp.Header.Get(n.HdrRateLimitResetKey), 10, 64)
+		if err == nil {
+			if wait := time.Unix(reset, 0).Sub(time.Now()); wait > 0 {
+				return wait + 1*time.Second
+			}
+		}
+		return delay
+
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return delay
+	}
+	return retry.Stop
+}
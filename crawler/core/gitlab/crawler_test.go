@@ -0,0 +1,241 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log/logtest"
+
+	n "losh/internal/lib/net"
+)
+
+const testToken = "secret"
+
+const testManifest = `okhv = "OKH-LOSHv1.0"
+name = "Motor Driver"
+repo = "https://git.example.org/group/sub/motor-driver"
+version = "1.0.0"
+license = "CERN-OHL-S-2.0"
+licensor = "Jane Doe"
+function = "Drives two brushed DC motors."
+documentation-language = "en"
+technology-readiness-level = "OTRL-4"
+documentation-readiness-level = "ODRL-3"
+source = ["board.kicad_pcb"]
+`
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+// testInstance is a self-hosted GitLab instance served from the sub path
+// `/gitlab`. It hosts the project `group/sub/motor-driver`, whose tags point
+// to the given commits. All requests, including file downloads, must be
+// authenticated.
+type testInstance struct {
+	*httptest.Server
+	tags []map[string]interface{}
+}
+
+func newTestInstance(t *testing.T) *testInstance {
+	t.Helper()
+	inst := &testInstance{}
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set(n.HdrContentTypeKey, "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	// the manifest doesn't exist in the commit `bbb`
+	trees := map[string][]string{
+		"aaa":  {"okh.toml", "README.md", "board.kicad_pcb"},
+		"bbb":  {"README.md"},
+		"ccc":  {"okh.toml", "README.md", "board.kicad_pcb"},
+		"main": {"okh.toml", "README.md", "board.kicad_pcb"},
+	}
+
+	inst.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(n.HdrAuthorizationKey) != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ref := r.URL.Query().Get("ref")
+		switch r.URL.EscapedPath() {
+		case "/gitlab/api/v4/projects/group%2Fsub%2Fmotor-driver":
+			writeJSON(w, map[string]interface{}{
+				"id":               7,
+				"name":             "Motor Driver",
+				"path":             "motor-driver",
+				"web_url":          inst.URL + "/gitlab/group/sub/motor-driver",
+				"default_branch":   "main",
+				"star_count":       42,
+				"last_activity_at": time.Now().Format(time.RFC3339),
+				"namespace":        map[string]interface{}{"kind": "group", "name": "Sub", "full_path": "group/sub"},
+			})
+		case "/gitlab/api/v4/projects/7/repository/tags":
+			writeJSON(w, inst.tags)
+		case "/gitlab/api/v4/projects/7/repository/branches/main":
+			writeJSON(w, map[string]interface{}{"name": "main", "commit": map[string]interface{}{"id": "ccc", "created_at": "2022-06-01T00:00:00Z"}})
+		case "/gitlab/api/v4/projects/7/repository/tree":
+			entries := []map[string]interface{}{}
+			for _, path := range trees[ref] {
+				entries = append(entries, map[string]interface{}{"path": path, "type": "blob"})
+			}
+			writeJSON(w, entries)
+		case "/gitlab/api/v4/projects/7/repository/files/okh.toml/raw":
+			if ref != "aaa" && ref != "ccc" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(testManifest))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return inst
+}
+
+// newTestCrawler creates a crawler for the given instance.
+func newTestCrawler(t *testing.T, inst *testInstance, repo services.Repository) *GitLabCrawler {
+	t.Helper()
+	svc := services.NewService(repo)
+	xid, name := "CERN-OHL-S-2.0", "CERN Open Hardware Licence Version 2 - Strongly Reciprocal"
+	svc.LoadLicenses([]*models.License{{ID: p("0x1"), Xid: &xid, Name: &name}}, nil)
+	c, err := NewGitLabCrawler(svc, config.GitLabConfig{
+		BaseURL:   inst.URL + "/gitlab/",
+		Token:     testToken,
+		RateLimit: config.RateLimitConfig{Interval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("NewGitLabCrawler() error = %v", err)
+	}
+	return c
+}
+
+func TestGetProductReleasesFromTags(t *testing.T) {
+	inst := newTestInstance(t)
+	defer inst.Close()
+	inst.tags = []map[string]interface{}{
+		{"name": "v1.0", "commit": map[string]interface{}{"id": "aaa", "created_at": "2022-05-01T00:00:00Z"}},
+		// the manifest was added later, therefore the tag is skipped
+		{"name": "v0.9", "commit": map[string]interface{}{"id": "bbb", "created_at": "2022-04-01T00:00:00Z"}},
+	}
+	c := newTestCrawler(t, inst, nil)
+
+	prdID := models.NewProductID(c.Domain(), "group/sub", "motor-driver", "okh.toml")
+	prd, err := c.GetProduct(context.Background(), prdID)
+	if err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+	// the namespace is escaped to separate it from the project path
+	if want := c.Domain() + "/group%2Fsub/motor-driver/okh.toml"; *prd.Xid != want {
+		t.Errorf("Xid = %s, want %s", *prd.Xid, want)
+	}
+	if len(prd.Releases) != 1 || *prd.Release.DataSource.Reference != "v1.0" {
+		t.Fatalf("Releases = %v, want the release of v1.0", prd.Releases)
+	}
+	if permaURL := *prd.Release.DataSource.PermaURL; !strings.HasPrefix(permaURL, inst.URL+"/gitlab/group/sub/motor-driver/-/raw/aaa/") {
+		t.Errorf("PermaURL = %s, want a URL of the instance pointing to the commit", permaURL)
+	}
+	if prd.License == nil || *prd.License.Xid != "CERN-OHL-S-2.0" {
+		t.Errorf("License = %v, want CERN-OHL-S-2.0", prd.License)
+	}
+
+	// the stored product is identified by its data source
+	storedID, err := c.storedProductID(prd)
+	if err != nil {
+		t.Fatalf("storedProductID() error = %v", err)
+	}
+	if storedID != prdID {
+		t.Errorf("storedProductID() = %s, want %s", storedID.String(), prdID.String())
+	}
+}
+
+func TestGetProductFallsBackToDefaultBranch(t *testing.T) {
+	inst := newTestInstance(t)
+	defer inst.Close()
+	inst.tags = []map[string]interface{}{
+		{"name": "v0.9", "commit": map[string]interface{}{"id": "bbb", "created_at": "2022-04-01T00:00:00Z"}},
+	}
+	c := newTestCrawler(t, inst, nil)
+
+	prd, err := c.GetProduct(context.Background(), models.NewProductID(c.Domain(), "group/sub", "motor-driver", ""))
+	if err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+	if len(prd.Releases) != 1 || *prd.Release.DataSource.Reference != "main" {
+		t.Errorf("Releases = %v, want the release of the default branch", prd.Releases)
+	}
+}
+
+func TestGetProductUnauthenticated(t *testing.T) {
+	inst := newTestInstance(t)
+	defer inst.Close()
+	c, err := NewGitLabCrawler(services.NewService(nil), config.GitLabConfig{BaseURL: inst.URL + "/gitlab"})
+	if err != nil {
+		t.Fatalf("NewGitLabCrawler() error = %v", err)
+	}
+
+	_, err = c.GetProduct(context.Background(), models.NewProductID(c.Domain(), "group/sub", "motor-driver", "okh.toml"))
+	if err == nil || isSkippable(err) {
+		t.Errorf("GetProduct() error = %v, want a request error", err)
+	}
+}
+
+// indexedRepo is a repository, that returns the stored products to update.
+type indexedRepo struct {
+	services.Repository
+	products []*models.Product
+	offsets  []int64
+}
+
+func (r *indexedRepo) GetProductsIndexedBefore(ctx context.Context, hostDomain string, indexedBefore time.Time, first, offset int64) ([]*models.Product, error) {
+	r.offsets = append(r.offsets, offset)
+	if offset >= int64(len(r.products)) {
+		return nil, nil
+	}
+	return r.products[offset:], nil
+}
+
+// TestUpdateProductsSkipsRemoved checks that products, that cannot be
+// re-indexed, are skipped instead of being requested again.
+func TestUpdateProductsSkipsRemoved(t *testing.T) {
+	inst := newTestInstance(t)
+	defer inst.Close()
+	removedURL, otherURL, path := inst.URL+"/gitlab/group/removed", "https://gitlab.com/group/other", "okh.toml"
+	repo := &indexedRepo{products: []*models.Product{
+		{ID: p("0x1"), DataSource: &models.Repository{URL: &removedURL, Path: &path}},
+		// products of other instances cannot be re-indexed
+		{ID: p("0x2"), DataSource: &models.Repository{URL: &otherURL, Path: &path}},
+		{ID: p("0x3")},
+	}}
+
+	if err := newTestCrawler(t, inst, repo).UpdateProducts(context.Background()); err != nil {
+		t.Fatalf("UpdateProducts() error = %v", err)
+	}
+	if len(repo.offsets) != 2 || repo.offsets[0] != 0 || repo.offsets[1] != 3 {
+		t.Errorf("requested offsets %v, want [0 3]", repo.offsets)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glclient
+
+import (
+	"context"
+	"net/http"
+	gourl "net/url"
+	"strconv"
+
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/go-resty/resty/v2"
+)
+
+// APIPath is the path of the REST API relative to the base URL of a GitLab
+// instance.
+const APIPath = "/api/v4"
+
+// ErrNotFound is returned if the requested resource does not exist.
+var ErrNotFound = errors.New("resource not found")
+
+// Client is a minimal client for the GitLab REST API.
+type Client struct {
+	restClient *resty.Client
+	requester  *request.RESTRequester
+}
+
+// NewClient creates a new GitLab REST API client. The REST client is expected
+// to be configured with the API URL and authentication of the instance.
+func NewClient(restClient *resty.Client, requester *request.RESTRequester) *Client {
+	return &Client{
+		restClient: restClient,
+		requester:  requester,
+	}
+}
+
+// ListProjects returns the projects with an ID greater than the given one in
+// ascending order.
+func (c *Client) ListProjects(ctx context.Context, idAfter int64, perPage int) ([]*Project, error) {
+	result := []*Project{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetQueryParam("order_by", "id").
+		SetQueryParam("sort", "asc").
+		SetQueryParam("id_after", strconv.FormatInt(idAfter, 10)).
+		SetQueryParam("per_page", strconv.Itoa(perPage)).
+		SetResult(&result)
+	if _, err := c.do(req, "/projects"); err != nil {
+		return nil, errors.Wrap(err, "failed to list projects")
+	}
+	return result, nil
+}
+
+// GetProject returns the project with the given path (including namespace).
+func (c *Client) GetProject(ctx context.Context, pathWithNamespace string) (*Project, error) {
+	result := &Project{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetResult(result)
+	if _, err := c.do(req, "/projects/"+gourl.PathEscape(pathWithNamespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to get project")
+	}
+	return result, nil
+}
+
+// ListTags returns the most recently updated tags of a project.
+func (c *Client) ListTags(ctx context.Context, projectID int64, perPage int) ([]*Tag, error) {
+	result := []*Tag{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetQueryParam("order_by", "updated").
+		SetQueryParam("sort", "desc").
+		SetQueryParam("per_page", strconv.Itoa(perPage)).
+		SetResult(&result)
+	if _, err := c.do(req, "/projects/"+strconv.FormatInt(projectID, 10)+"/repository/tags"); err != nil {
+		return nil, errors.Wrap(err, "failed to list tags")
+	}
+	return result, nil
+}
+
+// GetBranch returns the branch with the given name.
+func (c *Client) GetBranch(ctx context.Context, projectID int64, name string) (*Branch, error) {
+	result := &Branch{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetResult(result)
+	if _, err := c.do(req, "/projects/"+strconv.FormatInt(projectID, 10)+"/repository/branches/"+gourl.PathEscape(name)); err != nil {
+		return nil, errors.Wrap(err, "failed to get branch")
+	}
+	return result, nil
+}
+
+// ListTree returns the files and directories of the repository at the given
+// reference. At most `maxPages` pages are retrieved.
+func (c *Client) ListTree(ctx context.Context, projectID int64, ref string, recursive bool, maxPages int) ([]*TreeEntry, error) {
+	entries := []*TreeEntry{}
+	for page := 1; page > 0 && page <= maxPages; {
+		result := []*TreeEntry{}
+		req := c.restClient.R().
+			SetContext(ctx).
+			SetQueryParam("ref", ref).
+			SetQueryParam("recursive", strconv.FormatBool(recursive)).
+			SetQueryParam("per_page", "100").
+			SetQueryParam("page", strconv.Itoa(page)).
+			SetResult(&result)
+		resp, err := c.do(req, "/projects/"+strconv.FormatInt(projectID, 10)+"/repository/tree")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list repository tree")
+		}
+		entries = append(entries, result...)
+		page, _ = strconv.Atoi(resp.Header().Get("X-Next-Page"))
+	}
+	return entries, nil
+}
+
+// RawFileURL returns the API URL to download the raw content of a file.
+func (c *Client) RawFileURL(projectID int64, ref, path string) string {
+	return c.restClient.BaseURL + "/projects/" + strconv.FormatInt(projectID, 10) +
+		"/repository/files/" + gourl.PathEscape(path) + "/raw?ref=" + gourl.QueryEscape(ref)
+}
+
+// do executes a GET request and checks the response status.
+func (c *Client) do(req *resty.Request, path string) (*resty.Response, error) {
+	resp, err := c.requester.Do(request.NewRESTRequest(req, http.MethodGet, path))
+	if resp != nil && resp.StatusCode() == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, errors.Errorf("request failed with status code %d", resp.StatusCode())
+	}
+	return resp, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"losh/internal/lib/net/ratelimit"
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/go-resty/resty/v2"
+
+	n "losh/internal/lib/net"
+)
+
+const (
+	testToken = "secret"
+	// instances can be served from a sub path
+	testPrefix = "/gitlab" + APIPath
+)
+
+// newTestClient creates a client for a self-hosted instance served by the
+// given test server, that authenticates with a token and respects the
+// reported rate limits.
+func newTestClient(srv *httptest.Server) *Client {
+	restClient := resty.NewWithClient(srv.Client()).
+		SetBaseURL(srv.URL + testPrefix).
+		SetAuthToken(testToken)
+	requester := request.NewRESTRequester(restClient).
+		SetRetryCount(0).
+		SetMaxWaitTime(time.Minute).
+		AddRateLimiter(ratelimit.NewHeaderRateLimiter(n.HdrRateLimitRemainingKey, n.HdrRateLimitResetKey))
+	return NewClient(restClient, requester)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set(n.HdrContentTypeKey, "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetProject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(n.HdrAuthorizationKey) != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the namespace is part of the escaped project path
+		if r.URL.EscapedPath() != testPrefix+"/projects/group%2Fsub%2Fmotor-driver" {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"id":        7,
+			"path":      "motor-driver",
+			"namespace": map[string]interface{}{"kind": "group", "full_path": "group/sub"},
+		})
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv)
+	project, err := client.GetProject(context.Background(), "group/sub/motor-driver")
+	if err != nil {
+		t.Fatalf("GetProject() error = %v", err)
+	}
+	if project.ID != 7 || !project.Namespace.IsGroup() || project.Namespace.FullPath != "group/sub" {
+		t.Errorf("GetProject() = %+v", project)
+	}
+
+	_, err = client.GetProject(context.Background(), "group/missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProject() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListTreePagination(t *testing.T) {
+	pages := map[string][]string{
+		"1": {"okh.toml", "README.md"},
+		"2": {"docs/bom.csv"},
+		"3": {"board.kicad_pcb"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != testPrefix+"/projects/7/repository/tree" || query.Get("ref") != "abc" || query.Get("recursive") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page := query.Get("page")
+		if next, _ := strconv.Atoi(page); next < len(pages) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(next+1))
+		}
+		entries := []map[string]interface{}{}
+		for _, path := range pages[page] {
+			entries = append(entries, map[string]interface{}{"path": path, "type": "blob"})
+		}
+		writeJSON(t, w, entries)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv)
+	entries, err := client.ListTree(context.Background(), 7, "abc", true, 10)
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+	if len(entries) != 4 || entries[3].Path != "board.kicad_pcb" {
+		t.Errorf("ListTree() returned %d entries, want 4", len(entries))
+	}
+
+	// the number of pages is limited
+	entries, err = client.ListTree(context.Background(), 7, "abc", true, 2)
+	if err != nil {
+		t.Fatalf("ListTree() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("ListTree() returned %d entries, want 3", len(entries))
+	}
+}
+
+// TestRateLimitHeaders checks that no further requests are sent after the
+// server reported an exhausted rate limit.
+func TestRateLimitHeaders(t *testing.T) {
+	var numRequests int32
+	reset := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&numRequests, 1)
+		w.Header().Set(n.HdrRateLimitRemainingKey, "0")
+		w.Header().Set(n.HdrRateLimitResetKey, strconv.FormatInt(reset, 10))
+		writeJSON(t, w, map[string]interface{}{"name": "main"})
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv)
+	if _, err := client.GetBranch(context.Background(), 7, "main"); err != nil {
+		t.Fatalf("GetBranch() error = %v", err)
+	}
+	// the wait until the reset exceeds the max wait time of the requester
+	if _, err := client.GetBranch(context.Background(), 7, "main"); err == nil {
+		t.Errorf("GetBranch() succeeded despite exhausted rate limit")
+	}
+	if got := atomic.LoadInt32(&numRequests); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+func TestRawFileURL(t *testing.T) {
+	client := NewClient(resty.New().SetBaseURL("https://git.example.org/gitlab"+APIPath), nil)
+	got := client.RawFileURL(7, "v1.0", "docs/read me.md")
+	want := "https://git.example.org/gitlab/api/v4/projects/7/repository/files/docs%2Fread%20me.md/raw?ref=v1.0"
+	if got != want {
+		t.Errorf("RawFileURL() = %s, want %s", got, want)
+	}
+}
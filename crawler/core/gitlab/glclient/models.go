@@ -0,0 +1,85 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glclient
+
+import "time"
+
+// Project contains the information about a GitLab project.
+type Project struct {
+	ID                int64      `json:"id"`
+	Name              string     `json:"name"`
+	Path              string     `json:"path"`
+	PathWithNamespace string     `json:"path_with_namespace"`
+	Description       *string    `json:"description"`
+	WebURL            string     `json:"web_url"`
+	DefaultBranch     string     `json:"default_branch"`
+	Topics            []string   `json:"topics"`
+	StarCount         int64      `json:"star_count"`
+	ForksCount        int64      `json:"forks_count"`
+	Archived          bool       `json:"archived"`
+	EmptyRepo         bool       `json:"empty_repo"`
+	CreatedAt         *time.Time `json:"created_at"`
+	LastActivityAt    *time.Time `json:"last_activity_at"`
+	Namespace         *Namespace `json:"namespace"`
+	ForkedFromProject *Project   `json:"forked_from_project"`
+}
+
+// Namespace is the user or group a project belongs to.
+type Namespace struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	Path      string  `json:"path"`
+	Kind      string  `json:"kind"`
+	FullPath  string  `json:"full_path"`
+	AvatarURL *string `json:"avatar_url"`
+	WebURL    string  `json:"web_url"`
+}
+
+// IsGroup returns true if the namespace is a group.
+func (n *Namespace) IsGroup() bool {
+	return n.Kind == "group"
+}
+
+// Commit contains the information about a single commit.
+type Commit struct {
+	ID            string     `json:"id"`
+	CreatedAt     *time.Time `json:"created_at"`
+	CommittedDate *time.Time `json:"committed_date"`
+}
+
+// Tag is a tag of a repository.
+type Tag struct {
+	Name   string  `json:"name"`
+	Commit *Commit `json:"commit"`
+}
+
+// Branch is a branch of a repository.
+type Branch struct {
+	Name   string  `json:"name"`
+	Commit *Commit `json:"commit"`
+}
+
+// TreeEntry is a single file or directory of a repository tree.
+type TreeEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// IsFile returns true if the entry is a regular file.
+func (e *TreeEntry) IsFile() bool {
+	return e.Type == "blob"
+}
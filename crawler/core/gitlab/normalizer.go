@@ -0,0 +1,496 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"context"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/crawler/core/gitlab/glclient"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+
+	"github.com/abadojack/whatlanggo"
+)
+
+var activeTimeThreshold = 2 * 365 * 24 * time.Hour // 2 years
+
+type rawData struct {
+	timestamp    time.Time
+	project      *glclient.Project
+	manifestPath string
+	// releases ordered from latest to oldest
+	releases []*rawRelease
+}
+
+// rawRelease contains the information of a project at a specific tag or
+// branch.
+type rawRelease struct {
+	ref    string
+	commit *glclient.Commit
+	// paths of all files in the repository
+	files    map[string]struct{}
+	manifest *manifest.Manifest
+	// content of the license file, if the manifest declares no license
+	licenseText string
+}
+
+// NormalizeProduct creates a normalized product from the given GitLab project
+// information and manifest files.
+func (c *GitLabCrawler) NormalizeProduct(ctx context.Context, raw *rawData) (*models.Product, error) {
+	product := &models.Product{}
+	project := raw.project
+
+	// releases
+	licensor := c.normLicensor(project.Namespace)
+	releases := c.normReleases(ctx, raw, licensor)
+	latestRelease := releases[0] // latest release is always the first
+
+	// crawler info
+	product.DiscoveredAt = latestRelease.DiscoveredAt
+	product.LastIndexedAt = latestRelease.LastIndexedAt
+	product.DataSource = latestRelease.DataSource
+
+	// product info
+	// Xid format: domain.tld/owner/repo/file-path
+	product.Xid = asXid(c.Domain(), project.Namespace.FullPath, project.Path, raw.manifestPath)
+	// PlatformID format: domain.tld/project-id/file-path
+	product.PlatformID = asXid(c.Domain(), strconv.FormatInt(project.ID, 10), raw.manifestPath)
+	product.Name = latestRelease.Name
+	product.Description = latestRelease.Description
+	product.DocumentationLanguage = latestRelease.DocumentationLanguage
+	product.Version = latestRelease.Version
+	product.License = latestRelease.License
+	product.Licensor = licensor
+	product.Website = stringOrNil(p(s(project.WebURL)))
+	if product.Website == nil {
+		product.Website = product.DataSource.URL
+	}
+	product.State = c.normState(project)
+	product.LastUpdatedAt = project.LastActivityAt
+	product.Release = latestRelease
+	product.Releases = releases
+	for _, release := range releases {
+		release.Product = product
+	}
+
+	// forks are linked to their origin through the inverse of ForkOf
+	product.ForkOf = c.normForkOf(raw)
+	product.ForkCount = &project.ForksCount
+	product.StarCount = &project.StarCount
+	product.Tags = normTags(project.Topics)
+
+	return product, nil
+}
+
+func (c *GitLabCrawler) normState(project *glclient.Project) *dgclient.ProductState {
+	state := dgclient.ProductStateUndetermined
+	if project.Archived {
+		state = dgclient.ProductStateArchived
+	} else if project.LastActivityAt != nil && time.Now().Sub(*project.LastActivityAt) < activeTimeThreshold {
+		state = dgclient.ProductStateActive
+	} else {
+		state = dgclient.ProductStateInactive
+	}
+	return &state
+}
+
+// normReleases returns the releases of the product, one for each tag or the
+// default branch, if the project has no tags.
+func (c *GitLabCrawler) normReleases(ctx context.Context, raw *rawData, licensor models.UserOrGroup) []*models.Component {
+	releases := make([]*models.Component, 0, len(raw.releases))
+	for i, rawRls := range raw.releases {
+		crawlerMeta := &models.CrawlerMetaImpl{
+			DiscoveredAt:  &raw.timestamp,
+			LastIndexedAt: &raw.timestamp,
+			DataSource:    c.normRepository(raw, rawRls, licensor),
+		}
+		release := c.normRelease(ctx, raw, rawRls, licensor, crawlerMeta)
+		release.Releases = make([]*models.Component, 0, len(raw.releases))
+		release.IsLatest = p(i == 0)
+		normSubComponents(release, crawlerMeta)
+		releases = append(releases, release)
+	}
+
+	// link releases to each other
+	for i := 0; i < len(releases); i++ {
+		release := releases[i]
+		for j := 0; j < len(releases); j++ {
+			release.Releases = append(release.Releases, releases[j])
+		}
+	}
+
+	return releases
+}
+
+// normRelease returns the release described by the manifest file at a
+// specific reference.
+func (c *GitLabCrawler) normRelease(ctx context.Context, raw *rawData, rawRls *rawRelease, licensor models.UserOrGroup, crawlerMeta *models.CrawlerMetaImpl) *models.Component {
+	mnf := rawRls.manifest
+	project := raw.project
+
+	fileResolver := func(ref string) *models.File {
+		return c.normFile(raw, rawRls, ref, crawlerMeta)
+	}
+	release := mnf.ToComponent(fileResolver, c.productService.ResolveLicenseExpression)
+	release.DiscoveredAt = crawlerMeta.DiscoveredAt
+	release.LastIndexedAt = crawlerMeta.LastIndexedAt
+	release.DataSource = crawlerMeta.DataSource
+	if release.License == nil && rawRls.licenseText != "" {
+		// fall back to the license file, if the manifest declares no license
+		if license := c.productService.DetectLicense(ctx, rawRls.licenseText); license != nil {
+			release.License = license
+			release.LicenseExpression = license.Xid
+		}
+	}
+
+	// Xid format: domain.tld/owner/repo/ref/file-path/component-name
+	release.Xid = asXid(c.Domain(), project.Namespace.FullPath, project.Path, rawRls.ref, raw.manifestPath, s(mnf.Name))
+	if release.Description == nil {
+		release.Description = stringOrNil(sp(project.Description))
+	}
+	release.CreatedAt = rawRls.commit.CommittedDate
+	if release.CreatedAt == nil {
+		release.CreatedAt = rawRls.commit.CreatedAt
+	}
+	release.Repository = crawlerMeta.DataSource
+	release.Licensor = licensor
+	release.DocumentationLanguage = c.normDocumentationLanguage(mnf.DocumentationLanguage, release.Description)
+	for _, sw := range release.Software {
+		sw.DiscoveredAt = crawlerMeta.DiscoveredAt
+		sw.LastIndexedAt = crawlerMeta.LastIndexedAt
+		sw.DataSource = crawlerMeta.DataSource
+	}
+
+	// info files that are not referenced in the manifest are looked up by name
+	release.Readme = c.normInfoFile(raw, rawRls, mnf.Readme, []string{"README"}, crawlerMeta)
+	release.ContributionGuide = c.normInfoFile(raw, rawRls, mnf.ContributionGuide, []string{"CONTRIBUTING"}, crawlerMeta)
+	release.Bom = c.normInfoFile(raw, rawRls, mnf.Bom, []string{"BOM", "BILLOFMATERIALS"}, crawlerMeta)
+	release.ManufacturingInstructions = c.normInfoFile(raw, rawRls, mnf.ManufacturingInstructions, []string{"MANUFACTURINGINSTRUCTIONS", "MANUFACTURING"}, crawlerMeta)
+	release.UserManual = c.normInfoFile(raw, rawRls, mnf.UserManual, []string{"USERGUIDE", "USERMANUAL"}, crawlerMeta)
+
+	return release
+}
+
+// normSubComponents completes the information of the sub-components described
+// by the parts of the manifest. The information not contained in the manifest
+// is inherited from the parent component.
+func normSubComponents(parent *models.Component, crawlerMeta *models.CrawlerMetaImpl) {
+	for _, cmp := range parent.Components {
+		cmp.DiscoveredAt = crawlerMeta.DiscoveredAt
+		cmp.LastIndexedAt = crawlerMeta.LastIndexedAt
+		cmp.DataSource = crawlerMeta.DataSource
+
+		// Xid format: domain.tld/owner/repo/ref/file-path/component-name/part-name
+		cmp.Xid = p(*parent.Xid + "/" + *asXid(s(*cmp.Name)))
+		if cmp.Description == nil {
+			cmp.Description = parent.Description
+		}
+		if cmp.DocumentationLanguage == nil {
+			cmp.DocumentationLanguage = parent.DocumentationLanguage
+		}
+		cmp.CreatedAt = parent.CreatedAt
+		cmp.IsLatest = parent.IsLatest
+		cmp.Repository = parent.Repository
+		if cmp.License == nil {
+			cmp.License = parent.License
+			cmp.LicenseExpression = parent.LicenseExpression
+		}
+		cmp.Licensor = parent.Licensor
+
+		normSubComponents(cmp, crawlerMeta)
+	}
+}
+
+// normForkOf returns the product, that the project was forked from. As the
+// origin might not be indexed yet, a placeholder is returned. It refers to the
+// manifest file at the same path as in the fork.
+func (c *GitLabCrawler) normForkOf(raw *rawData) *models.Product {
+	parent := raw.project.ForkedFromProject
+	if parent == nil {
+		return nil
+	}
+	namespace := path.Dir(parent.PathWithNamespace)
+	if parent.Namespace != nil {
+		namespace = parent.Namespace.FullPath
+	}
+	dataSource := &models.Repository{
+		// Xid format: domain.tld/owner/repo/ref/file-path
+		Xid:       asXid(c.Domain(), namespace, parent.Path, parent.DefaultBranch, raw.manifestPath),
+		URL:       p(c.projectURL(parent)),
+		PermaURL:  p(c.rawFileURL(parent, parent.DefaultBranch, raw.manifestPath)),
+		Host:      c.host,
+		Name:      p(parent.Path),
+		Reference: p(parent.DefaultBranch),
+		Path:      p(raw.manifestPath),
+	}
+	// Xid format: domain.tld/owner/repo/file-path
+	xid := asXid(c.Domain(), namespace, parent.Path, raw.manifestPath)
+	return models.NewProductPlaceholder(*xid, parent.Name, dataSource, raw.timestamp)
+}
+
+// normRepository returns the source of the component. The reference is the
+// name of the tag or branch, whereas the perma URL points to the commit.
+func (c *GitLabCrawler) normRepository(raw *rawData, rawRls *rawRelease, owner models.UserOrGroup) *models.Repository {
+	project := raw.project
+	return &models.Repository{
+		// Xid format: domain.tld/owner/repo/ref/file-path
+		Xid:       asXid(c.Domain(), project.Namespace.FullPath, project.Path, rawRls.ref, raw.manifestPath),
+		URL:       p(c.projectURL(project)),
+		PermaURL:  p(c.rawFileURL(project, rawRls.commit.ID, raw.manifestPath)),
+		Host:      c.host,
+		Owner:     owner,
+		Name:      p(project.Path),
+		Reference: p(rawRls.ref),
+		Path:      p(raw.manifestPath),
+	}
+}
+
+// normDocumentationLanguage returns the documentation language of the
+// product. If not specified in the manifest, the language is guessed from the
+// description.
+func (c *GitLabCrawler) normDocumentationLanguage(lang string, description *string) *string {
+	lang = strings.TrimSpace(lang)
+	if lang != "" {
+		return &lang
+	}
+	defaultLang := "en"
+	if description == nil || *description == "" {
+		return &defaultLang
+	}
+	lang = whatlanggo.DetectLang(*description).Iso6391()
+	if lang == "" {
+		return &defaultLang
+	}
+	return &lang
+}
+
+// normFile returns the file referenced in the manifest. References are either
+// paths relative to the manifest file or URLs pointing into the same
+// project. Files that don't exist in the repository are ignored.
+func (c *GitLabCrawler) normFile(raw *rawData, rawRls *rawRelease, ref string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	project := raw.project
+
+	var filePath string
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		prdURL, err := models.NewProductURLFromURL(ref)
+		if err != nil || prdURL.Domain != c.Domain() ||
+			!strings.EqualFold(prdURL.Owner, project.Namespace.FullPath) ||
+			!strings.EqualFold(prdURL.Repo, project.Path) {
+			return nil
+		}
+		filePath = prdURL.Path
+	} else if strings.HasPrefix(ref, "/") {
+		filePath = path.Clean(strings.TrimLeft(ref, "/"))
+	} else {
+		filePath = path.Clean(path.Join(manifestDir(raw.manifestPath), ref))
+	}
+	if _, ok := rawRls.files[filePath]; !ok {
+		return nil
+	}
+
+	file := &models.File{}
+	file.Path = p(filePath)
+	file.Name = p(path.Base(filePath))
+	file.MimeType = stringOrNil(p(mime.TypeByExtension(path.Ext(filePath))))
+	file.URL = p(c.rawFileURL(project, rawRls.commit.ID, filePath))
+	file.DiscoveredAt = crawlerMeta.DiscoveredAt
+	file.LastIndexedAt = crawlerMeta.LastIndexedAt
+	file.DataSource = crawlerMeta.DataSource
+	// Xid format: domain.tld/owner/repo/ref/file-path
+	file.Xid = asXid(c.Domain(), project.Namespace.FullPath, project.Path, rawRls.ref, filePath)
+
+	return file
+}
+
+// normInfoFile returns the info file of the product. If the file is not
+// referenced in the manifest, then it is looked up by name in the directory
+// of the manifest file.
+func (c *GitLabCrawler) normInfoFile(raw *rawData, rawRls *rawRelease, ref string, names []string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	if file := c.normFile(raw, rawRls, ref, crawlerMeta); file != nil {
+		return file
+	}
+	dir := manifestDir(raw.manifestPath)
+	for filePath := range rawRls.files {
+		if manifestDir(filePath) != dir {
+			continue
+		}
+		filename := path.Base(filePath)
+		if pos := strings.LastIndexByte(filename, '.'); pos != -1 {
+			filename = filename[:pos]
+		}
+		filename = strings.TrimSpace(filename)
+		filename = strings.Replace(filename, " ", "", -1)
+		filename = strings.Replace(filename, "-", "", -1)
+		filename = strings.Replace(filename, "_", "", -1)
+		filename = strings.ToUpper(filename)
+		for _, name := range names {
+			if filename == name {
+				return c.normFile(raw, rawRls, "/"+filePath, crawlerMeta)
+			}
+		}
+	}
+	return nil
+}
+
+// normLicensor returns the namespace (user or group) of the project.
+func (c *GitLabCrawler) normLicensor(namespace *glclient.Namespace) models.UserOrGroup {
+	xid := asXid(c.Domain(), namespace.FullPath)
+	url := stringOrNil(p(s(namespace.WebURL)))
+	if url == nil {
+		url = p(c.baseURL + "/" + namespace.FullPath)
+	}
+
+	if namespace.IsGroup() {
+		return &models.Group{
+			Xid:      xid,
+			Host:     c.host,
+			Name:     p(namespace.FullPath),
+			FullName: stringOrNil(p(s(namespace.Name))),
+			Members:  []models.UserOrGroup{}, //TODO
+			URL:      url,
+		}
+	}
+	return &models.User{
+		Xid:      xid,
+		Host:     c.host,
+		Name:     p(namespace.FullPath),
+		FullName: stringOrNil(p(s(namespace.Name))),
+		URL:      url,
+	}
+}
+
+// projectURL returns the web URL of the project.
+func (c *GitLabCrawler) projectURL(project *glclient.Project) string {
+	if project.WebURL != "" {
+		return project.WebURL
+	}
+	return c.baseURL + "/" + escapePath(project.PathWithNamespace)
+}
+
+// rawFileURL returns the URL to the raw content of a file at the given
+// reference.
+// format: {base-url}/{namespace}/{repo}/-/raw/{ref}/{path}
+func (c *GitLabCrawler) rawFileURL(project *glclient.Project, ref, filePath string) string {
+	return c.projectURL(project) + "/-/raw/" + url.PathEscape(ref) + "/" + escapePath(filePath)
+}
+
+// TODO: make this better, e.g. https://github.com/abhinav/goldmark-hashtag
+// https://meta.stackexchange.com/a/298981
+var validTagPattern = regexp.MustCompile(`^[a-z0-9_.+-]+$`)
+
+// normTags parses the project topics into a slice of tags.
+func normTags(topics []string) []*models.Tag {
+	if len(topics) == 0 {
+		return nil
+	}
+	tags := make([]*models.Tag, 0, len(topics))
+	for _, topic := range topics {
+		name := strings.ToLower(strings.TrimSpace(topic))
+		if len(name) == 0 ||
+			len(name) > 128 ||
+			!validTagPattern.MatchString(name) {
+			continue
+		}
+		tags = append(tags, &models.Tag{
+			Name: &name,
+		})
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// manifestDir returns the directory of the manifest file including a trailing
+// slash or an empty string if the manifest is located in the root directory.
+func manifestDir(manifestPath string) string {
+	dir := path.Dir(manifestPath)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// escapePath escapes each segment of a slash separated path.
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// stringOrNil returns the string pointer if it is non nil and contains a string
+// else returns nil.
+func stringOrNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sp(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	*s = strings.TrimSpace(*s)
+	return s
+}
+
+func s(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func p[T any](v T) *T {
+	return &v
+}
+
+func asXid(args ...string) *string {
+	if args == nil || len(args) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(args); i++ {
+		if i > 0 {
+			b.WriteString("/")
+		}
+		if args[i] == "" {
+			b.WriteString("-")
+		} else {
+			b.WriteString(url.PathEscape(args[i]))
+		}
+	}
+	xid := b.String()
+	return &xid
+}
@@ -0,0 +1,313 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package local normalizes manifest files from the local file system, so that
+// they can be validated before they are published on a platform.
+package local
+
+import (
+	"context"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/infra/dgraph/dgclient"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+// maxFileSizeLicense is the maximum size of a license file, that is read to
+// detect the license.
+const maxFileSizeLicense = 100 * 1024
+
+// Normalizer creates products from local manifest files.
+type Normalizer struct {
+	productService *services.Service
+}
+
+// NewNormalizer creates a new Normalizer.
+func NewNormalizer(productService *services.Service) *Normalizer {
+	return &Normalizer{
+		productService: productService,
+	}
+}
+
+// ListFiles returns the paths of all files in the given directory and its sub
+// directories relative to it. Hidden directories (e.g. `.git`) are skipped.
+func ListFiles(dir string) (map[string]struct{}, error) {
+	files := make(map[string]struct{})
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list files next to the manifest")
+	}
+	return files, nil
+}
+
+// rawData is the information about a local manifest file.
+type rawData struct {
+	timestamp    time.Time
+	manifestPath string
+	// paths of all files next to the manifest
+	files map[string]struct{}
+	// repoXid is the Xid of the repository declared in the manifest without
+	// the file path, e.g. `github.com/owner/repo`
+	repoXid string
+	repoURL string
+}
+
+// NormalizeProduct creates a normalized product from the manifest file at the
+// given path. The files are the paths of the files next to the manifest as
+// returned by `ListFiles`. As the manifest is not published yet, the product is
+// identified by the repository declared in the manifest and the license of
+// the product is detected from a local license file, if the manifest declares
+// none.
+func (n *Normalizer) NormalizeProduct(ctx context.Context, manifestPath string, mnf *manifest.Manifest, files map[string]struct{}) (*models.Product, error) {
+	repoURL, err := url.Parse(s(mnf.Repo))
+	if err != nil || repoURL.Hostname() == "" {
+		return nil, errors.Errorf("invalid repository '%s'", mnf.Repo)
+	}
+	domain := strings.ToLower(repoURL.Hostname())
+	repoPath := strings.Trim(repoURL.Path, "/")
+	raw := &rawData{
+		timestamp:    time.Now(),
+		manifestPath: manifestPath,
+		files:        files,
+		repoXid:      domain + "/" + repoPath,
+		repoURL:      strings.TrimRight(repoURL.String(), "/"),
+	}
+	manifestName := filepath.Base(manifestPath)
+	host := &models.Host{Domain: &domain, Name: &domain}
+
+	// release
+	licensor := &models.User{
+		// Xid format: domain.tld/licensor
+		Xid:  p(domain + "/" + url.PathEscape(s(mnf.Licensor))),
+		Host: host,
+		Name: p(s(mnf.Licensor)),
+	}
+	crawlerMeta := &models.CrawlerMetaImpl{
+		DiscoveredAt:  &raw.timestamp,
+		LastIndexedAt: &raw.timestamp,
+		DataSource: &models.Repository{
+			// Xid format: domain.tld/repo-path/file-path
+			Xid:      p(raw.repoXid + "/" + manifestName),
+			URL:      &raw.repoURL,
+			PermaURL: &raw.repoURL,
+			Host:     host,
+			Name:     p(path.Base(repoPath)),
+			Path:     &manifestName,
+		},
+	}
+	fileResolver := func(ref string) *models.File {
+		return normFile(raw, ref, crawlerMeta)
+	}
+	release := mnf.ToComponent(fileResolver, n.productService.ResolveLicenseExpression)
+	release.DiscoveredAt = crawlerMeta.DiscoveredAt
+	release.LastIndexedAt = crawlerMeta.LastIndexedAt
+	release.DataSource = crawlerMeta.DataSource
+	if release.License == nil && s(mnf.License) == "" {
+		// fall back to the license file, if the manifest declares no license
+		if license := n.detectLicense(ctx, raw); license != nil {
+			release.License = license
+			release.LicenseExpression = license.Xid
+		}
+	}
+
+	// Xid format: domain.tld/repo-path/version/file-path/component-name
+	release.Xid = p(raw.repoXid + "/" + url.PathEscape(s(mnf.Version)) + "/" + manifestName + "/" + url.PathEscape(s(mnf.Name)))
+	release.CreatedAt = &raw.timestamp
+	release.Releases = []*models.Component{release}
+	release.IsLatest = p(true)
+	release.Repository = crawlerMeta.DataSource
+	release.Licensor = licensor
+	for _, sw := range release.Software {
+		sw.DiscoveredAt = crawlerMeta.DiscoveredAt
+		sw.LastIndexedAt = crawlerMeta.LastIndexedAt
+		sw.DataSource = crawlerMeta.DataSource
+	}
+
+	// info files that are not referenced in the manifest are looked up by name
+	release.Readme = normInfoFile(raw, mnf.Readme, []string{"README"}, crawlerMeta)
+	release.ContributionGuide = normInfoFile(raw, mnf.ContributionGuide, []string{"CONTRIBUTING"}, crawlerMeta)
+	release.Bom = normInfoFile(raw, mnf.Bom, []string{"BOM", "BILLOFMATERIALS"}, crawlerMeta)
+	release.ManufacturingInstructions = normInfoFile(raw, mnf.ManufacturingInstructions, []string{"MANUFACTURINGINSTRUCTIONS", "MANUFACTURING"}, crawlerMeta)
+	release.UserManual = normInfoFile(raw, mnf.UserManual, []string{"USERGUIDE", "USERMANUAL"}, crawlerMeta)
+
+	normSubComponents(release, crawlerMeta)
+
+	// product
+	state := dgclient.ProductStateActive
+	product := &models.Product{
+		DiscoveredAt:  release.DiscoveredAt,
+		LastIndexedAt: release.LastIndexedAt,
+		DataSource:    release.DataSource,
+		// Xid format: domain.tld/repo-path/file-path
+		Xid:                   crawlerMeta.DataSource.Xid,
+		Name:                  release.Name,
+		Description:           release.Description,
+		DocumentationLanguage: release.DocumentationLanguage,
+		Version:               release.Version,
+		License:               release.License,
+		Licensor:              licensor,
+		Website:               crawlerMeta.DataSource.URL,
+		State:                 &state,
+		Release:               release,
+		Releases:              []*models.Component{release},
+	}
+	release.Product = product
+
+	return product, nil
+}
+
+// detectLicense detects the license from the license file next to the
+// manifest.
+func (n *Normalizer) detectLicense(ctx context.Context, raw *rawData) *models.License {
+	licensePath := services.FindLicenseFile(raw.files, "")
+	if licensePath == "" {
+		return nil
+	}
+	licenseFile := filepath.Join(filepath.Dir(raw.manifestPath), filepath.FromSlash(licensePath))
+	if info, err := os.Stat(licenseFile); err != nil || info.Size() > maxFileSizeLicense {
+		return nil
+	}
+	content, err := os.ReadFile(licenseFile)
+	if err != nil {
+		return nil
+	}
+	return n.productService.DetectLicense(ctx, string(content))
+}
+
+// normSubComponents completes the information of the sub-components described
+// by the parts of the manifest. The information not contained in the manifest
+// is inherited from the parent component.
+func normSubComponents(parent *models.Component, crawlerMeta *models.CrawlerMetaImpl) {
+	for _, cmp := range parent.Components {
+		cmp.DiscoveredAt = crawlerMeta.DiscoveredAt
+		cmp.LastIndexedAt = crawlerMeta.LastIndexedAt
+		cmp.DataSource = crawlerMeta.DataSource
+
+		// Xid format: domain.tld/repo-path/file-path/component-name/part-name
+		cmp.Xid = p(*parent.Xid + "/" + url.PathEscape(s(*cmp.Name)))
+		if cmp.Description == nil {
+			cmp.Description = parent.Description
+		}
+		if cmp.DocumentationLanguage == nil {
+			cmp.DocumentationLanguage = parent.DocumentationLanguage
+		}
+		cmp.CreatedAt = parent.CreatedAt
+		cmp.IsLatest = parent.IsLatest
+		cmp.Repository = parent.Repository
+		if cmp.License == nil {
+			cmp.License = parent.License
+			cmp.LicenseExpression = parent.LicenseExpression
+		}
+		cmp.Licensor = parent.Licensor
+
+		normSubComponents(cmp, crawlerMeta)
+	}
+}
+
+// normFile returns the file referenced in the manifest or nil, if the file
+// doesn't exist next to the manifest. References by URL cannot be resolved
+// locally.
+func normFile(raw *rawData, ref string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return nil
+	}
+	filePath := path.Clean(strings.TrimLeft(ref, "/"))
+	if _, ok := raw.files[filePath]; !ok {
+		return nil
+	}
+
+	file := &models.File{}
+	file.Path = p(filePath)
+	file.Name = p(path.Base(filePath))
+	file.MimeType = stringOrNil(mime.TypeByExtension(path.Ext(filePath)))
+	file.URL = p(raw.repoURL + "/" + filePath)
+	file.DiscoveredAt = crawlerMeta.DiscoveredAt
+	file.LastIndexedAt = crawlerMeta.LastIndexedAt
+	file.DataSource = crawlerMeta.DataSource
+	// Xid format: domain.tld/repo-path/file-path
+	file.Xid = p(raw.repoXid + "/" + filePath)
+	return file
+}
+
+// normInfoFile returns the referenced info file or looks it up by its name, if
+// it isn't referenced in the manifest.
+func normInfoFile(raw *rawData, ref string, names []string, crawlerMeta *models.CrawlerMetaImpl) *models.File {
+	if file := normFile(raw, ref, crawlerMeta); file != nil {
+		return file
+	}
+	for filePath := range raw.files {
+		if strings.Contains(filePath, "/") {
+			continue
+		}
+		filename := filePath
+		if pos := strings.LastIndexByte(filename, '.'); pos != -1 {
+			filename = filename[:pos]
+		}
+		filename = strings.TrimSpace(filename)
+		filename = strings.Replace(filename, " ", "", -1)
+		filename = strings.Replace(filename, "-", "", -1)
+		filename = strings.Replace(filename, "_", "", -1)
+		filename = strings.ToUpper(filename)
+		for _, name := range names {
+			if filename == name {
+				return normFile(raw, filePath, crawlerMeta)
+			}
+		}
+	}
+	return nil
+}
+
+// stringOrNil returns a pointer to the string or nil, if it is empty.
+func stringOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func s(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func p[T any](v T) *T {
+	return &v
+}
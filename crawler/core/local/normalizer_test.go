@@ -0,0 +1,106 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"losh/crawler/core/validator"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log/logtest"
+)
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+func TestListFiles(t *testing.T) {
+	files, err := ListFiles("testdata/motor-driver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"okh.toml", "README.md", "docs/bom.csv", "board.kicad_pcb"} {
+		if _, ok := files[want]; !ok {
+			t.Errorf("ListFiles() lacks %s: %v", want, files)
+		}
+	}
+}
+
+// TestNormalizeProduct validates a local manifest without a database like a
+// published one.
+func TestNormalizeProduct(t *testing.T) {
+	manifestPath := filepath.Join("testdata", "motor-driver", "okh.toml")
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnf, err := manifest.Parse(manifestPath, content)
+	if err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	files, err := ListFiles(filepath.Dir(manifestPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := services.NewService(nil)
+	xid, name := "CERN-OHL-S-2.0", "CERN Open Hardware Licence Version 2 - Strongly Reciprocal"
+	svc.LoadLicenses([]*models.License{{Xid: &xid, Name: &name}}, nil)
+
+	prd, err := NewNormalizer(svc).NormalizeProduct(context.Background(), manifestPath, mnf, files)
+	if err != nil {
+		t.Fatalf("NormalizeProduct() error = %v", err)
+	}
+	if err = validator.NewValidator(svc).ValidateProduct(prd); err != nil {
+		t.Fatalf("ValidateProduct() error = %v", err)
+	}
+
+	if *prd.Xid != "github.com/example/motor-driver/okh.toml" {
+		t.Errorf("Xid = %s", *prd.Xid)
+	}
+	if prd.License == nil || *prd.License.Xid != xid {
+		t.Errorf("License = %v, want %s", prd.License, xid)
+	}
+	release := prd.Release
+	if *release.Xid != "github.com/example/motor-driver/1.2.0/okh.toml/Motor%20Driver" {
+		t.Errorf("release Xid = %s", *release.Xid)
+	}
+	if release.Readme == nil || *release.Readme.Path != "README.md" {
+		t.Errorf("Readme = %v, want README.md", release.Readme)
+	}
+	if release.Bom == nil || *release.Bom.URL != "https://github.com/example/motor-driver/docs/bom.csv" {
+		t.Errorf("Bom = %v, want docs/bom.csv", release.Bom)
+	}
+	if len(release.Components) != 1 || *release.Components[0].Xid != *release.Xid+"/Enclosure" {
+		t.Fatalf("Components = %v, want the enclosure", release.Components)
+	}
+	if release.Components[0].License != prd.License {
+		t.Errorf("part does not inherit the license")
+	}
+}
+
+func TestNormalizeProductInvalidRepository(t *testing.T) {
+	svc := services.NewService(nil)
+	svc.LoadLicenses(nil, nil)
+	_, err := NewNormalizer(svc).NormalizeProduct(context.Background(), "okh.toml", &manifest.Manifest{Repo: "example/repo"}, nil)
+	if err == nil {
+		t.Errorf("NormalizeProduct() succeeded for a repository without host")
+	}
+}
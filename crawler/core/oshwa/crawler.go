@@ -0,0 +1,315 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwa
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/oshwa/oshwaclient"
+	"losh/crawler/core/validator"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	lerrors "losh/internal/lib/errors"
+	"losh/internal/lib/log"
+	"losh/internal/lib/net/ratelimit"
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/go-resty/resty/v2"
+
+	"go.uber.org/zap"
+
+	n "losh/internal/lib/net"
+)
+
+// Constants that define the crawler behavior.
+const (
+	crawlerName  = "oshwa.org"
+	timeout      = time.Duration(5) * time.Minute
+	perPage      = 100
+	retries      = 5
+	maxWaitTime  = 5 * time.Minute
+	maxRedirects = 5
+)
+
+type CrawlerState struct {
+	StartTime   time.Time     `json:"startTime"`
+	ElapsedTime time.Duration `json:"elapsedTime"`
+	NumCrawled  int64         `json:"numCrawled"`
+	NumIndexed  int64         `json:"numIndexed"`
+	Offset      int           `json:"offset"`
+}
+
+// OSHWACrawler is a product crawler for the certification list of the Open
+// Source Hardware Association (OSHWA).
+type OSHWACrawler struct {
+	productService *services.Service
+	validator      *validator.Validator
+
+	oshwaClient oshwaclient.OSHWAClient
+	log         *zap.SugaredLogger
+
+	stateStore crawler.StateStore
+	reconciler *crawler.Reconciler
+}
+
+// NewOSHWACrawler creates a new OSHWACrawler that uses the OSHWA certification
+// API. The API URL defaults to the public API if empty. The API requires a
+// token, which can be obtained from the OSHWA website.
+func NewOSHWACrawler(productService *services.Service, cfg config.OSHWAConfig) *OSHWACrawler {
+	log := log.NewLogger("crawler-oshwa")
+	userAgent, apiURL, token := cfg.UserAgent, cfg.APIURL, cfg.Token
+	rateLimit := cfg.RateLimit.OrDefault(time.Second, 5)
+	if apiURL == "" {
+		apiURL = oshwaclient.DefaultBaseURL
+	}
+	apiURL = strings.TrimRight(apiURL, "/")
+
+	// clients for external requests
+	httpClient := &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: n.NewRedirectHandler(maxRedirects),
+	}
+
+	// OSHWA REST client
+	restClient := resty.NewWithClient(httpClient).
+		SetBaseURL(apiURL).
+		SetHeader(n.HdrUserAgentKey, userAgent).
+		SetHeader(n.HdrAcceptKey, "application/json")
+	if token != "" {
+		restClient.SetAuthToken(token)
+	}
+	requester := request.NewRESTRequester(restClient).
+		SetLogger(log).
+		SetRetryCount(retries).
+		SetMaxWaitTime(maxWaitTime).
+		SetRetryOnCodes([]int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}).
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
+
+	return NewOSHWACrawlerWithClient(productService, oshwaclient.NewAPIClient(restClient, requester))
+}
+
+// NewOSHWACrawlerWithClient creates a new OSHWACrawler that uses the given
+// client. Use it together with the `oshwaclient.FixtureClient` to run the
+// crawler against recorded API responses.
+func NewOSHWACrawlerWithClient(productService *services.Service, oshwaClient oshwaclient.OSHWAClient) *OSHWACrawler {
+	return &OSHWACrawler{
+		productService: productService,
+		validator:      validator.NewValidator(productService),
+
+		oshwaClient: oshwaClient,
+
+		log:        log.NewLogger("crawler-oshwa"),
+		stateStore: crawler.NewFileStateStore(pathlib.NewPath("."), false),
+	}
+}
+
+// Domain returns the domain of the crawled platform.
+func (c *OSHWACrawler) Domain() string {
+	return crawlerName
+}
+
+// SetReconciler sets the reconciler, that detects removed products after a
+// complete discovery run. No reconciliation takes place, if it is nil.
+func (c *OSHWACrawler) SetReconciler(reconciler *crawler.Reconciler) *OSHWACrawler {
+	c.reconciler = reconciler
+	return c
+}
+
+// SetStateStore sets the store, that keeps the state of the crawler runs.
+func (c *OSHWACrawler) SetStateStore(store crawler.StateStore) *OSHWACrawler {
+	c.stateStore = store
+	return c
+}
+
+func (c *OSHWACrawler) DiscoverProducts(ctx context.Context) error {
+	c.log.Infof("discovering products on %s", crawlerName)
+
+	state, err := c.loadState(ctx)
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to load state")
+	}
+
+	runStartedAt := time.Now()
+	prevElapsedTime := state.ElapsedTime
+	hasNextPage := true
+	for hasNextPage {
+		c.log.Debugf("getting %d certified projects at offset %d", perPage, state.Offset)
+
+		result, err := c.oshwaClient.ListProjects(ctx, state.Offset, perPage)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to list certified projects")
+		}
+		discoveredAt := time.Now()
+
+		for _, project := range result.Items {
+			productID := models.NewProductID(crawlerName, "", "", certificationPageName(project.OSHWAUID))
+
+			// get full product information
+			c.log.Infof("indexing product (%s)", productID.String())
+			prd, err := c.getProduct(project, discoveredAt)
+			if err != nil {
+				if vldErr, ok := err.(*validator.ValidationError); ok {
+					c.log.Debugf("skipping (%s): %s", productID.String(), vldErr.Error())
+					continue
+				}
+				return lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+			}
+
+			// save product
+			c.log.Debugf("saving product (%s)", productID.String())
+			diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+			if err != nil {
+				return lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+			}
+			for _, diff := range diffs {
+				c.log.Debugf("changed %s", diff)
+			}
+
+			state.NumIndexed++
+		}
+
+		hasNextPage = len(result.Items) == perPage && state.Offset+len(result.Items) < result.Total
+		state.Offset += len(result.Items)
+
+		// save state
+		state.NumCrawled += int64(len(result.Items))
+		state.ElapsedTime = prevElapsedTime + time.Now().Sub(runStartedAt)
+		err = c.saveState(ctx, state)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to save state")
+		}
+		c.log.Debugf("indexed %d of %d products on %s this far", state.NumIndexed, state.NumCrawled, crawlerName)
+	}
+
+	// detect products, that have been removed from the platform
+	if c.reconciler != nil {
+		err = c.reconciler.Reconcile(ctx, c, state.StartTime)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to reconcile products")
+		}
+	}
+
+	// the run is completed
+	err = c.stateStore.Complete(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover))
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to complete state")
+	}
+
+	return nil
+}
+
+func (c *OSHWACrawler) UpdateProducts(ctx context.Context) error {
+	return nil
+}
+
+// getProduct checks and normalizes the information of a certified project.
+func (c *OSHWACrawler) getProduct(project *oshwaclient.Project, discoveredAt time.Time) (*models.Product, error) {
+	// check mandatory fields
+	err := c.checkMandatory(project)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := c.NormalizeProduct(discoveredAt, project)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize product information")
+	}
+
+	// validate product
+	err = c.validator.ValidateProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	// return normalized product
+	return product, nil
+}
+
+// GetProduct retrieves the product information of the certified project
+// identified by the given product ID. The path of the product ID is either the
+// OSHWA UID or the name of the certification page (e.g. us000001.html).
+func (c *OSHWACrawler) GetProduct(ctx context.Context, productID models.ProductID) (*models.Product, error) {
+	project, err := c.oshwaClient.GetProject(ctx, productID.Path)
+	if err != nil {
+		if errors.Is(err, oshwaclient.ErrNotFound) {
+			return nil, errors.Wrapf(crawler.ErrProductNotFound, "failed to get product '%s'", productID.String())
+		}
+		return nil, errors.Wrap(err, "failed to get certified project")
+	}
+	product, err := c.getProduct(project, time.Now())
+	if err != nil {
+		if vldErr, ok := err.(*validator.ValidationError); ok {
+			return nil, errors.Wrap(vldErr, "invalid product")
+		}
+		return nil, errors.Wrap(err, "failed to get product information")
+	}
+	return product, nil
+}
+
+// loadState loads the state of the discovery run from the state store. A new
+// state is returned, if none is stored.
+func (c *OSHWACrawler) loadState(ctx context.Context) (CrawlerState, error) {
+	// new state
+	state := CrawlerState{
+		StartTime: time.Now(),
+		Offset:    0,
+	}
+	_, err := c.stateStore.Load(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), &state)
+	if err != nil {
+		return CrawlerState{}, err
+	}
+	return state, nil
+}
+
+// saveState saves the state of the discovery run to the state store.
+func (c *OSHWACrawler) saveState(ctx context.Context, state CrawlerState) error {
+	return c.stateStore.Save(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), state)
+}
+
+// checkMandatory checks if mandatory fields are present.
+func (c *OSHWACrawler) checkMandatory(project *oshwaclient.Project) error {
+	// the documentation URL serves as the readme of the product
+	filePaths := []string{}
+	if isURL(project.DocumentationURL) {
+		filePaths = append(filePaths, "README")
+	}
+
+	// quick check if mandatory fields are present
+	mdtFlds := validator.MandatoryFields{
+		OKHV:                  "OKH-LOSHv1.0",
+		Name:                  project.ProjectName,
+		Description:           project.ProjectDescription,
+		Version:               project.ProjectVersion,
+		Repository:            "https://certification.oshwa.org", // just to pass the check
+		License:               c.normLicenseID(project.HardwareLicense),
+		Licensor:              project.ResponsibleParty,
+		DocumentationLanguage: *c.normDocumentationLanguage(project.ProjectDescription),
+		FilePaths:             filePaths,
+	}
+	return c.validator.ValidateMandatory(mdtFlds)
+}
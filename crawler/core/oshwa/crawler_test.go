@@ -0,0 +1,157 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/oshwa/oshwaclient"
+	"losh/crawler/core/validator"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log/logtest"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+)
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+// newTestCrawler creates a crawler, that serves the recorded projects.
+func newTestCrawler(t *testing.T) *OSHWACrawler {
+	t.Helper()
+	licenses := []*models.License{}
+	for _, xid := range []string{"CERN-OHL-1.2", "MIT", "CC-BY-4.0", "CC-BY-SA-4.0"} {
+		xid := xid
+		licenses = append(licenses, &models.License{ID: p("0x" + xid), Xid: &xid, Name: &xid})
+	}
+	svc := services.NewService(nil)
+	svc.LoadLicenses(licenses, nil)
+	client, err := oshwaclient.NewFixtureClient(pathlib.NewPath("testdata/projects.json"))
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+	return NewOSHWACrawlerWithClient(svc, client)
+}
+
+// TestDiscoverFixtureProjects runs the discovery steps, except for saving,
+// against the recorded projects. Incomplete projects must be skipped.
+func TestDiscoverFixtureProjects(t *testing.T) {
+	c := newTestCrawler(t)
+	ctx := context.Background()
+
+	indexed := map[string]*models.Product{}
+	skipped := map[string]struct{}{}
+	for offset := 0; ; offset += 2 {
+		result, err := c.oshwaClient.ListProjects(ctx, offset, 2)
+		if err != nil {
+			t.Fatalf("failed to list projects: %v", err)
+		}
+		for _, project := range result.Items {
+			prd, err := c.getProduct(project, time.Now())
+			if err != nil {
+				var vldErr *validator.ValidationError
+				if !errors.As(err, &vldErr) {
+					t.Fatalf("getProduct(%s) failed: %v", project.OSHWAUID, err)
+				}
+				skipped[project.OSHWAUID] = struct{}{}
+				continue
+			}
+			indexed[project.OSHWAUID] = prd
+		}
+		if offset+len(result.Items) >= result.Total {
+			break
+		}
+	}
+
+	if len(indexed) != 2 || indexed["US000001"] == nil || indexed["DE000002"] == nil {
+		t.Fatalf("indexed %v, want US000001 and DE000002", indexed)
+	}
+	if _, ok := skipped["US000003"]; !ok {
+		t.Errorf("project US000003 was not skipped")
+	}
+
+	// unknown licenses are omitted
+	if lcs := indexed["DE000002"].License; lcs != nil {
+		t.Errorf("DE000002 License = %s, want nil", *lcs.Xid)
+	}
+	if lcss := indexed["DE000002"].Release.AdditionalLicenses; len(lcss) != 1 || *lcss[0].Xid != "CC-BY-4.0" {
+		t.Errorf("DE000002 AdditionalLicenses = %v, want [CC-BY-4.0]", lcss)
+	}
+}
+
+func TestNormalizeFixtureProject(t *testing.T) {
+	c := newTestCrawler(t)
+	prd, err := c.GetProduct(context.Background(), models.NewProductID(crawlerName, "", "", "us000001.html"))
+	if err != nil {
+		t.Fatalf("GetProduct failed: %v", err)
+	}
+
+	checkStr := func(name string, got *string, want string) {
+		t.Helper()
+		if got == nil || *got != want {
+			t.Errorf("%s = %v, want %q", name, got, want)
+		}
+	}
+	checkStr("Xid", prd.Xid, "oshwa.org/-/-/us000001.html")
+	checkStr("Name", prd.Name, "Example Motor Driver")
+	checkStr("Version", prd.Version, "1.2")
+	checkStr("Website", prd.Website, "https://example.org/motor-driver")
+	checkStr("DocumentationLanguage", prd.DocumentationLanguage, "en")
+	checkStr("Release.Xid", prd.Release.Xid, "oshwa.org/-/-/1.2/us000001.html")
+	checkStr("Release.Readme.URL", prd.Release.Readme.URL, "https://github.com/example/motor-driver")
+	checkStr("Licensor.Name", prd.Licensor.(*models.User).Name, "Example Robotics")
+	checkStr("Licensor.Email", prd.Licensor.(*models.User).Email, "hello@example.org")
+
+	if prd.License == nil {
+		t.Fatal("License = nil, want CERN-OHL-1.2")
+	}
+	checkStr("License.Xid", prd.License.Xid, "CERN-OHL-1.2")
+	additional := []string{}
+	for _, lcs := range prd.Release.AdditionalLicenses {
+		additional = append(additional, *lcs.Xid)
+	}
+	if len(additional) != 2 || additional[0] != "MIT" || additional[1] != "CC-BY-SA-4.0" {
+		t.Errorf("AdditionalLicenses = %v, want [MIT CC-BY-SA-4.0]", additional)
+	}
+
+	tags := []string{}
+	for _, tag := range prd.Tags {
+		tags = append(tags, *tag.Name)
+	}
+	wantTags := []string{"robotics", "electronics", "motor-driver", "robot"}
+	if len(tags) != len(wantTags) {
+		t.Fatalf("Tags = %v, want %v", tags, wantTags)
+	}
+	for i := range tags {
+		if tags[i] != wantTags[i] {
+			t.Errorf("Tags = %v, want %v", tags, wantTags)
+			break
+		}
+	}
+}
+
+func TestGetUnknownProject(t *testing.T) {
+	c := newTestCrawler(t)
+	_, err := c.GetProduct(context.Background(), models.NewProductID(crawlerName, "", "", "us999999.html"))
+	if !errors.Is(err, crawler.ErrProductNotFound) {
+		t.Errorf("GetProduct error = %v, want ErrProductNotFound", err)
+	}
+}
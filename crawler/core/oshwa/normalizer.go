@@ -0,0 +1,342 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwa
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"losh/crawler/core/oshwa/oshwaclient"
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+
+	"github.com/abadojack/whatlanggo"
+	"github.com/gookit/validate"
+)
+
+// licenseMapping translates the license names used by OSHWA into SPDX
+// license IDs.
+var licenseMapping = map[string]string{
+	// hardware
+	"CERN":           "CERN-OHL-1.2",
+	"CERN-OHL-P-2.0": "CERN-OHL-P-2.0",
+	"CERN-OHL-S-2.0": "CERN-OHL-S-2.0",
+	"CERN-OHL-W-2.0": "CERN-OHL-W-2.0",
+	"SOLDERPAD":      "SHL-2.1",
+	"TAPR":           "TAPR-OHL-1.0",
+	// software
+	"APACHE":       "Apache-2.0",
+	"BSD-2-CLAUSE": "BSD-2-Clause",
+	"BSD-3-CLAUSE": "BSD-3-Clause",
+	"GPL":          "GPL-3.0-only",
+	"LGPL":         "LGPL-3.0-only",
+	"MIT":          "MIT",
+	"MPL":          "MPL-2.0",
+	// documentation
+	"CC BY":         "CC-BY-4.0",
+	"CC BY-SA":      "CC-BY-SA-4.0",
+	"CC0":           "CC0-1.0",
+	"PUBLIC DOMAIN": "CC0-1.0",
+}
+
+// licensesWithoutID are license values that don't represent an actual
+// license.
+var licensesWithoutID = map[string]struct{}{
+	"":            {},
+	"NONE":        {},
+	"OTHER":       {},
+	"NO SOFTWARE": {},
+	"N/A":         {},
+}
+
+var host = &models.Host{
+	Domain: p("oshwa.org"),
+	Name:   p("OSHWA"),
+}
+
+// NormalizeProduct creates a normalized product from the given OSHWA
+// certification.
+func (c *OSHWACrawler) NormalizeProduct(timestamp time.Time, project *oshwaclient.Project) (*models.Product, error) {
+	product := &models.Product{}
+
+	// release
+	licensor := c.normLicensor(project)
+	crawlerMeta := &models.CrawlerMetaImpl{
+		DiscoveredAt:  &timestamp,
+		LastIndexedAt: &timestamp,
+		DataSource:    c.normRepository(project, licensor),
+	}
+	release := c.normRelease(project, licensor, crawlerMeta)
+
+	// crawler info
+	product.DiscoveredAt = release.DiscoveredAt
+	product.LastIndexedAt = release.LastIndexedAt
+	product.DataSource = release.DataSource
+
+	// product info
+	// Xid format: oshwa.org/-/-/us000000.html
+	product.Xid = asXid(*host.Domain, "", "", certificationPageName(project.OSHWAUID))
+	product.Name = release.Name
+	product.Description = release.Description
+	product.DocumentationLanguage = release.DocumentationLanguage
+	product.Version = release.Version
+	product.License = release.License
+	product.Licensor = licensor
+	product.Website = normURL(project.ProjectWebsite)
+	if product.Website == nil {
+		product.Website = normURL(project.DocumentationURL)
+	}
+	if product.Website == nil {
+		product.Website = product.DataSource.URL
+	}
+	product.State = p(dgclient.ProductStateUndetermined)
+	product.Release = release
+	product.Releases = []*models.Component{release}
+	release.Product = product
+
+	product.Forks = []*models.Product{}
+	product.Tags = normTags(project)
+
+	return product, nil
+}
+
+// normRelease returns the certified release of the product.
+func (c *OSHWACrawler) normRelease(project *oshwaclient.Project, licensor models.UserOrGroup, crawlerMeta *models.CrawlerMetaImpl) *models.Component {
+	release := &models.Component{}
+	release.DiscoveredAt = crawlerMeta.DiscoveredAt
+	release.LastIndexedAt = crawlerMeta.LastIndexedAt
+	release.DataSource = crawlerMeta.DataSource
+
+	// Xid format: oshwa.org/-/-/1.0.0/us000000.html
+	release.Xid = asXid(*host.Domain, "", "", s(project.ProjectVersion), certificationPageName(project.OSHWAUID))
+	release.Name = p(s(project.ProjectName))
+	release.Description = p(s(project.ProjectDescription))
+	release.Version = p(s(project.ProjectVersion))
+	release.CreatedAt = project.CertificationDate
+	release.Releases = []*models.Component{release}
+	release.IsLatest = p(true)
+	release.Repository = crawlerMeta.DataSource
+	release.License, release.AdditionalLicenses = c.normLicenses(project)
+	release.Licensor = licensor
+	release.DocumentationLanguage = c.normDocumentationLanguage(project.ProjectDescription)
+	release.TechnologyReadinessLevel = p(dgclient.TechnologyReadinessLevelUndetermined)
+	release.DocumentationReadinessLevel = p(dgclient.DocumentationReadinessLevelUndetermined)
+	// the certification page is the evidence of compliance
+	release.Attestation = crawlerMeta.DataSource.PermaURL
+	release.Software = []*models.Software{}
+
+	// files
+	// the externally hosted documentation serves as the readme
+	if docURL := normURL(project.DocumentationURL); docURL != nil {
+		release.Readme = &models.File{
+			DiscoveredAt:  crawlerMeta.DiscoveredAt,
+			LastIndexedAt: crawlerMeta.LastIndexedAt,
+			DataSource:    crawlerMeta.DataSource,
+			// Xid format: oshwa.org/-/-/ref/file-path
+			Xid:  asXid(*host.Domain, "", "", s(project.ProjectVersion), certificationPageName(project.OSHWAUID), "documentation"),
+			Name: p("documentation"),
+			Path: p("documentation"),
+			URL:  docURL,
+		}
+	}
+
+	return release
+}
+
+// normRepository returns the source of the component, which is the
+// certification page.
+func (c *OSHWACrawler) normRepository(project *oshwaclient.Project, owner models.UserOrGroup) *models.Repository {
+	productURL := &models.ProductURL{
+		Domain: *host.Domain,
+		Ref:    s(project.ProjectVersion),
+		Path:   certificationPageName(project.OSHWAUID),
+	}
+	return &models.Repository{
+		// Xid format: oshwa.org/-/-/ref/file-path
+		Xid:       asXid(productURL.Domain, productURL.Owner, productURL.Repo, productURL.Ref, productURL.Path),
+		URL:       p(productURL.RepositoryURL()),
+		PermaURL:  p(productURL.PermaURL()),
+		Host:      host,
+		Owner:     owner,
+		Name:      p(s(project.ProjectName)),
+		Reference: p(productURL.Ref),
+		Path:      p(productURL.Path),
+	}
+}
+
+// normLicenses returns the licenses of the product. The hardware license is
+// the main license, whereas the software and documentation licenses are
+// additional licenses.
+func (c *OSHWACrawler) normLicenses(project *oshwaclient.Project) (*models.License, []*models.License) {
+	var (
+		license            *models.License
+		additionalLicenses []*models.License
+	)
+	seen := map[string]struct{}{}
+	for i, lcsStr := range []string{project.HardwareLicense, project.SoftwareLicense, project.DocumentationLicense} {
+		lcsID := c.normLicenseID(lcsStr)
+		if lcsID == "" {
+			continue
+		}
+		lcs := c.productService.GetCachedLicenseByIDOrName(lcsID)
+		if lcs == nil {
+			continue
+		}
+		if i == 0 {
+			license = lcs
+			seen[lcsID] = struct{}{}
+			continue
+		}
+		if _, ok := seen[lcsID]; ok {
+			continue
+		}
+		seen[lcsID] = struct{}{}
+		additionalLicenses = append(additionalLicenses, lcs)
+	}
+	return license, additionalLicenses
+}
+
+// normLicenseID translates the license names used by OSHWA into SPDX license
+// IDs. It returns an empty string if the value doesn't represent a license.
+func (c *OSHWACrawler) normLicenseID(lcsStr string) string {
+	lcsStr = strings.TrimSpace(lcsStr)
+	key := strings.ToUpper(lcsStr)
+	if _, ok := licensesWithoutID[key]; ok {
+		return ""
+	}
+	if lcsID, ok := licenseMapping[key]; ok {
+		return lcsID
+	}
+	if c.productService.GetCachedLicenseByIDOrName(lcsStr) != nil {
+		return lcsStr
+	}
+	return ""
+}
+
+// normLicensor returns the responsible party of the certification.
+func (c *OSHWACrawler) normLicensor(project *oshwaclient.Project) models.UserOrGroup {
+	name := s(project.ResponsibleParty)
+	var email *string
+	if contact := s(project.PublicContact); validate.IsEmail(contact) {
+		email = &contact
+	}
+	return &models.User{
+		// Xid format: oshwa.org/responsible-party
+		Xid:      asXid(*host.Domain, name),
+		Host:     host,
+		Name:     &name,
+		FullName: &name,
+		Email:    email,
+	}
+}
+
+// normDocumentationLanguage returns the documentation language of the
+// product. The language is guessed from the description.
+func (c *OSHWACrawler) normDocumentationLanguage(description string) *string {
+	defaultLang := "en"
+	if strings.TrimSpace(description) == "" {
+		return &defaultLang
+	}
+	lang := whatlanggo.DetectLang(description).Iso6391()
+	if lang == "" {
+		return &defaultLang
+	}
+	return &lang
+}
+
+// TODO: make this better, e.g. https://github.com/abhinav/goldmark-hashtag
+// https://meta.stackexchange.com/a/298981
+var validTagPattern = regexp.MustCompile(`^[a-z0-9_.+-]+$`)
+
+// normTags turns the project types and keywords into a slice of tags.
+func normTags(project *oshwaclient.Project) []*models.Tag {
+	keywords := make([]string, 0, 1+len(project.AdditionalType)+len(project.ProjectKeywords))
+	keywords = append(keywords, project.PrimaryType)
+	keywords = append(keywords, project.AdditionalType...)
+	keywords = append(keywords, project.ProjectKeywords...)
+
+	tags := make([]*models.Tag, 0, len(keywords))
+	seen := make(map[string]struct{}, len(keywords))
+	for _, keyword := range keywords {
+		name := strings.ToLower(strings.TrimSpace(keyword))
+		name = strings.Join(strings.Fields(name), "-")
+		if _, ok := seen[name]; ok ||
+			len(name) == 0 ||
+			len(name) > 128 ||
+			!validTagPattern.MatchString(name) {
+			continue
+		}
+		seen[name] = struct{}{}
+		tags = append(tags, &models.Tag{
+			Name: p(name),
+		})
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// certificationPageName returns the file name of the certification page
+// (e.g. us000001.html) for the given OSHWA UID.
+func certificationPageName(uid string) string {
+	uid = strings.ToLower(strings.TrimSpace(uid))
+	if strings.HasSuffix(uid, ".html") {
+		return uid
+	}
+	return uid + ".html"
+}
+
+// normURL returns the URL if it is valid, else nil.
+func normURL(u string) *string {
+	u = strings.TrimSpace(u)
+	if !isURL(u) {
+		return nil
+	}
+	return &u
+}
+
+func isURL(u string) bool {
+	return validate.IsFullURL(strings.TrimSpace(u))
+}
+
+func s(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func p[T any](v T) *T {
+	return &v
+}
+
+func asXid(args ...string) *string {
+	if args == nil || len(args) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(args); i++ {
+		if i > 0 {
+			b.WriteString("/")
+		}
+		if args[i] == "" {
+			b.WriteString("-")
+		} else {
+			b.WriteString(url.PathEscape(args[i]))
+		}
+	}
+	xid := b.String()
+	return &xid
+}
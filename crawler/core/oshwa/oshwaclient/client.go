@@ -0,0 +1,197 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwaclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	gourl "net/url"
+	"strconv"
+	"strings"
+
+	"losh/internal/lib/net/request"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultBaseURL is the URL of the OSHWA certification API.
+const DefaultBaseURL = "https://certificationapi.oshwa.org/api"
+
+// ErrNotFound is returned if the requested project does not exist.
+var ErrNotFound = errors.New("project not found")
+
+// OSHWAClient provides access to the list of certified projects.
+type OSHWAClient interface {
+	// ListProjects returns a page of certified projects.
+	ListProjects(ctx context.Context, offset, limit int) (*ProjectList, error)
+	// GetProject returns the certified project with the given UID (e.g.
+	// US000001).
+	GetProject(ctx context.Context, uid string) (*Project, error)
+}
+
+// -----------------------------------------------------------------------------
+// APIClient
+// -----------------------------------------------------------------------------
+
+// APIClient is a client for the OSHWA certification API.
+type APIClient struct {
+	restClient *resty.Client
+	requester  *request.RESTRequester
+}
+
+// NewAPIClient creates a new OSHWA certification API client. The REST client
+// is expected to be configured with the API URL and authentication.
+func NewAPIClient(restClient *resty.Client, requester *request.RESTRequester) *APIClient {
+	return &APIClient{
+		restClient: restClient,
+		requester:  requester,
+	}
+}
+
+// ListProjects returns a page of certified projects.
+func (c *APIClient) ListProjects(ctx context.Context, offset, limit int) (*ProjectList, error) {
+	result := &ProjectList{}
+	req := c.restClient.R().
+		SetContext(ctx).
+		SetQueryParam("offset", strconv.Itoa(offset)).
+		SetQueryParam("limit", strconv.Itoa(limit)).
+		SetResult(result)
+	if _, err := c.do(req, "/projects"); err != nil {
+		return nil, errors.Wrap(err, "failed to list projects")
+	}
+	return result, nil
+}
+
+// GetProject returns the certified project with the given UID.
+func (c *APIClient) GetProject(ctx context.Context, uid string) (*Project, error) {
+	req := c.restClient.R().
+		SetContext(ctx)
+	resp, err := c.do(req, "/projects/"+gourl.PathEscape(normUID(uid)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get project")
+	}
+	project, err := decodeProject(resp.Body())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode project")
+	}
+	return project, nil
+}
+
+// do executes a GET request and checks the response status.
+func (c *APIClient) do(req *resty.Request, path string) (*resty.Response, error) {
+	resp, err := c.requester.Do(request.NewRESTRequest(req, http.MethodGet, path))
+	if resp != nil && resp.StatusCode() == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, errors.Errorf("request failed with status code %d", resp.StatusCode())
+	}
+	return resp, nil
+}
+
+// -----------------------------------------------------------------------------
+// FixtureClient
+// -----------------------------------------------------------------------------
+
+// FixtureClient serves certified projects from a recorded response of the
+// project list endpoint (`GET /projects`). It allows running the crawler
+// offline, e.g. for testing.
+type FixtureClient struct {
+	projects []*Project
+}
+
+// NewFixtureClient creates a new FixtureClient from the recorded JSON file.
+func NewFixtureClient(path pathlib.Path) (*FixtureClient, error) {
+	content, err := path.ReadFile()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read fixture file")
+	}
+	list := &ProjectList{}
+	content = bytes.TrimSpace(content)
+	if len(content) > 0 && content[0] == '[' {
+		err = json.Unmarshal(content, &list.Items)
+	} else {
+		err = json.Unmarshal(content, list)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal fixture file")
+	}
+	return &FixtureClient{projects: list.Items}, nil
+}
+
+// ListProjects returns a page of the recorded projects.
+func (c *FixtureClient) ListProjects(ctx context.Context, offset, limit int) (*ProjectList, error) {
+	result := &ProjectList{
+		Total:  len(c.projects),
+		Limit:  limit,
+		Offset: offset,
+		Items:  []*Project{},
+	}
+	if offset < len(c.projects) {
+		end := offset + limit
+		if end > len(c.projects) {
+			end = len(c.projects)
+		}
+		result.Items = c.projects[offset:end]
+	}
+	return result, nil
+}
+
+// GetProject returns the recorded project with the given UID.
+func (c *FixtureClient) GetProject(ctx context.Context, uid string) (*Project, error) {
+	uid = normUID(uid)
+	for _, project := range c.projects {
+		if strings.EqualFold(project.OSHWAUID, uid) {
+			return project, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// decodeProject decodes a single project. The API returns the project either
+// as an object or wrapped in a list.
+func decodeProject(content []byte) (*Project, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) > 0 && content[0] == '[' {
+		projects := []*Project{}
+		if err := json.Unmarshal(content, &projects); err != nil {
+			return nil, err
+		}
+		if len(projects) == 0 {
+			return nil, ErrNotFound
+		}
+		return projects[0], nil
+	}
+	project := &Project{}
+	if err := json.Unmarshal(content, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// normUID normalizes the UID of a project. It accepts the file name of the
+// certification page as well (e.g. us000001.html).
+func normUID(uid string) string {
+	uid = strings.TrimSpace(uid)
+	uid = strings.TrimSuffix(strings.ToLower(uid), ".html")
+	return strings.ToUpper(uid)
+}
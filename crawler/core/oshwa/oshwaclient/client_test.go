@@ -0,0 +1,90 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwaclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+)
+
+func newTestFixtureClient(t *testing.T) *FixtureClient {
+	t.Helper()
+	client, err := NewFixtureClient(pathlib.NewPath("../testdata/projects.json"))
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+	return client
+}
+
+func TestFixtureClientListProjects(t *testing.T) {
+	client := newTestFixtureClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		offset, limit int
+		wantUIDs      []string
+	}{
+		{0, 100, []string{"US000001", "DE000002", "US000003"}},
+		{0, 2, []string{"US000001", "DE000002"}},
+		{2, 2, []string{"US000003"}},
+		{3, 2, []string{}},
+	}
+	for _, tt := range tests {
+		list, err := client.ListProjects(ctx, tt.offset, tt.limit)
+		if err != nil {
+			t.Fatalf("ListProjects(%d, %d) failed: %v", tt.offset, tt.limit, err)
+		}
+		if list.Total != 3 {
+			t.Errorf("ListProjects(%d, %d).Total = %d, want 3", tt.offset, tt.limit, list.Total)
+		}
+		uids := make([]string, 0, len(list.Items))
+		for _, project := range list.Items {
+			uids = append(uids, project.OSHWAUID)
+		}
+		if len(uids) != len(tt.wantUIDs) {
+			t.Errorf("ListProjects(%d, %d) = %v, want %v", tt.offset, tt.limit, uids, tt.wantUIDs)
+			continue
+		}
+		for i := range uids {
+			if uids[i] != tt.wantUIDs[i] {
+				t.Errorf("ListProjects(%d, %d) = %v, want %v", tt.offset, tt.limit, uids, tt.wantUIDs)
+				break
+			}
+		}
+	}
+}
+
+func TestFixtureClientGetProject(t *testing.T) {
+	client := newTestFixtureClient(t)
+	ctx := context.Background()
+
+	for _, uid := range []string{"US000001", "us000001", "us000001.html"} {
+		project, err := client.GetProject(ctx, uid)
+		if err != nil {
+			t.Errorf("GetProject(%q) failed: %v", uid, err)
+			continue
+		}
+		if project.ProjectName != "Example Motor Driver" {
+			t.Errorf("GetProject(%q).ProjectName = %q, want %q", uid, project.ProjectName, "Example Motor Driver")
+		}
+	}
+
+	if _, err := client.GetProject(ctx, "US999999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProject(%q) error = %v, want ErrNotFound", "US999999", err)
+	}
+}
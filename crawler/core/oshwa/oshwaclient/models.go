@@ -0,0 +1,45 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oshwaclient
+
+import "time"
+
+// ProjectList is a page of certified projects.
+type ProjectList struct {
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+	Items  []*Project `json:"items"`
+}
+
+// Project contains the information about a certified project.
+type Project struct {
+	OSHWAUID             string     `json:"oshwaUid"`
+	ResponsibleParty     string     `json:"responsibleParty"`
+	Country              string     `json:"country"`
+	PublicContact        string     `json:"publicContact"`
+	ProjectName          string     `json:"projectName"`
+	ProjectWebsite       string     `json:"projectWebsite"`
+	ProjectVersion       string     `json:"projectVersion"`
+	ProjectDescription   string     `json:"projectDescription"`
+	PrimaryType          string     `json:"primaryType"`
+	AdditionalType       []string   `json:"additionalType"`
+	ProjectKeywords      []string   `json:"projectKeywords"`
+	DocumentationURL     string     `json:"documentationUrl"`
+	HardwareLicense      string     `json:"hardwareLicense"`
+	SoftwareLicense      string     `json:"softwareLicense"`
+	DocumentationLicense string     `json:"documentationLicense"`
+	CertificationDate    *time.Time `json:"certificationDate"`
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/lib/util/reflectutil"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/gookit/validate"
+)
+
+var (
+	// self-hosted instances may be served on a different port
+	xidPattern     = regexp.MustCompile(`(?i)^[a-z0-9-]+(\.[a-z0-9-]+)+(:[0-9]+)?/\S+$`)
+	versionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._+\-/]*$`)
+)
+
+// skippedFields are the fields of a product that refer to other products.
+// Those are validated on their own.
+var skippedFields = map[string]struct{}{
+	"RenamedTo":   {},
+	"RenamedFrom": {},
+	"ForkOf":      {},
+	"Forks":       {},
+}
+
+// checkProduct walks the graph of the product and checks every node, that is
+// not stored in the database yet.
+func (v *Validator) checkProduct(vldErr *ValidationError, product *models.Product) {
+	visited := make(map[models.Node]struct{})
+	v.checkNodeRec(vldErr, product, "", visited)
+	v.checkLicenseCompatibility(vldErr, product)
+}
+
+func (v *Validator) checkNodeRec(vldErr *ValidationError, node models.Node, path string, visited map[models.Node]struct{}) {
+	if reflectutil.IsNil(node) {
+		return
+	}
+	if _, ok := visited[node]; ok {
+		return
+	}
+	visited[node] = struct{}{}
+
+	// nodes with an ID are already stored and have been validated before
+	if node.GetID() != nil {
+		return
+	}
+	if prd, ok := node.(*models.Product); ok && prd.IsPlaceholder() {
+		return
+	}
+	// licenses are maintained separately and only referenced
+	switch node.(type) {
+	case *models.License, *models.Exception:
+		return
+	}
+
+	// check mandatory fields and descend into the sub nodes
+	val := reflectutil.Indirect(reflect.ValueOf(node))
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		if fld.PkgPath != "" || fld.Tag.Get("id") == "true" {
+			continue
+		}
+		if _, ok := skippedFields[fld.Name]; ok {
+			continue
+		}
+		fldVal := val.Field(i)
+		fldPath := joinPath(path, strings.Split(fld.Tag.Get("json"), ",")[0])
+
+		if isNilValue(fldVal) {
+			if fld.Tag.Get("mandatory") == "true" {
+				vldErr.Add(RuleMandatoryField, fldPath, "missing mandatory field")
+			}
+			continue
+		}
+
+		switch fldVal.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if sub, ok := fldVal.Interface().(models.Node); ok {
+				v.checkNodeRec(vldErr, sub, fldPath, visited)
+			}
+		case reflect.Slice:
+			for j := 0; j < fldVal.Len(); j++ {
+				if sub, ok := fldVal.Index(j).Interface().(models.Node); ok {
+					v.checkNodeRec(vldErr, sub, fmt.Sprintf("%s[%d]", fldPath, j), visited)
+				}
+			}
+		}
+	}
+
+	v.checkNode(vldErr, node, path)
+}
+
+// checkNode checks the format of the fields of a single node.
+func (v *Validator) checkNode(vldErr *ValidationError, node models.Node, path string) {
+	switch n := node.(type) {
+	case *models.Product:
+		checkXid(vldErr, n.Xid, path)
+		checkURL(vldErr, RuleURLFormat, n.Website, joinPath(path, "website"))
+		v.checkReachable(vldErr, n.Website, joinPath(path, "website"))
+		if n.License == nil {
+			vldErr.Add(RuleLicenseMissing, joinPath(path, "license"), "no license declared")
+		}
+		v.checkLicense(vldErr, n.License, joinPath(path, "license"))
+		checkVersion(vldErr, n.Version, joinPath(path, "version"))
+		checkLanguage(vldErr, n.DocumentationLanguage, joinPath(path, "documentationLanguage"))
+
+	case *models.Component:
+		checkXid(vldErr, n.Xid, path)
+		v.checkLicense(vldErr, n.License, joinPath(path, "license"))
+		if n.LicenseExpression != nil {
+			v.checkLicenseExpression(vldErr, *n.LicenseExpression, joinPath(path, "licenseExpression"))
+		}
+		checkVersion(vldErr, n.Version, joinPath(path, "version"))
+		checkLanguage(vldErr, n.DocumentationLanguage, joinPath(path, "documentationLanguage"))
+
+	case *models.Repository:
+		checkXid(vldErr, n.Xid, path)
+		checkURL(vldErr, RuleRepositoryURL, n.URL, joinPath(path, "url"))
+		v.checkReachable(vldErr, n.URL, joinPath(path, "url"))
+		checkURL(vldErr, RuleRepositoryURL, n.PermaURL, joinPath(path, "permaUrl"))
+
+	case *models.File:
+		checkXid(vldErr, n.Xid, path)
+		checkURL(vldErr, RuleURLFormat, n.URL, joinPath(path, "url"))
+		v.checkReachable(vldErr, n.URL, joinPath(path, "url"))
+	}
+}
+
+// checkLicenseCompatibility analyzes the licenses of the releases, that are not
+// stored in the database yet, and reports conflicting licenses.
+func (v *Validator) checkLicenseCompatibility(vldErr *ValidationError, product *models.Product) {
+	if product.IsPlaceholder() {
+		return
+	}
+	checked := make(map[*models.Component]struct{}, len(product.Releases)+1)
+	check := func(release *models.Component, path string) {
+		if release == nil || release.ID != nil {
+			return
+		}
+		if _, ok := checked[release]; ok {
+			return
+		}
+		checked[release] = struct{}{}
+
+		conflicts := v.productService.AnalyzeLicenseCompatibility(release)
+		for _, c := range conflicts {
+			vldErr.Add(RuleLicenseConflict, joinPath(path, c.Path), c.Message)
+		}
+	}
+	check(product.Release, "release")
+	for i, release := range product.Releases {
+		check(release, fmt.Sprintf("releases[%d]", i))
+	}
+}
+
+// checkLicense checks if the license is known.
+func (v *Validator) checkLicense(vldErr *ValidationError, license *models.License, path string) {
+	if license == nil || license.Xid == nil {
+		return
+	}
+	if v.productService.GetCachedLicenseByIDOrName(*license.Xid) == nil {
+		vldErr.Add(RuleLicenseUnknown, path, fmt.Sprintf("unknown license '%s'", *license.Xid))
+	}
+}
+
+// checkLicenseExpression checks if the license expression is well-formed and
+// refers to known licenses and exceptions only.
+func (v *Validator) checkLicenseExpression(vldErr *ValidationError, expr, path string) {
+	_, _, err := v.productService.ResolveLicenseExpression(expr)
+	if err == nil {
+		return
+	}
+	var leErr *services.LicenseExpressionError
+	if errors.As(err, &leErr) {
+		if leErr.IsUnknownLicense() {
+			vldErr.Add(RuleLicenseUnknown, path, fmt.Sprintf("unknown license '%s'", leErr.Token))
+			return
+		}
+		if leErr.IsUnknownException() {
+			vldErr.Add(RuleLicenseUnknown, path, fmt.Sprintf("unknown license exception '%s'", leErr.Token))
+			return
+		}
+	}
+	vldErr.Add(RuleLicenseExpression, path, err.Error())
+}
+
+// checkXid checks the format of an external ID.
+func checkXid(vldErr *ValidationError, xid *string, path string) {
+	if xid == nil {
+		return
+	}
+	if !xidPattern.MatchString(*xid) {
+		vldErr.Add(RuleXidFormat, joinPath(path, "xid"), fmt.Sprintf("invalid xid '%s'", *xid))
+	}
+}
+
+// checkURL checks if the URL is a full URL.
+func checkURL(vldErr *ValidationError, rule *Rule, url *string, path string) {
+	if url == nil {
+		return
+	}
+	if !validate.IsFullURL(*url) {
+		vldErr.Add(rule, path, fmt.Sprintf("invalid URL '%s'", *url))
+	}
+}
+
+// checkReachable checks if the URL is reachable, if a URL checker is set.
+// Malformed URLs are reported by `checkURL` already.
+func (v *Validator) checkReachable(vldErr *ValidationError, url *string, path string) {
+	if v.urlChecker == nil || url == nil || !validate.IsFullURL(*url) {
+		return
+	}
+	if err := v.urlChecker(*url); err != nil {
+		vldErr.Add(RuleURLUnreachable, path, fmt.Sprintf("unreachable URL '%s': %s", *url, err.Error()))
+	}
+}
+
+// checkVersion checks the format of a version string.
+func checkVersion(vldErr *ValidationError, version *string, path string) {
+	if version == nil {
+		return
+	}
+	if !versionPattern.MatchString(*version) {
+		vldErr.Add(RuleVersionFormat, path, fmt.Sprintf("unusual version '%s'", *version))
+	}
+}
+
+// checkLanguage checks if the language is a valid language tag.
+func checkLanguage(vldErr *ValidationError, lang *string, path string) {
+	if lang == nil {
+		return
+	}
+	if !isValidLanguageTag(*lang) {
+		vldErr.Add(RuleLanguageTag, path, fmt.Sprintf("invalid language tag '%s'", *lang))
+	}
+}
+
+// isNilValue returns true if the value is a nil pointer, interface, slice or
+// map.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// joinPath joins the path of a field with the path of its parent.
+func joinPath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError marks findings that prevent a product from being indexed.
+	SeverityError Severity = "error"
+	// SeverityWarning marks findings that are stored along with the product,
+	// but don't prevent it from being indexed.
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is a single conformance rule of the OKH-LOSH specification.
+type Rule struct {
+	// ID is the unique identifier of the rule.
+	ID string
+	// Severity is the severity of a violation of the rule.
+	Severity Severity
+	// Description is a short description of the rule.
+	Description string
+	// Hint describes how a violation of the rule can be fixed.
+	Hint string
+}
+
+var (
+	RuleManifestInvalid = &Rule{
+		ID:          "manifest-invalid",
+		Severity:    SeverityError,
+		Description: "The manifest file must exist and be a valid TOML or YAML file.",
+		Hint:        "Check the syntax of the manifest file and the types of its fields against the OKH-LOSH specification (https://github.com/iop-alliance/OpenKnowHow).",
+	}
+	RuleOKHVersion = &Rule{
+		ID:          "okh-version",
+		Severity:    SeverityError,
+		Description: "The manifest must declare a known OKH version.",
+		Hint:        "Set the field `okhv` of the manifest to `OKH-LOSHv1.0`.",
+	}
+	RuleMandatoryField = &Rule{
+		ID:          "mandatory-field",
+		Severity:    SeverityError,
+		Description: "Mandatory fields must be set.",
+		Hint:        "Add the missing field to the manifest or to the project description on its platform.",
+	}
+	RuleXidFormat = &Rule{
+		ID:          "xid-format",
+		Severity:    SeverityError,
+		Description: "External IDs must have the format `domain.tld/path`.",
+		Hint:        "This is most likely a problem of the crawler. Please report it along with the project URL.",
+	}
+	RuleRepositoryURL = &Rule{
+		ID:          "repository-url",
+		Severity:    SeverityError,
+		Description: "The repository URL must be a full URL.",
+		Hint:        "Set the field `repo` of the manifest to the full URL of the repository, e.g. `https://github.com/owner/repo`.",
+	}
+	RuleURLFormat = &Rule{
+		ID:          "url-format",
+		Severity:    SeverityWarning,
+		Description: "URLs should be full URLs.",
+		Hint:        "Use full URLs including the scheme, e.g. `https://example.com/page`.",
+	}
+	RuleURLUnreachable = &Rule{
+		ID:          "url-unreachable",
+		Severity:    SeverityWarning,
+		Description: "URLs should be reachable.",
+		Hint:        "Check if the URL is misspelled or if the linked page or file was moved or removed.",
+	}
+	RuleLicenseMissing = &Rule{
+		ID:          "license-missing",
+		Severity:    SeverityWarning,
+		Description: "The product should declare a license.",
+		Hint:        "Set the field `license` of the manifest to a SPDX license identifier, e.g. `CERN-OHL-S-2.0`.",
+	}
+	RuleLicenseExpression = &Rule{
+		ID:          "license-expression",
+		Severity:    SeverityError,
+		Description: "The license must be a valid SPDX license expression.",
+		Hint:        "Combine license identifiers with `AND`, `OR` and `WITH`, e.g. `CERN-OHL-S-2.0 AND CC-BY-4.0` (https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/).",
+	}
+	RuleLicenseUnknown = &Rule{
+		ID:          "license-unknown",
+		Severity:    SeverityError,
+		Description: "The license and its exceptions must be known.",
+		Hint:        "Use license and exception identifiers from the SPDX license list (https://spdx.org/licenses/, https://spdx.org/licenses/exceptions-index.html).",
+	}
+	RuleLicenseConflict = &Rule{
+		ID:          "license-conflict",
+		Severity:    SeverityWarning,
+		Description: "The licenses of the parts of a product should be compatible with the license of the product.",
+		Hint:        "Check if the licenses of the components, software and documentation may be combined, e.g. avoid strong copyleft components in a permissively licensed product.",
+	}
+	RuleVersionFormat = &Rule{
+		ID:          "version-format",
+		Severity:    SeverityWarning,
+		Description: "The version should be a single word without whitespace, e.g. `1.0.2`.",
+		Hint:        "Use semantic versioning (https://semver.org/) for the field `version` of the manifest.",
+	}
+	RuleLanguageTag = &Rule{
+		ID:          "language-tag",
+		Severity:    SeverityError,
+		Description: "The documentation language must be a valid BCP 47 language tag.",
+		Hint:        "Set the field `documentation-language` of the manifest to a language tag, e.g. `en` or `de-DE`.",
+	}
+	RuleReadmeMissing = &Rule{
+		ID:          "readme-missing",
+		Severity:    SeverityError,
+		Description: "The project must contain a readme file.",
+		Hint:        "Add a file named `README.md` to the root directory of the project.",
+	}
+)
+
+// Rules is the list of all rules known to the validator.
+var Rules = []*Rule{
+	RuleManifestInvalid,
+	RuleOKHVersion,
+	RuleMandatoryField,
+	RuleXidFormat,
+	RuleRepositoryURL,
+	RuleURLFormat,
+	RuleURLUnreachable,
+	RuleLicenseMissing,
+	RuleLicenseExpression,
+	RuleLicenseUnknown,
+	RuleLicenseConflict,
+	RuleVersionFormat,
+	RuleLanguageTag,
+	RuleReadmeMissing,
+}
+
+// LookupRule returns the rule with the given ID or nil, if no such rule exists.
+func LookupRule(id string) *Rule {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Finding is a violation of a rule.
+type Finding struct {
+	// RuleID is the ID of the violated rule.
+	RuleID string `json:"ruleId"`
+	// Severity is the severity of the violation.
+	Severity Severity `json:"severity"`
+	// Path is the path of the offending field, e.g. `release.components[0].name`.
+	Path string `json:"path,omitempty"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// NewFinding creates a finding for a violation of the given rule.
+func NewFinding(rule *Rule, path, msg string) Finding {
+	return Finding{
+		RuleID:   rule.ID,
+		Severity: rule.Severity,
+		Path:     path,
+		Message:  msg,
+	}
+}
+
+// String returns a textual representation of the finding.
+func (f Finding) String() string {
+	if f.Path == "" {
+		return f.Message
+	}
+	return f.Path + ": " + f.Message
+}
@@ -15,13 +15,18 @@
 package validator
 
 import (
+	"encoding/json"
+	"net/http"
 	"strings"
 
 	"losh/internal/core/product/models"
 	"losh/internal/core/product/services"
 
+	"github.com/aisbergg/go-errors/pkg/errors"
 	"github.com/gookit/validate"
 	"golang.org/x/text/language"
+
+	n "losh/internal/lib/net"
 )
 
 var knownOKHV = [2]string{
@@ -29,28 +34,64 @@ var knownOKHV = [2]string{
 	"OKH-LOSHv1.0",
 }
 
+// ValidationError is returned if a product violates at least one rule of
+// severity error. It holds all findings of the validation, including the
+// warnings.
 type ValidationError struct {
-	messages []string
+	findings []Finding
 }
 
 func newValidationError() *ValidationError {
 	return &ValidationError{
-		messages: []string{},
+		findings: []Finding{},
 	}
 }
 
 // Error returns the error message.
 func (e *ValidationError) Error() string {
-	return strings.Join(e.messages, "; ")
+	msgs := make([]string, 0, len(e.findings))
+	for _, f := range e.findings {
+		if f.Severity == SeverityError {
+			msgs = append(msgs, f.String())
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Add adds a finding for the given rule.
+func (e *ValidationError) Add(rule *Rule, path, msg string) {
+	e.findings = append(e.findings, NewFinding(rule, path, msg))
+}
+
+// Findings returns all findings of the validation.
+func (e *ValidationError) Findings() []Finding {
+	return e.findings
 }
 
-// Add adds an error message.
-func (e *ValidationError) Add(msg string) {
-	e.messages = append(e.messages, msg)
+// Warnings returns the findings of severity warning.
+func (e *ValidationError) Warnings() []Finding {
+	warnings := make([]Finding, 0, len(e.findings))
+	for _, f := range e.findings {
+		if f.Severity == SeverityWarning {
+			warnings = append(warnings, f)
+		}
+	}
+	return warnings
+}
+
+// HasErrors returns true, if at least one finding is of severity error.
+func (e *ValidationError) HasErrors() bool {
+	for _, f := range e.findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
 }
 
 type Validator struct {
 	productService *services.Service
+	urlChecker     URLChecker
 }
 
 // NewValidator creates a new validator.
@@ -60,54 +101,96 @@ func NewValidator(productService *services.Service) *Validator {
 	}
 }
 
+// SetURLChecker sets the checker used to test whether the URLs of a product
+// are reachable. URLs are not checked, if it is nil, which is the default.
+func (v *Validator) SetURLChecker(checker URLChecker) *Validator {
+	v.urlChecker = checker
+	return v
+}
+
+// URLChecker returns an error, if the given URL is not reachable.
+type URLChecker func(url string) error
+
+// NewHTTPURLChecker creates a `URLChecker`, that sends a HEAD request to the
+// URL. Servers that don't support HEAD requests or that limit the rate of
+// requests are considered reachable.
+func NewHTTPURLChecker(client *http.Client, userAgent string) URLChecker {
+	return func(url string) error {
+		req, err := http.NewRequest(http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(n.HdrUserAgentKey, userAgent)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusTooManyRequests:
+			return nil
+		}
+		if resp.StatusCode >= 400 {
+			return errors.Errorf("status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
 // ValidateMandatory checks if the mandatory fields are set correctly.
 func (v *Validator) ValidateMandatory(mdtFlds MandatoryFields) error {
 	vldErr := newValidationError()
 
 	if !isValidOKHV(mdtFlds.OKHV) {
-		vldErr.Add("invalid OKH version")
+		vldErr.Add(RuleOKHVersion, "okhv", "invalid OKH version")
 	}
 	if strings.TrimSpace(mdtFlds.Name) == "" {
-		vldErr.Add("missing name")
+		vldErr.Add(RuleMandatoryField, "name", "missing name")
 	}
 	if strings.TrimSpace(mdtFlds.Description) == "" {
-		vldErr.Add("missing description")
+		vldErr.Add(RuleMandatoryField, "description", "missing description")
 	}
 	if strings.TrimSpace(mdtFlds.Version) == "" {
-		vldErr.Add("missing version")
+		vldErr.Add(RuleMandatoryField, "version", "missing version")
 	}
 	if !validate.IsFullURL(mdtFlds.Repository) {
-		vldErr.Add("invalid repository")
-	}
-	if mdtFlds.License == "" {
-		// allow empty license
-		// vldErr.Add("missing license")
-	} else if v.productService.GetCachedLicenseByIDOrName(mdtFlds.License) == nil {
-		vldErr.Add("invalid license")
+		vldErr.Add(RuleRepositoryURL, "repo", "invalid repository")
 	}
+	// allow empty license
+	v.checkLicenseExpression(vldErr, mdtFlds.License, "license")
 	if strings.TrimSpace(mdtFlds.Licensor) == "" {
-		vldErr.Add("missing licensor")
+		vldErr.Add(RuleMandatoryField, "licensor", "missing licensor")
 	}
 	if !isValidLanguageTag(mdtFlds.DocumentationLanguage) {
-		vldErr.Add("invalid documentation language")
+		vldErr.Add(RuleLanguageTag, "documentation-language", "invalid documentation language")
 	}
 	if !containsReadme(mdtFlds.FilePaths) {
-		vldErr.Add("missing readme")
+		vldErr.Add(RuleReadmeMissing, "", "missing readme")
 	}
 
-	if len(vldErr.messages) != 0 {
+	if vldErr.HasErrors() {
 		return vldErr
 	}
 	return nil
 }
 
 // ValidateProduct checks if the product information conforms the LOSH
-// specification.
+// specification. The complete graph of the product is checked, except for
+// nodes that are already stored in the database and other products that are
+// only referenced. Only violations of severity error are returned as error.
+// Warnings are attached to the product instead, so that they are stored along
+// with it.
 func (v *Validator) ValidateProduct(product *models.Product) error {
-	// TODO: implement validation of product
 	vldErr := newValidationError()
+	v.checkProduct(vldErr, product)
 
-	if len(vldErr.messages) != 0 {
+	warnings, err := json.Marshal(vldErr.Warnings())
+	if err != nil {
+		return errors.Wrap(err, "failed to encode validation warnings")
+	}
+	product.ValidationWarnings = p(string(warnings))
+
+	if vldErr.HasErrors() {
 		return vldErr
 	}
 	return nil
@@ -175,3 +258,7 @@ func containsReadme(filePaths []string) bool {
 	}
 	return false
 }
+
+func p[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,363 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/infra/dgraph/dgclient"
+	"losh/internal/lib/log/logtest"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+// newTestValidator creates a validator, that knows a few licenses.
+func newTestValidator() *Validator {
+	svc := services.NewService(nil)
+	licenses := []*models.License{}
+	for _, xid := range []string{"CERN-OHL-S-2.0", "MIT", "GPL-2.0-or-later"} {
+		xid := xid
+		licenses = append(licenses, &models.License{ID: p("0x" + xid), Xid: &xid, Name: &xid})
+	}
+	exceptions := []*models.Exception{{ID: p("0xe"), Xid: p("Classpath-exception-2.0"), Name: p("Classpath exception 2.0")}}
+	svc.LoadLicenses(licenses, exceptions)
+	return NewValidator(svc)
+}
+
+// newTestProduct creates a valid product with a single release, that has a
+// sub-component and a readme file. The website is set to the given URL.
+func newTestProduct(website string) *models.Product {
+	now := time.Now()
+	host := &models.Host{ID: p("0x1"), Domain: p("github.com"), Name: p("GitHub")}
+	licensor := &models.User{ID: p("0x2"), Xid: p("github.com/example"), Name: p("example")}
+	license := &models.License{ID: p("0xCERN-OHL-S-2.0"), Xid: p("CERN-OHL-S-2.0")}
+	dataSource := &models.Repository{
+		Xid:      p("github.com/example/motor-driver/main/okh.toml"),
+		URL:      p(website),
+		PermaURL: p(website),
+		Host:     host,
+	}
+	newComponent := func(xid, name string) *models.Component {
+		return &models.Component{
+			DiscoveredAt:                &now,
+			LastIndexedAt:               &now,
+			DataSource:                  dataSource,
+			Xid:                         p(xid),
+			Name:                        p(name),
+			Description:                 p("Drives two brushed DC motors."),
+			Version:                     p("1.2.0"),
+			CreatedAt:                   &now,
+			IsLatest:                    p(true),
+			Repository:                  dataSource,
+			License:                     license,
+			LicenseExpression:           license.Xid,
+			Licensor:                    licensor,
+			DocumentationLanguage:       p("en"),
+			TechnologyReadinessLevel:    p(dgclient.TechnologyReadinessLevelOtrl4),
+			DocumentationReadinessLevel: p(dgclient.DocumentationReadinessLevelOdrl3),
+		}
+	}
+	release := newComponent("github.com/example/motor-driver/main/okh.toml/Motor%20Driver", "Motor Driver")
+	release.Components = []*models.Component{newComponent(*release.Xid+"/Enclosure", "Enclosure")}
+	release.Readme = &models.File{
+		DiscoveredAt:  &now,
+		LastIndexedAt: &now,
+		DataSource:    dataSource,
+		Xid:           p("github.com/example/motor-driver/main/README.md"),
+		Name:          p("README.md"),
+		Path:          p("README.md"),
+		URL:           p(website + "/README.md"),
+	}
+
+	prd := &models.Product{
+		DiscoveredAt:          &now,
+		LastIndexedAt:         &now,
+		DataSource:            dataSource,
+		Xid:                   p("github.com/example/motor-driver/okh.toml"),
+		Name:                  release.Name,
+		Description:           release.Description,
+		DocumentationLanguage: release.DocumentationLanguage,
+		Version:               release.Version,
+		License:               license,
+		Licensor:              licensor,
+		Website:               p(website),
+		State:                 p(dgclient.ProductStateActive),
+		Release:               release,
+		Releases:              []*models.Component{release},
+	}
+	release.Product = prd
+	release.Components[0].Product = prd
+	return prd
+}
+
+// findings returns the findings of the validation error by path.
+func findings(t *testing.T, err error) map[string]Finding {
+	t.Helper()
+	var vldErr *ValidationError
+	if !errors.As(err, &vldErr) {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+	byPath := map[string]Finding{}
+	for _, f := range vldErr.Findings() {
+		byPath[f.Path] = f
+	}
+	return byPath
+}
+
+func TestRules(t *testing.T) {
+	ids := map[string]struct{}{}
+	for _, rule := range Rules {
+		if _, ok := ids[rule.ID]; ok {
+			t.Errorf("duplicate rule ID '%s'", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+		if rule.Severity != SeverityError && rule.Severity != SeverityWarning {
+			t.Errorf("rule '%s' has invalid severity '%s'", rule.ID, rule.Severity)
+		}
+		if LookupRule(rule.ID) != rule {
+			t.Errorf("LookupRule(%s) returned a different rule", rule.ID)
+		}
+	}
+	if LookupRule("unknown") != nil {
+		t.Errorf("LookupRule() found an unknown rule")
+	}
+}
+
+func TestValidateMandatory(t *testing.T) {
+	valid := MandatoryFields{
+		OKHV:                  "OKH-LOSHv1.0",
+		Name:                  "Motor Driver",
+		Description:           "Drives two brushed DC motors.",
+		Version:               "1.2.0",
+		Repository:            "https://github.com/example/motor-driver",
+		License:               "CERN-OHL-S-2.0 OR MIT",
+		Licensor:              "Jane Doe",
+		DocumentationLanguage: "de-DE",
+		FilePaths:             []string{"okh.toml", "Read_Me.md"},
+	}
+	tests := []struct {
+		name   string
+		modify func(f *MandatoryFields)
+		// path and rule of the expected finding, none if empty
+		path string
+		rule *Rule
+	}{
+		{"valid", func(f *MandatoryFields) {}, "", nil},
+		{"empty license", func(f *MandatoryFields) { f.License = "" }, "", nil},
+		{"legacy OKH version", func(f *MandatoryFields) { f.OKHV = "OKHv1.0" }, "", nil},
+		{"unknown OKH version", func(f *MandatoryFields) { f.OKHV = "OKHv2.0" }, "okhv", RuleOKHVersion},
+		{"missing name", func(f *MandatoryFields) { f.Name = " " }, "name", RuleMandatoryField},
+		{"missing description", func(f *MandatoryFields) { f.Description = "" }, "description", RuleMandatoryField},
+		{"missing version", func(f *MandatoryFields) { f.Version = "" }, "version", RuleMandatoryField},
+		{"relative repository", func(f *MandatoryFields) { f.Repository = "github.com/example/motor-driver" }, "repo", RuleRepositoryURL},
+		{"malformed license", func(f *MandatoryFields) { f.License = "MIT AND" }, "license", RuleLicenseExpression},
+		{"unknown license", func(f *MandatoryFields) { f.License = "MIT OR Foo-1.0" }, "license", RuleLicenseUnknown},
+		{"unknown exception", func(f *MandatoryFields) { f.License = "GPL-2.0-or-later WITH Foo-exception" }, "license", RuleLicenseUnknown},
+		{"missing licensor", func(f *MandatoryFields) { f.Licensor = "" }, "licensor", RuleMandatoryField},
+		{"invalid language", func(f *MandatoryFields) { f.DocumentationLanguage = "english language" }, "documentation-language", RuleLanguageTag},
+		{"missing readme", func(f *MandatoryFields) { f.FilePaths = []string{"okh.toml", "docs/README.md"} }, "", RuleReadmeMissing},
+	}
+	vld := newTestValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flds := valid
+			tt.modify(&flds)
+			err := vld.ValidateMandatory(flds)
+			if tt.rule == nil {
+				if err != nil {
+					t.Errorf("ValidateMandatory() error = %v", err)
+				}
+				return
+			}
+			f, ok := findings(t, err)[tt.path]
+			if !ok || f.RuleID != tt.rule.ID {
+				t.Errorf("ValidateMandatory() error = %v, want '%s' at '%s'", err, tt.rule.ID, tt.path)
+			}
+		})
+	}
+}
+
+// TestValidateProductMandatoryWalk checks that the mandatory fields of all
+// nodes of the product graph are checked, except for stored nodes.
+func TestValidateProductMandatoryWalk(t *testing.T) {
+	vld := newTestValidator()
+	if err := vld.ValidateProduct(newTestProduct("https://github.com/example/motor-driver")); err != nil {
+		t.Fatalf("ValidateProduct() error = %v", err)
+	}
+
+	prd := newTestProduct("https://github.com/example/motor-driver")
+	prd.Release.Components[0].Name = nil
+	prd.Release.Readme.Path = nil
+	prd.DataSource.Host = nil
+	byPath := findings(t, vld.ValidateProduct(prd))
+	for _, path := range []string{"release.components[0].name", "release.readme.path", "dataSource.host"} {
+		if f, ok := byPath[path]; !ok || f.RuleID != RuleMandatoryField.ID || f.Severity != SeverityError {
+			t.Errorf("missing finding for '%s': %v", path, byPath)
+		}
+	}
+	if len(byPath) != 3 {
+		t.Errorf("got %d findings, want 3: %v", len(byPath), byPath)
+	}
+
+	// stored nodes have been validated before
+	prd = newTestProduct("https://github.com/example/motor-driver")
+	prd.Release.Components[0].ID = p("0x3")
+	prd.Release.Components[0].Name = nil
+	if err := vld.ValidateProduct(prd); err != nil {
+		t.Errorf("ValidateProduct() error = %v", err)
+	}
+}
+
+func TestFieldPatterns(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(vldErr *ValidationError, value *string, path string)
+		value string
+		valid bool
+	}{
+		{"xid", checkXid, "github.com/example/repo/okh.toml", true},
+		{"xid with port", checkXid, "git.example.org:8443/group%2Fsub/repo", true},
+		{"xid without domain", checkXid, "localhost/example/repo", false},
+		{"xid without path", checkXid, "github.com", false},
+		{"xid with space", checkXid, "github.com/example/my repo", false},
+		{"version", checkVersion, "1.2.0-rc.1+build", true},
+		{"version with prefix", checkVersion, "v2", true},
+		{"version with space", checkVersion, "1.0 beta", false},
+		{"version with leading dot", checkVersion, ".1", false},
+		{"language", checkLanguage, "en", true},
+		{"language with region", checkLanguage, "pt-BR", true},
+		{"language name", checkLanguage, "English", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vldErr := newValidationError()
+			tt.check(vldErr, &tt.value, "field")
+			if valid := len(vldErr.Findings()) == 0; valid != tt.valid {
+				t.Errorf("%s '%s' valid = %t, want %t: %v", tt.name, tt.value, valid, tt.valid, vldErr.Findings())
+			}
+		})
+	}
+}
+
+// TestValidateProductSeverity checks that only errors fail the validation,
+// whereas warnings are stored along with the product.
+func TestValidateProductSeverity(t *testing.T) {
+	vld := newTestValidator()
+
+	prd := newTestProduct("https://github.com/example/motor-driver")
+	prd.Version = p("1.2.0 beta")
+	prd.License = nil
+	if err := vld.ValidateProduct(prd); err != nil {
+		t.Fatalf("ValidateProduct() error = %v", err)
+	}
+	warnings := []Finding{}
+	if err := json.Unmarshal([]byte(*prd.ValidationWarnings), &warnings); err != nil {
+		t.Fatal(err)
+	}
+	rules := map[string]string{}
+	for _, w := range warnings {
+		rules[w.Path] = w.RuleID
+	}
+	if rules["version"] != RuleVersionFormat.ID || rules["license"] != RuleLicenseMissing.ID || len(rules) != 2 {
+		t.Errorf("ValidationWarnings = %s", *prd.ValidationWarnings)
+	}
+
+	prd = newTestProduct("https://github.com/example/motor-driver")
+	prd.Version = p("1.2.0 beta")
+	prd.Release.DocumentationLanguage = p("english language")
+	err := vld.ValidateProduct(prd)
+	byPath := findings(t, err)
+	if f := byPath["release.documentationLanguage"]; f.RuleID != RuleLanguageTag.ID || f.Severity != SeverityError {
+		t.Errorf("findings = %v, want a language tag error", byPath)
+	}
+	// warnings are kept along with the errors, but are not part of the message
+	if f := byPath["version"]; f.Severity != SeverityWarning {
+		t.Errorf("findings = %v, want a version warning", byPath)
+	}
+	if err.Error() != "release.documentationLanguage: invalid language tag 'english language'" {
+		t.Errorf("Error() = %s", err.Error())
+	}
+}
+
+func TestValidateProductURLReachability(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.URL.Path == "/motor-driver":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/head-not-allowed"):
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	checker := NewHTTPURLChecker(srv.Client(), "losh-test")
+
+	// URLs are only checked on demand
+	prd := newTestProduct(srv.URL + "/removed")
+	if err := newTestValidator().ValidateProduct(prd); err != nil || *prd.ValidationWarnings != "[]" {
+		t.Fatalf("ValidateProduct() error = %v, warnings = %s", err, *prd.ValidationWarnings)
+	}
+
+	tests := []struct {
+		path        string
+		unreachable []string
+	}{
+		// the readme file doesn't exist
+		{"/motor-driver", []string{"release.readme.url"}},
+		{"/head-not-allowed", []string{}},
+		{"/removed", []string{"dataSource.url", "release.readme.url", "website"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			prd := newTestProduct(srv.URL + tt.path)
+			if err := newTestValidator().SetURLChecker(checker).ValidateProduct(prd); err != nil {
+				t.Fatalf("ValidateProduct() error = %v", err)
+			}
+			warnings := []Finding{}
+			if err := json.Unmarshal([]byte(*prd.ValidationWarnings), &warnings); err != nil {
+				t.Fatal(err)
+			}
+			paths := []string{}
+			for _, w := range warnings {
+				if w.RuleID != RuleURLUnreachable.ID {
+					t.Errorf("unexpected warning %v", w)
+				}
+				paths = append(paths, w.Path)
+			}
+			sort.Strings(paths)
+			if !reflect.DeepEqual(paths, tt.unreachable) {
+				t.Errorf("unreachable URLs at %v, want %v", paths, tt.unreachable)
+			}
+		})
+	}
+}
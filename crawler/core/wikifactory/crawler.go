@@ -16,10 +16,12 @@ package wikifactory
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
+	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
 	"losh/crawler/core/validator"
 	"losh/crawler/core/wikifactory/wfclient"
 	"losh/internal/core/product/models"
@@ -47,10 +49,16 @@ const (
 	batchSize           = 10
 	retries             = 5
 	maxFileSizeManifest = 10 * unit.MiB
+	maxFileSizeLicense  = 1 * unit.MiB
 	maxWaitTime         = 5 * time.Minute
 	maxRedirects        = 5
+	defaultUpdateMaxAge = 7 * 24 * time.Hour
+	defaultConcurrency  = 4
+	pageBufferSize      = 2 // pages fetched ahead of the saver
 )
 
+var errProjectNotFound = errors.New("Wikifactory project not found")
+
 type CrawlerState struct {
 	StartTime   time.Time     `json:"startTime"`
 	ElapsedTime time.Duration `json:"elapsedTime"`
@@ -68,11 +76,21 @@ type WikifactoryCrawler struct {
 	fileDownloader *download.Downloader
 	wfClient       wfclient.WikifactoryGraphQLClient
 	log            *zap.SugaredLogger
+
+	stateStore crawler.StateStore
+	reconciler *crawler.Reconciler
+
+	// number of workers fetching products concurrently
+	concurrency int
+	// products indexed longer ago are re-indexed in update mode
+	updateMaxAge time.Duration
 }
 
 // NewWikifactoryCrawler creates a new WikifactoryCrawler.
-func NewWikifactoryCrawler(productService *services.Service, userAgent string) *WikifactoryCrawler {
+func NewWikifactoryCrawler(productService *services.Service, cfg config.WikifactoryConfig) *WikifactoryCrawler {
 	log := log.NewLogger("crawler-wikifactory")
+	userAgent := cfg.UserAgent
+	rateLimit := cfg.RateLimit.OrDefault(5*time.Second, 20)
 
 	// clients for external requests
 	httpClient := &http.Client{
@@ -95,128 +113,421 @@ func NewWikifactoryCrawler(productService *services.Service, userAgent string) *
 		SetLogger(log).
 		SetRetryCount(uint64(retries)).
 		SetMaxWaitTime(maxWaitTime).
-		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(5*time.Second, 20))
+		AddRateLimiter(ratelimit.NewTimedeltaRateLimiter(rateLimit.Interval, rateLimit.Jitter))
 	wfClient := wfclient.NewClient(graphQLRequester)
 
 	validator := validator.NewValidator(productService)
 
-	return &WikifactoryCrawler{
+	crwl := &WikifactoryCrawler{
 		productService: productService,
 		validator:      validator,
 
 		fileDownloader: fileDownloader,
 		wfClient:       wfClient,
 
-		log: log,
+		log:        log,
+		stateStore: crawler.NewFileStateStore(pathlib.NewPath("."), false),
+
+		concurrency:  defaultConcurrency,
+		updateMaxAge: defaultUpdateMaxAge,
 	}
+	return crwl.SetConcurrency(cfg.Concurrency)
+}
+
+// SetConcurrency sets the number of workers, that fetch and normalize products
+// concurrently during discovery. Values below 1 reset it to the default.
+func (c *WikifactoryCrawler) SetConcurrency(concurrency int) *WikifactoryCrawler {
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	c.concurrency = concurrency
+	return c
+}
+
+// Domain returns the domain of the crawled platform.
+func (c *WikifactoryCrawler) Domain() string {
+	return crawlerName
+}
+
+// SetUpdateMaxAge sets the age of the last indexing after which a product is
+// re-indexed by `UpdateProducts`.
+func (c *WikifactoryCrawler) SetUpdateMaxAge(maxAge time.Duration) *WikifactoryCrawler {
+	c.updateMaxAge = maxAge
+	return c
+}
+
+// SetReconciler sets the reconciler, that detects removed products after a
+// complete discovery run. No reconciliation takes place, if it is nil.
+func (c *WikifactoryCrawler) SetReconciler(reconciler *crawler.Reconciler) *WikifactoryCrawler {
+	c.reconciler = reconciler
+	return c
+}
+
+// SetStateStore sets the store, that keeps the state of the crawler runs.
+func (c *WikifactoryCrawler) SetStateStore(store crawler.StateStore) *WikifactoryCrawler {
+	c.stateStore = store
+	return c
 }
 
+// DiscoverProducts discovers the products on Wikifactory and saves them to the
+// database. The products are fetched and normalized concurrently by a number
+// of workers, but saved in the order of their discovery. The crawler state is
+// saved after all products of a page have been saved.
 func (c *WikifactoryCrawler) DiscoverProducts(ctx context.Context) error {
 	c.log.Infof("discovering products on %s", crawlerName)
 
-	// TODO: make state file configurable
-	stateFilePath := pathlib.NewPath("crawler-state.json")
-	state, err := c.loadState(stateFilePath)
+	state, err := c.loadState(ctx)
 	if err != nil {
 		return lerrors.NewAppErrorWrap(err, "failed to load state")
 	}
 
-	runStartedAt := time.Now()
-	prevElapsedTime := state.ElapsedTime
-	page := state.Page
-	cursor := state.Cursor
-	hasNextPage := true
-	for hasNextPage {
+	// cancel the producer and workers, if saving fails
+	pipeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pages := make(chan *discoveryPage, pageBufferSize)
+	jobs := make(chan *discoveryJob, batchSize)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.producePages(pipeCtx, state.Page, state.Cursor, pages, jobs)
+	}()
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.fetchProducts(pipeCtx, jobs)
+		}()
+	}
+
+	err = c.saveProducts(pipeCtx, &state, pages)
+	cancel()
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	// detect products, that have been removed from the platform
+	if c.reconciler != nil {
+		err = c.reconciler.Reconcile(ctx, c, state.StartTime)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to reconcile products")
+		}
+	}
+
+	// the run is completed
+	err = c.stateStore.Complete(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover))
+	if err != nil {
+		return lerrors.NewAppErrorWrap(err, "failed to complete state")
+	}
+
+	return nil
+}
+
+// discoveryPage is a page of projects in the discovery pipeline.
+type discoveryPage struct {
+	// state after the page has been processed
+	nextPage   int64
+	nextCursor string
+
+	numProjects int
+	jobs        []*discoveryJob
+	err         error
+}
+
+// discoveryJob is a product, that is fetched and normalized by a worker of the
+// discovery pipeline.
+type discoveryJob struct {
+	productID    models.ProductID
+	discoveredAt time.Time
+
+	// result of the worker; it is set before done is closed
+	product *models.Product
+	err     error
+	done    chan struct{}
+}
+
+// producePages queries the projects page by page starting at the given cursor.
+// The pages are passed on to the saver in order and the compliant projects are
+// passed on to the workers.
+func (c *WikifactoryCrawler) producePages(ctx context.Context, page int64, cursor string, pages chan<- *discoveryPage, jobs chan<- *discoveryJob) {
+	defer close(pages)
+	defer close(jobs)
+	for {
 		c.log.Debugf("getting %d results from page %d (cursor: %s)", batchSize, page, cursor)
 
 		// get project information
 		queryProjects, err := c.wfClient.QueryProjects(ctx, batchSize, cursor)
 		if err != nil {
-			return lerrors.NewAppErrorWrap(err, "failed to get project information")
+			select {
+			case pages <- &discoveryPage{err: err}:
+			case <-ctx.Done():
+			}
+			return
 		}
 		discoveredAt := time.Now()
 		wfPrjsInfo := queryProjects.Projects.Result.Edges
+		pageInfo := queryProjects.Projects.Result.PageInfo
+		page++
+		cursor = *pageInfo.EndCursor
+		dscPage := &discoveryPage{
+			nextPage:    page,
+			nextCursor:  cursor,
+			numProjects: len(wfPrjsInfo),
+			jobs:        make([]*discoveryJob, 0, len(wfPrjsInfo)),
+		}
 
-		// check each project and index if compliant
+		// check mandatory fields for compliance
 		for _, edge := range wfPrjsInfo {
 			wfPrjInfo := edge.Node
 			productID := models.NewProductID(crawlerName, *wfPrjInfo.ParentSlug, *wfPrjInfo.Slug, "")
-
-			// check mandatory fields for compliance
-			err = c.checkMandatory(wfPrjInfo)
-			if err != nil {
+			if err := c.checkMandatory(wfPrjInfo); err != nil {
 				c.log.Debugf("skipping (%s): %s", productID.String(), err.Error())
 				continue
 			}
+			dscPage.jobs = append(dscPage.jobs, &discoveryJob{
+				productID:    productID,
+				discoveredAt: discoveredAt,
+				done:         make(chan struct{}),
+			})
+		}
 
-			// get full product information
-			c.log.Infof("indexing product (%s)", productID.String())
-			prd, err := c.getProduct(ctx, productID, discoveredAt)
-			if err != nil {
-				var gqlErr *gql.ErrorResponse
-				if errors.As(err, &gqlErr) {
-					// if there is something wrong on the Wikifactory side
-					// (empty message), we skip the product
-					if gqlErr.GqlErrors != nil &&
-						len(*gqlErr.GqlErrors) > 0 &&
-						(*gqlErr.GqlErrors)[0].Message == "" {
-						c.log.Debugf("skipping (%s): invalid response from Wikifactory", productID.String())
-						continue
-					}
-				}
+		// the page must be passed on before its jobs, otherwise the saver
+		// might wait for jobs, that are never handed out
+		select {
+		case pages <- dscPage:
+		case <-ctx.Done():
+			return
+		}
+		for _, job := range dscPage.jobs {
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if !pageInfo.HasNextPage {
+			return
+		}
+	}
+}
 
-				if vldErr, ok := err.(*validator.ValidationError); ok {
-					c.log.Debugf("skipping (%s): %s", productID.String(), vldErr.Error())
+// fetchProducts retrieves the full product information of the jobs until the
+// jobs channel is closed.
+func (c *WikifactoryCrawler) fetchProducts(ctx context.Context, jobs <-chan *discoveryJob) {
+	for job := range jobs {
+		c.log.Infof("indexing product (%s)", job.productID.String())
+		job.product, job.err = c.getProduct(ctx, job.productID, job.discoveredAt)
+		close(job.done)
+	}
+}
+
+// saveProducts saves the products of each page in order of their discovery and
+// updates the crawler state after each page.
+func (c *WikifactoryCrawler) saveProducts(ctx context.Context, state *CrawlerState, pages <-chan *discoveryPage) error {
+	runStartedAt := time.Now()
+	prevElapsedTime := state.ElapsedTime
+	for dscPage := range pages {
+		if dscPage.err != nil {
+			return lerrors.NewAppErrorWrap(dscPage.err, "failed to get project information")
+		}
+
+		for _, job := range dscPage.jobs {
+			select {
+			case <-job.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if job.err != nil {
+				if reason, ok := skipReason(job.err); ok {
+					c.log.Debugf("skipping (%s): %s", job.productID.String(), reason)
 					continue
 				}
-				return lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+				return lerrors.NewAppErrorWrap(job.err, "failed to get product").Add("crawlerProductID", job.productID.String())
 			}
 
 			// save product
-			c.log.Debugf("saving product (%s)", productID.String())
-			err = c.productService.SaveNode(ctx, prd)
+			c.log.Debugf("saving product (%s)", job.productID.String())
+			diffs, err := c.productService.SaveNodeWithDiff(ctx, job.product)
 			if err != nil {
-				return lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+				return lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", job.productID.String())
+			}
+			for _, diff := range diffs {
+				c.log.Debugf("changed %s", diff)
 			}
-
 			state.NumIndexed++
 		}
 
-		pageInfo := queryProjects.Projects.Result.PageInfo
-		hasNextPage = pageInfo.HasNextPage
-		page++
-		cursor = *pageInfo.EndCursor
-
 		// save state
-		state.Cursor = cursor
-		state.Page = page
-		state.NumCrawled += int64(len(wfPrjsInfo))
+		state.Cursor = dscPage.nextCursor
+		state.Page = dscPage.nextPage
+		state.NumCrawled += int64(dscPage.numProjects)
 		state.ElapsedTime = prevElapsedTime + time.Now().Sub(runStartedAt)
-		err = c.saveState(stateFilePath, state)
+		err := c.saveState(ctx, *state)
 		if err != nil {
 			return lerrors.NewAppErrorWrap(err, "failed to save state")
 		}
 		c.log.Debugf("indexed %d of %d products on %s this far", state.NumIndexed, state.NumCrawled, crawlerName)
 	}
 
-	// TODO
-	// remove state file
+	// the producer stops early, if the context is canceled
+	return ctx.Err()
+}
 
-	return nil
+// skipReason returns the reason for skipping a product, if the given error
+// doesn't need to stop the crawler.
+func skipReason(err error) (string, bool) {
+	if errors.Is(err, errProjectNotFound) {
+		return err.Error(), true
+	}
+	// if there is something wrong on the Wikifactory side (empty message), we
+	// skip the product
+	var gqlErr *gql.ErrorResponse
+	if errors.As(err, &gqlErr) &&
+		gqlErr.GqlErrors != nil &&
+		len(*gqlErr.GqlErrors) > 0 &&
+		(*gqlErr.GqlErrors)[0].Message == "" {
+		return "invalid response from Wikifactory", true
+	}
+	if vldErr, ok := err.(*validator.ValidationError); ok {
+		return vldErr.Error(), true
+	}
+	return "", false
 }
 
+// UpdateProducts re-indexes the products from Wikifactory, that were last
+// indexed before the configured maximum age. A product is saved only if it was
+// updated on Wikifactory since, otherwise only the time of its last indexing
+// is updated.
 func (c *WikifactoryCrawler) UpdateProducts(ctx context.Context) error {
+	c.log.Infof("updating products on %s indexed more than %s ago", crawlerName, c.updateMaxAge)
+
+	indexedBefore := time.Now().Add(-c.updateMaxAge)
+	var numChecked, numUpdated int64
+	// products that are not re-indexed remain in the result set, therefore
+	// they need to be skipped
+	var offset int64
+	for {
+		storedPrds, err := c.productService.GetProductsIndexedBefore(ctx, crawlerName, indexedBefore, batchSize, offset)
+		if err != nil {
+			return lerrors.NewAppErrorWrap(err, "failed to get products to update")
+		}
+		if len(storedPrds) == 0 {
+			break
+		}
+
+		for _, storedPrd := range storedPrds {
+			numChecked++
+			updated, err := c.updateProduct(ctx, storedPrd)
+			if err != nil {
+				return err
+			}
+			if updated == nil {
+				offset++
+				continue
+			}
+			if *updated {
+				numUpdated++
+			}
+		}
+		c.log.Debugf("updated %d of %d checked products on %s this far", numUpdated, numChecked, crawlerName)
+	}
+
+	c.log.Infof("updated %d of %d checked products on %s", numUpdated, numChecked, crawlerName)
 	return nil
 }
 
+// updateProduct re-indexes a single stored product. It returns whether the
+// product was changed or nil, if the product was skipped.
+func (c *WikifactoryCrawler) updateProduct(ctx context.Context, storedPrd *models.Product) (*bool, error) {
+	if storedPrd.DataSource == nil || storedPrd.DataSource.URL == nil {
+		c.log.Debugf("skipping (%s): missing data source", stringOrEmpty(storedPrd.Xid))
+		return nil, nil
+	}
+	productID, err := models.NewProductIDFromURL(*storedPrd.DataSource.URL)
+	if err != nil {
+		c.log.Debugf("skipping (%s): %s", stringOrEmpty(storedPrd.Xid), err.Error())
+		return nil, nil
+	}
+
+	c.log.Infof("re-indexing product (%s)", productID.String())
+	indexedAt := time.Now()
+	prd, err := c.getProduct(ctx, productID, indexedAt)
+	if err != nil {
+		if reason, ok := skipReason(err); ok {
+			c.log.Debugf("skipping (%s): %s", productID.String(), reason)
+			return nil, nil
+		}
+		return nil, lerrors.NewAppErrorWrap(err, "failed to get product").Add("crawlerProductID", productID.String())
+	}
+
+	if !hasProductChanged(storedPrd, prd) {
+		c.log.Debugf("product unchanged (%s)", productID.String())
+		err = c.productService.TouchProduct(ctx, storedPrd, indexedAt)
+		if err != nil {
+			return nil, lerrors.NewAppErrorWrap(err, "failed to update product").Add("crawlerProductID", productID.String())
+		}
+		return p(false), nil
+	}
+
+	// save product
+	c.log.Debugf("saving product (%s)", productID.String())
+	prd.DiscoveredAt = storedPrd.DiscoveredAt
+	diffs, err := c.productService.SaveNodeWithDiff(ctx, prd)
+	if err != nil {
+		return nil, lerrors.NewAppErrorWrap(err, "failed to save product").Add("crawlerProductID", productID.String())
+	}
+	for _, diff := range diffs {
+		c.log.Debugf("changed %s", diff)
+	}
+
+	// the product was saved under a different identity, e.g. because it was
+	// renamed, therefore the stored product is marked as indexed as well
+	if prd.ID == nil || storedPrd.ID == nil || *prd.ID != *storedPrd.ID {
+		err = c.productService.TouchProduct(ctx, storedPrd, indexedAt)
+		if err != nil {
+			return nil, lerrors.NewAppErrorWrap(err, "failed to update product").Add("crawlerProductID", productID.String())
+		}
+	}
+	return p(true), nil
+}
+
+// hasProductChanged returns true if the product was updated or if its
+// contributions (releases) changed.
+func hasProductChanged(storedPrd, prd *models.Product) bool {
+	// the product was renamed or transferred
+	if stringOrEmpty(storedPrd.Xid) != stringOrEmpty(prd.Xid) {
+		return true
+	}
+	if (storedPrd.LastUpdatedAt == nil) != (prd.LastUpdatedAt == nil) ||
+		(prd.LastUpdatedAt != nil && !storedPrd.LastUpdatedAt.Equal(*prd.LastUpdatedAt)) {
+		return true
+	}
+	if len(storedPrd.Releases) != len(prd.Releases) {
+		return true
+	}
+	versions := make(map[string]struct{}, len(storedPrd.Releases))
+	for _, release := range storedPrd.Releases {
+		versions[stringOrEmpty(release.Version)] = struct{}{}
+	}
+	for _, release := range prd.Releases {
+		if _, ok := versions[stringOrEmpty(release.Version)]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *WikifactoryCrawler) getProduct(ctx context.Context, prdID models.ProductID, discoveredAt time.Time) (*models.Product, error) {
 	// get full project information
 	getProjectFullBySlug, err := c.wfClient.GetProjectFullBySlug(ctx, prdID.Owner, prdID.Repo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get Wikifactory project information")
 	}
-	if getProjectFullBySlug == nil {
-		return nil, errors.New("Wikifactory project not found")
+	if getProjectFullBySlug == nil || getProjectFullBySlug.Project.Result == nil {
+		return nil, errProjectNotFound
 	}
 
 	product, err := c.NormalizeProduct(ctx, discoveredAt, getProjectFullBySlug.Project.Result)
@@ -252,7 +563,7 @@ func (c *WikifactoryCrawler) GetProduct(ctx context.Context, productID models.Pr
 	}
 	projectInfo := getProjectMandatoryBySlug.Project.Result
 	if projectInfo == nil {
-		return nil, errors.New("project not found")
+		return nil, errors.Wrapf(crawler.ErrProductNotFound, "failed to get product '%s'", productID.String())
 	}
 	discoveredAt := time.Now()
 
@@ -267,8 +578,8 @@ func (c *WikifactoryCrawler) GetProduct(ctx context.Context, productID models.Pr
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get project information")
 	}
-	if getProjectFullBySlug == nil {
-		return nil, errors.New("project not found")
+	if getProjectFullBySlug == nil || getProjectFullBySlug.Project.Result == nil {
+		return nil, errors.Wrapf(crawler.ErrProductNotFound, "failed to get product '%s'", productID.String())
 	}
 
 	product, err := c.NormalizeProduct(ctx, discoveredAt, getProjectFullBySlug.Project.Result)
@@ -286,44 +597,25 @@ func (c *WikifactoryCrawler) GetProduct(ctx context.Context, productID models.Pr
 	return product, nil
 }
 
-// loadState loads the crawler state from the state file.
-func (c *WikifactoryCrawler) loadState(path pathlib.Path) (CrawlerState, error) {
-	// load state
-	exists, err := path.Exists()
-	if err != nil {
-		return CrawlerState{}, errors.Wrap(err, "failed to check file existence")
-	}
+// loadState loads the state of the discovery run from the state store. A new
+// state is returned, if none is stored.
+func (c *WikifactoryCrawler) loadState(ctx context.Context) (CrawlerState, error) {
 	// new state
 	state := CrawlerState{
 		StartTime: time.Now(),
 		Page:      1,
 		Cursor:    "",
 	}
-	if !exists {
-		return state, nil
-	}
-	fileContent, err := path.ReadFile()
+	_, err := c.stateStore.Load(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), &state)
 	if err != nil {
-		return CrawlerState{}, errors.Wrap(err, "failed to read state file")
-	}
-	err = json.Unmarshal(fileContent, &state)
-	if err != nil {
-		return CrawlerState{}, errors.Wrap(err, "failed to unmarshal state file")
+		return CrawlerState{}, err
 	}
 	return state, nil
 }
 
-// saveState saves the crawler state to the state file.
-func (c *WikifactoryCrawler) saveState(path pathlib.Path, state CrawlerState) error {
-	fileContent, err := json.Marshal(state)
-	if err != nil {
-		return errors.Wrap(err, "failed to marshal state")
-	}
-	err = path.WriteFile(fileContent)
-	if err != nil {
-		return errors.Wrap(err, "failed to write state file")
-	}
-	return nil
+// saveState saves the state of the discovery run to the state store.
+func (c *WikifactoryCrawler) saveState(ctx context.Context, state CrawlerState) error {
+	return c.stateStore.Save(ctx, crawler.StateNamespace(c.Domain(), crawler.RunDiscover), state)
 }
 
 // checkMandatory checks if mandatory fields are present.
@@ -26,6 +26,7 @@ import (
 
 	"losh/crawler/core/wikifactory/wfclient"
 	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
 	"losh/internal/infra/dgraph/dgclient"
 	"losh/internal/lib/fileformats"
 
@@ -39,14 +40,14 @@ var excludeFiles = []string{
 	"ACKNOWLEDGMENTS",
 	"AUTHORS",
 	"CHANGELOG",
-	"CODE_OF_CONDUCT",
+	"CODEOFCONDUCT",
 	"CODEOWNERS",
 	"CONTRIBUTING",
 	"CONTRIBUTORS",
 	"FUNDING",
-	"ISSUE_TEMPLATE",
+	"ISSUETEMPLATE",
 	"LICENSE",
-	"PULL_REQUEST_TEMPLATE",
+	"PULLREQUESTTEMPLATE",
 	"README",
 	"SECURITY",
 	"SUPPORT",
@@ -86,7 +87,7 @@ func (c *WikifactoryCrawler) NormalizeProduct(ctx context.Context, timestamp tim
 	if err != nil {
 		return nil, err
 	}
-	releases := c.normReleases(wfPrjInfo, licensor, timestamp)
+	releases := c.normReleases(ctx, wfPrjInfo, licensor, timestamp)
 	latestRelease := releases[0] // latest release is always the first
 
 	// crawler info
@@ -102,6 +103,8 @@ func (c *WikifactoryCrawler) NormalizeProduct(ctx context.Context, timestamp tim
 	}
 	// Xid format: domain.tld/owner/repo/file-path
 	product.Xid = asXid(productURL.Domain, productURL.Owner, productURL.Repo, "")
+	// PlatformID format: domain.tld/project-id/file-path
+	product.PlatformID = asXid(productURL.Domain, wfPrjInfo.ID, "")
 	product.Name = latestRelease.Name
 	product.Description = latestRelease.Description
 	product.DocumentationLanguage = latestRelease.DocumentationLanguage
@@ -117,10 +120,9 @@ func (c *WikifactoryCrawler) NormalizeProduct(ctx context.Context, timestamp tim
 		release.Product = product
 	}
 
-	// product.RenamedTo = "XXX"   // TODO
-	// product.RenamedFrom = "XXX" // TODO
-	// product.ForkOf = "XXX"      // TODO
-	product.Forks = []*models.Product{} // TODO
+	// renamed products are linked to their previous identity on save
+	// forks are linked to their origin through the inverse of ForkOf
+	product.ForkOf = c.normForkOf(wfPrjInfo, timestamp)
 	product.ForkCount = wfPrjInfo.ForkCount
 	product.StarCount = wfPrjInfo.StarCount
 	product.Tags = normTags(wfPrjInfo.Tags)
@@ -141,13 +143,16 @@ func (c *WikifactoryCrawler) normState(prjInfo *wfclient.ProjectFullFragment) *d
 }
 
 // normReleases returns the release of the product.
-func (c *WikifactoryCrawler) normReleases(prjInfo *wfclient.ProjectFullFragment, owner models.UserOrGroup, timestamp time.Time) []*models.Component {
+func (c *WikifactoryCrawler) normReleases(ctx context.Context, prjInfo *wfclient.ProjectFullFragment, owner models.UserOrGroup, timestamp time.Time) []*models.Component {
 	wfContribs := prjInfo.Contributions.Edges
 	releases := make([]*models.Component, 0, len(wfContribs))
 
 	// image (same for every release)
 	var image *models.File
 
+	// license reported by the platform (same for every release)
+	platformLicense := c.normLicense(prjInfo.License)
+
 	for i, edge := range wfContribs {
 		wfContrib := edge.Node
 		version := wfContrib.Version
@@ -170,11 +175,14 @@ func (c *WikifactoryCrawler) normReleases(prjInfo *wfclient.ProjectFullFragment,
 		release.Version = wfContrib.Version
 		release.CreatedAt = wfContrib.DateCreated
 		release.Releases = make([]*models.Component, 0, len(wfContribs))
-		if i == 0 {
-			release.IsLatest = p(true)
-		}
+		release.IsLatest = p(i == 0)
 		release.Repository = crawlerMeta.DataSource
-		release.License = c.normLicense(prjInfo.License)
+		release.License = platformLicense
+		if release.License == nil {
+			// fall back to the license file of the release, if the platform
+			// reports no license
+			release.License = c.detectLicense(ctx, files)
+		}
 		release.Licensor = owner
 		release.DocumentationLanguage = c.normDocumentationLanguage(*release.Description)
 		release.TechnologyReadinessLevel = p(dgclient.TechnologyReadinessLevelUndetermined)
@@ -185,9 +193,7 @@ func (c *WikifactoryCrawler) normReleases(prjInfo *wfclient.ProjectFullFragment,
 		// release.CpcPatentClass = "XXX" // TODO
 		// release.Tsdc = "XXX" // TODO
 
-		// TODO: mandatory information need to be added to subcomponents
-		// subCmps := c.getSubComponents(files, prjInfo)
-		// release.Components = subCmps
+		release.Components = c.getSubComponents(release, files)
 
 		release.Software = []*models.Software{}
 		if image == nil {
@@ -200,7 +206,6 @@ func (c *WikifactoryCrawler) normReleases(prjInfo *wfclient.ProjectFullFragment,
 		release.ManufacturingInstructions = c.normInfoFile([]string{"MANUFACTURINGINSTRUCTIONS", "MANUFACTURING"}, files)
 		release.UserManual = c.normInfoFile([]string{"USERGUIDE", "USERMANUAL"}, files)
 		// release.Product = "XXX" // TODO
-		// release.UsedIn = "XXX" // TODO
 		// release.Source = "XXX" // TODO
 		// release.Export = "XXX" // TODO
 		// release.Auxiliary = "XXX" // TODO
@@ -228,6 +233,34 @@ func (c *WikifactoryCrawler) normReleases(prjInfo *wfclient.ProjectFullFragment,
 	return releases
 }
 
+// normForkOf returns the product, that the project was forked from. As the
+// origin might not be indexed yet, a placeholder is returned.
+func (c *WikifactoryCrawler) normForkOf(prjInfo *wfclient.ProjectFullFragment, timestamp time.Time) *models.Product {
+	if prjInfo.ForkedFrom == nil || prjInfo.ForkedFrom.Project == nil {
+		return nil
+	}
+	parent := prjInfo.ForkedFrom.Project
+	if parent.ParentSlug == nil || parent.Slug == nil {
+		return nil
+	}
+	productURL := &models.ProductURL{
+		Domain: "wikifactory.com",
+		Owner:  *parent.ParentSlug,
+		Repo:   *parent.Slug,
+	}
+	dataSource := &models.Repository{
+		// Xid format: domain.tld/owner/repo/ref/file-path
+		Xid:      p(strings.Join([]string{productURL.Domain, productURL.Owner, productURL.Repo, "", "-"}, "/")),
+		URL:      p(productURL.RepositoryURL()),
+		PermaURL: p(productURL.RepositoryURL()),
+		Host:     host,
+		Name:     parent.Slug,
+	}
+	// Xid format: domain.tld/owner/repo/file-path
+	xid := asXid(productURL.Domain, productURL.Owner, productURL.Repo, "")
+	return models.NewProductPlaceholder(*xid, *parent.Slug, dataSource, timestamp)
+}
+
 // normRepository returns the source of the component.
 func (c *WikifactoryCrawler) normRepository(owner models.UserOrGroup, ref string, prjInfo *wfclient.ProjectFullFragment) *models.Repository {
 	productURL := &models.ProductURL{
@@ -406,6 +439,32 @@ func (c *WikifactoryCrawler) normLicense(wfLicense *wfclient.ProjectFullFragment
 	return c.productService.GetCachedLicenseByIDOrName(lcsStr)
 }
 
+// detectLicense detects the license from the content of the license file in
+// the root directory of the project.
+func (c *WikifactoryCrawler) detectLicense(ctx context.Context, files []*models.File) *models.License {
+	filesByPath := make(map[string]*models.File, len(files))
+	filePaths := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		if file.Path == nil || file.URL == nil {
+			continue
+		}
+		filePath := strings.TrimLeft(*file.Path, "/")
+		filesByPath[filePath] = file
+		filePaths[filePath] = struct{}{}
+	}
+	licensePath := services.FindLicenseFile(filePaths, "")
+	if licensePath == "" {
+		return nil
+	}
+	file := filesByPath[licensePath]
+	content, err := c.fileDownloader.DownloadContentWithMaxSize(ctx, *file.URL, maxFileSizeLicense)
+	if err != nil {
+		c.log.Debugw("failed to download license file", "url", *file.URL, "error", err)
+		return nil
+	}
+	return c.productService.DetectLicense(ctx, string(content))
+}
+
 // normLicensor returns the owner of the product.
 func (c *WikifactoryCrawler) normLicensor(ctx context.Context, prjInfo *wfclient.ProjectFullFragment, timestamp time.Time) (models.UserOrGroup, error) {
 	switch *prjInfo.ParentContent.Type {
@@ -477,9 +536,12 @@ func (c *WikifactoryCrawler) normLicensor(ctx context.Context, prjInfo *wfclient
 	}
 }
 
-// getSubComponents returns the sub components of the release.
+// getSubComponents returns the sub components of the release. Files sharing
+// the same path stem are grouped together and become a component, if a CAD or
+// PCB file is among them. The information that cannot be derived from the
+// files is inherited from the release.
 // XXX: need a better way of identifying components
-func (c *WikifactoryCrawler) getSubComponents(files []*models.File, prjInfo *wfclient.ProjectFullFragment) []*models.Component {
+func (c *WikifactoryCrawler) getSubComponents(release *models.Component, files []*models.File) []*models.Component {
 	// filter out readme and other files
 	filtered := make([]*models.File, 0, len(files))
 	for _, file := range files {
@@ -492,12 +554,16 @@ func (c *WikifactoryCrawler) getSubComponents(files []*models.File, prjInfo *wfc
 		filename = strings.Replace(filename, "-", "", -1)
 		filename = strings.Replace(filename, "_", "", -1)
 		filename = strings.ToUpper(filename)
+		excluded := false
 		for _, excl := range excludeFiles {
 			if filename == excl {
-				continue
+				excluded = true
+				break
 			}
 		}
-		filtered = append(filtered, file)
+		if !excluded {
+			filtered = append(filtered, file)
+		}
 	}
 
 	type FileWrap struct {
@@ -512,19 +578,22 @@ func (c *WikifactoryCrawler) getSubComponents(files []*models.File, prjInfo *wfc
 
 	// put files in buckets
 	buckets := make(map[string][]FileWrap, len(filtered))
+	stems := make([]string, 0, len(filtered))
 	for _, fileWrap := range fileWraps {
 		ps, _ := fileWrap.path.WithSuffix("")
 		normalizedName := strings.ToLower(ps.String())
 		bucket, ok := buckets[normalizedName]
 		if !ok {
 			bucket = make([]FileWrap, 0, 1)
+			stems = append(stems, normalizedName)
 		}
 		buckets[normalizedName] = append(bucket, fileWrap)
 	}
 
 	// figure out what files are the sources, the exports and the images
 	cmps := make([]*models.Component, 0, len(buckets))
-	for _, bucket := range buckets {
+	for _, stem := range stems {
+		bucket := buckets[stem]
 		cmp := &models.Component{}
 		for _, fileWrap := range bucket {
 			ext := fileWrap.path.Suffix()
@@ -572,10 +641,29 @@ func (c *WikifactoryCrawler) getSubComponents(files []*models.File, prjInfo *wfc
 		}
 
 		// # only add, if a source file was identified
-		if cmp.Source != nil {
-			cmp.Name = cmp.Source.Name
-			cmps = append(cmps, cmp)
+		if cmp.Source == nil {
+			continue
 		}
+		cmp.DiscoveredAt = release.DiscoveredAt
+		cmp.LastIndexedAt = release.LastIndexedAt
+		cmp.DataSource = release.DataSource
+
+		// Xid format: domain.tld/owner/repo/ref/file-path/component-name/file-stem
+		cmp.Xid = p(*release.Xid + "/" + *asXid(strings.TrimLeft(stem, "/")))
+		cmp.Name = cmp.Source.Name
+		cmp.Description = release.Description
+		cmp.Version = release.Version
+		cmp.CreatedAt = release.CreatedAt
+		cmp.IsLatest = release.IsLatest
+		cmp.Repository = release.Repository
+		cmp.License = release.License
+		cmp.Licensor = release.Licensor
+		cmp.DocumentationLanguage = release.DocumentationLanguage
+		cmp.TechnologyReadinessLevel = release.TechnologyReadinessLevel
+		cmp.DocumentationReadinessLevel = release.DocumentationReadinessLevel
+		cmp.Software = []*models.Software{}
+		cmp.UsedIn = []*models.Component{release}
+		cmps = append(cmps, cmp)
 	}
 
 	if len(cmps) == 0 {
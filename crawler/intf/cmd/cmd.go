@@ -16,10 +16,18 @@ package cmd
 
 import (
 	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/github"
+	"losh/crawler/core/gitlab"
+	"losh/crawler/core/oshwa"
+	"losh/crawler/core/oshwa/oshwaclient"
+	"losh/crawler/core/wikifactory"
+	"losh/internal/core/product/services"
 	"losh/internal/infra/dgraph"
 	"losh/internal/lib/log"
 
 	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
 )
 
 func initConfig(cfgPth string) (config.Config, error) {
@@ -44,15 +52,143 @@ func initConfigAndDatabase(cfgPth string) (config.Config, *dgraph.DgraphReposito
 	if err != nil {
 		return config.Config{}, nil, err
 	}
+	db, err := initDatabase(cfg)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	return cfg, db, nil
+}
 
-	// database
+func initDatabase(cfg config.Config) (*dgraph.DgraphRepository, error) {
 	db, err := dgraph.NewDgraphRepository(cfg.Database)
 	if err != nil {
-		return config.Config{}, nil, errors.Wrap(err, "failed to initialize Dgraph database connection")
+		return nil, errors.Wrap(err, "failed to initialize Dgraph database connection")
 	}
 	if err = db.WaitUntilReachable(); err != nil {
-		return config.Config{}, nil, errors.New("failed to connect to Dgraph database")
+		return nil, errors.New("failed to connect to Dgraph database")
 	}
+	return db, nil
+}
 
-	return cfg, db, nil
+// initCrawlers creates the crawlers of all enabled platforms and registers them
+// by the domain of their platform.
+func initCrawlers(svc *services.Service, stateStore crawler.StateStore, cfg config.Config) (*crawler.Registry, error) {
+	log := log.NewLogger("cmd")
+	registry := crawler.NewRegistry()
+	platforms := cfg.Crawler.Platforms
+	userAgent := cfg.Crawler.UserAgent
+
+	// detection of removed products
+	var reconciler *crawler.Reconciler
+	if cfg.Crawler.Reconcile.Enabled {
+		reconciler = crawler.NewReconciler(svc).SetPurgeAfter(cfg.Crawler.Reconcile.PurgeAfter)
+	}
+
+	// Wikifactory
+	if platforms.Wikifactory.IsEnabled() {
+		wfCfg := platforms.Wikifactory
+		wfCfg.UserAgent = stringOrDefault(wfCfg.UserAgent, userAgent)
+		wfCrwl := wikifactory.NewWikifactoryCrawler(svc, wfCfg).
+			SetStateStore(stateStore).
+			SetReconciler(reconciler).
+			SetUpdateMaxAge(cfg.Crawler.Update.MaxAge)
+		if err := registry.Register(wfCrwl); err != nil {
+			return nil, err
+		}
+	}
+
+	// GitHub
+	if platforms.GitHub.IsEnabled() {
+		ghCfg := platforms.GitHub
+		ghCfg.UserAgent = stringOrDefault(ghCfg.UserAgent, userAgent)
+		ghCrwl := github.NewGitHubCrawler(svc, ghCfg).
+			SetStateStore(stateStore).
+			SetReconciler(reconciler).
+			SetUpdateMaxAge(cfg.Crawler.Update.MaxAge)
+		if err := registry.Register(ghCrwl); err != nil {
+			return nil, err
+		}
+	}
+
+	// GitLab instances
+	for _, glCfg := range platforms.GitLab {
+		if !glCfg.IsEnabled() {
+			continue
+		}
+		glCfg.UserAgent = stringOrDefault(glCfg.UserAgent, userAgent)
+		glCrwl, err := gitlab.NewGitLabCrawler(svc, glCfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create GitLab crawler")
+		}
+		glCrwl.SetStateStore(stateStore).
+			SetReconciler(reconciler).
+			SetUpdateMaxAge(cfg.Crawler.Update.MaxAge)
+		if err := registry.Register(glCrwl); err != nil {
+			return nil, err
+		}
+	}
+
+	// OSHWA certification list requires authentication
+	if platforms.OSHWA.IsEnabled() {
+		oshwaCrwl, err := initOSHWACrawler(svc, platforms.OSHWA, userAgent)
+		if err != nil {
+			return nil, err
+		}
+		if oshwaCrwl != nil {
+			if err := registry.Register(oshwaCrwl.SetStateStore(stateStore).SetReconciler(reconciler)); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Info("no OSHWA token configured, skipping OSHWA")
+		}
+	}
+
+	return registry, nil
+}
+
+// initStateStore creates the configured store for the crawler states. The
+// database is only required for the `database` store.
+func initStateStore(cfg config.Config, db *dgraph.DgraphRepository) crawler.StateStore {
+	stateCfg := cfg.Crawler.State
+	if stateCfg.Store == "database" {
+		return crawler.NewDatabaseStateStore(db, stateCfg.Archive)
+	}
+	return crawler.NewFileStateStore(pathlib.NewPath(stateCfg.Directory), stateCfg.Archive)
+}
+
+// selectCrawlers returns the crawler of the given platform or all registered
+// crawlers, if no platform is given.
+func selectCrawlers(registry *crawler.Registry, platform string) ([]crawler.Crawler, error) {
+	if platform == "" {
+		return registry.All(), nil
+	}
+	crwl, err := registry.Get(platform)
+	if err != nil {
+		return nil, err
+	}
+	return []crawler.Crawler{crwl}, nil
+}
+
+// initOSHWACrawler creates the OSHWA crawler. It returns nil, if neither an
+// API token nor a fixture file is configured.
+func initOSHWACrawler(svc *services.Service, oshwaCfg config.OSHWAConfig, userAgent string) (*oshwa.OSHWACrawler, error) {
+	if oshwaCfg.FixtureFile != "" {
+		client, err := oshwaclient.NewFixtureClient(pathlib.NewPath(oshwaCfg.FixtureFile))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load OSHWA fixture file")
+		}
+		return oshwa.NewOSHWACrawlerWithClient(svc, client), nil
+	}
+	if oshwaCfg.Token == "" {
+		return nil, nil
+	}
+	oshwaCfg.UserAgent = stringOrDefault(oshwaCfg.UserAgent, userAgent)
+	return oshwa.NewOSHWACrawler(svc, oshwaCfg), nil
+}
+
+func stringOrDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
 }
@@ -30,6 +30,7 @@ var DevCommand = &gcli.Command{
 	Subs: []*gcli.Command{
 		DevCrawlProductCommand,
 		DevDownloadLicensesCommand,
+		DevSnapshotLicensesCommand,
 		DevUploadFile,
 		DevUploadLicensesCommand,
 		DevUploadTestDataCommand,
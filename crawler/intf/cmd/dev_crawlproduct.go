@@ -19,7 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"losh/crawler/core/wikifactory"
+	"losh/crawler/core/config"
+	"losh/crawler/core/gitlab"
 	"losh/internal/core/product/models"
 	"losh/internal/core/product/services"
 
@@ -30,6 +31,7 @@ import (
 
 var devCrawlProductOptions = struct {
 	OutputPath string
+	Platform   string
 }{}
 
 // DevCrawlProductCommand is the CLI command to crawl a bunch of products by
@@ -40,6 +42,7 @@ var DevCrawlProductCommand = &gcli.Command{
 	Config: func(c *gcli.Command) {
 		c.AddArg("url", "URLs to crawl", true, true)
 		c.StrOpt(&devCrawlProductOptions.OutputPath, "output", "o", "", "output file path; if defined, the output will be written to this file as json instead of saving to the database")
+		c.StrOpt(&devCrawlProductOptions.Platform, "platform", "p", "", "domain of the platform crawler to use (e.g. gitlab.example.com); defaults to the domain of the URL")
 	},
 	Func: func(cmd *gcli.Command, args []string) error {
 		urls := cmd.Arg("url").Strings()
@@ -65,8 +68,20 @@ var DevCrawlProductCommand = &gcli.Command{
 			return errors.Wrap(err, "failed to load licenses")
 		}
 
-		// setup crawler
-		crw := wikifactory.NewWikifactoryCrawler(svc, cfg.Crawler.UserAgent)
+		// setup crawlers
+		registry, err := initCrawlers(svc, initStateStore(cfg, db), cfg)
+		if err != nil {
+			return err
+		}
+		if !registry.Has("gitlab.com") {
+			// public projects on gitlab.com can be crawled anonymously
+			glCrwl, _ := gitlab.NewGitLabCrawler(svc, config.GitLabConfig{
+				Name:      "GitLab",
+				BaseURL:   "https://gitlab.com",
+				UserAgent: cfg.Crawler.UserAgent,
+			})
+			registry.Register(glCrwl)
+		}
 
 		// crawl products
 		prds := make([]models.Node, 0, len(prdIDs))
@@ -74,7 +89,15 @@ var DevCrawlProductCommand = &gcli.Command{
 		for _, prdID := range prdIDs {
 			fmt.Println("Crawling product:", prdID)
 
-			prd, err := crw.GetProduct(ctx, prdID)
+			platform := prdID.Platform
+			if devCrawlProductOptions.Platform != "" {
+				platform = devCrawlProductOptions.Platform
+			}
+			crwl, err := registry.Get(platform)
+			if err != nil {
+				return err
+			}
+			prd, err := crwl.GetProduct(ctx, prdID)
 			if err != nil {
 				return errors.Wrap(err, "failed to get product")
 			}
@@ -0,0 +1,79 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+
+	"losh/internal/infra/spdxorg"
+	"losh/internal/lib/log"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/gookit/gcli/v3"
+)
+
+var devSnapshotLicensesOptions = struct {
+	Version string
+}{}
+
+// DevSnapshotLicensesCommand is the CLI command to download the complete SPDX
+// license list and save it as a snapshot, that can be embedded for offline use.
+var DevSnapshotLicensesCommand = &gcli.Command{
+	Name: "snapshot-licenses",
+	Desc: "Download the SPDX license list and save it as a snapshot for offline use",
+	Config: func(c *gcli.Command) {
+		c.AddArg("file", "File to save to; use internal/infra/spdxorg/"+spdxorg.SnapshotFile+" to embed the snapshot", true, false)
+		c.StrOpt(&devSnapshotLicensesOptions.Version, "version", "", "", "version of the SPDX license list to download (e.g. 3.19); defaults to the latest version")
+	},
+	Func: func(cmd *gcli.Command, args []string) error {
+		snpFlePth := pathlib.NewPath(cmd.Arg("file").String())
+
+		cfg, err := initConfig(devOptions.ConfigPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load configuration")
+		}
+
+		// logging
+		err = log.Initialize(cfg.Log)
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize logging")
+		}
+
+		log := log.NewLogger("cmd")
+		log.Info("downloading license list now")
+
+		spdx := spdxorg.NewSpdxOrgProvider(cfg.Crawler.UserAgent).SetVersion(devSnapshotLicensesOptions.Version)
+		snapshot, err := spdx.CreateSnapshot(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to download license list")
+		}
+
+		// save snapshot
+		buf := &bytes.Buffer{}
+		if err = spdxorg.WriteSnapshot(buf, snapshot); err != nil {
+			return err
+		}
+		if err = snpFlePth.WriteFile(buf.Bytes()); err != nil {
+			return errors.Wrap(err, "failed to save license snapshot")
+		}
+
+		log.Infow("successfully saved license snapshot", "version", snapshot.LicenseListVersion,
+			"licenses", len(snapshot.Licenses), "exceptions", len(snapshot.Exceptions))
+
+		return nil
+	},
+}
@@ -16,8 +16,8 @@ package cmd
 
 import (
 	"context"
+	"time"
 
-	"losh/crawler/core/wikifactory"
 	"losh/internal/core/product/services"
 	"losh/internal/lib/log"
 
@@ -27,6 +27,7 @@ import (
 
 var discoverOptions = struct {
 	ConfigPath string
+	Platform   string
 }{}
 
 // DiscoverCommand is the CLI command to discover products and save them to the database.
@@ -35,6 +36,7 @@ var DiscoverCommand = &gcli.Command{
 	Desc: "Discover products and save them to the database",
 	Config: func(c *gcli.Command) {
 		c.StrOpt(&discoverOptions.ConfigPath, "config", "c", "", "configuration file path")
+		c.StrOpt(&discoverOptions.Platform, "platform", "p", "", "domain of the platform to discover products on (e.g. github.com); defaults to all enabled platforms")
 	},
 	Func: func(cmd *gcli.Command, args []string) error {
 		cfg, db, err := initConfigAndDatabase(discoverOptions.ConfigPath)
@@ -43,15 +45,32 @@ var DiscoverCommand = &gcli.Command{
 		}
 		log := log.NewLogger("cmd")
 
-		// setup crawler
-		svc := services.NewService(db)
+		// setup crawlers
+		svc := services.NewService(db).
+			SetPopularityWindows(cfg.Crawler.Popularity.SnapshotInterval, cfg.Crawler.Popularity.GrowthWindows)
 		svc.ReloadLicenseCache()
-		crwl := wikifactory.NewWikifactoryCrawler(svc, cfg.Crawler.UserAgent)
+		registry, err := initCrawlers(svc, initStateStore(cfg, db), cfg)
+		if err != nil {
+			return err
+		}
+		crawlers, err := selectCrawlers(registry, discoverOptions.Platform)
+		if err != nil {
+			return err
+		}
 
 		// discover products
-		err = crwl.DiscoverProducts(context.Background())
-		if err != nil {
-			return errors.Wrap(err, "failed to discover products")
+		for _, crwl := range crawlers {
+			// the changes of the products are recorded with the ID of the run
+			ctx := services.WithCrawlerRunID(context.Background(), services.NewCrawlerRunID(crwl.Domain()+"/discover", time.Now()))
+			err = crwl.DiscoverProducts(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to discover products on %s", crwl.Domain())
+			}
+		}
+
+		// the growth of products, that were not crawled, is refreshed as well
+		if _, err = svc.RefreshPopularity(context.Background()); err != nil {
+			return errors.Wrap(err, "failed to refresh popularity")
 		}
 
 		log.Info("successfully discovered products")
@@ -29,6 +29,7 @@ var ManageCommand = &gcli.Command{
 	},
 	Subs: []*gcli.Command{
 		ManageUpdateLicensesCommand,
+		ManageRefreshPopularityCommand,
 	},
 	Aliases: []string{"mng", "m"},
 }
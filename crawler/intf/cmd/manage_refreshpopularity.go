@@ -0,0 +1,52 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/gookit/gcli/v3"
+)
+
+// ManageRefreshPopularityCommand is the CLI command to recompute the growth of
+// the star and fork counts of all products. It is meant to be run on a
+// schedule, so that the growth of products, that are not crawled anymore,
+// declines as well.
+var ManageRefreshPopularityCommand = &gcli.Command{
+	Name: "refresh-popularity",
+	Desc: "Recompute the growth of the star and fork counts of all products",
+	Func: func(cmd *gcli.Command, args []string) error {
+		cfg, db, err := initConfigAndDatabase(manageOptions.ConfigPath)
+		if err != nil {
+			return err
+		}
+		log := log.NewLogger("cmd")
+		log.Info("refreshing popularity now")
+
+		svc := services.NewService(db).
+			SetPopularityWindows(cfg.Crawler.Popularity.SnapshotInterval, cfg.Crawler.Popularity.GrowthWindows)
+		updated, err := svc.RefreshPopularity(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to refresh popularity")
+		}
+
+		log.Infow("successfully refreshed popularity", "updated", updated)
+		return nil
+	},
+}
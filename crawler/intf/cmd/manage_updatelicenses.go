@@ -16,7 +16,12 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	"losh/internal/core/product/services"
 	"losh/internal/infra/spdxorg"
 	"losh/internal/lib/log"
 
@@ -24,30 +29,87 @@ import (
 	"github.com/gookit/gcli/v3"
 )
 
+var manageUpdateLicensesOptions = struct {
+	Version string
+	Offline bool
+	DryRun  bool
+}{}
+
 // ManageUpdateLicensesCommand is the CLI command to update the licenses.
 var ManageUpdateLicensesCommand = &gcli.Command{
 	Name: "update-licenses",
-	Desc: "Download SPDX licenses and update the license database entries",
+	Desc: "Download SPDX licenses and exceptions and update the license database entries",
+	Config: func(c *gcli.Command) {
+		c.StrOpt(&manageUpdateLicensesOptions.Version, "version", "", "", "version of the SPDX license list to use (e.g. 3.19); defaults to the latest version")
+		c.BoolOpt(&manageUpdateLicensesOptions.Offline, "offline", "", false, "use the embedded license list snapshot instead of downloading the licenses")
+		c.BoolOpt(&manageUpdateLicensesOptions.DryRun, "dry-run", "n", false, "only print the changes without applying them")
+	},
 	Func: func(cmd *gcli.Command, args []string) error {
 		cfg, db, err := initConfigAndDatabase(manageOptions.ConfigPath)
 		if err != nil {
 			return err
 		}
+		ctx := context.Background()
 
 		log := log.NewLogger("cmd")
 		log.Info("updating licenses now")
 
-		// download licenses
-		licenseProvider := spdxorg.NewSpdxOrgProvider(cfg.Crawler.UserAgent)
-		licenses, err := licenseProvider.GetAllLicenses(context.Background())
+		// get licenses from SPDX
+		licenseProvider := spdxorg.NewSpdxOrgProvider(cfg.Crawler.UserAgent).
+			SetVersion(manageUpdateLicensesOptions.Version).
+			SetOffline(manageUpdateLicensesOptions.Offline)
+		licenses, err := licenseProvider.GetAllLicenses(ctx)
 		if err != nil {
 			return errors.Wrap(err, "failed to download licenses")
 		}
+		exceptions, err := licenseProvider.GetAllExceptions(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to download license exceptions")
+		}
 
-		// upload licenses
-		err = db.CreateLicenses(context.Background(), licenses)
+		// compare with the stored licenses
+		storedLicenses, _, err := db.GetAllLicenses(ctx)
 		if err != nil {
-			return errors.Wrap(err, "failed to save licenses")
+			return errors.Wrap(err, "failed to get stored licenses")
+		}
+		storedExceptions, _, err := db.GetAllExceptions(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get stored license exceptions")
+		}
+		licenseDiff := services.DiffLicenses(storedLicenses, licenses)
+		exceptionDiff := services.DiffExceptions(storedExceptions, exceptions)
+		writeLicenseDiff(os.Stdout, licenseDiff, exceptionDiff)
+
+		if manageUpdateLicensesOptions.DryRun {
+			return nil
+		}
+
+		// apply changes of the licenses
+		if len(licenseDiff.Added) > 0 {
+			if err = db.CreateLicenses(ctx, licenseDiff.Added); err != nil {
+				return errors.Wrap(err, "failed to save licenses")
+			}
+		}
+		for _, changes := range [][]services.LicenseChange{licenseDiff.Deprecated, licenseDiff.Changed} {
+			for _, c := range changes {
+				if err = db.UpdateLicense(ctx, c.License); err != nil {
+					return errors.Wrap(err, "failed to save licenses")
+				}
+			}
+		}
+
+		// apply changes of the exceptions
+		if len(exceptionDiff.Added) > 0 {
+			if err = db.CreateExceptions(ctx, exceptionDiff.Added); err != nil {
+				return errors.Wrap(err, "failed to save license exceptions")
+			}
+		}
+		for _, changes := range [][]services.ExceptionChange{exceptionDiff.Deprecated, exceptionDiff.Changed} {
+			for _, c := range changes {
+				if err = db.UpdateException(ctx, c.Exception); err != nil {
+					return errors.Wrap(err, "failed to save license exceptions")
+				}
+			}
 		}
 
 		log.Info("successfully updated licenses")
@@ -55,3 +117,35 @@ var ManageUpdateLicensesCommand = &gcli.Command{
 		return nil
 	},
 }
+
+// writeLicenseDiff prints the changes of the licenses and exceptions.
+func writeLicenseDiff(w io.Writer, licenseDiff *services.LicenseDiff, exceptionDiff *services.ExceptionDiff) {
+	if licenseDiff.IsEmpty() && exceptionDiff.IsEmpty() {
+		fmt.Fprintln(w, "licenses are up to date")
+		return
+	}
+
+	fmt.Fprintf(w, "licenses: %d added, %d deprecated, %d changed, %d unchanged\n",
+		len(licenseDiff.Added), len(licenseDiff.Deprecated), len(licenseDiff.Changed), licenseDiff.Unchanged)
+	for _, l := range licenseDiff.Added {
+		fmt.Fprintf(w, "  + %s (%s)\n", *l.Xid, *l.Name)
+	}
+	for _, c := range licenseDiff.Deprecated {
+		fmt.Fprintf(w, "  - %s (deprecated)\n", *c.License.Xid)
+	}
+	for _, c := range licenseDiff.Changed {
+		fmt.Fprintf(w, "  ~ %s (%s)\n", *c.License.Xid, strings.Join(c.Fields, ", "))
+	}
+
+	fmt.Fprintf(w, "exceptions: %d added, %d deprecated, %d changed, %d unchanged\n",
+		len(exceptionDiff.Added), len(exceptionDiff.Deprecated), len(exceptionDiff.Changed), exceptionDiff.Unchanged)
+	for _, e := range exceptionDiff.Added {
+		fmt.Fprintf(w, "  + %s (%s)\n", *e.Xid, *e.Name)
+	}
+	for _, c := range exceptionDiff.Deprecated {
+		fmt.Fprintf(w, "  - %s (deprecated)\n", *c.Exception.Xid)
+	}
+	for _, c := range exceptionDiff.Changed {
+		fmt.Fprintf(w, "  ~ %s (%s)\n", *c.Exception.Xid, strings.Join(c.Fields, ", "))
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"losh/crawler/core/crawler"
+
+	"github.com/gookit/gcli/v3"
+)
+
+var stateOptions = struct {
+	ConfigPath string
+}{}
+
+// StateCommand is the CLI command to inspect and reset the crawler states.
+var StateCommand = &gcli.Command{
+	Name: "state",
+	Desc: "Show and reset the states of the crawler runs",
+	Config: func(c *gcli.Command) {
+		c.StrOpt(&stateOptions.ConfigPath, "config", "c", "", "configuration file path")
+	},
+	Subs: []*gcli.Command{
+		StateShowCommand,
+		StateResetCommand,
+	},
+}
+
+// initConfiguredStateStore loads the configuration and creates the configured
+// state store. The database connection is only established, if the states are
+// stored in the database.
+func initConfiguredStateStore(cfgPth string) (crawler.StateStore, error) {
+	cfg, err := initConfig(cfgPth)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Crawler.State.Store != "database" {
+		return initStateStore(cfg, nil), nil
+	}
+	db, err := initDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return initStateStore(cfg, db), nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/gookit/gcli/v3"
+)
+
+var stateResetOptions = struct {
+	All bool
+}{}
+
+// StateResetCommand is the CLI command to reset crawler states, so that the
+// next run starts from the beginning.
+var StateResetCommand = &gcli.Command{
+	Name:    "reset",
+	Desc:    "Reset the states of the crawler runs",
+	Aliases: []string{"rst", "r"},
+	Config: func(c *gcli.Command) {
+		c.BoolOpt(&stateResetOptions.All, "all", "a", false, "reset all states including the archived ones")
+		c.AddArg("namespace", "namespaces of the states to reset (e.g. wikifactory.com/discover)", false, true)
+	},
+	Func: func(cmd *gcli.Command, args []string) error {
+		namespaces := cmd.Arg("namespace").Strings()
+		if len(namespaces) == 0 && !stateResetOptions.All {
+			return errors.New("either a namespace or --all must be given")
+		}
+
+		store, err := initConfiguredStateStore(stateOptions.ConfigPath)
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		if stateResetOptions.All {
+			entries, err := store.List(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to list crawler states")
+			}
+			namespaces = namespaces[:0]
+			for _, entry := range entries {
+				namespaces = append(namespaces, entry.Namespace)
+			}
+		}
+
+		for _, ns := range namespaces {
+			if err = store.Reset(ctx, ns); err != nil {
+				return errors.Wrapf(err, "failed to reset crawler state '%s'", ns)
+			}
+			fmt.Println("Reset crawler state:", ns)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"losh/crawler/core/crawler"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/gookit/gcli/v3"
+)
+
+// StateShowCommand is the CLI command to show the stored crawler states.
+var StateShowCommand = &gcli.Command{
+	Name:    "show",
+	Desc:    "Show the stored states of the crawler runs",
+	Aliases: []string{"shw", "s"},
+	Config: func(c *gcli.Command) {
+		c.AddArg("namespace", "namespaces of the states to show (e.g. wikifactory.com/discover); defaults to all", false, true)
+	},
+	Func: func(cmd *gcli.Command, args []string) error {
+		store, err := initConfiguredStateStore(stateOptions.ConfigPath)
+		if err != nil {
+			return err
+		}
+		entries, err := store.List(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to list crawler states")
+		}
+
+		// filter by namespace
+		namespaces := cmd.Arg("namespace").Strings()
+		if len(namespaces) > 0 {
+			filtered := make([]crawler.StateEntry, 0, len(namespaces))
+			for _, entry := range entries {
+				for _, ns := range namespaces {
+					if entry.Namespace == ns {
+						filtered = append(filtered, entry)
+						break
+					}
+				}
+			}
+			entries = filtered
+		}
+
+		// serialize to JSON
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal crawler states")
+		}
+		os.Stdout.Write(b)
+		return nil
+	},
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"losh/internal/core/product/services"
+	"losh/internal/lib/log"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/gookit/gcli/v3"
+)
+
+var updateOptions = struct {
+	ConfigPath string
+	MaxAge     string
+	Platform   string
+}{}
+
+// UpdateCommand is the CLI command to re-index products that are already
+// stored in the database.
+var UpdateCommand = &gcli.Command{
+	Name: "update",
+	Desc: "Re-index products that haven't been indexed for a while",
+	Config: func(c *gcli.Command) {
+		c.StrOpt(&updateOptions.ConfigPath, "config", "c", "", "configuration file path")
+		c.StrOpt(&updateOptions.MaxAge, "max-age", "", "", "re-index products last indexed longer ago than this (e.g. 72h); overrides the configuration")
+		c.StrOpt(&updateOptions.Platform, "platform", "p", "", "domain of the platform to update products of (e.g. wikifactory.com); defaults to all enabled platforms")
+	},
+	Func: func(cmd *gcli.Command, args []string) error {
+		cfg, db, err := initConfigAndDatabase(updateOptions.ConfigPath)
+		if err != nil {
+			return err
+		}
+		log := log.NewLogger("cmd")
+
+		if updateOptions.MaxAge != "" {
+			cfg.Crawler.Update.MaxAge, err = time.ParseDuration(updateOptions.MaxAge)
+			if err != nil {
+				return errors.Wrap(err, "invalid max age")
+			}
+		}
+
+		// setup crawlers
+		svc := services.NewService(db).
+			SetPopularityWindows(cfg.Crawler.Popularity.SnapshotInterval, cfg.Crawler.Popularity.GrowthWindows)
+		svc.ReloadLicenseCache()
+		registry, err := initCrawlers(svc, initStateStore(cfg, db), cfg)
+		if err != nil {
+			return err
+		}
+		crawlers, err := selectCrawlers(registry, updateOptions.Platform)
+		if err != nil {
+			return err
+		}
+
+		// update products
+		for _, crwl := range crawlers {
+			// the changes of the products are recorded with the ID of the run
+			ctx := services.WithCrawlerRunID(context.Background(), services.NewCrawlerRunID(crwl.Domain()+"/update", time.Now()))
+			err = crwl.UpdateProducts(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to update products on %s", crwl.Domain())
+			}
+		}
+
+		// the growth of products, that were not crawled, is refreshed as well
+		if _, err = svc.RefreshPopularity(context.Background()); err != nil {
+			return errors.Wrap(err, "failed to refresh popularity")
+		}
+
+		log.Info("successfully updated products")
+		return nil
+	},
+}
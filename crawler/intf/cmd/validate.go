@@ -0,0 +1,345 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"losh/crawler/core/config"
+	"losh/crawler/core/crawler"
+	"losh/crawler/core/gitlab"
+	"losh/crawler/core/local"
+	"losh/crawler/core/validator"
+	"losh/internal/core/manifest"
+	"losh/internal/core/product/models"
+	"losh/internal/core/product/services"
+	"losh/internal/infra/dgraph"
+	"losh/internal/infra/spdxorg"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/aisbergg/go-pathlib/pkg/pathlib"
+	"github.com/gookit/gcli/v3"
+)
+
+// urlCheckTimeout is the timeout of a single request checking the
+// reachability of a URL.
+const urlCheckTimeout = 30 * time.Second
+
+var validateOptions = struct {
+	ConfigPath string
+	Format     string
+	Platform   string
+	Offline    bool
+	CheckURLs  bool
+}{}
+
+// ValidateCommand is the CLI command to check a product for conformance with
+// the OKH-LOSH specification.
+var ValidateCommand = &gcli.Command{
+	Name:    "validate",
+	Desc:    "Check a product for conformance without saving it",
+	Aliases: []string{"vld"},
+	Config: func(c *gcli.Command) {
+		c.AddArg("target", "URL of the product on its platform or path of a local manifest file", true, false)
+		c.StrOpt(&validateOptions.ConfigPath, "config", "c", "", "configuration file path")
+		c.StrOpt(&validateOptions.Format, "format", "f", "text", "output format of the report; one of: text, json")
+		c.StrOpt(&validateOptions.Platform, "platform", "p", "", "domain of the platform crawler to use (e.g. gitlab.example.com); defaults to the domain of the URL")
+		c.BoolOpt(&validateOptions.Offline, "offline", "", false, "use the embedded license list snapshot instead of downloading the license list to validate a local manifest file")
+		c.BoolOpt(&validateOptions.CheckURLs, "check-urls", "", false, "check if the URLs of a published product are reachable")
+	},
+	Func: func(cmd *gcli.Command, args []string) error {
+		target := cmd.Arg("target").String()
+		if validateOptions.Format != "text" && validateOptions.Format != "json" {
+			return errors.Errorf("invalid output format '%s'", validateOptions.Format)
+		}
+
+		// validate either a local manifest file or a product on its platform
+		var report *validationReport
+		mnfPath := pathlib.NewPath(target)
+		if exists, _ := mnfPath.Exists(); exists {
+			// local manifest files are validated without a database
+			if validateOptions.CheckURLs {
+				return errors.New("URLs can only be checked for published products")
+			}
+			cfg, err := initConfig(validateOptions.ConfigPath)
+			if err != nil {
+				return err
+			}
+			report, err = validateManifestFile(cfg, mnfPath)
+			if err != nil {
+				return err
+			}
+		} else {
+			cfg, db, err := initConfigAndDatabase(validateOptions.ConfigPath)
+			if err != nil {
+				return err
+			}
+			svc := services.NewService(db)
+			if err = svc.ReloadLicenseCache(); err != nil {
+				return errors.Wrap(err, "failed to load licenses")
+			}
+			report, err = validateProductURL(svc, cfg, db, target)
+			if err != nil {
+				return err
+			}
+		}
+
+		// print report
+		if validateOptions.Format == "json" {
+			b, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal validation report")
+			}
+			os.Stdout.Write(b)
+			os.Stdout.WriteString("\n")
+		} else {
+			report.WriteText(os.Stdout)
+		}
+
+		if !report.Valid {
+			return errors.New("product does not conform to the specification")
+		}
+		return nil
+	},
+}
+
+// validateManifestFile parses, normalizes and validates a local manifest file
+// like a published one, but without saving it. The files next to the manifest
+// are considered for the check of the referenced files. The licenses are
+// taken from the SPDX license list instead of the database.
+func validateManifestFile(cfg config.Config, mnfPath pathlib.Path) (*validationReport, error) {
+	ctx := context.Background()
+	report := newValidationReport(mnfPath.String())
+	content, err := mnfPath.ReadFile()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest file")
+	}
+	mnf, err := manifest.Parse(mnfPath.String(), content)
+	if err != nil {
+		var parseErr *manifest.ParseError
+		if !errors.As(err, &parseErr) {
+			return nil, errors.Wrap(err, "failed to parse manifest file")
+		}
+		msg := parseErr.Message
+		if parseErr.Line > 0 {
+			msg = fmt.Sprintf("%s (line %d)", msg, parseErr.Line)
+		}
+		report.Add(validator.NewFinding(validator.RuleManifestInvalid, parseErr.Field, msg))
+		return report, nil
+	}
+
+	files, err := local.ListFiles(mnfPath.Parent().String())
+	if err != nil {
+		return nil, err
+	}
+	filePaths := make([]string, 0, len(files))
+	for filePath := range files {
+		filePaths = append(filePaths, filePath)
+	}
+
+	// load licenses
+	licenses, exceptions, err := spdxorg.NewSpdxOrgProvider(cfg.Crawler.UserAgent).
+		SetOffline(validateOptions.Offline).
+		GetLicenseList(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load licenses")
+	}
+	svc := services.NewService(nil)
+	svc.LoadLicenses(licenses, exceptions)
+	vld := validator.NewValidator(svc)
+
+	// quick check if mandatory fields are present
+	mdtFlds := validator.MandatoryFields{
+		OKHV:                  mnf.OKHV,
+		Name:                  mnf.Name,
+		Description:           mnf.Function,
+		Version:               mnf.Version,
+		Repository:            mnf.Repo,
+		License:               strings.TrimSpace(mnf.License),
+		Licensor:              mnf.Licensor,
+		DocumentationLanguage: mnf.DocumentationLanguage,
+		FilePaths:             filePaths,
+	}
+	if err = vld.ValidateMandatory(mdtFlds); err != nil {
+		return report, report.AddValidationError(err)
+	}
+
+	// validate the normalized product
+	prd, err := local.NewNormalizer(svc).NormalizeProduct(ctx, mnfPath.String(), mnf, files)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize product information")
+	}
+	if err = vld.ValidateProduct(prd); err != nil {
+		return report, report.AddValidationError(err)
+	}
+	return report, report.AddWarnings(prd)
+}
+
+// validateProductURL fetches, normalizes and validates the product identified
+// by the given URL.
+func validateProductURL(svc *services.Service, cfg config.Config, db *dgraph.DgraphRepository, url string) (*validationReport, error) {
+	report := newValidationReport(url)
+	prdID, err := models.NewProductIDFromURL(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid or unsupported URL")
+	}
+
+	// setup crawlers
+	registry, err := initCrawlers(svc, initStateStore(cfg, db), cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !registry.Has("gitlab.com") {
+		// public projects on gitlab.com can be crawled anonymously
+		glCrwl, err := gitlab.NewGitLabCrawler(svc, config.GitLabConfig{
+			Name:      "GitLab",
+			BaseURL:   "https://gitlab.com",
+			UserAgent: cfg.Crawler.UserAgent,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create GitLab crawler")
+		}
+		if err = registry.Register(glCrwl); err != nil {
+			return nil, err
+		}
+	}
+	platform := prdID.Platform
+	if validateOptions.Platform != "" {
+		platform = validateOptions.Platform
+	}
+	crwl, err := registry.Get(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	prd, err := crwl.GetProduct(context.Background(), prdID)
+	if err != nil {
+		var mnfErr *manifest.ErrInvalidManifest
+		if errors.As(err, &mnfErr) {
+			report.Add(validator.NewFinding(validator.RuleManifestInvalid, "", mnfErr.Error()))
+			return report, nil
+		}
+		if errors.Is(err, crawler.ErrProductNotFound) {
+			return nil, errors.Wrapf(err, "product '%s' not found", url)
+		}
+		return report, report.AddValidationError(err)
+	}
+
+	// the reachability of the URLs is checked on demand only, because it
+	// requires a request for each file
+	if validateOptions.CheckURLs {
+		httpClient := &http.Client{Timeout: urlCheckTimeout}
+		vld := validator.NewValidator(svc).
+			SetURLChecker(validator.NewHTTPURLChecker(httpClient, cfg.Crawler.UserAgent))
+		if err = vld.ValidateProduct(prd); err != nil {
+			return report, report.AddValidationError(err)
+		}
+	}
+
+	return report, report.AddWarnings(prd)
+}
+
+// validationReport lists the findings of the validation of a single product.
+type validationReport struct {
+	Target   string          `json:"target"`
+	Valid    bool            `json:"valid"`
+	Findings []reportFinding `json:"findings"`
+}
+
+// reportFinding is a finding together with a hint on how to fix it.
+type reportFinding struct {
+	validator.Finding
+	Hint string `json:"hint,omitempty"`
+}
+
+func newValidationReport(target string) *validationReport {
+	return &validationReport{
+		Target:   target,
+		Valid:    true,
+		Findings: []reportFinding{},
+	}
+}
+
+// Add adds a finding to the report.
+func (r *validationReport) Add(f validator.Finding) {
+	rf := reportFinding{Finding: f}
+	if rule := validator.LookupRule(f.RuleID); rule != nil {
+		rf.Hint = rule.Hint
+	}
+	if f.Severity == validator.SeverityError {
+		r.Valid = false
+	}
+	r.Findings = append(r.Findings, rf)
+}
+
+// AddValidationError adds the findings of the validation error to the report.
+// Any other error is returned as is.
+func (r *validationReport) AddValidationError(err error) error {
+	var vldErr *validator.ValidationError
+	if !errors.As(err, &vldErr) {
+		return err
+	}
+	for _, f := range vldErr.Findings() {
+		r.Add(f)
+	}
+	return nil
+}
+
+// AddWarnings adds the validation warnings attached to a valid product to the
+// report.
+func (r *validationReport) AddWarnings(prd *models.Product) error {
+	if prd.ValidationWarnings == nil {
+		return nil
+	}
+	warnings := []validator.Finding{}
+	if err := json.Unmarshal([]byte(*prd.ValidationWarnings), &warnings); err != nil {
+		return errors.Wrap(err, "failed to decode validation warnings")
+	}
+	for _, f := range warnings {
+		r.Add(f)
+	}
+	return nil
+}
+
+// WriteText writes the report in a human-readable form.
+func (r *validationReport) WriteText(w io.Writer) {
+	numErrs, numWarns := 0, 0
+	for _, f := range r.Findings {
+		if f.Severity == validator.SeverityError {
+			numErrs++
+		} else {
+			numWarns++
+		}
+	}
+	result := "PASSED"
+	if !r.Valid {
+		result = "FAILED"
+	}
+	fmt.Fprintf(w, "Validation of %s: %s (%d errors, %d warnings)\n", r.Target, result, numErrs, numWarns)
+
+	for _, f := range r.Findings {
+		fmt.Fprintf(w, "\n  %-7s [%s] %s\n", strings.ToUpper(string(f.Severity)), f.RuleID, f.String())
+		if f.Hint != "" {
+			fmt.Fprintf(w, "          hint: %s\n", f.Hint)
+		}
+	}
+}
@@ -36,6 +36,9 @@ func main() {
 	// register commands
 	app.Add(cmd.DevCommand)
 	app.Add(cmd.DiscoverCommand)
+	app.Add(cmd.UpdateCommand)
+	app.Add(cmd.ValidateCommand)
+	app.Add(cmd.StateCommand)
 	app.Add(cmd.ConfigCommand)
 	app.Add(cmd.ManageCommand)
 
@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/99designs/gqlgen v0.17.2
+	github.com/BurntSushi/toml v1.1.0
 	github.com/Yamashou/gqlgenc v0.0.6
 	github.com/abadojack/whatlanggo v1.0.1
 	github.com/aisbergg/go-copier v0.0.0-20220822180427-d38739757c35
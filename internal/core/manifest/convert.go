@@ -0,0 +1,245 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+
+	"losh/internal/core/product/models"
+)
+
+// FileResolver returns the file referenced in a manifest or nil if the
+// reference cannot be resolved.
+type FileResolver func(ref string) *models.File
+
+// LicenseResolver resolves a SPDX license expression into the primary license
+// and the additional licenses. It returns an error, if the expression is
+// malformed or refers to an unknown license.
+type LicenseResolver func(expr string) (*models.License, []*models.License, error)
+
+// ToComponent converts the manifest into a component including its parts and
+// software. Only the information contained in the manifest is set; the
+// crawler specific information (e.g. Xid, crawler meta, repository, licensor,
+// creation date) must be completed by the caller.
+func (m *Manifest) ToComponent(resolveFile FileResolver, resolveLicense LicenseResolver) *models.Component {
+	cmp := &models.Component{}
+	cmp.Name = p(s(m.Name))
+	cmp.Description = stringOrNil(s(m.Function))
+	cmp.Version = p(s(m.Version))
+	// invalid license expressions are reported by the validation
+	cmp.License, cmp.AdditionalLicenses, _ = resolveLicense(s(m.License))
+	cmp.LicenseExpression = stringOrNil(s(m.License))
+	cmp.DocumentationLanguage = stringOrNil(s(m.DocumentationLanguage))
+	cmp.TechnologyReadinessLevel = TechnologyReadinessLevel(m.TechnologyReadinessLevel)
+	cmp.DocumentationReadinessLevel = DocumentationReadinessLevel(m.DocumentationReadinessLevel)
+	cmp.Attestation = stringOrNil(s(m.Attestation))
+	cmp.Publication = stringOrNil(s(m.Publication))
+	cmp.CpcPatentClass = stringOrNil(s(m.CpcPatentClass))
+	cmp.CompliesWith = technicalStandard(m.StandardCompliance)
+	cmp.Tsdc = tsdc(m.Tsdc)
+	cmp.Mass = m.Mass
+	cmp.OuterDimensions = m.OuterDimensions.toModel()
+	cmp.Material = material(m.Material)
+	cmp.ManufacturingProcess = manufacturingProcess(m.ManufacturingProcess)
+
+	// files
+	cmp.Image = resolveFile(m.Image)
+	cmp.Readme = resolveFile(m.Readme)
+	cmp.ContributionGuide = resolveFile(m.ContributionGuide)
+	cmp.Bom = resolveFile(m.Bom)
+	cmp.ManufacturingInstructions = resolveFile(m.ManufacturingInstructions)
+	cmp.UserManual = resolveFile(m.UserManual)
+	setDesignFiles(cmp, m.Source, m.Export, m.Auxiliary, resolveFile)
+
+	// software
+	cmp.Software = make([]*models.Software, 0, len(m.Software))
+	for _, sw := range m.Software {
+		swLicense, _, _ := resolveLicense(s(sw.License))
+		cmp.Software = append(cmp.Software, &models.Software{
+			Release:               p(s(sw.Release)),
+			InstallationGuide:     resolveFile(sw.InstallationGuide),
+			DocumentationLanguage: stringOrNil(s(sw.DocumentationLanguage)),
+			License:               swLicense,
+			Licensor:              stringOrNil(s(sw.Licensor)),
+		})
+	}
+
+	// parts
+	for _, part := range m.Parts {
+		cmp.Components = append(cmp.Components, part.toComponent(cmp, resolveFile))
+	}
+
+	return cmp
+}
+
+// toComponent converts the part into a sub-component of the given component.
+// Information not available for parts is inherited from the parent component.
+func (pt *Part) toComponent(parent *models.Component, resolveFile FileResolver) *models.Component {
+	cmp := &models.Component{}
+	cmp.Name = p(s(pt.Name))
+	cmp.Description = parent.Description
+	cmp.Version = parent.Version
+	cmp.License = parent.License
+	cmp.AdditionalLicenses = parent.AdditionalLicenses
+	cmp.LicenseExpression = parent.LicenseExpression
+	cmp.DocumentationLanguage = stringOrNil(s(pt.DocumentationLanguage))
+	if cmp.DocumentationLanguage == nil {
+		cmp.DocumentationLanguage = parent.DocumentationLanguage
+	}
+	cmp.TechnologyReadinessLevel = parent.TechnologyReadinessLevel
+	cmp.DocumentationReadinessLevel = parent.DocumentationReadinessLevel
+	cmp.Tsdc = tsdc(pt.Tsdc)
+	cmp.Mass = pt.Mass
+	cmp.OuterDimensions = pt.OuterDimensions.toModel()
+	cmp.Material = material(pt.Material)
+	cmp.ManufacturingProcess = manufacturingProcess(pt.ManufacturingProcess)
+	cmp.UsedIn = []*models.Component{parent}
+
+	// files
+	cmp.Image = resolveFile(pt.Image)
+	setDesignFiles(cmp, pt.Source, pt.Export, pt.Auxiliary, resolveFile)
+
+	// nested parts
+	for _, part := range pt.Parts {
+		cmp.Components = append(cmp.Components, part.toComponent(cmp, resolveFile))
+	}
+
+	return cmp
+}
+
+// setDesignFiles sets the source, export and auxiliary files of the
+// component. The first resolvable source file is the main source, additional
+// source files are treated as exports.
+func setDesignFiles(cmp *models.Component, source, export, auxiliary []string, resolveFile FileResolver) {
+	for _, src := range source {
+		file := resolveFile(src)
+		if file == nil {
+			continue
+		}
+		if cmp.Source == nil {
+			cmp.Source = file
+		} else {
+			cmp.Export = append(cmp.Export, file)
+		}
+	}
+	for _, exp := range export {
+		if file := resolveFile(exp); file != nil {
+			cmp.Export = append(cmp.Export, file)
+		}
+	}
+	for _, aux := range auxiliary {
+		if file := resolveFile(aux); file != nil {
+			cmp.Auxiliary = append(cmp.Auxiliary, file)
+		}
+	}
+}
+
+// toModel converts the outer dimensions into their model representation.
+func (d *OuterDimensions) toModel() models.OuterDimensions {
+	if d == nil {
+		return nil
+	}
+	unit := stringOrNil(s(d.Unit))
+	if unit == nil {
+		unit = p("mm")
+	}
+	if d.IsOpenSCAD() {
+		return &models.OpenSCADDimensions{
+			Openscad: p(s(d.OpenSCAD)),
+			Unit:     unit,
+		}
+	}
+	return &models.BoundingBoxDimensions{
+		Width:  toMillimeters(*d.Width, *unit),
+		Height: toMillimeters(*d.Height, *unit),
+		Depth:  toMillimeters(*d.Depth, *unit),
+	}
+}
+
+// unitFactors contains the factors to convert lengths into millimeters.
+var unitFactors = map[string]float64{
+	"mm": 1,
+	"cm": 10,
+	"dm": 100,
+	"m":  1000,
+	"in": 25.4,
+}
+
+// toMillimeters converts the length given in the unit into millimeters, which
+// is the unit used for bounding box dimensions. Unknown units are taken as
+// millimeters.
+func toMillimeters(length float64, unit string) *float64 {
+	if factor, ok := unitFactors[strings.ToLower(unit)]; ok {
+		length *= factor
+	}
+	return &length
+}
+
+// technicalStandard returns the technical standard the component complies
+// with.
+func technicalStandard(name string) *models.TechnicalStandard {
+	name = s(name)
+	if name == "" {
+		return nil
+	}
+	return &models.TechnicalStandard{
+		Xid:  p(name),
+		Name: p(name),
+	}
+}
+
+// tsdc returns the technology-specific documentation criteria.
+func tsdc(name string) *models.TechnologySpecificDocumentationCriteria {
+	name = s(name)
+	if name == "" {
+		return nil
+	}
+	return &models.TechnologySpecificDocumentationCriteria{
+		Xid:  p(name),
+		Name: p(name),
+	}
+}
+
+func material(name string) *models.Material {
+	name = s(name)
+	if name == "" {
+		return nil
+	}
+	return &models.Material{Name: p(name)}
+}
+
+func manufacturingProcess(name string) *models.ManufacturingProcess {
+	name = s(name)
+	if name == "" {
+		return nil
+	}
+	return &models.ManufacturingProcess{Name: p(name)}
+}
+
+// stringOrNil returns a pointer to the string if it is non empty, else nil.
+func stringOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func s(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func p[T any](v T) *T {
+	return &v
+}
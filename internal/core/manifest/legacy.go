@@ -0,0 +1,405 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// legacyOKHV is the OKH version of converted legacy manifests.
+const legacyOKHV = "OKHv1.0"
+
+// legacyFieldMappings maps the top level fields of a legacy OKH v1.0 manifest
+// onto the OKH-LOSH fields. Fields that are not listed have no counterpart in
+// OKH-LOSH and are lost in the conversion.
+var legacyFieldMappings = map[string]string{
+	"title":                    "name",
+	"description":              "function",
+	"version":                  "version",
+	"documentation-home":       "repo",
+	"project-link":             "repo", // if documentation-home is missing
+	"license":                  "license",
+	"licensor":                 "licensor", // affiliation becomes the organization
+	"documentation-language":   "documentation-language",
+	"manifest-language":        "documentation-language", // if documentation-language is missing
+	"image":                    "image",
+	"standards-used":           "standard-compliance",
+	"bom":                      "bom",
+	"design-files":             "source",
+	"schematics":               "source",
+	"manufacturing-files":      "export",
+	"making-instructions":      "manufacturing-instructions",
+	"operating-instructions":   "user-manual",
+	"tool-list":                "auxiliary",
+	"maintenance-instructions": "auxiliary",
+	"disposal-instructions":    "auxiliary",
+	"risk-assessment":          "auxiliary",
+	"software":                 "software",
+}
+
+// LegacyManifest contains the information of a manifest in the original Open
+// Know-How v1.0 format, that is relevant for the conversion.
+type LegacyManifest struct {
+	Title                   string            `manifest:"title"`
+	Description             string            `manifest:"description"`
+	Version                 string            `manifest:"version"`
+	ProjectLink             string            `manifest:"project-link"`
+	DocumentationHome       string            `manifest:"documentation-home"`
+	ManifestLanguage        string            `manifest:"manifest-language"`
+	DocumentationLanguage   string            `manifest:"documentation-language"`
+	Image                   string            `manifest:"image"`
+	License                 *LegacyLicense    `manifest:"license"`
+	Licensor                []*LegacyPerson   `manifest:"licensor"`
+	StandardsUsed           []*LegacyStandard `manifest:"standards-used"`
+	Bom                     []*LegacyFile     `manifest:"bom"`
+	DesignFiles             []*LegacyFile     `manifest:"design-files"`
+	Schematics              []*LegacyFile     `manifest:"schematics"`
+	ManufacturingFiles      []*LegacyFile     `manifest:"manufacturing-files"`
+	MakingInstructions      []*LegacyFile     `manifest:"making-instructions"`
+	OperatingInstructions   []*LegacyFile     `manifest:"operating-instructions"`
+	ToolList                []*LegacyFile     `manifest:"tool-list"`
+	MaintenanceInstructions []*LegacyFile     `manifest:"maintenance-instructions"`
+	DisposalInstructions    []*LegacyFile     `manifest:"disposal-instructions"`
+	RiskAssessment          []*LegacyFile     `manifest:"risk-assessment"`
+	Software                []*LegacyFile     `manifest:"software"`
+}
+
+// LegacyLicense contains the licenses of a legacy manifest.
+type LegacyLicense struct {
+	Hardware      string `manifest:"hardware"`
+	Documentation string `manifest:"documentation"`
+	Software      string `manifest:"software"`
+}
+
+// decodeScalar implements the scalarDecoder interface. A single license is
+// taken as the hardware license.
+func (l *LegacyLicense) decodeScalar(value string) {
+	l.Hardware = value
+}
+
+// LegacyPerson is a person mentioned in a legacy manifest.
+type LegacyPerson struct {
+	Name        string `manifest:"name"`
+	Affiliation string `manifest:"affiliation"`
+	Email       string `manifest:"email"`
+}
+
+// decodeScalar implements the scalarDecoder interface.
+func (p *LegacyPerson) decodeScalar(value string) {
+	p.Name = value
+}
+
+// LegacyStandard is a standard used by the product of a legacy manifest.
+type LegacyStandard struct {
+	StandardTitle string `manifest:"standard-title"`
+	Publisher     string `manifest:"publisher"`
+	Reference     string `manifest:"reference"`
+	Certification string `manifest:"certification"`
+}
+
+// decodeScalar implements the scalarDecoder interface.
+func (s *LegacyStandard) decodeScalar(value string) {
+	s.StandardTitle = value
+}
+
+// LegacyFile is a file referenced in a legacy manifest.
+type LegacyFile struct {
+	Path  string `manifest:"path"`
+	Title string `manifest:"title"`
+}
+
+// decodeScalar implements the scalarDecoder interface.
+func (f *LegacyFile) decodeScalar(value string) {
+	f.Path = value
+}
+
+// ConversionReport describes how a legacy OKH v1.0 manifest was converted into
+// the OKH-LOSH format.
+type ConversionReport struct {
+	// Mapped lists the legacy fields that were converted.
+	Mapped []MappedField
+	// Lost lists the information that couldn't be converted.
+	Lost []LostField
+}
+
+// MappedField is a legacy field that was mapped onto an OKH-LOSH field.
+type MappedField struct {
+	From string
+	To   string
+}
+
+// LostField is information of a legacy manifest that was lost in the
+// conversion.
+type LostField struct {
+	Field  string
+	Line   int
+	Column int
+	Reason string
+}
+
+// LostFieldNames returns the names of the lost fields.
+func (r *ConversionReport) LostFieldNames() []string {
+	names := make([]string, 0, len(r.Lost))
+	for _, lost := range r.Lost {
+		names = append(names, lost.Field)
+	}
+	return names
+}
+
+// ParseLegacy parses a manifest in the original Open Know-How v1.0 format
+// (e.g. `okh-my-project.yml`) and converts it into the OKH-LOSH format. The
+// returned report lists the fields that were mapped and those that were lost.
+func ParseLegacy(path string, content []byte) (*Manifest, *ConversionReport, error) {
+	root, err := parseYAMLNode(path, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertLegacy(path, root)
+}
+
+// isLegacy returns true if the document is a legacy OKH v1.0 manifest. Those
+// are identified by the `title` field, which was replaced by `name` in OKH-LOSH.
+func isLegacy(root *node) bool {
+	m, ok := root.value.(map[string]*node)
+	if !ok {
+		return false
+	}
+	hasTitle := false
+	for key := range m {
+		switch normKey(key) {
+		case "okhv", "name":
+			return false
+		case "title":
+			hasTitle = true
+		}
+	}
+	return hasTitle
+}
+
+// convertLegacy decodes the legacy manifest and converts it into the OKH-LOSH
+// format.
+func convertLegacy(path string, root *node) (*Manifest, *ConversionReport, error) {
+	if _, ok := root.value.(map[string]*node); !ok {
+		return nil, nil, &ParseError{Path: path, Line: root.line, Column: root.column, Message: "manifest must be a mapping of keys to values"}
+	}
+	lgc := &LegacyManifest{}
+	dec := &decoder{path: path}
+	if err := dec.decode(root, "", reflect.ValueOf(lgc).Elem()); err != nil {
+		return nil, nil, err
+	}
+	cnv := &legacyConverter{root: root, report: &ConversionReport{}}
+	mnf := cnv.convert(lgc)
+	return mnf, cnv.report, nil
+}
+
+// legacyConverter converts a legacy manifest and keeps track of the fields.
+type legacyConverter struct {
+	root   *node
+	report *ConversionReport
+}
+
+func (c *legacyConverter) convert(lgc *LegacyManifest) *Manifest {
+	c.reportTopLevelFields()
+
+	mnf := &Manifest{OKHV: legacyOKHV, Conversion: c.report}
+	mnf.Name = lgc.Title
+	mnf.Function = lgc.Description
+	mnf.Version = lgc.Version
+	mnf.Image = lgc.Image
+
+	// repository
+	mnf.Repo = s(lgc.DocumentationHome)
+	if mnf.Repo == "" {
+		mnf.Repo = s(lgc.ProjectLink)
+	} else if s(lgc.ProjectLink) != "" && s(lgc.ProjectLink) != mnf.Repo {
+		c.lose("project-link", "only a single repository is supported, 'documentation-home' is used instead")
+	}
+
+	// documentation language
+	mnf.DocumentationLanguage = s(lgc.DocumentationLanguage)
+	if mnf.DocumentationLanguage == "" {
+		mnf.DocumentationLanguage = s(lgc.ManifestLanguage)
+	}
+
+	// license; OKH-LOSH supports a single license only, preferably the
+	// hardware license
+	if lcs := lgc.License; lcs != nil {
+		for _, l := range []struct{ field, id string }{
+			{"license.hardware", lcs.Hardware},
+			{"license.documentation", lcs.Documentation},
+			{"license.software", lcs.Software},
+		} {
+			id := s(l.id)
+			if id == "" {
+				continue
+			}
+			if mnf.License == "" {
+				mnf.License = id
+			} else if id != mnf.License {
+				c.lose(l.field, "only a single license is supported")
+			}
+		}
+	}
+
+	// licensor
+	for i, licensor := range lgc.Licensor {
+		if i > 0 {
+			c.lose("licensor["+strconv.Itoa(i)+"]", "only a single licensor is supported")
+			continue
+		}
+		mnf.Licensor = s(licensor.Name)
+		mnf.Organization = s(licensor.Affiliation)
+		if s(licensor.Email) != "" {
+			c.lose("licensor[0].email", "the licensor is given by name only")
+		}
+	}
+
+	// standards
+	for i, std := range lgc.StandardsUsed {
+		if i > 0 {
+			c.lose("standards-used["+strconv.Itoa(i)+"]", "only a single standard is supported")
+			continue
+		}
+		mnf.StandardCompliance = s(std.StandardTitle)
+	}
+
+	// files; additional files of single file fields become auxiliary files
+	mnf.Bom, mnf.Auxiliary = splitFiles(lgc.Bom, mnf.Auxiliary)
+	mnf.ManufacturingInstructions, mnf.Auxiliary = splitFiles(lgc.MakingInstructions, mnf.Auxiliary)
+	mnf.UserManual, mnf.Auxiliary = splitFiles(lgc.OperatingInstructions, mnf.Auxiliary)
+	mnf.Source = appendFiles(mnf.Source, lgc.DesignFiles, lgc.Schematics)
+	mnf.Export = appendFiles(mnf.Export, lgc.ManufacturingFiles)
+	mnf.Auxiliary = appendFiles(mnf.Auxiliary, lgc.ToolList, lgc.MaintenanceInstructions, lgc.DisposalInstructions, lgc.RiskAssessment)
+	for _, sw := range lgc.Software {
+		if path := s(sw.Path); path != "" {
+			mnf.Software = append(mnf.Software, &Software{Release: path})
+		}
+	}
+
+	return mnf
+}
+
+// reportTopLevelFields adds the top level fields of the legacy manifest to
+// the report, in the order of their appearance.
+func (c *legacyConverter) reportTopLevelFields() {
+	m := c.root.value.(map[string]*node)
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]].line != m[keys[j]].line {
+			return m[keys[i]].line < m[keys[j]].line
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		if m[key].value == nil {
+			continue
+		}
+		field := normKey(key)
+		if to, ok := legacyFieldMappings[field]; ok {
+			c.report.Mapped = append(c.report.Mapped, MappedField{From: field, To: to})
+			continue
+		}
+		c.lose(field, "no equivalent in OKH-LOSH")
+	}
+}
+
+// lose adds a lost field to the report.
+func (c *legacyConverter) lose(field, reason string) {
+	lost := LostField{Field: field, Reason: reason}
+	if n := nodeAt(c.root, field); n != nil {
+		lost.Line, lost.Column = n.line, n.column
+	}
+	c.report.Lost = append(c.report.Lost, lost)
+}
+
+// nodeAt returns the node at the given key path (e.g. `licensor[1].email`)
+// or nil if it doesn't exist.
+func nodeAt(root *node, keyPath string) *node {
+	n := root
+	for _, key := range strings.Split(keyPath, ".") {
+		idx := -1
+		if pos := strings.IndexByte(key, '['); pos != -1 {
+			idx, _ = strconv.Atoi(strings.TrimSuffix(key[pos+1:], "]"))
+			key = key[:pos]
+		}
+		m, ok := n.value.(map[string]*node)
+		if !ok {
+			return nil
+		}
+		var child *node
+		for k, v := range m {
+			if normKey(k) == key {
+				child = v
+				break
+			}
+		}
+		if child == nil {
+			return nil
+		}
+		n = child
+		if idx >= 0 {
+			lst, ok := n.value.([]*node)
+			if !ok {
+				// a single value is treated as a list with one element
+				if idx == 0 {
+					continue
+				}
+				return nil
+			}
+			if idx >= len(lst) {
+				return nil
+			}
+			n = lst[idx]
+		}
+	}
+	return n
+}
+
+// splitFiles returns the first file and appends the remaining files to the
+// given list.
+func splitFiles(files []*LegacyFile, rest []string) (string, []string) {
+	first := ""
+	for _, file := range files {
+		path := s(file.Path)
+		if path == "" {
+			continue
+		}
+		if first == "" {
+			first = path
+		} else {
+			rest = append(rest, path)
+		}
+	}
+	return first, rest
+}
+
+// appendFiles appends the paths of the files to the list.
+func appendFiles(lst []string, fileLists ...[]*LegacyFile) []string {
+	for _, files := range fileLists {
+		for _, file := range files {
+			if path := s(file.Path); path != "" {
+				lst = append(lst, path)
+			}
+		}
+	}
+	return lst
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLegacy(t *testing.T) {
+	path := filepath.Join("testdata", "okh-motor-driver.yml")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnf, report, err := ParseLegacy(path, content)
+	if err != nil {
+		t.Fatalf("ParseLegacy() error = %v", err)
+	}
+
+	tests := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"okhv", mnf.OKHV, legacyOKHV},
+		{"name", mnf.Name, "Motor Driver"},
+		{"function", mnf.Function, "Drives two brushed DC motors."},
+		{"version", mnf.Version, "1.0.0"},
+		// documentation-home takes precedence over project-link
+		{"repo", mnf.Repo, "https://github.com/example/motor-driver"},
+		{"documentation-language", mnf.DocumentationLanguage, "en"},
+		{"license", mnf.License, "CERN-OHL-1.2"},
+		{"licensor", mnf.Licensor, "Jane Doe"},
+		{"organization", mnf.Organization, "Example Lab"},
+		{"standard-compliance", mnf.StandardCompliance, "IPC-2221"},
+		{"bom", mnf.Bom, "bom.csv"},
+		{"manufacturing-instructions", mnf.ManufacturingInstructions, "docs/assembly.md"},
+		{"source", mnf.Source, []string{"board.kicad_pcb", "board.kicad_sch"}},
+		{"export", mnf.Export, []string{"gerber.zip"}},
+		// additional BOM files become auxiliary files
+		{"auxiliary", mnf.Auxiliary, []string{"bom-alternatives.csv", "docs/tools.md"}},
+		{"software", len(mnf.Software), 1},
+		{"software[0].release", mnf.Software[0].Release, "https://github.com/example/firmware"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+
+	// top level fields without counterpart are reported first, followed by
+	// the information lost in the conversion of the mapped fields
+	wantLost := []string{"date-created", "contact", "project-link", "license.documentation", "licensor[0].email", "licensor[1]"}
+	if got := report.LostFieldNames(); !reflect.DeepEqual(got, wantLost) {
+		t.Errorf("LostFieldNames() = %v, want %v", got, wantLost)
+	}
+	if lost := report.Lost[5]; lost.Line != 17 || lost.Column != 5 {
+		t.Errorf("position of '%s' = %d:%d, want 17:5", lost.Field, lost.Line, lost.Column)
+	}
+	if len(report.Mapped) == 0 || report.Mapped[0] != (MappedField{From: "title", To: "name"}) {
+		t.Errorf("Mapped = %v, want 'title' to be mapped first", report.Mapped)
+	}
+
+	// legacy manifests are converted when parsed as YAML
+	parsed, err := Parse(path, content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, mnf) {
+		t.Errorf("Parse() = %+v, want the converted manifest %+v", parsed, mnf)
+	}
+}
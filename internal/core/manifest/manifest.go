@@ -0,0 +1,230 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifest parses OKH-LOSH v1 manifest files written in TOML or YAML
+// and converts them into the domain models. Manifests in the original Open
+// Know-How v1.0 format are converted into the OKH-LOSH format.
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"losh/internal/infra/dgraph/dgclient"
+)
+
+// manifestNamePattern matches the accepted manifest file names (without
+// extension).
+var manifestNamePattern = regexp.MustCompile(`^okh([_\-\t ].+)*$`)
+
+var technologyReadinessLevels = map[string]dgclient.TechnologyReadinessLevel{
+	"OTRL1": dgclient.TechnologyReadinessLevelOtrl1,
+	"OTRL2": dgclient.TechnologyReadinessLevelOtrl2,
+	"OTRL3": dgclient.TechnologyReadinessLevelOtrl3,
+	"OTRL4": dgclient.TechnologyReadinessLevelOtrl4,
+	"OTRL5": dgclient.TechnologyReadinessLevelOtrl5,
+}
+var documentationReadinessLevels = map[string]dgclient.DocumentationReadinessLevel{
+	"ODRL1": dgclient.DocumentationReadinessLevelOdrl1,
+	"ODRL2": dgclient.DocumentationReadinessLevelOdrl2,
+	"ODRL3": dgclient.DocumentationReadinessLevelOdrl3,
+	"ODRL4": dgclient.DocumentationReadinessLevelOdrl4,
+	"ODRL5": dgclient.DocumentationReadinessLevelOdrl5,
+}
+
+// ErrInvalidManifest indicates that a manifest file is missing or could not be
+// parsed.
+type ErrInvalidManifest struct {
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *ErrInvalidManifest) Error() string {
+	return fmt.Sprintf("invalid manifest '%s': %s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrInvalidManifest) Unwrap() error {
+	return e.Err
+}
+
+// Manifest contains the information of an OKH-LOSH manifest file.
+type Manifest struct {
+	OKHV                        string           `manifest:"okhv"`
+	Name                        string           `manifest:"name"`
+	Repo                        string           `manifest:"repo"`
+	Version                     string           `manifest:"version"`
+	Release                     string           `manifest:"release"`
+	License                     string           `manifest:"license"`
+	Licensor                    string           `manifest:"licensor"`
+	Organization                string           `manifest:"organization"`
+	Readme                      string           `manifest:"readme"`
+	ContributionGuide           string           `manifest:"contribution-guide"`
+	Image                       string           `manifest:"image"`
+	Function                    string           `manifest:"function"`
+	DocumentationLanguage       string           `manifest:"documentation-language"`
+	TechnologyReadinessLevel    string           `manifest:"technology-readiness-level"`
+	DocumentationReadinessLevel string           `manifest:"documentation-readiness-level"`
+	Attestation                 string           `manifest:"attestation"`
+	Publication                 string           `manifest:"publication"`
+	StandardCompliance          string           `manifest:"standard-compliance"`
+	CpcPatentClass              string           `manifest:"cpc-patent-class"`
+	Tsdc                        string           `manifest:"tsdc"`
+	Bom                         string           `manifest:"bom"`
+	ManufacturingInstructions   string           `manifest:"manufacturing-instructions"`
+	UserManual                  string           `manifest:"user-manual"`
+	OuterDimensions             *OuterDimensions `manifest:"outer-dimensions"`
+	Material                    string           `manifest:"material"`
+	ManufacturingProcess        string           `manifest:"manufacturing-process"`
+	Mass                        *float64         `manifest:"mass"`
+	Source                      []string         `manifest:"source"`
+	Export                      []string         `manifest:"export"`
+	Auxiliary                   []string         `manifest:"auxiliary"`
+	Software                    []*Software      `manifest:"software"`
+	Parts                       []*Part          `manifest:"part"`
+
+	// Conversion is set if the manifest was converted from a legacy OKH v1.0
+	// manifest.
+	Conversion *ConversionReport
+}
+
+// validate implements the validator interface.
+func (m *Manifest) validate() string {
+	if m.Mass != nil && *m.Mass < 0 {
+		return "mass must not be negative"
+	}
+	return ""
+}
+
+// Part is a sub-part of the product described in the manifest.
+type Part struct {
+	Name                  string           `manifest:"name"`
+	Image                 string           `manifest:"image"`
+	DocumentationLanguage string           `manifest:"documentation-language"`
+	Tsdc                  string           `manifest:"tsdc"`
+	OuterDimensions       *OuterDimensions `manifest:"outer-dimensions"`
+	Material              string           `manifest:"material"`
+	ManufacturingProcess  string           `manifest:"manufacturing-process"`
+	Mass                  *float64         `manifest:"mass"`
+	Source                []string         `manifest:"source"`
+	Export                []string         `manifest:"export"`
+	Auxiliary             []string         `manifest:"auxiliary"`
+	Parts                 []*Part          `manifest:"part"`
+}
+
+// validate implements the validator interface.
+func (p *Part) validate() string {
+	if strings.TrimSpace(p.Name) == "" {
+		return "missing name of part"
+	}
+	if p.Mass != nil && *p.Mass < 0 {
+		return "mass must not be negative"
+	}
+	return ""
+}
+
+// OuterDimensions are the outer dimensions of a product or part. They are
+// either given as an OpenSCAD expression or as a bounding box.
+type OuterDimensions struct {
+	OpenSCAD string   `manifest:"openscad"`
+	Unit     string   `manifest:"unit"`
+	Width    *float64 `manifest:"width"`
+	Height   *float64 `manifest:"height"`
+	Depth    *float64 `manifest:"depth"`
+}
+
+// IsOpenSCAD returns true if the dimensions are given as OpenSCAD expression.
+func (d *OuterDimensions) IsOpenSCAD() bool {
+	return strings.TrimSpace(d.OpenSCAD) != ""
+}
+
+// validate implements the validator interface.
+func (d *OuterDimensions) validate() string {
+	if d.IsOpenSCAD() {
+		if d.Width != nil || d.Height != nil || d.Depth != nil {
+			return "outer dimensions must be given either as OpenSCAD expression or as bounding box"
+		}
+		return ""
+	}
+	if d.Width == nil || d.Height == nil || d.Depth == nil {
+		return "outer dimensions require either 'openscad' or 'width', 'height' and 'depth'"
+	}
+	if *d.Width < 0 || *d.Height < 0 || *d.Depth < 0 {
+		return "outer dimensions must not be negative"
+	}
+	return ""
+}
+
+// Software is a software release the product depends on.
+type Software struct {
+	Release               string `manifest:"release"`
+	InstallationGuide     string `manifest:"installation-guide"`
+	DocumentationLanguage string `manifest:"documentation-language"`
+	License               string `manifest:"license"`
+	Licensor              string `manifest:"licensor"`
+}
+
+// validate implements the validator interface.
+func (s *Software) validate() string {
+	if strings.TrimSpace(s.Release) == "" {
+		return "missing release of software"
+	}
+	return ""
+}
+
+// IsManifestFile returns true if the given path points to a file with an
+// accepted manifest file name and a supported format.
+func IsManifestFile(filePath string) bool {
+	name := strings.ToLower(path.Base(filePath))
+	pos := strings.LastIndexByte(name, '.')
+	if pos == -1 {
+		return false
+	}
+	switch name[pos+1:] {
+	case "toml", "yml", "yaml":
+		return manifestNamePattern.MatchString(name[:pos])
+	}
+	return false
+}
+
+// TechnologyReadinessLevel translates the TRL given in the manifest (e.g.
+// 'OTRL-4').
+func TechnologyReadinessLevel(trl string) *dgclient.TechnologyReadinessLevel {
+	lvl, ok := technologyReadinessLevels[normLevel(trl)]
+	if !ok {
+		lvl = dgclient.TechnologyReadinessLevelUndetermined
+	}
+	return &lvl
+}
+
+// DocumentationReadinessLevel translates the DRL given in the manifest (e.g.
+// 'ODRL-3').
+func DocumentationReadinessLevel(drl string) *dgclient.DocumentationReadinessLevel {
+	lvl, ok := documentationReadinessLevels[normLevel(drl)]
+	if !ok {
+		lvl = dgclient.DocumentationReadinessLevelUndetermined
+	}
+	return &lvl
+}
+
+func normLevel(lvl string) string {
+	lvl = strings.ToUpper(strings.TrimSpace(lvl))
+	lvl = strings.Replace(lvl, "-", "", -1)
+	lvl = strings.Replace(lvl, "_", "", -1)
+	lvl = strings.Replace(lvl, " ", "", -1)
+	return lvl
+}
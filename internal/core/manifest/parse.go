@@ -0,0 +1,578 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/aisbergg/go-errors/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseError describes a problem found in a manifest file. Line and column
+// start at 1 and are 0 if unknown.
+type ParseError struct {
+	Path    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Path)
+	if e.Line > 0 {
+		b.WriteString(":" + strconv.Itoa(e.Line))
+		if e.Column > 0 {
+			b.WriteString(":" + strconv.Itoa(e.Column))
+		}
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// validator is implemented by manifest types that check their values after
+// decoding.
+type validator interface {
+	validate() string
+}
+
+// scalarDecoder is implemented by manifest types that can also be given as a
+// single string instead of a mapping.
+type scalarDecoder interface {
+	decodeScalar(value string)
+}
+
+// node is a value of the manifest file together with its position.
+type node struct {
+	// value is either nil, string, int64, float64, bool, []*node or
+	// map[string]*node
+	value interface{}
+	// raw is the literal text of a scalar value, if available
+	raw    string
+	line   int
+	column int
+}
+
+// Parse parses the content of a manifest file. The format is determined by the
+// file extension. Problems are reported as `*ParseError`.
+func Parse(path string, content []byte) (*Manifest, error) {
+	ext := strings.ToLower(path[strings.LastIndexByte(path, '.')+1:])
+	switch ext {
+	case "toml":
+		return ParseTOML(path, content)
+	case "yml", "yaml":
+		return ParseYAML(path, content)
+	}
+	return nil, &ParseError{Path: path, Message: fmt.Sprintf("unsupported manifest format '%s'", ext)}
+}
+
+// ParseTOML parses the content of a TOML manifest file.
+func ParseTOML(path string, content []byte) (*Manifest, error) {
+	data := map[string]interface{}{}
+	if _, err := toml.Decode(string(content), &data); err != nil {
+		var tomlErr toml.ParseError
+		if errors.As(err, &tomlErr) {
+			line, column := offsetToPosition(content, tomlErr.Position.Start)
+			if line == 0 {
+				line = tomlErr.Position.Line
+			}
+			msg := tomlErrorPrefixPattern.ReplaceAllString(tomlErr.Error(), "")
+			return nil, &ParseError{Path: path, Line: line, Column: column, Field: tomlErr.LastKey, Message: msg}
+		}
+		return nil, &ParseError{Path: path, Message: err.Error()}
+	}
+	positions := locateTOMLKeys(content)
+	root := tomlNode(data, "", positions, position{1, 1})
+	return decodeManifest(path, root)
+}
+
+// ParseYAML parses the content of a YAML manifest file. Legacy OKH v1.0
+// manifests are converted into the OKH-LOSH format (see `ParseLegacy`).
+func ParseYAML(path string, content []byte) (*Manifest, error) {
+	root, err := parseYAMLNode(path, content)
+	if err != nil {
+		return nil, err
+	}
+	if isLegacy(root) {
+		mnf, _, err := convertLegacy(path, root)
+		return mnf, err
+	}
+	return decodeManifest(path, root)
+}
+
+// parseYAMLNode parses the content of a YAML file into a node.
+func parseYAMLNode(path string, content []byte) (*node, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		line, msg := yamlError(err)
+		return nil, &ParseError{Path: path, Line: line, Message: msg}
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, &ParseError{Path: path, Message: "manifest is empty"}
+	}
+	return yamlNode(path, doc.Content[0])
+}
+
+func decodeManifest(path string, root *node) (*Manifest, error) {
+	if _, ok := root.value.(map[string]*node); !ok {
+		return nil, &ParseError{Path: path, Line: root.line, Column: root.column, Message: "manifest must be a mapping of keys to values"}
+	}
+	mnf := &Manifest{}
+	dec := &decoder{path: path}
+	if err := dec.decode(root, "", reflect.ValueOf(mnf).Elem()); err != nil {
+		return nil, err
+	}
+	return mnf, nil
+}
+
+// -----------------------------------------------------------------------------
+// YAML
+// -----------------------------------------------------------------------------
+
+var yamlErrorPattern = regexp.MustCompile(`^yaml: (?:line (\d+): )?`)
+
+// yamlError extracts the line number and message from a YAML error.
+func yamlError(err error) (int, string) {
+	msg := err.Error()
+	m := yamlErrorPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, msg
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, msg[len(m[0]):]
+}
+
+// yamlNode converts a YAML node into a node.
+func yamlNode(path string, yn *yaml.Node) (*node, error) {
+	n := &node{line: yn.Line, column: yn.Column}
+	switch yn.Kind {
+	case yaml.AliasNode:
+		return yamlNode(path, yn.Alias)
+
+	case yaml.MappingNode:
+		m := make(map[string]*node, len(yn.Content)/2)
+		for i := 0; i+1 < len(yn.Content); i += 2 {
+			key, val := yn.Content[i], yn.Content[i+1]
+			// merge keys
+			if key.Tag == "!!merge" {
+				merged, err := yamlNode(path, val)
+				if err != nil {
+					return nil, err
+				}
+				if mm, ok := merged.value.(map[string]*node); ok {
+					for k, v := range mm {
+						if _, exists := m[k]; !exists {
+							m[k] = v
+						}
+					}
+				}
+				continue
+			}
+			child, err := yamlNode(path, val)
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = child
+		}
+		n.value = m
+
+	case yaml.SequenceNode:
+		lst := make([]*node, 0, len(yn.Content))
+		for _, item := range yn.Content {
+			child, err := yamlNode(path, item)
+			if err != nil {
+				return nil, err
+			}
+			lst = append(lst, child)
+		}
+		n.value = lst
+
+	case yaml.ScalarNode:
+		n.raw = yn.Value
+		var err error
+		switch yn.ShortTag() {
+		case "!!null":
+			n.value = nil
+		case "!!bool":
+			n.value, err = strconv.ParseBool(yn.Value)
+		case "!!int":
+			var v int64
+			err = yn.Decode(&v)
+			n.value = v
+		case "!!float":
+			var v float64
+			err = yn.Decode(&v)
+			n.value = v
+		default:
+			n.value = yn.Value
+		}
+		if err != nil {
+			return nil, &ParseError{Path: path, Line: yn.Line, Column: yn.Column, Message: err.Error()}
+		}
+
+	default:
+		return nil, &ParseError{Path: path, Line: yn.Line, Column: yn.Column, Message: "unsupported YAML node"}
+	}
+	return n, nil
+}
+
+// -----------------------------------------------------------------------------
+// TOML
+// -----------------------------------------------------------------------------
+
+type position struct {
+	line   int
+	column int
+}
+
+// tomlNode converts a decoded TOML value into a node. The positions are looked
+// up by key path; values without known position inherit the position of their
+// parent.
+func tomlNode(value interface{}, keyPath string, positions map[string]position, parentPos position) *node {
+	pos, ok := positions[keyPath]
+	if !ok {
+		pos = parentPos
+	}
+	n := &node{line: pos.line, column: pos.column}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]*node, len(v))
+		for key, val := range v {
+			m[key] = tomlNode(val, joinKeyPath(keyPath, key), positions, pos)
+		}
+		n.value = m
+	case []map[string]interface{}:
+		lst := make([]*node, 0, len(v))
+		for i, val := range v {
+			lst = append(lst, tomlNode(val, keyPath+"["+strconv.Itoa(i)+"]", positions, pos))
+		}
+		n.value = lst
+	case []interface{}:
+		lst := make([]*node, 0, len(v))
+		for i, val := range v {
+			lst = append(lst, tomlNode(val, keyPath+"["+strconv.Itoa(i)+"]", positions, pos))
+		}
+		n.value = lst
+	case time.Time:
+		n.value = v.Format(time.RFC3339)
+	default:
+		n.value = v
+	}
+	return n
+}
+
+var (
+	tomlErrorPrefixPattern = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+	tomlArrayTablePattern  = regexp.MustCompile(`^\s*\[\[\s*([^\]]+?)\s*\]\]`)
+	tomlTablePattern       = regexp.MustCompile(`^\s*\[\s*([^\]]+?)\s*\]`)
+	tomlKeyPattern         = regexp.MustCompile(`^(\s*)((?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*')(?:\s*\.\s*(?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*'))*)\s*=\s*`)
+)
+
+// locateTOMLKeys determines the positions of the values in a TOML document.
+// The positions are indexed by key path (e.g. `part[1].outer-dimensions`).
+// The TOML decoder doesn't provide positions, therefore the document is
+// scanned line by line. Keys of inline tables are not located.
+func locateTOMLKeys(content []byte) map[string]position {
+	positions := map[string]position{}
+	arrayCount := map[string]int{} // number of elements of array tables
+	table := ""
+	multiline := ""
+
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+
+		// skip content of multi-line strings
+		if multiline != "" {
+			if strings.Count(line, multiline)%2 == 1 {
+				multiline = ""
+			}
+			continue
+		}
+
+		if m := tomlArrayTablePattern.FindStringSubmatch(line); m != nil {
+			keyPath := resolveTOMLTable(splitTOMLKey(m[1]), arrayCount, false)
+			idx := arrayCount[keyPath]
+			arrayCount[keyPath] = idx + 1
+			if idx == 0 {
+				positions[keyPath] = position{lineNum, 1}
+			}
+			table = keyPath + "[" + strconv.Itoa(idx) + "]"
+			positions[table] = position{lineNum, 1}
+			continue
+		}
+		if m := tomlTablePattern.FindStringSubmatch(line); m != nil {
+			table = resolveTOMLTable(splitTOMLKey(m[1]), arrayCount, true)
+			positions[table] = position{lineNum, 1}
+			continue
+		}
+		if m := tomlKeyPattern.FindStringSubmatch(line); m != nil {
+			keyPath := joinKeyPath(table, strings.Join(splitTOMLKey(m[2]), "."))
+			positions[keyPath] = position{lineNum, len(m[0]) + 1}
+
+			// entries of multi-line arrays are located on following lines,
+			// they inherit the position of the key
+			rest := line[len(m[0]):]
+			for _, delim := range []string{`"""`, `'''`} {
+				if strings.Count(rest, delim)%2 == 1 {
+					multiline = delim
+					break
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// resolveTOMLTable returns the key path of a table. Tables nested in array
+// tables refer to the last element of the array.
+func resolveTOMLTable(keys []string, arrayCount map[string]int, isTable bool) string {
+	keyPath := ""
+	for i, key := range keys {
+		keyPath = joinKeyPath(keyPath, key)
+		if isTable || i < len(keys)-1 {
+			if cnt, ok := arrayCount[keyPath]; ok && cnt > 0 {
+				keyPath += "[" + strconv.Itoa(cnt-1) + "]"
+			}
+		}
+	}
+	return keyPath
+}
+
+// splitTOMLKey splits a dotted key into its parts and removes the quotes.
+func splitTOMLKey(key string) []string {
+	parts := []string{}
+	var b strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(b.String()))
+			b.Reset()
+		case c == ' ' || c == '\t':
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(parts, strings.TrimSpace(b.String()))
+}
+
+// offsetToPosition converts a byte offset into a line and column number.
+func offsetToPosition(content []byte, offset int) (int, int) {
+	if offset <= 0 || offset > len(content) {
+		return 0, 0
+	}
+	before := string(content[:offset])
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return strings.Count(before, "\n") + 1, offset - lineStart + 1
+}
+
+func joinKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// -----------------------------------------------------------------------------
+// Decoder
+// -----------------------------------------------------------------------------
+
+// decoder decodes nodes into the manifest types. The fields are mapped by the
+// `manifest` struct tag. Unknown keys are ignored.
+type decoder struct {
+	path string
+}
+
+func (d *decoder) errorf(n *node, field, format string, args ...interface{}) error {
+	return &ParseError{
+		Path:    d.path,
+		Line:    n.line,
+		Column:  n.column,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (d *decoder) decode(n *node, field string, rv reflect.Value) error {
+	if n.value == nil {
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		v := reflect.New(rv.Type().Elem())
+		if err := d.decode(n, field, v.Elem()); err != nil {
+			return err
+		}
+		rv.Set(v)
+		return nil
+
+	case reflect.String:
+		// numbers like versions are kept as written
+		if _, ok := n.value.(string); !ok && n.raw != "" {
+			rv.SetString(n.raw)
+			return nil
+		}
+		switch v := n.value.(type) {
+		case string:
+			rv.SetString(v)
+		case int64:
+			rv.SetString(strconv.FormatInt(v, 10))
+		case float64:
+			rv.SetString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			rv.SetString(strconv.FormatBool(v))
+		default:
+			return d.errorf(n, field, "expected a string, got %s", typeName(n.value))
+		}
+		return nil
+
+	case reflect.Float64:
+		switch v := n.value.(type) {
+		case float64:
+			rv.SetFloat(v)
+		case int64:
+			rv.SetFloat(float64(v))
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return d.errorf(n, field, "expected a number, got '%s'", v)
+			}
+			rv.SetFloat(f)
+		default:
+			return d.errorf(n, field, "expected a number, got %s", typeName(n.value))
+		}
+		return nil
+
+	case reflect.Slice:
+		items, ok := n.value.([]*node)
+		if !ok {
+			// a single value is treated as a list with one element
+			items = []*node{n}
+		}
+		lst := reflect.MakeSlice(rv.Type(), 0, len(items))
+		for i, item := range items {
+			elm := reflect.New(rv.Type().Elem()).Elem()
+			itemField := field
+			if _, ok := n.value.([]*node); ok {
+				itemField = field + "[" + strconv.Itoa(i) + "]"
+			}
+			if err := d.decode(item, itemField, elm); err != nil {
+				return err
+			}
+			if item.value != nil {
+				lst = reflect.Append(lst, elm)
+			}
+		}
+		rv.Set(lst)
+		return nil
+
+	case reflect.Struct:
+		m, ok := n.value.(map[string]*node)
+		if !ok {
+			if sd, ok := rv.Addr().Interface().(scalarDecoder); ok {
+				if str, ok := n.value.(string); ok {
+					sd.decodeScalar(str)
+					return nil
+				}
+			}
+			return d.errorf(n, field, "expected a mapping, got %s", typeName(n.value))
+		}
+		if err := d.decodeStruct(m, field, rv); err != nil {
+			return err
+		}
+		if v, ok := rv.Addr().Interface().(validator); ok {
+			if msg := v.validate(); msg != "" {
+				return d.errorf(n, field, "%s", msg)
+			}
+		}
+		return nil
+	}
+
+	return errors.Errorf("unsupported field type %s", rv.Type())
+}
+
+func (d *decoder) decodeStruct(m map[string]*node, field string, rv reflect.Value) error {
+	// normalize keys; sort them to report errors deterministically
+	keys := make([]string, 0, len(m))
+	normKeys := make(map[string]string, len(m))
+	for key := range m {
+		keys = append(keys, key)
+		normKeys[normKey(key)] = key
+	}
+	sort.Strings(keys)
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get("manifest")
+		if name == "" {
+			continue
+		}
+		key, ok := normKeys[name]
+		if !ok {
+			continue
+		}
+		if err := d.decode(m[key], joinKeyPath(field, name), rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normKey normalizes a key, so that e.g. `Documentation_Language` matches
+// `documentation-language`.
+func normKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.Replace(key, "_", "-", -1)
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case int64, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []*node:
+		return "a list"
+	case map[string]*node:
+		return "a mapping"
+	}
+	return "an unknown type"
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+func parseFixture(t *testing.T, name string) *Manifest {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnf, err := Parse(path, content)
+	if err != nil {
+		t.Fatalf("Parse(%s) error = %v", name, err)
+	}
+	return mnf
+}
+
+// TestParseFixtures checks that the TOML and YAML fixtures, which describe the
+// same product, are parsed into the same manifest.
+func TestParseFixtures(t *testing.T) {
+	mnf := parseFixture(t, "okh.toml")
+	if mnf.Name != "Motor Driver" || mnf.Version != "1.2.0" || mnf.Tsdc != "PCB" || mnf.Mass == nil || *mnf.Mass != 120.5 {
+		t.Errorf("unexpected product fields: %+v", mnf)
+	}
+	if mnf.Function != "Drives two brushed DC motors.\nname = \"not a key\"\n" {
+		t.Errorf("Function = %q", mnf.Function)
+	}
+	if dims := mnf.OuterDimensions; dims == nil || !dims.IsOpenSCAD() || dims.OpenSCAD != "cube([60, 40, 20]);" || dims.Unit != "mm" {
+		t.Errorf("OuterDimensions = %+v, want OpenSCAD expression", dims)
+	}
+	if !reflect.DeepEqual(mnf.Source, []string{"board.kicad_pcb", "board.kicad_sch"}) {
+		t.Errorf("Source = %v", mnf.Source)
+	}
+	if len(mnf.Software) != 1 || mnf.Software[0].License != "GPL-3.0-or-later" {
+		t.Errorf("Software = %+v", mnf.Software)
+	}
+
+	if len(mnf.Parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(mnf.Parts))
+	}
+	board, enclosure := mnf.Parts[0], mnf.Parts[1]
+	if board.Name != "Board" || board.Tsdc != "PCB" || !reflect.DeepEqual(board.Source, []string{"board.kicad_pcb"}) {
+		t.Errorf("part[0] = %+v", board)
+	}
+	if dims := enclosure.OuterDimensions; dims == nil || dims.IsOpenSCAD() || *dims.Width != 62 || *dims.Height != 42 || *dims.Depth != 22.5 {
+		t.Errorf("part[1].OuterDimensions = %+v, want bounding box", dims)
+	}
+	if enclosure.Mass == nil || *enclosure.Mass != 80 {
+		t.Errorf("part[1].Mass = %v, want 80", enclosure.Mass)
+	}
+	if len(enclosure.Parts) != 1 || enclosure.Parts[0].Name != "Lid" || !reflect.DeepEqual(enclosure.Parts[0].Source, []string{"lid.FCStd"}) {
+		t.Errorf("part[1].Parts = %+v, want the lid", enclosure.Parts)
+	}
+
+	if yamlMnf := parseFixture(t, "okh.yml"); !reflect.DeepEqual(yamlMnf, mnf) {
+		t.Errorf("YAML manifest differs from TOML manifest:\n%+v\n%+v", yamlMnf, mnf)
+	}
+}
+
+func TestLocateTOMLKeys(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "okh.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	positions := locateTOMLKeys(content)
+	tests := []struct {
+		keyPath string
+		want    position
+	}{
+		{"okhv", position{2, 8}},
+		{"function", position{8, 12}},
+		{"documentation-language", position{12, 26}},
+		{"tsdc", position{15, 8}},
+		{"outer-dimensions.openscad", position{17, 29}},
+		{"source", position{19, 10}},
+		{"software", position{24, 1}},
+		{"software[0].license", position{26, 11}},
+		{"part", position{28, 1}},
+		{"part[0]", position{28, 1}},
+		{"part[0].tsdc", position{30, 8}},
+		{"part[1]", position{33, 1}},
+		{"part[1].mass", position{35, 8}},
+		{"part[1].outer-dimensions", position{37, 1}},
+		{"part[1].outer-dimensions.depth", position{40, 9}},
+		{"part[1].part", position{42, 1}},
+		{"part[1].part[0].name", position{43, 8}},
+	}
+	for _, tt := range tests {
+		if got, ok := positions[tt.keyPath]; !ok || got != tt.want {
+			t.Errorf("position of '%s' = %v, want %v", tt.keyPath, got, tt.want)
+		}
+	}
+
+	// keys inside multi-line strings are not located
+	if pos, ok := positions["name"]; !ok || pos.line != 3 {
+		t.Errorf("position of 'name' = %v, want line 3", pos)
+	}
+}
+
+func TestResolveTOMLTable(t *testing.T) {
+	arrayCount := map[string]int{"part": 2, "part[1].part": 1}
+	tests := []struct {
+		keys    []string
+		isTable bool
+		want    string
+	}{
+		{[]string{"outer-dimensions"}, true, "outer-dimensions"},
+		// tables refer to the last element of an array table
+		{[]string{"part"}, true, "part[1]"},
+		{[]string{"part", "outer-dimensions"}, true, "part[1].outer-dimensions"},
+		{[]string{"part[1]", "part"}, true, "part[1].part[0]"},
+		// array tables append a new element to the last array
+		{[]string{"part"}, false, "part"},
+		{[]string{"part", "part"}, false, "part[1].part"},
+	}
+	for _, tt := range tests {
+		if got := resolveTOMLTable(tt.keys, arrayCount, tt.isTable); got != tt.want {
+			t.Errorf("resolveTOMLTable(%v, %t) = %s, want %s", tt.keys, tt.isTable, got, tt.want)
+		}
+	}
+}
+
+func TestOffsetToPosition(t *testing.T) {
+	content := []byte("a = 1\nbb = 2\n\nc = 3")
+	tests := []struct {
+		offset       int
+		line, column int
+	}{
+		{0, 0, 0},
+		{4, 1, 5},
+		{6, 2, 1},
+		{11, 2, 6},
+		{13, 3, 1},
+		{18, 4, 5},
+		// end of the content
+		{19, 4, 6},
+		{20, 0, 0},
+	}
+	for _, tt := range tests {
+		line, column := offsetToPosition(content, tt.offset)
+		if line != tt.line || column != tt.column {
+			t.Errorf("offsetToPosition(%d) = %d:%d, want %d:%d", tt.offset, line, column, tt.line, tt.column)
+		}
+	}
+}
+
+// TestParseErrorPositions checks that problems are reported at the position of
+// the offending value.
+func TestParseErrorPositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		line    int
+		column  int
+		field   string
+	}{
+		{
+			name:    "TOML type error",
+			path:    "okh.toml",
+			content: "name = \"Motor Driver\"\nmass = \"heavy\"\n",
+			line:    2, column: 8, field: "mass",
+		},
+		{
+			name:    "TOML type error of a sub-part",
+			path:    "okh.toml",
+			content: "name = \"Motor Driver\"\n\n[[part]]\nname = \"Board\"\n\n[[part]]\nname = \"Enclosure\"\n\n[part.outer-dimensions]\nwidth = \"wide\"\n",
+			line:    10, column: 9, field: "part[1].outer-dimensions.width",
+		},
+		{
+			name:    "TOML invalid outer dimensions",
+			path:    "okh.toml",
+			content: "name = \"Motor Driver\"\nouter-dimensions = { width = 1 }\n",
+			line:    2, column: 20, field: "outer-dimensions",
+		},
+		{
+			name:    "TOML list instead of tsdc",
+			path:    "okh.toml",
+			content: "name = \"Motor Driver\"\n[[part]]\nname = \"Board\"\ntsdc = [\"PCB\", \"MEC\"]\n",
+			line:    4, column: 8, field: "part[0].tsdc",
+		},
+		{
+			name:    "TOML syntax error",
+			path:    "okh.toml",
+			content: "name = \"Motor Driver\"\nversion = 1.2.0\n",
+			line:    2, column: 11, field: "version",
+		},
+		{
+			name:    "YAML type error",
+			path:    "okh.yml",
+			content: "okhv: OKH-LOSHv1.0\nname: Motor Driver\nmass: heavy\n",
+			line:    3, column: 7, field: "mass",
+		},
+		{
+			name:    "YAML type error of a sub-part",
+			path:    "okh.yaml",
+			content: "okhv: OKH-LOSHv1.0\npart:\n  - name: Board\n  - name: Enclosure\n    outer-dimensions:\n      width: wide\n",
+			line:    6, column: 14, field: "part[1].outer-dimensions.width",
+		},
+		{
+			name:    "YAML missing name of a sub-part",
+			path:    "okh.yml",
+			content: "okhv: OKH-LOSHv1.0\npart:\n  - tsdc: PCB\n",
+			line:    3, column: 5, field: "part[0]",
+		},
+		{
+			name:    "YAML syntax error",
+			path:    "okh.yml",
+			content: "okhv: OKH-LOSHv1.0\nname: Motor: Driver\nversion: 1.2.0\n",
+			line:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.path, []byte(tt.content))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, want a parse error", err)
+			}
+			if parseErr.Line != tt.line || (tt.column > 0 && parseErr.Column != tt.column) || parseErr.Field != tt.field {
+				t.Errorf("Parse() error at %d:%d (%s), want %d:%d (%s): %v",
+					parseErr.Line, parseErr.Column, parseErr.Field, tt.line, tt.column, tt.field, err)
+			}
+		})
+	}
+}
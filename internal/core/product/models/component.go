@@ -38,6 +38,8 @@ type Component struct {
 	Repository                  *Repository                              `mandatory:"true" json:"repository,omitempty" graphql:"repository" dql:"Component.repository"`
 	License                     *License                                 `json:"license,omitempty" graphql:"license" dql:"Component.license"`
 	AdditionalLicenses          []*License                               `json:"additionalLicenses,omitempty" graphql:"additionalLicenses" dql:"Component.additionalLicenses"`
+	LicenseExpression           *string                                  `json:"licenseExpression,omitempty" graphql:"licenseExpression" dql:"Component.licenseExpression"`
+	HasLicenseConflict          *bool                                    `json:"hasLicenseConflict,omitempty" graphql:"hasLicenseConflict" dql:"Component.hasLicenseConflict"`
 	Licensor                    UserOrGroup                              `mandatory:"true" json:"licensor,omitempty" graphql:"licensor" dql:"Component.licensor"`
 	DocumentationLanguage       *string                                  `mandatory:"true" json:"documentationLanguage,omitempty" graphql:"documentationLanguage" dql:"Component.documentationLanguage"`
 	TechnologyReadinessLevel    *dgclient.TechnologyReadinessLevel       `mandatory:"true" json:"technologyReadinessLevel,omitempty" graphql:"technologyReadinessLevel" dql:"Component.technologyReadinessLevel"`
@@ -0,0 +1,26 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+type CrawlerState struct {
+	ID        *string    `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Namespace *string    `mandatory:"true" json:"namespace,omitempty" graphql:"namespace" dql:"CrawlerState.namespace"`
+	State     *string    `mandatory:"true" json:"state,omitempty" graphql:"state" dql:"CrawlerState.state"`
+	UpdatedAt *time.Time `mandatory:"true" json:"updatedAt,omitempty" graphql:"updatedAt" dql:"CrawlerState.updatedAt"`
+}
+
+func (*CrawlerState) IsNode() {}
@@ -15,8 +15,9 @@
 package models
 
 type Database struct {
-	ID      *string `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
-	Version *string `mandatory:"true" json:"version,omitempty" graphql:"version" dql:"Database.version"`
+	ID            *string         `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Version       *string         `mandatory:"true" json:"version,omitempty" graphql:"version" dql:"Database.version"`
+	CrawlerStates []*CrawlerState `json:"crawlerStates,omitempty" graphql:"crawlerStates" dql:"Database.crawlerStates"`
 }
 
 func (*Database) IsNode() {}
@@ -0,0 +1,257 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"losh/internal/lib/util/reflectutil"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// FieldChange is a changed field of a node.
+type FieldChange struct {
+	// Field is the name of the struct field, e.g. `Name`.
+	Field string
+	// Predicate is the DQL predicate of the field, e.g. `Product.name`.
+	Predicate string
+	// Old and New are the stored and the updated values. Sub nodes are
+	// represented by their IDs.
+	Old interface{}
+	New interface{}
+}
+
+// NodeDiff holds the changed fields of a node compared to its stored version.
+type NodeDiff struct {
+	Node    Node
+	Changes []FieldChange
+}
+
+// IsEmpty returns true, if no field changed.
+func (d *NodeDiff) IsEmpty() bool {
+	return len(d.Changes) == 0
+}
+
+// Predicates returns the DQL predicates of the changed fields.
+func (d *NodeDiff) Predicates() []string {
+	preds := make([]string, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		preds = append(preds, c.Predicate)
+	}
+	return preds
+}
+
+// String returns a textual representation of the diff, e.g.
+// `Product 0x1a (name, description)`.
+func (d *NodeDiff) String() string {
+	id := ""
+	if d.Node.GetID() != nil {
+		id = *d.Node.GetID()
+	}
+	fields := make([]string, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		fields = append(fields, c.Predicate[strings.LastIndex(c.Predicate, ".")+1:])
+	}
+	return fmt.Sprintf("%s %s (%s)", reflect.Indirect(reflect.ValueOf(d.Node)).Type().Name(), id, strings.Join(fields, ", "))
+}
+
+// Patch returns a copy of the node, that only contains the ID, the alternative
+// ID and the changed fields. It can be used to update only the changed fields.
+func (d *NodeDiff) Patch() Node {
+	ndeVal := reflect.Indirect(reflect.ValueOf(d.Node))
+	ptcVal := reflect.New(ndeVal.Type())
+	ptcValInd := ptcVal.Elem()
+	flds := reflectutil.GetStructFields(ndeVal)
+	for _, sf := range flds {
+		if sf.IsID || sf.IsAltID {
+			ptcValInd.Field(sf.Index).Set(ndeVal.Field(sf.Index))
+		}
+	}
+	for _, c := range d.Changes {
+		sf := flds[c.Field]
+		ptcValInd.Field(sf.Index).Set(ndeVal.Field(sf.Index))
+	}
+	return ptcVal.Interface().(Node)
+}
+
+// DiffNode compares a node with its stored version. Only fields, that are set
+// in the updated node, are compared, because unset fields are left untouched
+// by an update. Sub nodes are compared by their IDs; sub nodes without an ID
+// are always considered a change.
+func DiffNode(stored, updated Node) *NodeDiff {
+	diff := &NodeDiff{Node: updated, Changes: []FieldChange{}}
+	strVal := reflect.Indirect(reflect.ValueOf(stored))
+	updVal := reflect.Indirect(reflect.ValueOf(updated))
+	if strVal.Type() != updVal.Type() {
+		panic(fmt.Sprintf("cannot compare nodes of different types: %T and %T", stored, updated))
+	}
+
+	// compare fields in the order of their declaration
+	flds := reflectutil.GetStructFields(updVal)
+	names := make([]string, 0, len(flds))
+	for name, sf := range flds {
+		if sf.IsID || sf.Tag.Get("dql") == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return flds[names[i]].Index < flds[names[j]].Index })
+
+	for _, name := range names {
+		sf := flds[name]
+		oldVal, newVal, changed := compareField(strVal.Field(sf.Index), updVal.Field(sf.Index))
+		if changed {
+			diff.Changes = append(diff.Changes, FieldChange{
+				Field:     name,
+				Predicate: sf.Tag.Get("dql"),
+				Old:       oldVal,
+				New:       newVal,
+			})
+		}
+	}
+	return diff
+}
+
+// compareField compares the stored and the updated value of a field and
+// returns their comparable representations.
+func compareField(stored, updated reflect.Value) (oldVal, newVal interface{}, changed bool) {
+	strInd := reflectutil.Indirect(stored)
+	updInd := reflectutil.Indirect(updated)
+
+	switch updInd.Kind() {
+	case reflect.Invalid:
+		// not set
+		return nil, nil, false
+
+	case reflect.Slice:
+		if updInd.Len() == 0 {
+			return nil, nil, false
+		}
+		if !updInd.Type().Elem().Implements(NodeType) {
+			if strInd.Kind() == reflect.Slice && reflect.DeepEqual(strInd.Interface(), updInd.Interface()) {
+				return nil, nil, false
+			}
+			return sliceInterface(strInd), updInd.Interface(), true
+		}
+		oldIDs, _ := nodeIDs(strInd)
+		newIDs, complete := nodeIDs(updInd)
+		if !complete || !sameIDs(oldIDs, newIDs) {
+			return oldIDs, newIDs, true
+		}
+		return nil, nil, false
+
+	case reflect.Struct:
+		if updInd.Type() == timeType {
+			newTime := updInd.Interface().(time.Time)
+			if strInd.Kind() == reflect.Struct && strInd.Interface().(time.Time).Equal(newTime) {
+				return nil, nil, false
+			}
+			if strInd.Kind() == reflect.Struct {
+				oldVal = strInd.Interface()
+			}
+			return oldVal, newTime, true
+		}
+		newNode, ok := AssertNode(updated.Interface())
+		if !ok {
+			if strInd.Kind() == reflect.Struct && reflect.DeepEqual(strInd.Interface(), updInd.Interface()) {
+				return nil, nil, false
+			}
+			return valueInterface(strInd), updInd.Interface(), true
+		}
+		var oldID, newID *string
+		if oldNode, ok := AssertNode(stored.Interface()); ok {
+			oldID = oldNode.GetID()
+		}
+		newID = newNode.GetID()
+		if newID != nil && oldID != nil && *newID == *oldID {
+			return nil, nil, false
+		}
+		return derefID(oldID), derefID(newID), true
+
+	default:
+		// scalars and enums
+		if strInd.IsValid() && strInd.Interface() == updInd.Interface() {
+			return nil, nil, false
+		}
+		return valueInterface(strInd), updInd.Interface(), true
+	}
+}
+
+// nodeIDs returns the IDs of the nodes of the slice. It returns false, if not
+// all nodes have an ID.
+func nodeIDs(slice reflect.Value) ([]string, bool) {
+	if slice.Kind() != reflect.Slice {
+		return []string{}, true
+	}
+	ids := make([]string, 0, slice.Len())
+	complete := true
+	for i := 0; i < slice.Len(); i++ {
+		n, ok := AssertNode(slice.Index(i).Interface())
+		if !ok {
+			continue
+		}
+		if id := n.GetID(); id != nil {
+			ids = append(ids, *id)
+		} else {
+			complete = false
+		}
+	}
+	return ids, complete
+}
+
+// sameIDs returns true, if both lists contain the same IDs regardless of their
+// order.
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]int, len(a))
+	for _, id := range a {
+		set[id]++
+	}
+	for _, id := range b {
+		if set[id] == 0 {
+			return false
+		}
+		set[id]--
+	}
+	return true
+}
+
+func valueInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func sliceInterface(v reflect.Value) interface{} {
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	return v.Interface()
+}
+
+func derefID(id *string) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
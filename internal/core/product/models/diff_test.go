@@ -0,0 +1,120 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDiffNode(t *testing.T) {
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	stored := &Product{
+		ID:            strPtr("0x1"),
+		Xid:           strPtr("github.com/a/b/"),
+		Name:          strPtr("Old Name"),
+		Website:       strPtr("https://example.org"),
+		LastIndexedAt: &created,
+		DataSource:    &Repository{ID: strPtr("0x2")},
+		Tags:          []*Tag{{ID: strPtr("0x3")}, {ID: strPtr("0x4")}},
+	}
+
+	tests := []struct {
+		name    string
+		updated *Product
+		want    []string
+	}{
+		{
+			name: "unchanged",
+			updated: &Product{
+				ID:            strPtr("0x1"),
+				Xid:           strPtr("github.com/a/b/"),
+				Name:          strPtr("Old Name"),
+				LastIndexedAt: func() *time.Time { t := created.In(time.FixedZone("CET", 3600)); return &t }(),
+				DataSource:    &Repository{ID: strPtr("0x2")},
+				// the order of sub nodes doesn't matter
+				Tags: []*Tag{{ID: strPtr("0x4")}, {ID: strPtr("0x3")}},
+			},
+			want: []string{},
+		},
+		{
+			name: "changed scalar",
+			updated: &Product{
+				ID:   strPtr("0x1"),
+				Name: strPtr("New Name"),
+			},
+			want: []string{"Product.name"},
+		},
+		{
+			name: "changed edge",
+			updated: &Product{
+				ID:         strPtr("0x1"),
+				DataSource: &Repository{ID: strPtr("0x5")},
+				Tags:       []*Tag{{ID: strPtr("0x3")}, {}},
+			},
+			want: []string{"Product.dataSource", "Product.tags"},
+		},
+		{
+			// the ID and unset fields are never compared
+			name: "ignored fields",
+			updated: &Product{
+				ID: strPtr("0x9"),
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		diff := DiffNode(stored, tt.updated)
+		if got := diff.Predicates(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: DiffNode() predicates = %v, want %v", tt.name, got, tt.want)
+		}
+		if diff.IsEmpty() != (len(tt.want) == 0) {
+			t.Errorf("%s: IsEmpty() = %v, want %v", tt.name, diff.IsEmpty(), len(tt.want) == 0)
+		}
+	}
+}
+
+func TestDiffNodeChangeValues(t *testing.T) {
+	stored := &Product{ID: strPtr("0x1"), Name: strPtr("Old Name"), DataSource: &Repository{ID: strPtr("0x2")}}
+	updated := &Product{ID: strPtr("0x1"), Name: strPtr("New Name"), DataSource: &Repository{ID: strPtr("0x5")}}
+	changes := DiffNode(stored, updated).Changes
+	want := []FieldChange{
+		{Field: "DataSource", Predicate: "Product.dataSource", Old: "0x2", New: "0x5"},
+		{Field: "Name", Predicate: "Product.name", Old: "Old Name", New: "New Name"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffNode() changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestNodeDiffPatch(t *testing.T) {
+	stored := &Product{ID: strPtr("0x1"), Xid: strPtr("github.com/a/b/"), Name: strPtr("Old Name"), Website: strPtr("https://example.org")}
+	updated := &Product{ID: strPtr("0x1"), Xid: strPtr("github.com/a/b/"), Name: strPtr("New Name"), Website: strPtr("https://example.org")}
+
+	patch, ok := DiffNode(stored, updated).Patch().(*Product)
+	if !ok {
+		t.Fatal("Patch() did not return a *Product")
+	}
+	if patch == updated {
+		t.Fatal("Patch() returned the updated node instead of a copy")
+	}
+	want := &Product{ID: strPtr("0x1"), Xid: strPtr("github.com/a/b/"), Name: strPtr("New Name")}
+	if !reflect.DeepEqual(patch, want) {
+		t.Errorf("Patch() = %+v, want %+v", patch, want)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+var _ Node = (*Exception)(nil)
+
+type Exception struct {
+	ID                 *string `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Xid                *string `altID:"true" mandatory:"true" json:"xid,omitempty" graphql:"xid" dql:"Exception.xid"`
+	Name               *string `mandatory:"true" json:"name,omitempty" graphql:"name" dql:"Exception.name"`
+	Text               *string `json:"text,omitempty" graphql:"text" dql:"Exception.text"`
+	TextHTML           *string `json:"textHTML,omitempty" graphql:"textHTML" dql:"Exception.textHTML"`
+	ReferenceURL       *string `json:"referenceURL,omitempty" graphql:"referenceURL" dql:"Exception.referenceURL"`
+	DetailsURL         *string `json:"detailsURL,omitempty" graphql:"detailsURL" dql:"Exception.detailsURL"`
+	LicenseListVersion *string `json:"licenseListVersion,omitempty" graphql:"licenseListVersion" dql:"Exception.licenseListVersion"`
+	IsDeprecated       *bool   `mandatory:"true" json:"isDeprecated,omitempty" graphql:"isDeprecated" dql:"Exception.isDeprecated"`
+}
+
+// GetID returns the ID of the node.
+func (e *Exception) GetID() *string {
+	return e.ID
+}
+
+// GetAltID returns the alternative IDs of the node.
+func (e *Exception) GetAltID() *string {
+	return e.Xid
+}
+
+func (*Exception) IsNode() {}
@@ -23,19 +23,20 @@ import (
 var _ Node = (*License)(nil)
 
 type License struct {
-	ID            *string               `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
-	Xid           *string               `altID:"true" mandatory:"true" json:"xid,omitempty" graphql:"xid" dql:"License.xid"`
-	Name          *string               `mandatory:"true" json:"name,omitempty" graphql:"name" dql:"License.name"`
-	Text          *string               `json:"text,omitempty" graphql:"text" dql:"License.text"`
-	TextHTML      *string               `json:"textHTML,omitempty" graphql:"textHTML" dql:"License.textHTML"`
-	ReferenceURL  *string               `json:"referenceURL,omitempty" graphql:"referenceURL" dql:"License.referenceURL"`
-	DetailsURL    *string               `json:"detailsURL,omitempty" graphql:"detailsURL" dql:"License.detailsURL"`
-	Type          *dgclient.LicenseType `mandatory:"true" json:"type,omitempty" graphql:"type" dql:"License.type"`
-	IsSpdx        *bool                 `mandatory:"true" json:"isSpdx,omitempty" graphql:"isSpdx" dql:"License.isSpdx"`
-	IsDeprecated  *bool                 `mandatory:"true" json:"isDeprecated,omitempty" graphql:"isDeprecated" dql:"License.isDeprecated"`
-	IsOsiApproved *bool                 `mandatory:"true" json:"isOsiApproved,omitempty" graphql:"isOsiApproved" dql:"License.isOsiApproved"`
-	IsFsfLibre    *bool                 `mandatory:"true" json:"isFsfLibre,omitempty" graphql:"isFsfLibre" dql:"License.isFsfLibre"`
-	IsBlocked     *bool                 `mandatory:"true" json:"isBlocked,omitempty" graphql:"isBlocked" dql:"License.isBlocked"`
+	ID                 *string               `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Xid                *string               `altID:"true" mandatory:"true" json:"xid,omitempty" graphql:"xid" dql:"License.xid"`
+	Name               *string               `mandatory:"true" json:"name,omitempty" graphql:"name" dql:"License.name"`
+	Text               *string               `json:"text,omitempty" graphql:"text" dql:"License.text"`
+	TextHTML           *string               `json:"textHTML,omitempty" graphql:"textHTML" dql:"License.textHTML"`
+	ReferenceURL       *string               `json:"referenceURL,omitempty" graphql:"referenceURL" dql:"License.referenceURL"`
+	DetailsURL         *string               `json:"detailsURL,omitempty" graphql:"detailsURL" dql:"License.detailsURL"`
+	LicenseListVersion *string               `json:"licenseListVersion,omitempty" graphql:"licenseListVersion" dql:"License.licenseListVersion"`
+	Type               *dgclient.LicenseType `mandatory:"true" json:"type,omitempty" graphql:"type" dql:"License.type"`
+	IsSpdx             *bool                 `mandatory:"true" json:"isSpdx,omitempty" graphql:"isSpdx" dql:"License.isSpdx"`
+	IsDeprecated       *bool                 `mandatory:"true" json:"isDeprecated,omitempty" graphql:"isDeprecated" dql:"License.isDeprecated"`
+	IsOsiApproved      *bool                 `mandatory:"true" json:"isOsiApproved,omitempty" graphql:"isOsiApproved" dql:"License.isOsiApproved"`
+	IsFsfLibre         *bool                 `mandatory:"true" json:"isFsfLibre,omitempty" graphql:"isFsfLibre" dql:"License.isFsfLibre"`
+	IsBlocked          *bool                 `mandatory:"true" json:"isBlocked,omitempty" graphql:"isBlocked" dql:"License.isBlocked"`
 }
 
 // GetID returns the ID of the node.
@@ -0,0 +1,39 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// PopularitySnapshot records the star and fork count of a product at a point in
+// time.
+type PopularitySnapshot struct {
+	ID        *string    `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Product   *Product   `mandatory:"true" json:"product,omitempty" graphql:"product" dql:"PopularitySnapshot.product"`
+	CreatedAt *time.Time `mandatory:"true" json:"createdAt,omitempty" graphql:"createdAt" dql:"PopularitySnapshot.createdAt"`
+	StarCount *int64     `json:"starCount,omitempty" graphql:"starCount" dql:"PopularitySnapshot.starCount"`
+	ForkCount *int64     `json:"forkCount,omitempty" graphql:"forkCount" dql:"PopularitySnapshot.forkCount"`
+}
+
+// GetID returns the ID of the node.
+func (s *PopularitySnapshot) GetID() *string {
+	return s.ID
+}
+
+// GetAltID returns the alternative IDs of the node. Snapshots have none.
+func (s *PopularitySnapshot) GetAltID() *string {
+	return nil
+}
+
+func (*PopularitySnapshot) IsNode() {}
@@ -31,6 +31,7 @@ type Product struct {
 	LastIndexedAt         *time.Time             `mandatory:"true" json:"lastIndexedAt,omitempty" graphql:"lastIndexedAt" dql:"CrawlerMeta.lastIndexedAt"`
 	DataSource            *Repository            `mandatory:"true" json:"dataSource,omitempty" graphql:"dataSource" dql:"Product.dataSource"`
 	Xid                   *string                `altID:"true" mandatory:"true" json:"xid,omitempty" graphql:"xid" dql:"Product.xid"`
+	PlatformID            *string                `json:"platformId,omitempty" graphql:"platformId" dql:"Product.platformId"`
 	Name                  *string                `mandatory:"true" json:"name,omitempty" graphql:"name" dql:"Product.name"`
 	Description           *string                `mandatory:"true" json:"description,omitempty" graphql:"description" dql:"Product.description"`
 	DocumentationLanguage *string                `mandatory:"true" json:"documentationLanguage,omitempty" graphql:"documentationLanguage" dql:"Product.documentationLanguage"`
@@ -40,6 +41,8 @@ type Product struct {
 	Website               *string                `json:"website,omitempty" graphql:"website" dql:"Product.website"`
 	State                 *dgclient.ProductState `mandatory:"true" json:"state,omitempty" graphql:"state" dql:"Product.state"`
 	LastUpdatedAt         *time.Time             `json:"lastUpdatedAt,omitempty" graphql:"lastUpdatedAt" dql:"Product.lastUpdatedAt"`
+	MissingSince          *time.Time             `json:"missingSince,omitempty" graphql:"missingSince" dql:"Product.missingSince"`
+	ValidationWarnings    *string                `json:"validationWarnings,omitempty" graphql:"validationWarnings" dql:"Product.validationWarnings"`
 	Release               *Component             `mandatory:"true" json:"release,omitempty" graphql:"release" dql:"Product.release"`
 	Releases              []*Component           `mandatory:"true" json:"releases,omitempty" graphql:"releases" dql:"Product.releases"`
 	RenamedTo             *Product               `json:"renamedTo,omitempty" graphql:"renamedTo" dql:"Product.renamedTo"`
@@ -48,10 +51,34 @@ type Product struct {
 	Forks                 []*Product             `json:"forks,omitempty" graphql:"forks" dql:"Product.forks"`
 	ForkCount             *int64                 `json:"forkCount" graphql:"forkCount" dql:"Product.forkCount"`
 	StarCount             *int64                 `json:"starCount" graphql:"starCount" dql:"Product.starCount"`
+	StarGrowth            *int64                 `json:"starGrowth,omitempty" graphql:"starGrowth" dql:"Product.starGrowth"`
+	ForkGrowth            *int64                 `json:"forkGrowth,omitempty" graphql:"forkGrowth" dql:"Product.forkGrowth"`
 	Tags                  []*Tag                 `json:"tags,omitempty" graphql:"tags" dql:"Product.tags"`
 	Category              *Category              `json:"category,omitempty" graphql:"category" dql:"Product.category"`
 }
 
+// NewProductPlaceholder creates a placeholder for a product, that is only known
+// as the origin of a fork. The placeholder has never been indexed, so that it
+// gets indexed from its data source by the next update run.
+func NewProductPlaceholder(xid, name string, dataSource *Repository, discoveredAt time.Time) *Product {
+	state := dgclient.ProductStatePlaceholder
+	lastIndexedAt := time.Time{}
+	return &Product{
+		DiscoveredAt:  &discoveredAt,
+		LastIndexedAt: &lastIndexedAt,
+		DataSource:    dataSource,
+		Xid:           &xid,
+		Name:          &name,
+		State:         &state,
+	}
+}
+
+// IsPlaceholder returns true, if the product is a placeholder, that hasn't
+// been indexed yet.
+func (p *Product) IsPlaceholder() bool {
+	return p.State != nil && *p.State == dgclient.ProductStatePlaceholder
+}
+
 // GetID returns the ID of the node.
 func (p *Product) GetID() *string {
 	return p.ID
@@ -0,0 +1,47 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ProductRevision records the changes of a product or one of its releases made
+// by a single crawler run.
+type ProductRevision struct {
+	ID           *string          `id:"true" mandatory:"true" json:"id,omitempty" graphql:"id" dql:"uid"`
+	Product      *Product         `mandatory:"true" json:"product,omitempty" graphql:"product" dql:"ProductRevision.product"`
+	Component    *Component       `json:"component,omitempty" graphql:"component" dql:"ProductRevision.component"`
+	CreatedAt    *time.Time       `mandatory:"true" json:"createdAt,omitempty" graphql:"createdAt" dql:"ProductRevision.createdAt"`
+	CrawlerRunID *string          `json:"crawlerRunId,omitempty" graphql:"crawlerRunId" dql:"ProductRevision.crawlerRunId"`
+	Changes      []RevisionChange `mandatory:"true" json:"changes,omitempty" graphql:"changes" dql:"ProductRevision.changes"`
+}
+
+// RevisionChange is a changed field recorded by a product revision.
+type RevisionChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// GetID returns the ID of the node.
+func (r *ProductRevision) GetID() *string {
+	return r.ID
+}
+
+// GetAltID returns the alternative IDs of the node. Revisions have none.
+func (r *ProductRevision) GetAltID() *string {
+	return nil
+}
+
+func (*ProductRevision) IsNode() {}
@@ -68,6 +68,8 @@ func NewProductURLFromURL(url string) (*ProductURL, error) {
 		} else if domain == "raw.githubusercontent.com" {
 			productURL.Ref = pathParts[2]
 			productURL.Path = strings.Join(pathParts[3:], "/")
+		} else if len(pathParts) == 2 && pathParts[1] != "" {
+			// plain repository URL: https://github.com/{owner}/{repo}
 		} else {
 			return nil, ErrInvalidURL
 		}
@@ -76,15 +78,14 @@ func NewProductURLFromURL(url string) (*ProductURL, error) {
 
 	case "gitlab.com":
 		productURL.Domain = "gitlab.com"
-		// if len(path_parts) >= 5 and path_parts[2] == "-" and path_parts[3] in ["tree", "blob", "raw"]:
-		if len(pathParts) > 5 && pathParts[2] == "-" && (pathParts[3] == "tree" || pathParts[3] == "blob" || pathParts[3] == "raw") {
-			productURL.Ref = pathParts[4]
-			productURL.Path = strings.Join(pathParts[5:], "/")
-		} else {
-			return nil, ErrInvalidURL
+		if !parseGitLabPath(productURL, pathParts) {
+			// plain project URL: https://gitlab.com/{namespace}/{repo}
+			if len(pathParts) < 2 || pathParts[len(pathParts)-1] == "" {
+				return nil, ErrInvalidURL
+			}
+			productURL.Owner = strings.Join(pathParts[:len(pathParts)-1], "/")
+			productURL.Repo = pathParts[len(pathParts)-1]
 		}
-		productURL.Owner = pathParts[0]
-		productURL.Repo = pathParts[1]
 
 	case "wikifactory.com":
 		productURL.Domain = "wikifactory.com"
@@ -109,13 +110,44 @@ func NewProductURLFromURL(url string) (*ProductURL, error) {
 		productURL.Path = pathParts[0]
 
 	default:
-		return nil, ErrUnsupportedPlatform
+		// self-hosted GitLab instances share the URL layout of gitlab.com
+		productURL.Domain = strings.ToLower(parsedURL.Host)
+		if !parseGitLabPath(productURL, pathParts) {
+			return nil, ErrUnsupportedPlatform
+		}
 
 	}
 
 	return productURL, nil
 }
 
+// parseGitLabPath parses the path of a GitLab URL with the format
+// `{namespace}/{repo}/-/(tree|blob|raw)/{ref}/{path}`. The namespace may
+// consist of nested groups. It returns false if the path doesn't match the
+// format.
+func parseGitLabPath(productURL *ProductURL, pathParts []string) bool {
+	sep := -1
+	for i, part := range pathParts {
+		if part == "-" {
+			sep = i
+			break
+		}
+	}
+	if sep < 2 || len(pathParts) < sep+3 {
+		return false
+	}
+	switch pathParts[sep+1] {
+	case "tree", "blob", "raw":
+	default:
+		return false
+	}
+	productURL.Owner = strings.Join(pathParts[:sep-1], "/")
+	productURL.Repo = pathParts[sep-1]
+	productURL.Ref = pathParts[sep+2]
+	productURL.Path = strings.Join(pathParts[sep+3:], "/")
+	return true
+}
+
 // RepositoryURL returns the URL to the repository.
 func (pu *ProductURL) RepositoryURL() string {
 	switch pu.Domain {
@@ -148,7 +180,7 @@ func (pu *ProductURL) RepositoryURL() string {
 	case "oshwa.org":
 		return (&gourl.URL{
 			Scheme: "https",
-			Host:   "oshwa.org",
+			Host:   "certification.oshwa.org",
 			Path:   fmt.Sprintf("/%s", pu.Path),
 		}).String()
 
@@ -196,6 +228,10 @@ func (pu *ProductURL) PermaURL() string {
 			url.Path = fmt.Sprintf("/%s/%s/contributions/%s/file/%s", pu.Owner, pu.Repo, pu.Ref, pu.Path)
 		}
 		return url.String()
+
+	// format: https://certification.oshwa.org/{path}
+	case "oshwa.org":
+		return pu.RepositoryURL()
 	}
 
 	// TODO: add other platforms
@@ -16,6 +16,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"losh/internal/core/product/models"
 	"losh/internal/infra/dgraph/dgclient"
@@ -29,12 +30,17 @@ type LicenseProvider interface {
 
 	// GetAllLicenses returns a list of all licenses.
 	GetAllLicenses(ctx context.Context) ([]*models.License, error)
+
+	// GetAllExceptions returns a list of all license exceptions.
+	GetAllExceptions(ctx context.Context) ([]*models.Exception, error)
 }
 
 // Repository is the interface for getting and saving licenses to a repository.
 type Repository interface {
 	NodeRepository
 	ProductRepository
+	ProductRevisionRepository
+	PopularitySnapshotRepository
 	ComponentRepository
 	SoftwareRepository
 	RepositoryRepository
@@ -53,12 +59,34 @@ type Repository interface {
 	CategoryRepository
 	TagRepository
 	LicenseRepository
+	ExceptionRepository
 	HostRepository
 }
 
 // NodeRepository is an interface for getting `Node` objects from a repository.
 type NodeRepository interface {
 	GetNode(ctx context.Context, id string) (interface{}, error)
+	// GetStoredNodes returns the stored versions of the nodes keyed by their
+	// IDs. Only the scalar fields and the IDs of the sub nodes are set.
+	GetStoredNodes(ctx context.Context, nodes []models.Node) (map[string]models.Node, error)
+}
+
+// BulkNodeRepository is an optional interface for repositories, that can save
+// a whole set of nodes at once.
+type BulkNodeRepository interface {
+	// SaveNodes creates or updates all nodes of the set within a single
+	// transaction. Nodes without an ID are resolved by their alternative ID.
+	// The time since when the recovered products were missing is removed
+	// within the same transaction.
+	SaveNodes(ctx context.Context, nodes *models.NodeSet, recovered []string) error
+}
+
+// NodeIDResolver is an optional interface for repositories, that can look up
+// the IDs of many nodes at once.
+type NodeIDResolver interface {
+	// ResolveNodeIDs sets the IDs of the nodes of the set, that already exist,
+	// by looking them up by their alternative IDs.
+	ResolveNodeIDs(ctx context.Context, nodes *models.NodeSet) error
 }
 
 // ProductRepository is an interface for getting and saving `Product` objects to a repository.
@@ -67,12 +95,32 @@ type ProductRepository interface {
 	GetProductID(ctx context.Context, xid *string) (*string, error)
 	GetProducts(ctx context.Context, filter *dgclient.ProductFilter, order *dgclient.ProductOrder, first *int64, offset *int64) ([]*models.Product, int64, error)
 	GetAllProducts(ctx context.Context) ([]*models.Product, int64, error)
+	GetProductsIndexedBefore(ctx context.Context, hostDomain string, indexedBefore time.Time, first, offset int64) ([]*models.Product, error)
 	CreateProduct(ctx context.Context, input *models.Product) error
+	CreateProductPlaceholder(ctx context.Context, input *models.Product) error
+	GetProductForkTree(ctx context.Context, id string, depth int) (*models.Product, error)
+	GetRenamedProduct(ctx context.Context, platformID, xid string) (*models.Product, error)
 	UpdateProduct(ctx context.Context, input *models.Product) error
+	ClearProductsMissingSince(ctx context.Context, ids []string) error
 	DeleteProduct(ctx context.Context, id, xid *string) error
+	DeleteProductSubgraph(ctx context.Context, id string) error
 	DeleteAllProducts(ctx context.Context) error
 }
 
+// ProductRevisionRepository is an interface for getting and saving `ProductRevision` objects to a repository.
+type ProductRevisionRepository interface {
+	GetProductRevisions(ctx context.Context, productID string, first, offset int64) ([]*models.ProductRevision, int64, error)
+	CreateProductRevisions(ctx context.Context, revisions []*models.ProductRevision) error
+}
+
+// PopularitySnapshotRepository is an interface for getting and saving `PopularitySnapshot` objects to a repository.
+type PopularitySnapshotRepository interface {
+	GetPopularitySnapshots(ctx context.Context, productIDs []string, since time.Time) (map[string][]*models.PopularitySnapshot, error)
+	GetProductsPopularity(ctx context.Context, since time.Time, first, offset int64) ([]*models.Product, map[string][]*models.PopularitySnapshot, error)
+	UpdateProductsGrowth(ctx context.Context, products []*models.Product) error
+	CreatePopularitySnapshots(ctx context.Context, snapshots []*models.PopularitySnapshot) error
+}
+
 // ComponentRepository is an interface for getting and saving `Component` objects to a repository.
 type ComponentRepository interface {
 	GetComponent(ctx context.Context, id, xid *string) (*models.Component, error)
@@ -281,6 +329,19 @@ type LicenseRepository interface {
 	DeleteAllLicenses(ctx context.Context) error
 }
 
+// ExceptionRepository is an interface for getting and saving `Exception` objects to a repository.
+type ExceptionRepository interface {
+	GetException(ctx context.Context, id, xid *string) (*models.Exception, error)
+	GetExceptionID(ctx context.Context, xid *string) (*string, error)
+	GetExceptions(ctx context.Context, filter *dgclient.ExceptionFilter, order *dgclient.ExceptionOrder, first *int64, offset *int64) ([]*models.Exception, int64, error)
+	GetAllExceptions(ctx context.Context) ([]*models.Exception, int64, error)
+	GetAllExceptionsBasic(ctx context.Context) ([]*models.Exception, error)
+	CreateException(ctx context.Context, input *models.Exception) error
+	UpdateException(ctx context.Context, input *models.Exception) error
+	DeleteException(ctx context.Context, id, xid *string) error
+	DeleteAllExceptions(ctx context.Context) error
+}
+
 // HostRepository is an interface for getting and saving `Host` objects to a repository.
 type HostRepository interface {
 	GetHost(ctx context.Context, id, domain *string) (*models.Host, error)
@@ -55,12 +55,32 @@ func (s *Service) GetCachedLicenseByIDOrName(idOrName string) *models.License {
 	return nil
 }
 
+// GetCachedExceptionByID returns a license exception by its SPDX identifier.
+func (s *Service) GetCachedExceptionByID(id string) *models.Exception {
+	e, ok := s.exceptions[stringutil.NormalizeName(id)]
+	if ok {
+		return e
+	}
+	return nil
+}
+
 // ReloadLicenseCache reloads the license cache.
 func (s *Service) ReloadLicenseCache() error {
 	return s.loadLicenses()
 }
 
-// loadLicenses loads licenses from the repo.
+// LoadLicenses fills the license cache with the given licenses and exceptions
+// instead of loading them from the repo. This allows to use the service
+// without a database, e.g. to validate local manifest files. Licenses with a
+// text are used to detect the license of license files.
+func (s *Service) LoadLicenses(licenses []*models.License, exceptions []*models.Exception) {
+	s.cacheLicenses(licenses, exceptions)
+	s.licenseTextsMu.Lock()
+	s.licenseTexts = s.newLicenseTexts(licenses)
+	s.licenseTextsMu.Unlock()
+}
+
+// loadLicenses loads licenses and license exceptions from the repo.
 func (s *Service) loadLicenses() error {
 	// get licenses from repo
 	licenses, err := s.repo.GetAllLicensesBasic(context.Background())
@@ -68,6 +88,22 @@ func (s *Service) loadLicenses() error {
 		return errors.Wrap(err, "failed to get a list of licenses")
 	}
 
+	// get license exceptions from repo
+	exceptions, err := s.repo.GetAllExceptionsBasic(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to get a list of license exceptions")
+	}
+	s.cacheLicenses(licenses, exceptions)
+
+	// license texts are loaded again on next use
+	s.licenseTextsMu.Lock()
+	s.licenseTexts = nil
+	s.licenseTextsMu.Unlock()
+	return nil
+}
+
+// cacheLicenses replaces the cached licenses and license exceptions.
+func (s *Service) cacheLicenses(licenses []*models.License, exceptions []*models.Exception) {
 	s.licenses = make(map[string]*models.License, len(licenses))
 	s.nameToID = make(map[string]string, len(licenses))
 	for _, l := range licenses {
@@ -76,5 +112,9 @@ func (s *Service) loadLicenses() error {
 		s.licenses[normalizedID] = l
 		s.nameToID[stringutil.NormalizeName(*l.Name)] = normalizedID
 	}
-	return nil
+
+	s.exceptions = make(map[string]*models.Exception, len(exceptions))
+	for _, e := range exceptions {
+		s.exceptions[stringutil.NormalizeName(*e.Xid)] = e
+	}
 }
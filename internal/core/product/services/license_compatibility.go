@@ -0,0 +1,270 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+)
+
+// LicenseConflict is a likely conflict between the license of a part of a
+// release and the license of its enclosing part.
+type LicenseConflict struct {
+	// Path is the path of the conflicting license relative to the release, e.g.
+	// `components[0].software[1].license`.
+	Path string `json:"path"`
+	// License is the ID of the conflicting license.
+	License string `json:"license"`
+	// ConflictsWith is the ID of the license of the enclosing part.
+	ConflictsWith string `json:"conflictsWith"`
+	// Message describes the conflict.
+	Message string `json:"message"`
+}
+
+// String returns a textual representation of the conflict.
+func (c LicenseConflict) String() string {
+	return c.Path + ": " + c.Message
+}
+
+// licenseAnalyzer walks the parts of a release and collects the conflicts of
+// their licenses.
+type licenseAnalyzer struct {
+	svc *Service
+	// loadComponent and loadSoftware load parts that are only referenced. If
+	// nil, referenced parts are skipped.
+	loadComponent func(cmp *models.Component) (*models.Component, error)
+	loadSoftware  func(sw *models.Software) (*models.Software, error)
+
+	visited   map[*models.Component]struct{}
+	conflicts []LicenseConflict
+}
+
+// AnalyzeLicenseCompatibility walks the components, software and additional
+// licenses of a release and reports likely conflicts of their licenses, e.g. a
+// strong copyleft component inside a permissively licensed product. Only the
+// parts that are present in the given graph are analyzed; parts that are only
+// referenced by their ID are skipped.
+func (s *Service) AnalyzeLicenseCompatibility(release *models.Component) []LicenseConflict {
+	a := &licenseAnalyzer{svc: s}
+	// without loaders, the analysis cannot fail
+	_ = a.analyze(release)
+	return a.conflicts
+}
+
+// markLicenseConflicts analyzes the licenses of the releases, that are not
+// stored in the database yet, and sets their `HasLicenseConflict` field
+// accordingly. Stored releases are immutable and keep their marking.
+func (s *Service) markLicenseConflicts(nodes *models.NodeSet) {
+	nodes.Range(func(node models.Node) bool {
+		prd, ok := node.(*models.Product)
+		if !ok || prd.IsPlaceholder() {
+			return true
+		}
+		for _, release := range append([]*models.Component{prd.Release}, prd.Releases...) {
+			if release == nil || release.ID != nil || release.HasLicenseConflict != nil {
+				continue
+			}
+			hasConflict := len(s.AnalyzeLicenseCompatibility(release)) > 0
+			release.HasLicenseConflict = &hasConflict
+		}
+		return true
+	})
+}
+
+// GetLicenseConflicts is like `AnalyzeLicenseCompatibility`, but loads the parts
+// of a stored release, that are only referenced, from the repository first.
+func (s *Service) GetLicenseConflicts(ctx context.Context, release *models.Component) ([]LicenseConflict, error) {
+	a := &licenseAnalyzer{
+		svc: s,
+		loadComponent: func(cmp *models.Component) (*models.Component, error) {
+			return s.repo.GetComponent(ctx, cmp.ID, nil)
+		},
+		loadSoftware: func(sw *models.Software) (*models.Software, error) {
+			return s.repo.GetSoftware(ctx, sw.ID)
+		},
+	}
+	if err := a.analyze(release); err != nil {
+		return nil, err
+	}
+	return a.conflicts, nil
+}
+
+func (a *licenseAnalyzer) analyze(release *models.Component) error {
+	a.visited = make(map[*models.Component]struct{})
+	a.conflicts = []LicenseConflict{}
+	if release == nil {
+		return nil
+	}
+	a.visited[release] = struct{}{}
+	return a.analyzeParts(release, a.resolveLicense(release.License), "")
+}
+
+// analyzeParts checks the additional licenses, software and sub components of
+// the component against the given license of the component.
+func (a *licenseAnalyzer) analyzeParts(cmp *models.Component, license *models.License, path string) error {
+	for i, l := range cmp.AdditionalLicenses {
+		a.checkAdditionalLicense(license, a.resolveLicense(l), joinLicensePath(path, fmt.Sprintf("additionalLicenses[%d]", i)))
+	}
+
+	for i, sw := range cmp.Software {
+		if sw == nil {
+			continue
+		}
+		// software that is only referenced has no crawler metadata
+		if sw.DiscoveredAt == nil && sw.ID != nil {
+			if a.loadSoftware == nil {
+				continue
+			}
+			loaded, err := a.loadSoftware(sw)
+			if err != nil {
+				return err
+			}
+			if loaded == nil {
+				continue
+			}
+			sw = loaded
+		}
+		swPath := joinLicensePath(path, fmt.Sprintf("software[%d]", i))
+		a.checkPart(license, a.resolveLicense(sw.License), joinLicensePath(swPath, "license"), false)
+	}
+
+	for i, sub := range cmp.Components {
+		if sub == nil {
+			continue
+		}
+		// components that are only referenced lack their mandatory xid
+		if sub.Xid == nil && sub.ID != nil {
+			if a.loadComponent == nil {
+				continue
+			}
+			loaded, err := a.loadComponent(sub)
+			if err != nil {
+				return err
+			}
+			if loaded == nil {
+				continue
+			}
+			sub = loaded
+		}
+		if _, ok := a.visited[sub]; ok {
+			continue
+		}
+		a.visited[sub] = struct{}{}
+
+		subPath := joinLicensePath(path, fmt.Sprintf("components[%d]", i))
+		subLicense := a.resolveLicense(sub.License)
+		a.checkPart(license, subLicense, joinLicensePath(subPath, "license"), true)
+		// sub components without a license inherit the one of their parent
+		if subLicense == nil {
+			subLicense = license
+		}
+		if err := a.analyzeParts(sub, subLicense, subPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPart checks the license of a part against the license of its enclosing
+// part. Software is a separate work, therefore using a different strong
+// copyleft license for it is fine.
+func (a *licenseAnalyzer) checkPart(outer, inner *models.License, path string, isComponent bool) {
+	if outer == nil || inner == nil || *outer.Xid == *inner.Xid {
+		return
+	}
+	outerType, innerType := licenseTypeOf(outer), licenseTypeOf(inner)
+
+	switch {
+	case innerType == dgclient.LicenseTypeStrong && (outerType == dgclient.LicenseTypePermissive || outerType == dgclient.LicenseTypeWeak):
+		a.add(path, inner, outer, fmt.Sprintf("strong copyleft license '%s' is used within a part licensed under the %s license '%s'", *inner.Xid, describeLicenseType(outerType), *outer.Xid))
+	case isComponent && innerType == dgclient.LicenseTypeStrong && outerType == dgclient.LicenseTypeStrong:
+		a.add(path, inner, outer, fmt.Sprintf("strong copyleft license '%s' is used within a part licensed under the different strong copyleft license '%s'", *inner.Xid, *outer.Xid))
+	default:
+		a.checkNonFree(outer, inner, path)
+	}
+}
+
+// checkAdditionalLicense checks an additional license, which usually covers the
+// documentation and other assets, against the main license of the component.
+func (a *licenseAnalyzer) checkAdditionalLicense(outer, inner *models.License, path string) {
+	if outer == nil || inner == nil || *outer.Xid == *inner.Xid {
+		return
+	}
+	a.checkNonFree(outer, inner, path)
+}
+
+// checkNonFree reports non-free licenses within copyleft licensed parts, because
+// copyleft licenses require all parts to be available under free terms.
+func (a *licenseAnalyzer) checkNonFree(outer, inner *models.License, path string) {
+	outerType := licenseTypeOf(outer)
+	if outerType != dgclient.LicenseTypeStrong && outerType != dgclient.LicenseTypeWeak {
+		return
+	}
+	if inner.IsOsiApproved == nil || inner.IsFsfLibre == nil || *inner.IsOsiApproved || *inner.IsFsfLibre {
+		return
+	}
+	a.add(path, inner, outer, fmt.Sprintf("non-free license '%s' is used within a part licensed under the %s license '%s'", *inner.Xid, describeLicenseType(outerType), *outer.Xid))
+}
+
+func (a *licenseAnalyzer) add(path string, inner, outer *models.License, msg string) {
+	a.conflicts = append(a.conflicts, LicenseConflict{
+		Path:          path,
+		License:       *inner.Xid,
+		ConflictsWith: *outer.Xid,
+		Message:       msg,
+	})
+}
+
+// resolveLicense returns the cached version of the license, which carries all
+// attributes, even if only a reference to the license was loaded.
+func (a *licenseAnalyzer) resolveLicense(license *models.License) *models.License {
+	if license == nil || license.Xid == nil {
+		return nil
+	}
+	if cached := a.svc.GetCachedLicenseByIDOrName(*license.Xid); cached != nil {
+		return cached
+	}
+	return license
+}
+
+func licenseTypeOf(license *models.License) dgclient.LicenseType {
+	if license.Type == nil {
+		return dgclient.LicenseTypeUnknown
+	}
+	return *license.Type
+}
+
+func describeLicenseType(typ dgclient.LicenseType) string {
+	switch typ {
+	case dgclient.LicenseTypeStrong:
+		return "strong copyleft"
+	case dgclient.LicenseTypeWeak:
+		return "weak copyleft"
+	case dgclient.LicenseTypePermissive:
+		return "permissive"
+	default:
+		return "unknown"
+	}
+}
+
+func joinLicensePath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
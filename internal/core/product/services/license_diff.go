@@ -0,0 +1,192 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"losh/internal/core/product/models"
+)
+
+// LicenseChange is a license, that differs from its stored version.
+type LicenseChange struct {
+	// License is the updated license, which carries the ID of the stored one.
+	License *models.License
+	// Fields are the names of the changed fields.
+	Fields []string
+}
+
+// LicenseDiff holds the differences between the stored licenses and an updated
+// license list.
+type LicenseDiff struct {
+	Added      []*models.License
+	Deprecated []LicenseChange
+	Changed    []LicenseChange
+	Unchanged  int
+}
+
+// IsEmpty returns true, if the license list did not change.
+func (d *LicenseDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Deprecated) == 0 && len(d.Changed) == 0
+}
+
+// DiffLicenses compares the stored licenses with the licenses of an updated
+// license list. Licenses, that became deprecated, are listed separately from
+// other changes. The version of the license list is not considered a change.
+func DiffLicenses(stored, updated []*models.License) *LicenseDiff {
+	storedMap := make(map[string]*models.License, len(stored))
+	for _, l := range stored {
+		storedMap[*l.Xid] = l
+	}
+
+	diff := &LicenseDiff{
+		Added:      []*models.License{},
+		Deprecated: []LicenseChange{},
+		Changed:    []LicenseChange{},
+	}
+	for _, l := range updated {
+		old, ok := storedMap[*l.Xid]
+		if !ok {
+			diff.Added = append(diff.Added, l)
+			continue
+		}
+		fields := fieldChanges{}
+		fields.compareString("name", old.Name, l.Name)
+		fields.compareString("text", old.Text, l.Text)
+		fields.compareString("textHTML", old.TextHTML, l.TextHTML)
+		fields.compareString("referenceURL", old.ReferenceURL, l.ReferenceURL)
+		fields.compareString("detailsURL", old.DetailsURL, l.DetailsURL)
+		if (old.Type == nil) != (l.Type == nil) || (old.Type != nil && *old.Type != *l.Type) {
+			fields = append(fields, "type")
+		}
+		fields.compareBool("isSpdx", old.IsSpdx, l.IsSpdx)
+		fields.compareBool("isOsiApproved", old.IsOsiApproved, l.IsOsiApproved)
+		fields.compareBool("isFsfLibre", old.IsFsfLibre, l.IsFsfLibre)
+		fields.compareBool("isBlocked", old.IsBlocked, l.IsBlocked)
+		becameDeprecated := !isTrue(old.IsDeprecated) && isTrue(l.IsDeprecated)
+		if !becameDeprecated {
+			fields.compareBool("isDeprecated", old.IsDeprecated, l.IsDeprecated)
+		}
+		if !becameDeprecated && len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+
+		l.ID = old.ID
+		change := LicenseChange{License: l, Fields: []string(fields)}
+		if becameDeprecated {
+			diff.Deprecated = append(diff.Deprecated, change)
+		} else {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	return diff
+}
+
+// ExceptionChange is a license exception, that differs from its stored version.
+type ExceptionChange struct {
+	// Exception is the updated exception, which carries the ID of the stored
+	// one.
+	Exception *models.Exception
+	// Fields are the names of the changed fields.
+	Fields []string
+}
+
+// ExceptionDiff holds the differences between the stored license exceptions
+// and an updated license list.
+type ExceptionDiff struct {
+	Added      []*models.Exception
+	Deprecated []ExceptionChange
+	Changed    []ExceptionChange
+	Unchanged  int
+}
+
+// IsEmpty returns true, if the license exceptions did not change.
+func (d *ExceptionDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Deprecated) == 0 && len(d.Changed) == 0
+}
+
+// DiffExceptions compares the stored license exceptions with the exceptions of
+// an updated license list. See `DiffLicenses`.
+func DiffExceptions(stored, updated []*models.Exception) *ExceptionDiff {
+	storedMap := make(map[string]*models.Exception, len(stored))
+	for _, e := range stored {
+		storedMap[*e.Xid] = e
+	}
+
+	diff := &ExceptionDiff{
+		Added:      []*models.Exception{},
+		Deprecated: []ExceptionChange{},
+		Changed:    []ExceptionChange{},
+	}
+	for _, e := range updated {
+		old, ok := storedMap[*e.Xid]
+		if !ok {
+			diff.Added = append(diff.Added, e)
+			continue
+		}
+		fields := fieldChanges{}
+		fields.compareString("name", old.Name, e.Name)
+		fields.compareString("text", old.Text, e.Text)
+		fields.compareString("textHTML", old.TextHTML, e.TextHTML)
+		fields.compareString("referenceURL", old.ReferenceURL, e.ReferenceURL)
+		fields.compareString("detailsURL", old.DetailsURL, e.DetailsURL)
+		becameDeprecated := !isTrue(old.IsDeprecated) && isTrue(e.IsDeprecated)
+		if !becameDeprecated {
+			fields.compareBool("isDeprecated", old.IsDeprecated, e.IsDeprecated)
+		}
+		if !becameDeprecated && len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+
+		e.ID = old.ID
+		change := ExceptionChange{Exception: e, Fields: []string(fields)}
+		if becameDeprecated {
+			diff.Deprecated = append(diff.Deprecated, change)
+		} else {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	return diff
+}
+
+// fieldChanges collects the names of changed fields.
+type fieldChanges []string
+
+// compareString records the field as changed, if the values differ. Nil equals
+// the empty string.
+func (c *fieldChanges) compareString(name string, old, new *string) {
+	if derefString(old) != derefString(new) {
+		*c = append(*c, name)
+	}
+}
+
+// compareBool records the field as changed, if the values differ. Nil equals
+// false.
+func (c *fieldChanges) compareBool(name string, old, new *bool) {
+	if isTrue(old) != isTrue(new) {
+		*c = append(*c, name)
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,383 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"losh/internal/core/product/models"
+	"losh/internal/lib/util/stringutil"
+)
+
+// LicenseExpressionOperator is the operator of a compound license expression.
+type LicenseExpressionOperator string
+
+const (
+	LicenseExpressionAnd LicenseExpressionOperator = "AND"
+	LicenseExpressionOr  LicenseExpressionOperator = "OR"
+)
+
+// LicenseExpression is a parsed SPDX license expression. It is either a simple
+// expression referring to a single license (optionally with an exception) or a
+// compound expression combining two expressions by an operator.
+// See: https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+type LicenseExpression struct {
+	// LicenseID is the identifier of the license of a simple expression.
+	LicenseID string
+	// OrLater is true, if the license is followed by `+`.
+	OrLater bool
+	// Exception is the identifier of the exception following `WITH`, if any.
+	Exception string
+	// Position and ExceptionPosition are the byte offsets of the license and
+	// exception identifiers in the parsed expression.
+	Position          int
+	ExceptionPosition int
+
+	// Operator is the operator of a compound expression.
+	Operator LicenseExpressionOperator
+	// Left and Right are the operands of a compound expression.
+	Left  *LicenseExpression
+	Right *LicenseExpression
+}
+
+// IsCompound returns true, if the expression combines two expressions.
+func (e *LicenseExpression) IsCompound() bool {
+	return e.Operator != ""
+}
+
+// LicenseIDs returns the identifiers of all licenses referenced in the
+// expression in the order of their appearance.
+func (e *LicenseExpression) LicenseIDs() []string {
+	if !e.IsCompound() {
+		return []string{e.LicenseID}
+	}
+	return append(e.Left.LicenseIDs(), e.Right.LicenseIDs()...)
+}
+
+// Exceptions returns the identifiers of all exceptions referenced in the
+// expression in the order of their appearance.
+func (e *LicenseExpression) Exceptions() []string {
+	if !e.IsCompound() {
+		if e.Exception == "" {
+			return nil
+		}
+		return []string{e.Exception}
+	}
+	return append(e.Left.Exceptions(), e.Right.Exceptions()...)
+}
+
+// simpleExpressions returns all simple expressions in the order of their
+// appearance.
+func (e *LicenseExpression) simpleExpressions() []*LicenseExpression {
+	if !e.IsCompound() {
+		return []*LicenseExpression{e}
+	}
+	return append(e.Left.simpleExpressions(), e.Right.simpleExpressions()...)
+}
+
+// String returns the expression in its canonical form.
+func (e *LicenseExpression) String() string {
+	if !e.IsCompound() {
+		s := e.LicenseID
+		if e.OrLater {
+			s += "+"
+		}
+		if e.Exception != "" {
+			s += " WITH " + e.Exception
+		}
+		return s
+	}
+	return e.Left.operandString(e.Operator) + " " + string(e.Operator) + " " + e.Right.operandString(e.Operator)
+}
+
+// operandString returns the expression as operand of the given operator. The
+// expression is put in parentheses if it binds weaker than the operator.
+func (e *LicenseExpression) operandString(parent LicenseExpressionOperator) string {
+	if parent == LicenseExpressionAnd && e.Operator == LicenseExpressionOr {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// LicenseExpressionError is returned if a license expression is malformed or
+// refers to an unknown license.
+type LicenseExpressionError struct {
+	Expression string
+	// Token is the offending token.
+	Token string
+	// Position is the byte offset of the offending token in the expression.
+	Position int
+	Message  string
+}
+
+const (
+	msgUnknownLicense   = "unknown license"
+	msgUnknownException = "unknown exception"
+)
+
+// IsUnknownLicense returns true, if the expression is well-formed, but the
+// offending token is an unknown license identifier.
+func (e *LicenseExpressionError) IsUnknownLicense() bool {
+	return e.Message == msgUnknownLicense
+}
+
+// IsUnknownException returns true, if the expression is well-formed, but the
+// offending token is an unknown exception identifier.
+func (e *LicenseExpressionError) IsUnknownException() bool {
+	return e.Message == msgUnknownException
+}
+
+// Error implements the error interface.
+func (e *LicenseExpressionError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("invalid license expression '%s': %s", e.Expression, e.Message)
+	}
+	return fmt.Sprintf("invalid license expression '%s': %s '%s' at position %d", e.Expression, e.Message, e.Token, e.Position)
+}
+
+type licenseToken struct {
+	value string
+	pos   int
+}
+
+// ParseLicenseExpression parses a SPDX license expression. The operators are
+// case insensitive; `AND` binds stronger than `OR`, which can be changed using
+// parentheses.
+func ParseLicenseExpression(expr string) (*LicenseExpression, error) {
+	p := &licenseExpressionParser{expr: expr, tokens: tokenizeLicenseExpression(expr)}
+	if len(p.tokens) == 0 {
+		return nil, &LicenseExpressionError{Expression: expr, Message: "expression is empty"}
+	}
+	le, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		return nil, p.errorf(tok, "unexpected token")
+	}
+	return le, nil
+}
+
+// tokenizeLicenseExpression splits the expression into identifiers, operators,
+// parentheses and `+`.
+func tokenizeLicenseExpression(expr string) []licenseToken {
+	tokens := []licenseToken{}
+	start := -1
+	flush := func(end int) {
+		if start != -1 {
+			tokens = append(tokens, licenseToken{expr[start:end], start})
+			start = -1
+		}
+	}
+	for i, r := range expr {
+		switch {
+		case r == '(' || r == ')' || r == '+':
+			flush(i)
+			tokens = append(tokens, licenseToken{string(r), i})
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush(i)
+		default:
+			if start == -1 {
+				start = i
+			}
+		}
+	}
+	flush(len(expr))
+	return tokens
+}
+
+type licenseExpressionParser struct {
+	expr   string
+	tokens []licenseToken
+	pos    int
+}
+
+func (p *licenseExpressionParser) peek() *licenseToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *licenseExpressionParser) next() *licenseToken {
+	tok := p.peek()
+	if tok != nil {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword returns true, if the token is the given operator keyword.
+func (p *licenseExpressionParser) isKeyword(tok *licenseToken, keyword string) bool {
+	return tok != nil && strings.EqualFold(tok.value, keyword)
+}
+
+func (p *licenseExpressionParser) errorf(tok *licenseToken, msg string) error {
+	if tok == nil {
+		return &LicenseExpressionError{Expression: p.expr, Message: msg}
+	}
+	return &LicenseExpressionError{Expression: p.expr, Token: tok.value, Position: tok.pos, Message: msg}
+}
+
+// parseOr parses: and-expr { OR and-expr }
+func (p *licenseExpressionParser) parseOr() (*LicenseExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LicenseExpression{Operator: LicenseExpressionOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: primary { AND primary }
+func (p *licenseExpressionParser) parseAnd() (*LicenseExpression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "AND") {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &LicenseExpression{Operator: LicenseExpressionAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parsePrimary parses: "(" or-expr ")" | license-id [ "+" ] [ WITH exception-id ]
+func (p *licenseExpressionParser) parsePrimary() (*LicenseExpression, error) {
+	tok := p.next()
+	if tok == nil {
+		return nil, p.errorf(nil, "unexpected end of expression")
+	}
+	if tok.value == "(" {
+		le, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing == nil || closing.value != ")" {
+			return nil, p.errorf(tok, "unbalanced parenthesis")
+		}
+		return le, nil
+	}
+	if !isLicenseIdentifier(tok.value) {
+		return nil, p.errorf(tok, "expected license identifier, got")
+	}
+
+	le := &LicenseExpression{LicenseID: tok.value, Position: tok.pos}
+	if nxt := p.peek(); nxt != nil && nxt.value == "+" {
+		p.next()
+		le.OrLater = true
+	}
+	if p.isKeyword(p.peek(), "WITH") {
+		p.next()
+		exc := p.next()
+		if exc == nil {
+			return nil, p.errorf(nil, "missing exception after WITH")
+		}
+		if !isLicenseIdentifier(exc.value) {
+			return nil, p.errorf(exc, "expected exception identifier, got")
+		}
+		le.Exception = exc.value
+		le.ExceptionPosition = exc.pos
+	}
+	return le, nil
+}
+
+// isLicenseIdentifier returns true, if the token is a valid SPDX identifier.
+// Identifiers consist of letters, digits, `-`, `.` and `:` (for document
+// references) and must not be an operator.
+func isLicenseIdentifier(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH", "(", ")", "+":
+		return false
+	}
+	for _, r := range token {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveLicenseExpression parses the SPDX license expression and resolves
+// all referenced licenses and exceptions through the license cache. The first
+// license of the expression is returned as the primary license, the remaining
+// ones as additional licenses. Exceptions must be known, but are only kept in
+// the expression itself. For compatibility, a plain license name (e.g. `MIT
+// License`) is accepted as well. An empty expression resolves to no license.
+func (s *Service) ResolveLicenseExpression(expr string) (*models.License, []*models.License, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil, nil
+	}
+	if lcs := s.GetCachedLicenseByIDOrName(expr); lcs != nil {
+		return lcs, nil, nil
+	}
+
+	le, err := ParseLicenseExpression(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		license            *models.License
+		additionalLicenses []*models.License
+		seen               = map[string]struct{}{}
+	)
+	simpleExprs := le.simpleExpressions()
+	for _, se := range simpleExprs {
+		lcs := s.GetCachedLicenseByIDOrName(se.LicenseID)
+		if lcs == nil {
+			return nil, nil, &LicenseExpressionError{
+				Expression: expr,
+				Token:      se.LicenseID,
+				Position:   se.Position,
+				Message:    msgUnknownLicense,
+			}
+		}
+		normalizedID := stringutil.NormalizeName(*lcs.Xid)
+		if _, ok := seen[normalizedID]; ok {
+			continue
+		}
+		seen[normalizedID] = struct{}{}
+		if license == nil {
+			license = lcs
+		} else {
+			additionalLicenses = append(additionalLicenses, lcs)
+		}
+	}
+	for _, se := range simpleExprs {
+		if se.Exception != "" && s.GetCachedExceptionByID(se.Exception) == nil {
+			return nil, nil, &LicenseExpressionError{
+				Expression: expr,
+				Token:      se.Exception,
+				Position:   se.ExceptionPosition,
+				Message:    msgUnknownException,
+			}
+		}
+	}
+	return license, additionalLicenses, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"losh/internal/core/product/models"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expr       string
+		want       string
+		licenseIDs []string
+		exceptions []string
+	}{
+		{"MIT", "MIT", []string{"MIT"}, nil},
+		{"  MIT\t", "MIT", []string{"MIT"}, nil},
+		// AND binds stronger than OR
+		{"MIT OR Apache-2.0 AND BSD-3-Clause", "MIT OR Apache-2.0 AND BSD-3-Clause", []string{"MIT", "Apache-2.0", "BSD-3-Clause"}, nil},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause", []string{"MIT", "Apache-2.0", "BSD-3-Clause"}, nil},
+		{"MIT AND (Apache-2.0)", "MIT AND Apache-2.0", []string{"MIT", "Apache-2.0"}, nil},
+		{"((MIT))", "MIT", []string{"MIT"}, nil},
+		{"GPL-2.0+", "GPL-2.0+", []string{"GPL-2.0"}, nil},
+		{"GPL-2.0 + WITH Classpath-exception-2.0", "GPL-2.0+ WITH Classpath-exception-2.0", []string{"GPL-2.0"}, []string{"Classpath-exception-2.0"}},
+		{"GPL-2.0-or-later WITH Bison-exception-2.2 OR MIT", "GPL-2.0-or-later WITH Bison-exception-2.2 OR MIT", []string{"GPL-2.0-or-later", "MIT"}, []string{"Bison-exception-2.2"}},
+		// operators are case insensitive
+		{"mit or apache-2.0 and bsd-3-clause", "mit OR apache-2.0 AND bsd-3-clause", []string{"mit", "apache-2.0", "bsd-3-clause"}, nil},
+		{"GPL-2.0 with Classpath-exception-2.0", "GPL-2.0 WITH Classpath-exception-2.0", []string{"GPL-2.0"}, []string{"Classpath-exception-2.0"}},
+		{"LicenseRef-Custom OR DocumentRef-spdx:LicenseRef-Other", "LicenseRef-Custom OR DocumentRef-spdx:LicenseRef-Other", []string{"LicenseRef-Custom", "DocumentRef-spdx:LicenseRef-Other"}, nil},
+	}
+	for _, tt := range tests {
+		le, err := ParseLicenseExpression(tt.expr)
+		if err != nil {
+			t.Errorf("ParseLicenseExpression(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := le.String(); got != tt.want {
+			t.Errorf("ParseLicenseExpression(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+		if got := le.LicenseIDs(); !reflect.DeepEqual(got, tt.licenseIDs) {
+			t.Errorf("ParseLicenseExpression(%q).LicenseIDs() = %v, want %v", tt.expr, got, tt.licenseIDs)
+		}
+		if got := le.Exceptions(); !reflect.DeepEqual(got, tt.exceptions) {
+			t.Errorf("ParseLicenseExpression(%q).Exceptions() = %v, want %v", tt.expr, got, tt.exceptions)
+		}
+	}
+}
+
+func TestParseLicenseExpressionPrecedence(t *testing.T) {
+	le, err := ParseLicenseExpression("MIT OR Apache-2.0 AND BSD-3-Clause OR ISC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (MIT OR (Apache-2.0 AND BSD-3-Clause)) OR ISC
+	if le.Operator != LicenseExpressionOr || le.Right.LicenseID != "ISC" {
+		t.Fatalf("root = %s, want OR with ISC on the right", le)
+	}
+	inner := le.Left
+	if inner.Operator != LicenseExpressionOr || inner.Left.LicenseID != "MIT" || inner.Right.Operator != LicenseExpressionAnd {
+		t.Errorf("left operand = %s, want MIT OR (Apache-2.0 AND BSD-3-Clause)", inner)
+	}
+}
+
+func TestParseLicenseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr     string
+		token    string
+		position int
+	}{
+		{"", "", 0},
+		{"MIT AND", "", 0},
+		{"AND MIT", "AND", 0},
+		{"MIT OR OR Apache-2.0", "OR", 7},
+		{"MIT Apache-2.0", "Apache-2.0", 4},
+		{"(MIT OR Apache-2.0", "(", 0},
+		{"MIT OR Apache-2.0)", ")", 17},
+		{"MIT WITH", "", 0},
+		{"MIT WITH AND", "AND", 9},
+		{"MIT License", "License", 4},
+		{"GPL-2.0 OR MIT_X11", "MIT_X11", 11},
+	}
+	for _, tt := range tests {
+		_, err := ParseLicenseExpression(tt.expr)
+		var leErr *LicenseExpressionError
+		if !errors.As(err, &leErr) {
+			t.Errorf("ParseLicenseExpression(%q) error = %v, want a license expression error", tt.expr, err)
+			continue
+		}
+		if leErr.Token != tt.token || leErr.Position != tt.position {
+			t.Errorf("ParseLicenseExpression(%q) error at '%s' (%d), want '%s' (%d): %v", tt.expr, leErr.Token, leErr.Position, tt.token, tt.position, err)
+		}
+	}
+}
+
+func TestResolveLicenseExpression(t *testing.T) {
+	svc := NewService(nil)
+	var licenses []*models.License
+	for _, xid := range []string{"MIT", "Apache-2.0", "GPL-2.0-or-later"} {
+		licenses = append(licenses, newTestLicense(xid, ""))
+	}
+	exc := "Classpath-exception-2.0"
+	svc.LoadLicenses(licenses, []*models.Exception{{Xid: &exc, Name: &exc}})
+
+	license, additional, err := svc.ResolveLicenseExpression("mit OR (Apache-2.0 AND MIT) OR GPL-2.0-or-later WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatalf("ResolveLicenseExpression() error = %v", err)
+	}
+	if *license.Xid != "MIT" || len(additional) != 2 || *additional[0].Xid != "Apache-2.0" || *additional[1].Xid != "GPL-2.0-or-later" {
+		t.Errorf("ResolveLicenseExpression() = %v, %v, want MIT and [Apache-2.0 GPL-2.0-or-later]", license, additional)
+	}
+
+	tests := []struct {
+		expr      string
+		token     string
+		position  int
+		exception bool
+	}{
+		// the unknown license is part of a known one
+		{"GPL-2.0-or-later OR GPL-2.0", "GPL-2.0", 20, false},
+		{"MIT OR Apache-2.0 AND MIT-0", "MIT-0", 22, false},
+		{"MIT WITH Classpath-exception-2.0 OR Apache-2.0 WITH Classpath", "Classpath", 52, true},
+	}
+	for _, tt := range tests {
+		_, _, err := svc.ResolveLicenseExpression(tt.expr)
+		var leErr *LicenseExpressionError
+		if !errors.As(err, &leErr) {
+			t.Errorf("ResolveLicenseExpression(%q) error = %v, want a license expression error", tt.expr, err)
+			continue
+		}
+		if leErr.Token != tt.token || leErr.Position != tt.position || leErr.IsUnknownException() != tt.exception || leErr.IsUnknownLicense() == tt.exception {
+			t.Errorf("ResolveLicenseExpression(%q) error = %v, want unknown '%s' at position %d", tt.expr, err, tt.token, tt.position)
+		}
+	}
+}
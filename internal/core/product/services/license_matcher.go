@@ -0,0 +1,286 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+
+	"losh/internal/core/product/models"
+
+	"github.com/aisbergg/go-errors/pkg/errors"
+)
+
+// MinLicenseMatchConfidence is the minimum confidence of a license match to be
+// considered a detected license.
+const MinLicenseMatchConfidence = 0.9
+
+// licenseMatchMargin is the minimum lead of the best match over other matches
+// above `MinLicenseMatchConfidence`. Otherwise the match is ambiguous, e.g. for
+// licenses with identical texts like GPL-2.0-only and GPL-2.0-or-later.
+const licenseMatchMargin = 0.02
+
+// LicenseFileNames are the upper case base names (without extension) of files
+// that usually contain the license text of a project.
+var LicenseFileNames = []string{"LICENSE", "LICENCE", "COPYING"}
+
+// IsLicenseFileName returns true, if the name of the file indicates, that it
+// contains the license text of a project, e.g. `LICENSE.md` or `COPYING`.
+func IsLicenseFileName(filename string) bool {
+	filename = path.Base(filename)
+	if pos := strings.LastIndexByte(filename, '.'); pos != -1 {
+		filename = filename[:pos]
+	}
+	filename = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.TrimSpace(filename))
+	filename = strings.ToUpper(filename)
+	for _, name := range LicenseFileNames {
+		if filename == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLicenseFile returns the path of the license file in the given directory
+// or an empty string, if there is none. The directory is either empty for the
+// root directory or ends with a slash. If there are multiple license files, the
+// first in lexical order is returned.
+func FindLicenseFile(filePaths map[string]struct{}, dir string) string {
+	found := ""
+	for filePath := range filePaths {
+		filePath = strings.TrimLeft(filePath, "/")
+		fileDir := path.Dir(filePath)
+		if fileDir == "." {
+			fileDir = ""
+		} else {
+			fileDir += "/"
+		}
+		if fileDir != dir || !IsLicenseFileName(filePath) {
+			continue
+		}
+		if found == "" || filePath < found {
+			found = filePath
+		}
+	}
+	return found
+}
+
+// LicenseMatch is the result of matching a text against the known licenses.
+type LicenseMatch struct {
+	License *models.License
+	// Confidence is the similarity of the texts between 0 and 1.
+	Confidence float64
+	// Ambiguous indicates, that another license matches the text above
+	// `MinLicenseMatchConfidence` nearly as well, so that both cannot be told
+	// apart.
+	Ambiguous bool
+}
+
+// licenseText is the normalized text of a license prepared for matching.
+type licenseText struct {
+	license *models.License
+	words   map[string]struct{}
+}
+
+var (
+	// equivalentWords are the words that are considered equal according to the
+	// SPDX matching guidelines. See: https://spdx.org/licenses/equivalentwords.txt
+	equivalentWords = strings.NewReplacer(
+		"acknowledgement", "acknowledgment",
+		"analogue", "analog",
+		"analyse", "analyze",
+		"artefact", "artifact",
+		"authorisation", "authorization",
+		"authorised", "authorized",
+		"calibre", "caliber",
+		"cancelled", "canceled",
+		"catalogue", "catalog",
+		"categorise", "categorize",
+		"centre", "center",
+		"emphasised", "emphasized",
+		"favour", "favor",
+		"fulfil ", "fulfill ",
+		"fulfilment", "fulfillment",
+		"initialise", "initialize",
+		"judgement", "judgment",
+		"labelling", "labeling",
+		"labour", "labor",
+		"licence", "license",
+		"maximise", "maximize",
+		"modelled", "modeled",
+		"modelling", "modeling",
+		"offence", "offense",
+		"optimise", "optimize",
+		"organisation", "organization",
+		"organise", "organize",
+		"practise", "practice",
+		"programme", "program",
+		"realise", "realize",
+		"recognise", "recognize",
+		"signalling", "signaling",
+		"sub-license", "sublicense",
+		"sub license", "sublicense",
+		"utilisation", "utilization",
+		"whilst", "while",
+		"wilful", "willful",
+		"non-commercial", "noncommercial",
+		"per cent", "percent",
+		"copyright owner", "copyright holder",
+		"https://", "http://",
+	)
+
+	// copyrightLinePattern matches copyright notices, which are ignored.
+	copyrightLinePattern = regexp.MustCompile(`(?m)^\s*(copyright\b|\(c\)|©).*$`)
+	// bulletPattern matches the bullets and numbering of list items.
+	bulletPattern = regexp.MustCompile(`(?m)^\s*(\(?[0-9a-z]{1,3}[.)]|[*•·-])\s+`)
+	// nonWordPattern matches punctuation and whitespace.
+	nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// NormalizeLicenseText normalizes a license text according to the SPDX license
+// matching guidelines, so that texts differing only in formatting compare
+// equal. Case, punctuation, bullets, copyright notices, equivalent words and
+// whitespace are normalized and everything after `END OF TERMS AND CONDITIONS`
+// is dropped.
+// See: https://spdx.github.io/spdx-spec/v2.3/license-matching-guidelines-and-templates/
+func NormalizeLicenseText(text string) string {
+	text = strings.ToLower(text)
+	if pos := strings.Index(text, "end of terms and conditions"); pos != -1 {
+		text = text[:pos]
+	}
+	text = copyrightLinePattern.ReplaceAllString(text, "")
+	text = bulletPattern.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+	text = equivalentWords.Replace(text + " ")
+	text = nonWordPattern.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
+
+// newLicenseText prepares the text for matching.
+func newLicenseText(text string, license *models.License) *licenseText {
+	words := strings.Fields(NormalizeLicenseText(text))
+	lt := &licenseText{
+		license: license,
+		words:   make(map[string]struct{}, len(words)),
+	}
+	for _, w := range words {
+		lt.words[w] = struct{}{}
+	}
+	return lt
+}
+
+// similarity returns the Sørensen–Dice coefficient of the word sets of both
+// texts.
+func (lt *licenseText) similarity(other *licenseText) float64 {
+	if len(lt.words) == 0 || len(other.words) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range lt.words {
+		if _, ok := other.words[w]; ok {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(lt.words)+len(other.words))
+}
+
+// MatchLicenseText scores the given text (e.g. the content of a LICENSE file)
+// against the texts of all known licenses and returns the best match. Nil is
+// returned, if no license text is known. The caller should check the confidence
+// of the match against `MinLicenseMatchConfidence` and whether it is ambiguous.
+func (s *Service) MatchLicenseText(ctx context.Context, text string) (*LicenseMatch, error) {
+	licenseTexts, err := s.getLicenseTexts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := newLicenseText(text, nil)
+
+	var best *LicenseMatch
+	second := 0.0
+	for _, lt := range licenseTexts {
+		confidence := lt.similarity(target)
+		// ties are resolved by the license ID to be independent of the order
+		if best == nil || confidence > best.Confidence ||
+			(confidence == best.Confidence && *lt.license.Xid < *best.License.Xid) {
+			if best != nil && best.Confidence > second {
+				second = best.Confidence
+			}
+			best = &LicenseMatch{License: lt.license, Confidence: confidence}
+		} else if confidence > second {
+			second = confidence
+		}
+	}
+	if best != nil && second >= MinLicenseMatchConfidence && best.Confidence-second < licenseMatchMargin {
+		best.Ambiguous = true
+	}
+	return best, nil
+}
+
+// DetectLicense detects the license from the content of a license file. It
+// returns nil, if the text doesn't match any known license with sufficient
+// confidence or matches multiple licenses equally well.
+func (s *Service) DetectLicense(ctx context.Context, text string) *models.License {
+	match, err := s.MatchLicenseText(ctx, text)
+	if err != nil {
+		s.log.Warnw("failed to match license text", "error", err)
+		return nil
+	}
+	if match == nil || match.Confidence < MinLicenseMatchConfidence {
+		return nil
+	}
+	if match.Ambiguous {
+		s.log.Debugw("license file matches multiple licenses", "license", *match.License.Xid, "confidence", match.Confidence)
+		return nil
+	}
+	s.log.Debugw("detected license from license file", "license", *match.License.Xid, "confidence", match.Confidence)
+	return match.License
+}
+
+// getLicenseTexts returns the prepared texts of all non-deprecated licenses.
+// The texts are loaded from the repo on first use.
+func (s *Service) getLicenseTexts(ctx context.Context) ([]*licenseText, error) {
+	s.licenseTextsMu.Lock()
+	defer s.licenseTextsMu.Unlock()
+	if s.licenseTexts != nil {
+		return s.licenseTexts, nil
+	}
+
+	licenses, _, err := s.repo.GetAllLicenses(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get license texts")
+	}
+	s.licenseTexts = s.newLicenseTexts(licenses)
+	return s.licenseTexts, nil
+}
+
+// newLicenseTexts prepares the texts of the given licenses for matching.
+// Deprecated licenses and licenses without a text are left out.
+func (s *Service) newLicenseTexts(licenses []*models.License) []*licenseText {
+	licenseTexts := make([]*licenseText, 0, len(licenses))
+	for _, l := range licenses {
+		if l.Text == nil || *l.Text == "" || (l.IsDeprecated != nil && *l.IsDeprecated) {
+			continue
+		}
+		// use the cached license, which is shared with the other nodes
+		license := l
+		if cached := s.GetCachedLicenseByIDOrName(*l.Xid); cached != nil {
+			license = cached
+		}
+		licenseTexts = append(licenseTexts, newLicenseText(*l.Text, license))
+	}
+	return licenseTexts
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"losh/internal/core/product/models"
+	"losh/internal/lib/log/logtest"
+)
+
+const (
+	mitText = `Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.`
+
+	iscText = `Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.`
+
+	gplPreamble = `The licenses for most software are designed to take away your freedom to share and change it. By contrast, the GNU General Public License is intended to guarantee your freedom to share and change free software--to make sure the software is free for all its users. This General Public License applies to most of the Free Software Foundation's software and to any other program whose authors commit to using it. (Some other Free Software Foundation software is covered by the GNU Lesser General Public License instead.) You can apply it to your programs, too.`
+)
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+func newTestLicense(xid, text string) *models.License {
+	return &models.License{Xid: &xid, Name: &xid, Text: &text}
+}
+
+// newLicenseService creates a service, that knows the given licenses.
+func newLicenseService(licenses ...*models.License) *Service {
+	svc := NewService(nil)
+	svc.LoadLicenses(licenses, nil)
+	return svc
+}
+
+func TestNormalizeLicenseTextEquivalentWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"wilful", "willful"},
+		{"willful", "willful"},
+		{"Licence", "license"},
+		{"the Copyright Owner", "the copyright holder"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLicenseText(tt.text); got != tt.want {
+			t.Errorf("NormalizeLicenseText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	svc := newLicenseService(newTestLicense("MIT", mitText), newTestLicense("ISC", iscText))
+
+	license := svc.DetectLicense(context.Background(), "MIT License\n\nCopyright (c) 2022 Jane Doe\n\n"+mitText)
+	if license == nil || *license.Xid != "MIT" {
+		t.Errorf("DetectLicense() = %v, want MIT", license)
+	}
+	if license = svc.DetectLicense(context.Background(), gplPreamble); license != nil {
+		t.Errorf("DetectLicense() = %s, want no license", *license.Xid)
+	}
+}
+
+func TestDetectLicenseAmbiguous(t *testing.T) {
+	// the texts of both licenses are identical, the license notices differ
+	for _, licenses := range [][]*models.License{
+		{newTestLicense("GPL-2.0-only", gplPreamble), newTestLicense("GPL-2.0-or-later", gplPreamble), newTestLicense("MIT", mitText)},
+		{newTestLicense("MIT", mitText), newTestLicense("GPL-2.0-or-later", gplPreamble), newTestLicense("GPL-2.0-only", gplPreamble)},
+	} {
+		svc := newLicenseService(licenses...)
+		match, err := svc.MatchLicenseText(context.Background(), gplPreamble)
+		if err != nil {
+			t.Fatalf("MatchLicenseText() error = %v", err)
+		}
+		if !match.Ambiguous {
+			t.Errorf("MatchLicenseText() = %s, want ambiguous match", *match.License.Xid)
+		}
+		if license := svc.DetectLicense(context.Background(), gplPreamble); license != nil {
+			t.Errorf("DetectLicense() = %s, want no license", *license.Xid)
+		}
+	}
+}
@@ -27,7 +27,8 @@ func (s *Service) GetNode(ctx context.Context, id string) (interface{}, error) {
 
 // getExistingNode tries to check if the node already exists in the DB. If it exists,
 // then its ID will be saved in the node object.
-// TODO: only get ID and not all the other stuff
+// Repositories implementing `NodeIDResolver` resolve the IDs of all nodes at
+// once instead.
 func (s *Service) determineID(ctx context.Context, node models.Node) (err error) {
 	// we don't need to continue if the node already has an ID
 	if node.GetID() != nil {
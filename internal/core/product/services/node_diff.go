@@ -0,0 +1,93 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+)
+
+// diffStoredNodes compares the nodes, that already exist in the DB, with their
+// stored versions. It returns the non-empty diffs and the patches to save
+// instead of the nodes. A nil patch means, that the node is unchanged and must
+// not be saved at all. Nodes without a patch are saved as they are. It also
+// returns the IDs of the products, that were missing and are not anymore.
+func (s *Service) diffStoredNodes(ctx context.Context, nodes *models.NodeSet) (diffs []*models.NodeDiff, patches map[models.Node]models.Node, recovered []string, err error) {
+	diffs = []*models.NodeDiff{}
+	patches = make(map[models.Node]models.Node)
+
+	// load the stored versions of all compared nodes at once
+	compared := []models.Node{}
+	nodes.Range(func(node models.Node) bool {
+		if isCompared(node) {
+			compared = append(compared, node)
+		}
+		return true
+	})
+	if len(compared) == 0 {
+		return
+	}
+	stored, err := s.repo.GetStoredNodes(ctx, compared)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, node := range compared {
+		strNode, ok := stored[*node.GetID()]
+		if !ok {
+			continue
+		}
+		if isRecovered(strNode, node) {
+			recovered = append(recovered, *node.GetID())
+		}
+		diff := models.DiffNode(strNode, node)
+		if diff.IsEmpty() {
+			patches[node] = nil
+			continue
+		}
+		diffs = append(diffs, diff)
+		patches[node] = diff.Patch()
+	}
+	return
+}
+
+// isCompared returns true, if the node already exists and is compared with its
+// stored version. Licenses and placeholders are never compared.
+func isCompared(node models.Node) bool {
+	if node.GetID() == nil {
+		return false
+	}
+	switch n := node.(type) {
+	case *models.Product:
+		return !n.IsPlaceholder()
+	case *models.License, *models.Exception:
+		// licenses and exceptions are never updated
+		return false
+	}
+	return true
+}
+
+// isRecovered returns true, if the stored node is a product, that was marked
+// as missing, and the node is not missing anymore.
+func isRecovered(storedNode, node models.Node) bool {
+	strPrd, ok := storedNode.(*models.Product)
+	if !ok || strPrd.MissingSince == nil {
+		return false
+	}
+	prd := node.(*models.Product)
+	return prd.State != nil && *prd.State != dgclient.ProductStateMissing
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"time"
+
+	"losh/internal/core/product/models"
+)
+
+const (
+	// DefaultSnapshotInterval is the default minimum time between two
+	// popularity snapshots of a product.
+	DefaultSnapshotInterval = 24 * time.Hour
+
+	// popularityRefreshPageSize is the number of products, whose growth is
+	// refreshed at once.
+	popularityRefreshPageSize = 1000
+)
+
+// DefaultGrowthWindows are the default time windows over which the growth of
+// the star and fork counts is computed.
+var DefaultGrowthWindows = []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour}
+
+// PopularityGrowth is the growth of the star and fork counts of a product
+// within a time window.
+type PopularityGrowth struct {
+	Window     time.Duration
+	StarGrowth *int64
+	ForkGrowth *int64
+}
+
+// SetPopularityWindows sets the minimum time between two popularity snapshots
+// of a product and the time windows over which the growth of the star and fork
+// counts is computed. The growth over the first window is stored with the
+// products and used to rank trending products, the others are computed on
+// demand (see `GetProductGrowth`). Non-positive durations are ignored and
+// leave the defaults untouched.
+func (s *Service) SetPopularityWindows(snapshotInterval time.Duration, growthWindows []time.Duration) *Service {
+	if snapshotInterval > 0 {
+		s.snapshotInterval = snapshotInterval
+	}
+	windows := make([]time.Duration, 0, len(growthWindows))
+	for _, window := range growthWindows {
+		if window > 0 {
+			windows = append(windows, window)
+		}
+	}
+	if len(windows) > 0 {
+		s.growthWindows = windows
+	}
+	return s
+}
+
+// rankingWindow returns the growth window, whose growth is stored with the
+// products.
+func (s *Service) rankingWindow() time.Duration {
+	return s.growthWindows[0]
+}
+
+// updatePopularity computes the growth of the star and fork counts of the
+// products from their stored popularity snapshots. It returns the products, for
+// which a new snapshot is due. The snapshots are saved together with the
+// products, because new products don't have an ID yet.
+func (s *Service) updatePopularity(ctx context.Context, nodes *models.NodeSet) (due []*models.Product, err error) {
+	now := time.Now()
+	windowStart := now.Add(-s.rankingWindow())
+	lastDue := now.Add(-s.snapshotInterval)
+	since := windowStart
+	if lastDue.Before(since) {
+		since = lastDue
+	}
+
+	stored := []*models.Product{}
+	ids := []string{}
+	nodes.Range(func(node models.Node) bool {
+		prd, ok := node.(*models.Product)
+		if !ok || prd.IsPlaceholder() || (prd.StarCount == nil && prd.ForkCount == nil) {
+			return true
+		}
+		if prd.ID == nil {
+			due = append(due, prd)
+			return true
+		}
+		stored = append(stored, prd)
+		ids = append(ids, *prd.ID)
+		return true
+	})
+	if len(stored) == 0 {
+		return
+	}
+
+	// the snapshots of all products are retrieved at once
+	snapshots, err := s.repo.GetPopularitySnapshots(ctx, ids, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, prd := range stored {
+		prdSnapshots := snapshots[*prd.ID]
+		prd.StarGrowth, prd.ForkGrowth = computeGrowth(prd, prdSnapshots, windowStart)
+		if len(prdSnapshots) == 0 || !prdSnapshots[len(prdSnapshots)-1].CreatedAt.After(lastDue) {
+			due = append(due, prd)
+		}
+	}
+	return
+}
+
+// RefreshPopularity recomputes the growth of the star and fork counts of all
+// products, that have popularity snapshots. Products are only updated when
+// they are crawled, so without a refresh the growth of products, that are not
+// crawled anymore, would never decline. The growth of products without
+// snapshots within the growth window is removed. It returns the number of
+// updated products.
+func (s *Service) RefreshPopularity(ctx context.Context) (int, error) {
+	windowStart := time.Now().Add(-s.rankingWindow())
+	updated := 0
+	for offset := int64(0); ; offset += popularityRefreshPageSize {
+		products, snapshots, err := s.repo.GetProductsPopularity(ctx, windowStart, popularityRefreshPageSize, offset)
+		if err != nil {
+			return updated, err
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		changed := []*models.Product{}
+		for _, prd := range products {
+			starGrowth, forkGrowth := computeGrowth(prd, snapshots[*prd.ID], windowStart)
+			if !equalInt64Ptr(starGrowth, prd.StarGrowth) || !equalInt64Ptr(forkGrowth, prd.ForkGrowth) {
+				prd.StarGrowth, prd.ForkGrowth = starGrowth, forkGrowth
+				changed = append(changed, prd)
+			}
+		}
+		if err = s.repo.UpdateProductsGrowth(ctx, changed); err != nil {
+			return updated, err
+		}
+		updated += len(changed)
+		s.log.Debugw("refreshed popularity", "offset", offset, "updated", len(changed))
+
+		if int64(len(products)) < popularityRefreshPageSize {
+			break
+		}
+	}
+	return updated, nil
+}
+
+// GetProductGrowth computes the growth of the star and fork counts of the
+// product over each of the growth windows.
+func (s *Service) GetProductGrowth(ctx context.Context, prd *models.Product) ([]PopularityGrowth, error) {
+	if prd.ID == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	longest := s.rankingWindow()
+	for _, window := range s.growthWindows {
+		if window > longest {
+			longest = window
+		}
+	}
+	snapshots, err := s.repo.GetPopularitySnapshots(ctx, []string{*prd.ID}, now.Add(-longest))
+	if err != nil {
+		return nil, err
+	}
+	growths := make([]PopularityGrowth, 0, len(s.growthWindows))
+	for _, window := range s.growthWindows {
+		starGrowth, forkGrowth := computeGrowth(prd, snapshots[*prd.ID], now.Add(-window))
+		growths = append(growths, PopularityGrowth{Window: window, StarGrowth: starGrowth, ForkGrowth: forkGrowth})
+	}
+	return growths, nil
+}
+
+// computeGrowth computes the growth of the star and fork counts of the product
+// relative to the oldest snapshot within the growth window. Snapshots taken
+// before the start of the window are ignored.
+func computeGrowth(prd *models.Product, snapshots []*models.PopularitySnapshot, windowStart time.Time) (starGrowth, forkGrowth *int64) {
+	var starBase, forkBase *int64
+	for _, snp := range snapshots {
+		if snp.CreatedAt.Before(windowStart) {
+			continue
+		}
+		if starBase == nil {
+			starBase = snp.StarCount
+		}
+		if forkBase == nil {
+			forkBase = snp.ForkCount
+		}
+	}
+	return growth(starBase, prd.StarCount), growth(forkBase, prd.ForkCount)
+}
+
+// savePopularitySnapshots takes a snapshot of the current star and fork counts
+// of the products. It is used for repositories, that cannot save the snapshots
+// together with the nodes.
+func (s *Service) savePopularitySnapshots(ctx context.Context, products []*models.Product) error {
+	snapshots := make([]*models.PopularitySnapshot, 0, len(products))
+	for _, snp := range newPopularitySnapshots(products) {
+		if snp.Product.ID != nil {
+			snapshots = append(snapshots, snp)
+		}
+	}
+	return s.repo.CreatePopularitySnapshots(ctx, snapshots)
+}
+
+// newPopularitySnapshots creates snapshots of the current star and fork counts
+// of the products.
+func newPopularitySnapshots(products []*models.Product) []*models.PopularitySnapshot {
+	now := time.Now()
+	snapshots := make([]*models.PopularitySnapshot, 0, len(products))
+	for _, prd := range products {
+		snapshots = append(snapshots, &models.PopularitySnapshot{
+			Product:   prd,
+			CreatedAt: &now,
+			StarCount: prd.StarCount,
+			ForkCount: prd.ForkCount,
+		})
+	}
+	return snapshots
+}
+
+// growth returns the difference between the current count and the base count.
+// It returns nil, if either of them is unknown.
+func growth(base, current *int64) *int64 {
+	if base == nil || current == nil {
+		return nil
+	}
+	g := *current - *base
+	return &g
+}
+
+// equalInt64Ptr reports whether both values are nil or equal.
+func equalInt64Ptr(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
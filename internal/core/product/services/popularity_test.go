@@ -0,0 +1,116 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"losh/internal/core/product/models"
+)
+
+// snapshotRepo is a repository, that provides the popularity snapshots of a
+// single product.
+type snapshotRepo struct {
+	Repository
+	snapshots []*models.PopularitySnapshot
+	since     time.Time
+}
+
+func (r *snapshotRepo) GetPopularitySnapshots(ctx context.Context, productIDs []string, since time.Time) (map[string][]*models.PopularitySnapshot, error) {
+	r.since = since
+	ret := map[string][]*models.PopularitySnapshot{}
+	for _, snp := range r.snapshots {
+		if !snp.CreatedAt.Before(since) {
+			ret[productIDs[0]] = append(ret[productIDs[0]], snp)
+		}
+	}
+	return ret, nil
+}
+
+func newTestSnapshot(age time.Duration, stars, forks int64) *models.PopularitySnapshot {
+	createdAt := time.Now().Add(-age)
+	return &models.PopularitySnapshot{CreatedAt: &createdAt, StarCount: &stars, ForkCount: &forks}
+}
+
+func TestSetPopularityWindows(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		windows []time.Duration
+		want    []time.Duration
+	}{
+		{nil, DefaultGrowthWindows},
+		{[]time.Duration{0, -day}, DefaultGrowthWindows},
+		{[]time.Duration{7 * day, 0, day}, []time.Duration{7 * day, day}},
+	}
+	for _, tt := range tests {
+		svc := NewService(nil).SetPopularityWindows(0, tt.windows)
+		if !reflect.DeepEqual(svc.growthWindows, tt.want) {
+			t.Errorf("SetPopularityWindows(%v) = %v, want %v", tt.windows, svc.growthWindows, tt.want)
+		}
+		if svc.snapshotInterval != DefaultSnapshotInterval {
+			t.Errorf("snapshot interval = %s, want the default", svc.snapshotInterval)
+		}
+	}
+}
+
+func TestGetProductGrowth(t *testing.T) {
+	day := 24 * time.Hour
+	repo := &snapshotRepo{snapshots: []*models.PopularitySnapshot{
+		newTestSnapshot(40*day, 10, 1),
+		newTestSnapshot(20*day, 15, 2),
+		newTestSnapshot(5*day, 25, 3),
+		newTestSnapshot(day/2, 28, 3),
+	}}
+	svc := NewService(repo).SetPopularityWindows(0, []time.Duration{30 * day, 7 * day, 60 * day})
+	id, stars, forks := "0x1", int64(30), int64(4)
+
+	growths, err := svc.GetProductGrowth(context.Background(), &models.Product{ID: &id, StarCount: &stars, ForkCount: &forks})
+	if err != nil {
+		t.Fatalf("GetProductGrowth() error = %v", err)
+	}
+	// the snapshots of the longest window are retrieved at once
+	if age := time.Since(repo.since); age < 60*day || age > 60*day+time.Minute {
+		t.Errorf("snapshots retrieved since %s ago, want 60 days", age)
+	}
+	want := []struct {
+		window       time.Duration
+		stars, forks int64
+	}{
+		{30 * day, 15, 2},
+		{7 * day, 5, 1},
+		{60 * day, 20, 3},
+	}
+	if len(growths) != len(want) {
+		t.Fatalf("got %d growths, want %d", len(growths), len(want))
+	}
+	for i, w := range want {
+		g := growths[i]
+		if g.Window != w.window || *g.StarGrowth != w.stars || *g.ForkGrowth != w.forks {
+			t.Errorf("growth over %s = +%d stars, +%d forks, want +%d stars, +%d forks", g.Window, *g.StarGrowth, *g.ForkGrowth, w.stars, w.forks)
+		}
+	}
+
+	// there is no growth without snapshots within the window
+	repo.snapshots = repo.snapshots[:1]
+	if growths, err = svc.GetProductGrowth(context.Background(), &models.Product{ID: &id, StarCount: &stars}); err != nil {
+		t.Fatalf("GetProductGrowth() error = %v", err)
+	}
+	if growths[0].StarGrowth != nil || growths[2].StarGrowth == nil || *growths[2].StarGrowth != 20 || growths[2].ForkGrowth != nil {
+		t.Errorf("GetProductGrowth() = %+v, want growth over 60 days only", growths)
+	}
+}
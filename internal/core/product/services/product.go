@@ -0,0 +1,118 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"time"
+
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+)
+
+// GetProductsIndexedBefore returns the products originating from the given
+// host, that were last indexed before the given time. The oldest products are
+// returned first. Only the information required to re-index the products is
+// returned (ID, Xid, crawler meta, state, last update and versions of the
+// releases).
+func (s *Service) GetProductsIndexedBefore(ctx context.Context, hostDomain string, indexedBefore time.Time, first, offset int64) ([]*models.Product, error) {
+	return s.repo.GetProductsIndexedBefore(ctx, hostDomain, indexedBefore, first, offset)
+}
+
+// GetProductForkTree returns the product with the given ID together with its
+// lineage (`ForkOf`) and forks (`Forks`) up to the given depth. Only the basic
+// information of the products is returned.
+func (s *Service) GetProductForkTree(ctx context.Context, id string, depth int) (*models.Product, error) {
+	return s.repo.GetProductForkTree(ctx, id, depth)
+}
+
+// TouchProduct sets the time of the last indexing of an existing product
+// without altering the rest of the product.
+func (s *Service) TouchProduct(ctx context.Context, product *models.Product, indexedAt time.Time) error {
+	if err := s.determineID(ctx, product); err != nil {
+		return err
+	}
+	if product.ID == nil {
+		return nil
+	}
+	product.LastIndexedAt = &indexedAt
+	return s.repo.UpdateProduct(ctx, &models.Product{
+		ID:            product.ID,
+		Xid:           product.Xid,
+		LastIndexedAt: &indexedAt,
+	})
+}
+
+// MarkProductMissing moves an existing product into the state MISSING and
+// records since when it is missing.
+func (s *Service) MarkProductMissing(ctx context.Context, product *models.Product, missingSince time.Time) error {
+	if err := s.determineID(ctx, product); err != nil {
+		return err
+	}
+	if product.ID == nil {
+		return nil
+	}
+	state := dgclient.ProductStateMissing
+	product.State = &state
+	product.MissingSince = &missingSince
+	return s.repo.UpdateProduct(ctx, &models.Product{
+		ID:           product.ID,
+		Xid:          product.Xid,
+		State:        &state,
+		MissingSince: &missingSince,
+	})
+}
+
+// linkRenamedProduct links a new product to the existing product with the same
+// platform ID, which is the same product before it was renamed or transferred
+// to another owner. The new product keeps the time of the original discovery.
+func (s *Service) linkRenamedProduct(ctx context.Context, product *models.Product) error {
+	if product.ID != nil || product.Xid == nil || product.PlatformID == nil || product.RenamedFrom != nil || product.IsPlaceholder() {
+		return nil
+	}
+	prevPrd, err := s.repo.GetRenamedProduct(ctx, *product.PlatformID, *product.Xid)
+	if err != nil {
+		return err
+	}
+	if prevPrd == nil {
+		return nil
+	}
+	product.RenamedFrom = &models.Product{ID: prevPrd.ID, Xid: prevPrd.Xid}
+	if prevPrd.DiscoveredAt != nil {
+		product.DiscoveredAt = prevPrd.DiscoveredAt
+	}
+	return nil
+}
+
+// DeleteProduct deletes a product together with its releases, components,
+// revisions and popularity snapshots. It is identified by its ID or, if the ID
+// is unknown, by its Xid. Shared nodes like files, repositories, users and
+// licenses are kept.
+func (s *Service) DeleteProduct(ctx context.Context, product *models.Product) error {
+	id := product.ID
+	if id == nil {
+		var err error
+		if id, err = s.repo.GetProductID(ctx, product.Xid); err != nil {
+			return err
+		}
+		if id == nil {
+			return nil
+		}
+	}
+	if err := s.repo.DeleteProductSubgraph(ctx, *id); err != nil {
+		return err
+	}
+	return s.repo.DeleteProduct(ctx, id, nil)
+}
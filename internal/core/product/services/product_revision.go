@@ -0,0 +1,134 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"losh/internal/core/product/models"
+)
+
+type crawlerRunIDKey struct{}
+
+// revisionIgnoredPredicates are the predicates, that change with every crawl,
+// are derived from other fields or are determined by the crawler and are
+// therefore not recorded in product revisions.
+var revisionIgnoredPredicates = map[string]struct{}{
+	"CrawlerMeta.lastIndexedAt": {},
+	"CrawlerMeta.dataSource":    {},
+	"Product.dataSource":        {},
+	"Component.dataSource":      {},
+	"Product.starGrowth":        {},
+	"Product.forkGrowth":        {},
+}
+
+// NewCrawlerRunID creates the ID of a crawler run from the name of the run,
+// e.g. `github.com/update`, and the time it started.
+func NewCrawlerRunID(name string, startedAt time.Time) string {
+	return name + "/" + startedAt.UTC().Format(time.RFC3339)
+}
+
+// WithCrawlerRunID returns a copy of the context, that carries the ID of the
+// crawler run. The revisions of products saved with the context record it.
+func WithCrawlerRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, crawlerRunIDKey{}, runID)
+}
+
+// CrawlerRunIDFromContext returns the ID of the crawler run carried by the
+// context or an empty string.
+func CrawlerRunIDFromContext(ctx context.Context) string {
+	runID, _ := ctx.Value(crawlerRunIDKey{}).(string)
+	return runID
+}
+
+// GetProductRevisions returns the revisions of the product with the given ID,
+// latest first, together with the total number of revisions.
+func (s *Service) GetProductRevisions(ctx context.Context, productID string, first, offset int64) ([]*models.ProductRevision, int64, error) {
+	return s.repo.GetProductRevisions(ctx, productID, first, offset)
+}
+
+// saveProductRevisions records the changes of the products and components as
+// product revisions. It is used for repositories, that cannot save the
+// revisions together with the nodes.
+func (s *Service) saveProductRevisions(ctx context.Context, root models.Node, nodes *models.NodeSet, diffs []*models.NodeDiff) error {
+	return s.repo.CreateProductRevisions(ctx, newProductRevisions(ctx, root, nodes, diffs))
+}
+
+// newProductRevisions creates the product revisions, that record the changes
+// of the products and components.
+func newProductRevisions(ctx context.Context, root models.Node, nodes *models.NodeSet, diffs []*models.NodeDiff) []*models.ProductRevision {
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	// map releases to their products
+	products := make(map[*models.Component]*models.Product)
+	nodes.Range(func(node models.Node) bool {
+		if prd, ok := node.(*models.Product); ok {
+			if prd.Release != nil {
+				products[prd.Release] = prd
+			}
+			for _, r := range prd.Releases {
+				products[r] = prd
+			}
+		}
+		return true
+	})
+	// sub components belong to the saved product
+	rootPrd, _ := root.(*models.Product)
+
+	now := time.Now()
+	var runID *string
+	if id := CrawlerRunIDFromContext(ctx); id != "" {
+		runID = &id
+	}
+	revisions := []*models.ProductRevision{}
+	for _, diff := range diffs {
+		rev := &models.ProductRevision{CreatedAt: &now, CrawlerRunID: runID}
+		switch n := diff.Node.(type) {
+		case *models.Product:
+			rev.Product = n
+		case *models.Component:
+			rev.Component = n
+			if prd, ok := products[n]; ok {
+				rev.Product = prd
+			} else {
+				rev.Product = rootPrd
+			}
+		default:
+			continue
+		}
+		if rev.Product == nil {
+			continue
+		}
+
+		for _, c := range diff.Changes {
+			if _, ok := revisionIgnoredPredicates[c.Predicate]; ok {
+				continue
+			}
+			rev.Changes = append(rev.Changes, models.RevisionChange{
+				Field: c.Predicate[strings.LastIndex(c.Predicate, ".")+1:],
+				Old:   c.Old,
+				New:   c.New,
+			})
+		}
+		if len(rev.Changes) > 0 {
+			revisions = append(revisions, rev)
+		}
+	}
+	return revisions
+}
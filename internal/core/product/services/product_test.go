@@ -0,0 +1,73 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"losh/internal/core/product/models"
+)
+
+// deletionRepo is a repository, that records the deletions of products.
+type deletionRepo struct {
+	Repository
+	ids     map[string]string
+	deleted []string
+}
+
+func (r *deletionRepo) GetProductID(ctx context.Context, xid *string) (*string, error) {
+	if id, ok := r.ids[*xid]; ok {
+		return &id, nil
+	}
+	return nil, nil
+}
+
+func (r *deletionRepo) DeleteProductSubgraph(ctx context.Context, id string) error {
+	r.deleted = append(r.deleted, "subgraph:"+id)
+	return nil
+}
+
+func (r *deletionRepo) DeleteProduct(ctx context.Context, id, xid *string) error {
+	r.deleted = append(r.deleted, "product:"+*id)
+	return nil
+}
+
+func TestDeleteProduct(t *testing.T) {
+	id, xid, unknownXid := "0x1", "github.com/example/motor-driver/okh.toml", "github.com/example/unknown/okh.toml"
+	tests := []struct {
+		name    string
+		product *models.Product
+		want    []string
+	}{
+		{"by ID", &models.Product{ID: &id}, []string{"subgraph:0x1", "product:0x1"}},
+		{"by Xid", &models.Product{Xid: &xid}, []string{"subgraph:0x1", "product:0x1"}},
+		{"unknown Xid", &models.Product{Xid: &unknownXid}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &deletionRepo{ids: map[string]string{xid: id}}
+			if err := NewService(repo).DeleteProduct(context.Background(), tt.product); err != nil {
+				t.Fatalf("DeleteProduct() error = %v", err)
+			}
+			// the subgraph is deleted first, because it is found through the
+			// edges of the product
+			if !reflect.DeepEqual(repo.deleted, tt.want) {
+				t.Errorf("deleted %v, want %v", repo.deleted, tt.want)
+			}
+		})
+	}
+}
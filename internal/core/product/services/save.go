@@ -39,9 +39,19 @@ func (s *Service) SaveNodes(ctx context.Context, node []models.Node) (err error)
 	return
 }
 
-func (s *Service) SaveNode(ctx context.Context, node models.Node) (err error) {
+// SaveNode creates or updates the node and all of its sub nodes.
+func (s *Service) SaveNode(ctx context.Context, node models.Node) error {
+	_, err := s.SaveNodeWithDiff(ctx, node)
+	return err
+}
+
+// SaveNodeWithDiff is like `SaveNode`, but it also returns the changes of the
+// nodes, that already existed. Unchanged nodes are not updated at all and only
+// the changed fields of the other existing nodes are updated. The changes of
+// products and components are recorded as product revisions.
+func (s *Service) SaveNodeWithDiff(ctx context.Context, node models.Node) (diffs []*models.NodeDiff, err error) {
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return nil, ctx.Err()
 	}
 	if node == nil {
 		return
@@ -59,16 +69,96 @@ func (s *Service) SaveNode(ctx context.Context, node models.Node) (err error) {
 	})
 
 	// check if nodes already exists in the DB
+	bulkRepo, isBulk := s.repo.(BulkNodeRepository)
+	if resolver, ok := s.repo.(NodeIDResolver); ok {
+		if err = resolver.ResolveNodeIDs(ctx, traversed); err != nil {
+			return
+		}
+	} else {
+		// a bulk save resolves the IDs by itself, except for products, which
+		// need special treatment below, and licenses, which are never created
+		traversed.Range(func(node models.Node) bool {
+			if isBulk {
+				switch node.(type) {
+				case *models.Product, *models.License:
+				default:
+					return true
+				}
+			}
+			if err = s.determineID(ctx, node); err != nil {
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	// placeholders must never overwrite existing products, therefore only a
+	// reference to the existing product is kept
+	resolved := false
 	traversed.Range(func(node models.Node) bool {
-		if err = s.determineID(ctx, node); err != nil {
-			return false
+		if prd, ok := node.(*models.Product); ok && prd.IsPlaceholder() && prd.ID != nil {
+			*prd = models.Product{ID: prd.ID, Xid: prd.Xid, State: prd.State}
+			resolved = true
 		}
 		return true
 	})
+	if resolved {
+		traversed = models.NewNodeSetFromDepthFirst(node)
+	}
+
+	// link new products to their previous identity, if they were renamed
+	traversed.Range(func(node models.Node) bool {
+		if prd, ok := node.(*models.Product); ok {
+			err = s.linkRenamedProduct(ctx, prd)
+		}
+		return err == nil
+	})
 	if err != nil {
 		return
 	}
 
+	// mark new releases with conflicting licenses
+	s.markLicenseConflicts(traversed)
+
+	// compute the growth of the popularity metrics
+	dueSnapshots, err := s.updatePopularity(ctx, traversed)
+	if err != nil {
+		return
+	}
+
+	// compare existing nodes with their stored versions
+	diffs, patches, recovered, err := s.diffStoredNodes(ctx, traversed)
+	if err != nil {
+		return
+	}
+
+	// save all nodes together with the revisions and popularity snapshots
+	// within a single transaction, if supported
+	if isBulk {
+		toSave := models.NewNodeSet()
+		traversed.Range(func(node models.Node) bool {
+			if patch, ok := patches[node]; ok {
+				if patch != nil {
+					toSave.AddBack(patch)
+				}
+				return true
+			}
+			toSave.AddBack(node)
+			return true
+		})
+		for _, rev := range newProductRevisions(ctx, node, traversed, diffs) {
+			toSave.AddBack(rev)
+		}
+		for _, snp := range newPopularitySnapshots(dueSnapshots) {
+			toSave.AddBack(snp)
+		}
+		err = bulkRepo.SaveNodes(ctx, toSave, recovered)
+		return
+	}
+
 	// fmt.Println("before")
 	// traversed.Range(func(node models.Node) bool {
 	// 	fmt.Println("node type", reflect.TypeOf(node))
@@ -98,6 +188,14 @@ func (s *Service) SaveNode(ctx context.Context, node models.Node) (err error) {
 			return true
 		}
 
+		// skip unchanged nodes and update only the changed fields of the others
+		if patch, ok := patches[node]; ok {
+			if patch == nil {
+				return true
+			}
+			node = patch
+		}
+
 		// if all sub nodes already exists in the DB, save the node directly
 		// without the need for an extra pass
 		allSubNodesHaveIDs := doAllSubNodesHaveIDs(node)
@@ -125,8 +223,21 @@ func (s *Service) SaveNode(ctx context.Context, node models.Node) (err error) {
 		}
 		return true
 	})
+	if err != nil {
+		return
+	}
 
-	return err
+	// products, that are not missing anymore, must not be purged later on
+	if err = s.repo.ClearProductsMissingSince(ctx, recovered); err != nil {
+		return
+	}
+
+	// record the popularity metrics and the changes of products and components
+	if err = s.savePopularitySnapshots(ctx, dueSnapshots); err != nil {
+		return
+	}
+	err = s.saveProductRevisions(ctx, node, traversed, diffs)
+	return
 }
 
 func reorderMandatory(node models.Node, reordered *models.NodeSet, processed map[models.Node]struct{}) {
@@ -220,6 +331,13 @@ func (s *Service) saveNode(ctx context.Context, node models.Node, update bool) e
 	// save the node itself
 	switch n := node.(type) {
 	case *models.Product:
+		if n.IsPlaceholder() {
+			// existing products are never updated by placeholders
+			if n.ID != nil {
+				return nil
+			}
+			return s.repo.CreateProductPlaceholder(ctx, n)
+		}
 		if n.ID == nil {
 			return s.repo.CreateProduct(ctx, n)
 		}
@@ -15,15 +15,31 @@
 package services
 
 import (
+	"sync"
+	"time"
+
 	"losh/internal/core/product/models"
+	"losh/internal/lib/log"
+
+	"go.uber.org/zap"
 )
 
 type Service struct {
 	repo Repository
+	log  *zap.SugaredLogger
+
+	// popularity snapshots
+	snapshotInterval time.Duration
+	growthWindows    []time.Duration
 
 	// used to cache licenses
-	licenses map[string]*models.License
-	nameToID map[string]string
+	licenses   map[string]*models.License
+	nameToID   map[string]string
+	exceptions map[string]*models.Exception
+
+	// used to cache the normalized license texts for matching
+	licenseTexts   []*licenseText
+	licenseTextsMu sync.Mutex
 
 	// used to cache struct fields to speed up copying
 	// structFieldsCache map[reflect.Type]map[string]structField
@@ -31,7 +47,10 @@ type Service struct {
 
 func NewService(repo Repository) *Service {
 	return &Service{
-		repo: repo,
+		repo:             repo,
+		log:              log.NewLogger("svc-product"),
+		snapshotInterval: DefaultSnapshotInterval,
+		growthWindows:    DefaultGrowthWindows,
 		// structFieldsCache: make(map[reflect.Type]map[string]structField),
 	}
 }
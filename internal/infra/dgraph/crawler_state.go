@@ -0,0 +1,184 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+const (
+	errGetCrawlerStateStr    = "failed to get crawler state(s)"
+	errSaveCrawlerStateStr   = "failed to save crawler state"
+	errDeleteCrawlerStateStr = "failed to delete crawler state"
+)
+
+const crawlerStateFields = `
+	uid
+	CrawlerState.namespace
+	CrawlerState.state
+	CrawlerState.updatedAt
+`
+
+const crawlerStateQuery = `
+query q($namespace: string) {
+	q(func: eq(CrawlerState.namespace, $namespace)) {` + crawlerStateFields + `}
+}`
+
+const allCrawlerStatesQuery = `
+{
+	q(func: type(CrawlerState), orderasc: CrawlerState.namespace) {` + crawlerStateFields + `}
+}`
+
+// crawlerStateUpsertQuery finds the state to update and the database node, to
+// which new states are attached.
+const crawlerStateUpsertQuery = `
+query q($namespace: string) {
+	db as var(func: type(Database), first: 1)
+	s as var(func: eq(CrawlerState.namespace, $namespace))
+}`
+
+// dqlCrawlerState is the DQL representation of a crawler state.
+type dqlCrawlerState struct {
+	UID       string    `json:"uid,omitempty"`
+	Type      string    `json:"dgraph.type,omitempty"`
+	Namespace string    `json:"CrawlerState.namespace"`
+	State     string    `json:"CrawlerState.state"`
+	UpdatedAt time.Time `json:"CrawlerState.updatedAt"`
+}
+
+func (s dqlCrawlerState) toModel() *models.CrawlerState {
+	return &models.CrawlerState{
+		ID:        &s.UID,
+		Namespace: &s.Namespace,
+		State:     &s.State,
+		UpdatedAt: &s.UpdatedAt,
+	}
+}
+
+// GetCrawlerState returns the crawler state with the given namespace or nil,
+// if it doesn't exist.
+func (dr *DgraphRepository) GetCrawlerState(ctx context.Context, namespace string) (*models.CrawlerState, error) {
+	dr.log.Debugw("get CrawlerState", "namespace", namespace)
+	states, err := dr.queryCrawlerStates(ctx, crawlerStateQuery, map[string]string{"$namespace": namespace})
+	if err != nil {
+		return nil, WrapRepoError(err, errGetCrawlerStateStr).Add("namespace", namespace)
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return states[0], nil
+}
+
+// GetAllCrawlerStates returns all crawler states ordered by their namespace.
+func (dr *DgraphRepository) GetAllCrawlerStates(ctx context.Context) ([]*models.CrawlerState, error) {
+	dr.log.Debugw("get all CrawlerStates")
+	states, err := dr.queryCrawlerStates(ctx, allCrawlerStatesQuery, nil)
+	if err != nil {
+		return nil, WrapRepoError(err, errGetCrawlerStateStr)
+	}
+	return states, nil
+}
+
+// SaveCrawlerState creates or updates the crawler state with the namespace of
+// the given state. New states are attached to the database node, if there is
+// one.
+func (dr *DgraphRepository) SaveCrawlerState(ctx context.Context, state *models.CrawlerState) error {
+	dr.log.Debugw("save CrawlerState", "namespace", *state.Namespace)
+	newState := dqlCrawlerState{
+		UID:       "_:state",
+		Type:      "CrawlerState",
+		Namespace: *state.Namespace,
+		State:     *state.State,
+		UpdatedAt: state.UpdatedAt.UTC(),
+	}
+	existingState := newState
+	existingState.UID = "uid(s)"
+	existingState.Type = ""
+
+	var mutations []*api.Mutation
+	for _, mu := range []struct {
+		cond string
+		obj  interface{}
+	}{
+		{`@if(gt(len(s), 0))`, existingState},
+		{`@if(eq(len(s), 0) AND gt(len(db), 0))`, map[string]interface{}{
+			"uid":                    "uid(db)",
+			"Database.crawlerStates": []dqlCrawlerState{newState},
+		}},
+		{`@if(eq(len(s), 0) AND eq(len(db), 0))`, newState},
+	} {
+		setJSON, err := json.Marshal(mu.obj)
+		if err != nil {
+			return WrapRepoError(err, errSaveCrawlerStateStr).Add("namespace", *state.Namespace)
+		}
+		mutations = append(mutations, &api.Mutation{Cond: mu.cond, SetJson: setJSON})
+	}
+
+	req := &api.Request{
+		Query:     crawlerStateUpsertQuery,
+		Vars:      map[string]string{"$namespace": *state.Namespace},
+		Mutations: mutations,
+		CommitNow: true,
+	}
+	if _, err := dr.dgraphClient.NewTxn().Do(ctx, req); err != nil {
+		return WrapRepoError(err, errSaveCrawlerStateStr).Add("namespace", *state.Namespace)
+	}
+	return nil
+}
+
+// DeleteCrawlerState deletes the crawler state with the given namespace.
+func (dr *DgraphRepository) DeleteCrawlerState(ctx context.Context, namespace string) error {
+	dr.log.Debugw("delete CrawlerState", "namespace", namespace)
+	req := &api.Request{
+		Query: crawlerStateUpsertQuery,
+		Vars:  map[string]string{"$namespace": namespace},
+		Mutations: []*api.Mutation{{
+			DelNquads: []byte(`
+				uid(db) <Database.crawlerStates> uid(s) .
+				uid(s) * * .
+			`),
+		}},
+		CommitNow: true,
+	}
+	if _, err := dr.dgraphClient.NewTxn().Do(ctx, req); err != nil {
+		return WrapRepoError(err, errDeleteCrawlerStateStr).Add("namespace", namespace)
+	}
+	return nil
+}
+
+// queryCrawlerStates runs the given query and returns the resulting states.
+func (dr *DgraphRepository) queryCrawlerStates(ctx context.Context, query string, vars map[string]string) ([]*models.CrawlerState, error) {
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+	var rspData struct {
+		Q []dqlCrawlerState `json:"q"`
+	}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, err
+	}
+	states := make([]*models.CrawlerState, 0, len(rspData.Q))
+	for _, s := range rspData.Q {
+		states = append(states, s.toModel())
+	}
+	return states, nil
+}
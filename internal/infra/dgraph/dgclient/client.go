@@ -28,6 +28,14 @@ type DgraphGraphQLClient interface {
 	CreateDatabases(ctx context.Context, createInput []*AddDatabaseInput) (*CreateDatabases, error)
 	UpdateDatabases(ctx context.Context, updateInput UpdateDatabaseInput) (*UpdateDatabases, error)
 	DeleteDatabases(ctx context.Context, delFilter DatabaseFilter) (*DeleteDatabases, error)
+	GetExceptionByID(ctx context.Context, id string) (*GetExceptionByID, error)
+	GetExceptionByXid(ctx context.Context, xid string) (*GetExceptionByXid, error)
+	GetExceptionID(ctx context.Context, xid string) (*GetExceptionID, error)
+	GetExceptions(ctx context.Context, getFilter *ExceptionFilter, order *ExceptionOrder, first *int64, offset *int64) (*GetExceptions, error)
+	GetAllExceptionsBasic(ctx context.Context) (*GetAllExceptionsBasic, error)
+	CreateExceptions(ctx context.Context, createInput []*AddExceptionInput) (*CreateExceptions, error)
+	UpdateExceptions(ctx context.Context, updateInput UpdateExceptionInput) (*UpdateExceptions, error)
+	DeleteExceptions(ctx context.Context, delFilter ExceptionFilter) (*DeleteExceptions, error)
 	GetFileByID(ctx context.Context, id string) (*GetFileByID, error)
 	GetFileByXid(ctx context.Context, xid string) (*GetFileByXid, error)
 	GetFileID(ctx context.Context, xid string) (*GetFileID, error)
@@ -191,6 +199,9 @@ type Query struct {
 	GetLicense                                       *License                                                "json:\"getLicense,omitempty\" graphql:\"getLicense\""
 	QueryLicense                                     []*License                                              "json:\"queryLicense,omitempty\" graphql:\"queryLicense\""
 	AggregateLicense                                 *LicenseAggregateResult                                 "json:\"aggregateLicense,omitempty\" graphql:\"aggregateLicense\""
+	GetException                                     *Exception                                              "json:\"getException,omitempty\" graphql:\"getException\""
+	QueryException                                   []*Exception                                            "json:\"queryException,omitempty\" graphql:\"queryException\""
+	AggregateException                               *ExceptionAggregateResult                               "json:\"aggregateException,omitempty\" graphql:\"aggregateException\""
 	GetTechnologySpecificDocumentationCriteria       *TechnologySpecificDocumentationCriteria                "json:\"getTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"getTechnologySpecificDocumentationCriteria\""
 	QueryTechnologySpecificDocumentationCriteria     []*TechnologySpecificDocumentationCriteria              "json:\"queryTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"queryTechnologySpecificDocumentationCriteria\""
 	AggregateTechnologySpecificDocumentationCriteria *TechnologySpecificDocumentationCriteriaAggregateResult "json:\"aggregateTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"aggregateTechnologySpecificDocumentationCriteria\""
@@ -262,6 +273,9 @@ type Mutation struct {
 	AddLicense                                    *AddLicensePayload                                    "json:\"addLicense,omitempty\" graphql:\"addLicense\""
 	UpdateLicense                                 *UpdateLicensePayload                                 "json:\"updateLicense,omitempty\" graphql:\"updateLicense\""
 	DeleteLicense                                 *DeleteLicensePayload                                 "json:\"deleteLicense,omitempty\" graphql:\"deleteLicense\""
+	AddException                                  *AddExceptionPayload                                  "json:\"addException,omitempty\" graphql:\"addException\""
+	UpdateException                               *UpdateExceptionPayload                               "json:\"updateException,omitempty\" graphql:\"updateException\""
+	DeleteException                               *DeleteExceptionPayload                               "json:\"deleteException,omitempty\" graphql:\"deleteException\""
 	AddTechnologySpecificDocumentationCriteria    *AddTechnologySpecificDocumentationCriteriaPayload    "json:\"addTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"addTechnologySpecificDocumentationCriteria\""
 	UpdateTechnologySpecificDocumentationCriteria *UpdateTechnologySpecificDocumentationCriteriaPayload "json:\"updateTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"updateTechnologySpecificDocumentationCriteria\""
 	DeleteTechnologySpecificDocumentationCriteria *DeleteTechnologySpecificDocumentationCriteriaPayload "json:\"deleteTechnologySpecificDocumentationCriteria,omitempty\" graphql:\"deleteTechnologySpecificDocumentationCriteria\""
@@ -332,6 +346,8 @@ type ComponentFragment struct {
 	Repository                  ComponentFragment_Repository                 "json:\"repository\" graphql:\"repository\""
 	License                     *ComponentFragment_License                   "json:\"license\" graphql:\"license\""
 	AdditionalLicenses          []*ComponentFragment_AdditionalLicenses      "json:\"additionalLicenses\" graphql:\"additionalLicenses\""
+	LicenseExpression           *string                                      "json:\"licenseExpression\" graphql:\"licenseExpression\""
+	HasLicenseConflict          *bool                                        "json:\"hasLicenseConflict\" graphql:\"hasLicenseConflict\""
 	Licensor                    *UserOrGroupBasicFragment                    "json:\"licensor\" graphql:\"licensor\""
 	DocumentationLanguage       string                                       "json:\"documentationLanguage\" graphql:\"documentationLanguage\""
 	TechnologyReadinessLevel    TechnologyReadinessLevel                     "json:\"technologyReadinessLevel\" graphql:\"technologyReadinessLevel\""
@@ -376,6 +392,8 @@ type ComponentFullFragment struct {
 	Repository                  *RepositoryFragment                         "json:\"repository\" graphql:\"repository\""
 	License                     *LicenseFragmentBasic                       "json:\"license\" graphql:\"license\""
 	AdditionalLicenses          []*LicenseFragmentBasic                     "json:\"additionalLicenses\" graphql:\"additionalLicenses\""
+	LicenseExpression           *string                                     "json:\"licenseExpression\" graphql:\"licenseExpression\""
+	HasLicenseConflict          *bool                                       "json:\"hasLicenseConflict\" graphql:\"hasLicenseConflict\""
 	Licensor                    *UserOrGroupFullFragment                    "json:\"licensor\" graphql:\"licensor\""
 	DocumentationLanguage       string                                      "json:\"documentationLanguage\" graphql:\"documentationLanguage\""
 	TechnologyReadinessLevel    TechnologyReadinessLevel                    "json:\"technologyReadinessLevel\" graphql:\"technologyReadinessLevel\""
@@ -409,6 +427,23 @@ type DatabaseFragment struct {
 	ID      string "json:\"id\" graphql:\"id\""
 	Version string "json:\"version\" graphql:\"version\""
 }
+type ExceptionFragment struct {
+	ID                 string  "json:\"id\" graphql:\"id\""
+	Xid                string  "json:\"xid\" graphql:\"xid\""
+	Name               string  "json:\"name\" graphql:\"name\""
+	Text               *string "json:\"text\" graphql:\"text\""
+	TextHTML           *string "json:\"textHTML\" graphql:\"textHTML\""
+	ReferenceURL       *string "json:\"referenceURL\" graphql:\"referenceURL\""
+	DetailsURL         *string "json:\"detailsURL\" graphql:\"detailsURL\""
+	LicenseListVersion *string "json:\"licenseListVersion\" graphql:\"licenseListVersion\""
+	IsDeprecated       bool    "json:\"isDeprecated\" graphql:\"isDeprecated\""
+}
+type ExceptionFragmentBasic struct {
+	ID           string "json:\"id\" graphql:\"id\""
+	Xid          string "json:\"xid\" graphql:\"xid\""
+	Name         string "json:\"name\" graphql:\"name\""
+	IsDeprecated bool   "json:\"isDeprecated\" graphql:\"isDeprecated\""
+}
 type FileFragment struct {
 	DiscoveredAt  time.Time           "json:\"discoveredAt\" graphql:\"discoveredAt\""
 	LastIndexedAt time.Time           "json:\"lastIndexedAt\" graphql:\"lastIndexedAt\""
@@ -440,13 +475,25 @@ type StringVFragment struct {
 	Value string "json:\"value\" graphql:\"value\""
 }
 type LicenseFragment struct {
+	ID                 string      "json:\"id\" graphql:\"id\""
+	Xid                string      "json:\"xid\" graphql:\"xid\""
+	Name               string      "json:\"name\" graphql:\"name\""
+	Text               *string     "json:\"text\" graphql:\"text\""
+	TextHTML           *string     "json:\"textHTML\" graphql:\"textHTML\""
+	ReferenceURL       *string     "json:\"referenceURL\" graphql:\"referenceURL\""
+	DetailsURL         *string     "json:\"detailsURL\" graphql:\"detailsURL\""
+	LicenseListVersion *string     "json:\"licenseListVersion\" graphql:\"licenseListVersion\""
+	Type               LicenseType "json:\"type\" graphql:\"type\""
+	IsSpdx             bool        "json:\"isSpdx\" graphql:\"isSpdx\""
+	IsDeprecated       bool        "json:\"isDeprecated\" graphql:\"isDeprecated\""
+	IsOsiApproved      bool        "json:\"isOsiApproved\" graphql:\"isOsiApproved\""
+	IsFsfLibre         bool        "json:\"isFsfLibre\" graphql:\"isFsfLibre\""
+	IsBlocked          bool        "json:\"isBlocked\" graphql:\"isBlocked\""
+}
+type LicenseFragmentBasic struct {
 	ID            string      "json:\"id\" graphql:\"id\""
 	Xid           string      "json:\"xid\" graphql:\"xid\""
 	Name          string      "json:\"name\" graphql:\"name\""
-	Text          *string     "json:\"text\" graphql:\"text\""
-	TextHTML      *string     "json:\"textHTML\" graphql:\"textHTML\""
-	ReferenceURL  *string     "json:\"referenceURL\" graphql:\"referenceURL\""
-	DetailsURL    *string     "json:\"detailsURL\" graphql:\"detailsURL\""
 	Type          LicenseType "json:\"type\" graphql:\"type\""
 	IsSpdx        bool        "json:\"isSpdx\" graphql:\"isSpdx\""
 	IsDeprecated  bool        "json:\"isDeprecated\" graphql:\"isDeprecated\""
@@ -454,16 +501,6 @@ type LicenseFragment struct {
 	IsFsfLibre    bool        "json:\"isFsfLibre\" graphql:\"isFsfLibre\""
 	IsBlocked     bool        "json:\"isBlocked\" graphql:\"isBlocked\""
 }
-type LicenseFragmentBasic struct {
-	ID            string "json:\"id\" graphql:\"id\""
-	Xid           string "json:\"xid\" graphql:\"xid\""
-	Name          string "json:\"name\" graphql:\"name\""
-	IsSpdx        bool   "json:\"isSpdx\" graphql:\"isSpdx\""
-	IsDeprecated  bool   "json:\"isDeprecated\" graphql:\"isDeprecated\""
-	IsOsiApproved bool   "json:\"isOsiApproved\" graphql:\"isOsiApproved\""
-	IsFsfLibre    bool   "json:\"isFsfLibre\" graphql:\"isFsfLibre\""
-	IsBlocked     bool   "json:\"isBlocked\" graphql:\"isBlocked\""
-}
 type ManufacturingProcessFragment struct {
 	ID          string  "json:\"id\" graphql:\"id\""
 	Name        string  "json:\"name\" graphql:\"name\""
@@ -505,16 +542,20 @@ type ProductSearchFragment struct {
 	DataSource    *RepositoryFragment                "json:\"dataSource\" graphql:\"dataSource\""
 	ID            string                             "json:\"id\" graphql:\"id\""
 	Xid           string                             "json:\"xid\" graphql:\"xid\""
+	PlatformID    *string                            "json:\"platformId\" graphql:\"platformId\""
 	Name          string                             "json:\"name\" graphql:\"name\""
 	Website       *string                            "json:\"website\" graphql:\"website\""
 	State         ProductState                       "json:\"state\" graphql:\"state\""
 	LastUpdatedAt *time.Time                         "json:\"lastUpdatedAt\" graphql:\"lastUpdatedAt\""
+	MissingSince  *time.Time                         "json:\"missingSince\" graphql:\"missingSince\""
 	RenamedTo     *ProductSearchFragment_RenamedTo   "json:\"renamedTo\" graphql:\"renamedTo\""
 	RenamedFrom   *ProductSearchFragment_RenamedFrom "json:\"renamedFrom\" graphql:\"renamedFrom\""
 	ForkOf        *ProductSearchFragment_ForkOf      "json:\"forkOf\" graphql:\"forkOf\""
 	Forks         []*ProductSearchFragment_Forks     "json:\"forks\" graphql:\"forks\""
 	ForkCount     *int64                             "json:\"forkCount\" graphql:\"forkCount\""
 	StarCount     *int64                             "json:\"starCount\" graphql:\"starCount\""
+	StarGrowth    *int64                             "json:\"starGrowth\" graphql:\"starGrowth\""
+	ForkGrowth    *int64                             "json:\"forkGrowth\" graphql:\"forkGrowth\""
 	Tags          []*TagFragment                     "json:\"tags\" graphql:\"tags\""
 	Category      *CategoryFragment                  "json:\"category\" graphql:\"category\""
 	Releases      []*ProductSearchFragment_Releases  "json:\"releases\" graphql:\"releases\""
@@ -526,6 +567,7 @@ type ProductFullFragment struct {
 	DataSource            *RepositoryFragment              "json:\"dataSource\" graphql:\"dataSource\""
 	ID                    string                           "json:\"id\" graphql:\"id\""
 	Xid                   string                           "json:\"xid\" graphql:\"xid\""
+	PlatformID            *string                          "json:\"platformId\" graphql:\"platformId\""
 	Name                  string                           "json:\"name\" graphql:\"name\""
 	Description           string                           "json:\"description\" graphql:\"description\""
 	DocumentationLanguage string                           "json:\"documentationLanguage\" graphql:\"documentationLanguage\""
@@ -535,12 +577,16 @@ type ProductFullFragment struct {
 	Website               *string                          "json:\"website\" graphql:\"website\""
 	State                 ProductState                     "json:\"state\" graphql:\"state\""
 	LastUpdatedAt         *time.Time                       "json:\"lastUpdatedAt\" graphql:\"lastUpdatedAt\""
+	MissingSince          *time.Time                       "json:\"missingSince\" graphql:\"missingSince\""
+	ValidationWarnings    *string                          "json:\"validationWarnings\" graphql:\"validationWarnings\""
 	RenamedTo             *ProductFullFragment_RenamedTo   "json:\"renamedTo\" graphql:\"renamedTo\""
 	RenamedFrom           *ProductFullFragment_RenamedFrom "json:\"renamedFrom\" graphql:\"renamedFrom\""
 	ForkOf                *ProductFullFragment_ForkOf      "json:\"forkOf\" graphql:\"forkOf\""
 	Forks                 []*ProductFullFragment_Forks     "json:\"forks\" graphql:\"forks\""
 	ForkCount             *int64                           "json:\"forkCount\" graphql:\"forkCount\""
 	StarCount             *int64                           "json:\"starCount\" graphql:\"starCount\""
+	StarGrowth            *int64                           "json:\"starGrowth\" graphql:\"starGrowth\""
+	ForkGrowth            *int64                           "json:\"forkGrowth\" graphql:\"forkGrowth\""
 	Tags                  []*TagFragment                   "json:\"tags\" graphql:\"tags\""
 	Category              *CategoryFragment                "json:\"category\" graphql:\"category\""
 	Releases              []*ComponentFullFragment         "json:\"releases\" graphql:\"releases\""
@@ -911,15 +957,8 @@ type ProductSearchFragment_RenamedTo struct {
 type ProductSearchFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type ProductSearchFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type ProductSearchFragment_ForkOf_Release struct {
-	Repository ProductSearchFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type ProductSearchFragment_ForkOf struct {
-	ID      string                               "json:\"id\" graphql:\"id\""
-	Release ProductSearchFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type ProductSearchFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -1083,15 +1122,8 @@ type ProductFullFragment_RenamedTo struct {
 type ProductFullFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type ProductFullFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type ProductFullFragment_ForkOf_Release struct {
-	Repository ProductFullFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type ProductFullFragment_ForkOf struct {
-	ID      string                             "json:\"id\" graphql:\"id\""
-	Release ProductFullFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type ProductFullFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -1894,6 +1926,30 @@ type DeleteDatabases_DeleteDatabase_Database struct {
 type DeleteDatabases_DeleteDatabase struct {
 	Database []*DeleteDatabases_DeleteDatabase_Database "json:\"database\" graphql:\"database\""
 }
+type GetExceptionID_GetException struct {
+	ID string "json:\"id\" graphql:\"id\""
+}
+type GetExceptions_AggregateException struct {
+	Count *int64 "json:\"count\" graphql:\"count\""
+}
+type CreateExceptions_AddException_Exception struct {
+	ID string "json:\"id\" graphql:\"id\""
+}
+type CreateExceptions_AddException struct {
+	Exception []*CreateExceptions_AddException_Exception "json:\"exception\" graphql:\"exception\""
+}
+type UpdateExceptions_UpdateException_Exception struct {
+	ID string "json:\"id\" graphql:\"id\""
+}
+type UpdateExceptions_UpdateException struct {
+	Exception []*UpdateExceptions_UpdateException_Exception "json:\"exception\" graphql:\"exception\""
+}
+type DeleteExceptions_DeleteException_Exception struct {
+	ID string "json:\"id\" graphql:\"id\""
+}
+type DeleteExceptions_DeleteException struct {
+	Exception []*DeleteExceptions_DeleteException_Exception "json:\"exception\" graphql:\"exception\""
+}
 type GetFileByID_GetFile_FileFragment_CrawlerMetaFragment_DataSource_RepositoryFragment_Host struct {
 	ID   string "json:\"id\" graphql:\"id\""
 	Name string "json:\"name\" graphql:\"name\""
@@ -2172,15 +2228,8 @@ type GetProductByID_GetProduct_ProductFullFragment_RenamedTo struct {
 type GetProductByID_GetProduct_ProductFullFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type GetProductByID_GetProduct_ProductFullFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type GetProductByID_GetProduct_ProductFullFragment_ForkOf_Release struct {
-	Repository GetProductByID_GetProduct_ProductFullFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type GetProductByID_GetProduct_ProductFullFragment_ForkOf struct {
-	ID      string                                                       "json:\"id\" graphql:\"id\""
-	Release GetProductByID_GetProduct_ProductFullFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type GetProductByID_GetProduct_ProductFullFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -2463,15 +2512,8 @@ type GetProductByXid_GetProduct_ProductFullFragment_RenamedTo struct {
 type GetProductByXid_GetProduct_ProductFullFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type GetProductByXid_GetProduct_ProductFullFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type GetProductByXid_GetProduct_ProductFullFragment_ForkOf_Release struct {
-	Repository GetProductByXid_GetProduct_ProductFullFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type GetProductByXid_GetProduct_ProductFullFragment_ForkOf struct {
-	ID      string                                                        "json:\"id\" graphql:\"id\""
-	Release GetProductByXid_GetProduct_ProductFullFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type GetProductByXid_GetProduct_ProductFullFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -2757,15 +2799,8 @@ type GetProducts_QueryProduct_ProductFullFragment_RenamedTo struct {
 type GetProducts_QueryProduct_ProductFullFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type GetProducts_QueryProduct_ProductFullFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type GetProducts_QueryProduct_ProductFullFragment_ForkOf_Release struct {
-	Repository GetProducts_QueryProduct_ProductFullFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type GetProducts_QueryProduct_ProductFullFragment_ForkOf struct {
-	ID      string                                                      "json:\"id\" graphql:\"id\""
-	Release GetProducts_QueryProduct_ProductFullFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type GetProducts_QueryProduct_ProductFullFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -3047,15 +3082,8 @@ type SearchProducts_QueryProduct_ProductSearchFragment_RenamedTo struct {
 type SearchProducts_QueryProduct_ProductSearchFragment_RenamedFrom struct {
 	ID string "json:\"id\" graphql:\"id\""
 }
-type SearchProducts_QueryProduct_ProductSearchFragment_ForkOf_Release_Repository struct {
-	URL string "json:\"url\" graphql:\"url\""
-}
-type SearchProducts_QueryProduct_ProductSearchFragment_ForkOf_Release struct {
-	Repository SearchProducts_QueryProduct_ProductSearchFragment_ForkOf_Release_Repository "json:\"repository\" graphql:\"repository\""
-}
 type SearchProducts_QueryProduct_ProductSearchFragment_ForkOf struct {
-	ID      string                                                           "json:\"id\" graphql:\"id\""
-	Release SearchProducts_QueryProduct_ProductSearchFragment_ForkOf_Release "json:\"release\" graphql:\"release\""
+	ID string "json:\"id\" graphql:\"id\""
 }
 type SearchProducts_QueryProduct_ProductSearchFragment_Forks struct {
 	ID string "json:\"id\" graphql:\"id\""
@@ -3787,6 +3815,31 @@ type UpdateDatabases struct {
 type DeleteDatabases struct {
 	DeleteDatabase *DeleteDatabases_DeleteDatabase "json:\"deleteDatabase\" graphql:\"deleteDatabase\""
 }
+type GetExceptionByID struct {
+	GetException *ExceptionFragment "json:\"getException\" graphql:\"getException\""
+}
+type GetExceptionByXid struct {
+	GetException *ExceptionFragment "json:\"getException\" graphql:\"getException\""
+}
+type GetExceptionID struct {
+	GetException *GetExceptionID_GetException "json:\"getException\" graphql:\"getException\""
+}
+type GetExceptions struct {
+	QueryException     []*ExceptionFragment              "json:\"queryException\" graphql:\"queryException\""
+	AggregateException *GetExceptions_AggregateException "json:\"aggregateException\" graphql:\"aggregateException\""
+}
+type GetAllExceptionsBasic struct {
+	QueryException []*ExceptionFragmentBasic "json:\"queryException\" graphql:\"queryException\""
+}
+type CreateExceptions struct {
+	AddException *CreateExceptions_AddException "json:\"addException\" graphql:\"addException\""
+}
+type UpdateExceptions struct {
+	UpdateException *UpdateExceptions_UpdateException "json:\"updateException\" graphql:\"updateException\""
+}
+type DeleteExceptions struct {
+	DeleteException *DeleteExceptions_DeleteException "json:\"deleteException\" graphql:\"deleteException\""
+}
 type GetFileByID struct {
 	GetFile *FileFragment "json:\"getFile\" graphql:\"getFile\""
 }
@@ -4602,6 +4655,8 @@ fragment ComponentFragment on Component {
 		id
 		xid
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupBasicFragment
 	}
@@ -4851,6 +4906,8 @@ fragment ComponentFragment on Component {
 		id
 		xid
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupBasicFragment
 	}
@@ -5098,6 +5155,8 @@ fragment ComponentFragment on Component {
 		id
 		xid
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupBasicFragment
 	}
@@ -5617,6 +5676,392 @@ func (c *Client) DeleteDatabasesWithResponse(ctx context.Context, delFilter Data
 	return nil
 }
 
+const GetExceptionByIDDocument = `query GetExceptionByID ($id: ID!) {
+	getException(id: $id) {
+		... ExceptionFragment
+	}
+}
+fragment ExceptionFragment on Exception {
+	id
+	xid
+	name
+	text
+	textHTML
+	referenceURL
+	detailsURL
+	licenseListVersion
+	isDeprecated
+}
+`
+
+func (c *Client) GetExceptionByID(ctx context.Context, id string) (*GetExceptionByID, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionByID",
+		Query:         GetExceptionByIDDocument,
+		Variables: map[string]interface{}{
+			"id": id,
+		},
+	}
+
+	var resp GetExceptionByID
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetExceptionByIDWithResponse(ctx context.Context, id string, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionByID",
+		Query:         GetExceptionByIDDocument,
+		Variables: map[string]interface{}{
+			"id": id,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const GetExceptionByXidDocument = `query GetExceptionByXid ($xid: String!) {
+	getException(xid: $xid) {
+		... ExceptionFragment
+	}
+}
+fragment ExceptionFragment on Exception {
+	id
+	xid
+	name
+	text
+	textHTML
+	referenceURL
+	detailsURL
+	licenseListVersion
+	isDeprecated
+}
+`
+
+func (c *Client) GetExceptionByXid(ctx context.Context, xid string) (*GetExceptionByXid, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionByXid",
+		Query:         GetExceptionByXidDocument,
+		Variables: map[string]interface{}{
+			"xid": xid,
+		},
+	}
+
+	var resp GetExceptionByXid
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetExceptionByXidWithResponse(ctx context.Context, xid string, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionByXid",
+		Query:         GetExceptionByXidDocument,
+		Variables: map[string]interface{}{
+			"xid": xid,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const GetExceptionIDDocument = `query GetExceptionID ($xid: String!) {
+	getException(xid: $xid) {
+		id
+	}
+}
+`
+
+func (c *Client) GetExceptionID(ctx context.Context, xid string) (*GetExceptionID, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionID",
+		Query:         GetExceptionIDDocument,
+		Variables: map[string]interface{}{
+			"xid": xid,
+		},
+	}
+
+	var resp GetExceptionID
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetExceptionIDWithResponse(ctx context.Context, xid string, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptionID",
+		Query:         GetExceptionIDDocument,
+		Variables: map[string]interface{}{
+			"xid": xid,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const GetExceptionsDocument = `query GetExceptions ($getFilter: ExceptionFilter, $order: ExceptionOrder, $first: Int, $offset: Int) {
+	queryException(filter: $getFilter, order: $order, first: $first, offset: $offset) {
+		... ExceptionFragment
+	}
+	aggregateException(filter: $getFilter) {
+		count
+	}
+}
+fragment ExceptionFragment on Exception {
+	id
+	xid
+	name
+	text
+	textHTML
+	referenceURL
+	detailsURL
+	licenseListVersion
+	isDeprecated
+}
+`
+
+func (c *Client) GetExceptions(ctx context.Context, getFilter *ExceptionFilter, order *ExceptionOrder, first *int64, offset *int64) (*GetExceptions, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptions",
+		Query:         GetExceptionsDocument,
+		Variables: map[string]interface{}{
+			"getFilter": getFilter,
+			"order":     order,
+			"first":     first,
+			"offset":    offset,
+		},
+	}
+
+	var resp GetExceptions
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetExceptionsWithResponse(ctx context.Context, getFilter *ExceptionFilter, order *ExceptionOrder, first *int64, offset *int64, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetExceptions",
+		Query:         GetExceptionsDocument,
+		Variables: map[string]interface{}{
+			"getFilter": getFilter,
+			"order":     order,
+			"first":     first,
+			"offset":    offset,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const GetAllExceptionsBasicDocument = `query GetAllExceptionsBasic {
+	queryException {
+		... ExceptionFragmentBasic
+	}
+}
+fragment ExceptionFragmentBasic on Exception {
+	id
+	xid
+	name
+	isDeprecated
+}
+`
+
+func (c *Client) GetAllExceptionsBasic(ctx context.Context) (*GetAllExceptionsBasic, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetAllExceptionsBasic",
+		Query:         GetAllExceptionsBasicDocument,
+		Variables:     map[string]interface{}{},
+	}
+
+	var resp GetAllExceptionsBasic
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetAllExceptionsBasicWithResponse(ctx context.Context, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "GetAllExceptionsBasic",
+		Query:         GetAllExceptionsBasicDocument,
+		Variables:     map[string]interface{}{},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const CreateExceptionsDocument = `mutation CreateExceptions ($createInput: [AddExceptionInput!]!) {
+	addException(input: $createInput, upsert: true) {
+		exception {
+			id
+		}
+	}
+}
+`
+
+func (c *Client) CreateExceptions(ctx context.Context, createInput []*AddExceptionInput) (*CreateExceptions, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "CreateExceptions",
+		Query:         CreateExceptionsDocument,
+		Variables: map[string]interface{}{
+			"createInput": createInput,
+		},
+	}
+
+	var resp CreateExceptions
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) CreateExceptionsWithResponse(ctx context.Context, createInput []*AddExceptionInput, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "CreateExceptions",
+		Query:         CreateExceptionsDocument,
+		Variables: map[string]interface{}{
+			"createInput": createInput,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const UpdateExceptionsDocument = `mutation UpdateExceptions ($updateInput: UpdateExceptionInput!) {
+	updateException(input: $updateInput) {
+		exception {
+			id
+		}
+	}
+}
+`
+
+func (c *Client) UpdateExceptions(ctx context.Context, updateInput UpdateExceptionInput) (*UpdateExceptions, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "UpdateExceptions",
+		Query:         UpdateExceptionsDocument,
+		Variables: map[string]interface{}{
+			"updateInput": updateInput,
+		},
+	}
+
+	var resp UpdateExceptions
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) UpdateExceptionsWithResponse(ctx context.Context, updateInput UpdateExceptionInput, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "UpdateExceptions",
+		Query:         UpdateExceptionsDocument,
+		Variables: map[string]interface{}{
+			"updateInput": updateInput,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+const DeleteExceptionsDocument = `mutation DeleteExceptions ($delFilter: ExceptionFilter!) {
+	deleteException(filter: $delFilter) {
+		exception {
+			id
+		}
+	}
+}
+`
+
+func (c *Client) DeleteExceptions(ctx context.Context, delFilter ExceptionFilter) (*DeleteExceptions, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "DeleteExceptions",
+		Query:         DeleteExceptionsDocument,
+		Variables: map[string]interface{}{
+			"delFilter": delFilter,
+		},
+	}
+
+	var resp DeleteExceptions
+	err := c.Requester.Do(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) DeleteExceptionsWithResponse(ctx context.Context, delFilter ExceptionFilter, resp interface{}) error {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: "DeleteExceptions",
+		Query:         DeleteExceptionsDocument,
+		Variables: map[string]interface{}{
+			"delFilter": delFilter,
+		},
+	}
+
+	err := c.Requester.Do(req, resp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 const GetFileByIDDocument = `query GetFileByID ($id: ID!) {
 	getFile(id: $id) {
 		... FileFragment
@@ -7099,6 +7544,7 @@ fragment LicenseFragment on License {
 	textHTML
 	referenceURL
 	detailsURL
+	licenseListVersion
 	type
 	isSpdx
 	isDeprecated
@@ -7156,6 +7602,7 @@ fragment LicenseFragment on License {
 	textHTML
 	referenceURL
 	detailsURL
+	licenseListVersion
 	type
 	isSpdx
 	isDeprecated
@@ -7258,6 +7705,7 @@ fragment LicenseFragment on License {
 	textHTML
 	referenceURL
 	detailsURL
+	licenseListVersion
 	type
 	isSpdx
 	isDeprecated
@@ -7317,6 +7765,7 @@ fragment LicenseFragmentBasic on License {
 	id
 	xid
 	name
+	type
 	isSpdx
 	isDeprecated
 	isOsiApproved
@@ -8685,6 +9134,7 @@ fragment ProductFullFragment on Product {
 	... CrawlerMetaFragment
 	id
 	xid
+	platformId
 	name
 	description
 	documentationLanguage
@@ -8699,6 +9149,8 @@ fragment ProductFullFragment on Product {
 	website
 	state
 	lastUpdatedAt
+	missingSince
+	validationWarnings
 	renamedTo {
 		id
 	}
@@ -8707,17 +9159,14 @@ fragment ProductFullFragment on Product {
 	}
 	forkOf {
 		id
-		release {
-			repository {
-				url
-			}
-		}
 	}
 	forks {
 		id
 	}
 	forkCount
 	starCount
+	starGrowth
+	forkGrowth
 	tags {
 		... TagFragment
 	}
@@ -8779,6 +9228,8 @@ fragment ComponentFullFragment on Component {
 	additionalLicenses {
 		... LicenseFragmentBasic
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupFullFragment
 	}
@@ -8854,6 +9305,7 @@ fragment LicenseFragmentBasic on License {
 	id
 	xid
 	name
+	type
 	isSpdx
 	isDeprecated
 	isOsiApproved
@@ -9086,6 +9538,7 @@ fragment LicenseFragmentBasic on License {
 	id
 	xid
 	name
+	type
 	isSpdx
 	isDeprecated
 	isOsiApproved
@@ -9170,6 +9623,7 @@ fragment ProductFullFragment on Product {
 	... CrawlerMetaFragment
 	id
 	xid
+	platformId
 	name
 	description
 	documentationLanguage
@@ -9184,6 +9638,8 @@ fragment ProductFullFragment on Product {
 	website
 	state
 	lastUpdatedAt
+	missingSince
+	validationWarnings
 	renamedTo {
 		id
 	}
@@ -9192,17 +9648,14 @@ fragment ProductFullFragment on Product {
 	}
 	forkOf {
 		id
-		release {
-			repository {
-				url
-			}
-		}
 	}
 	forks {
 		id
 	}
 	forkCount
 	starCount
+	starGrowth
+	forkGrowth
 	tags {
 		... TagFragment
 	}
@@ -9243,6 +9696,8 @@ fragment ComponentFullFragment on Component {
 	additionalLicenses {
 		... LicenseFragmentBasic
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupFullFragment
 	}
@@ -9428,6 +9883,8 @@ fragment ComponentFullFragment on Component {
 	additionalLicenses {
 		... LicenseFragmentBasic
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupFullFragment
 	}
@@ -9519,6 +9976,7 @@ fragment ProductFullFragment on Product {
 	... CrawlerMetaFragment
 	id
 	xid
+	platformId
 	name
 	description
 	documentationLanguage
@@ -9533,6 +9991,8 @@ fragment ProductFullFragment on Product {
 	website
 	state
 	lastUpdatedAt
+	missingSince
+	validationWarnings
 	renamedTo {
 		id
 	}
@@ -9541,17 +10001,14 @@ fragment ProductFullFragment on Product {
 	}
 	forkOf {
 		id
-		release {
-			repository {
-				url
-			}
-		}
 	}
 	forks {
 		id
 	}
 	forkCount
 	starCount
+	starGrowth
+	forkGrowth
 	tags {
 		... TagFragment
 	}
@@ -9640,6 +10097,7 @@ fragment LicenseFragmentBasic on License {
 	id
 	xid
 	name
+	type
 	isSpdx
 	isDeprecated
 	isOsiApproved
@@ -9774,6 +10232,8 @@ fragment ComponentFullFragment on Component {
 	additionalLicenses {
 		... LicenseFragmentBasic
 	}
+	licenseExpression
+	hasLicenseConflict
 	licensor {
 		... UserOrGroupFullFragment
 	}
@@ -9849,6 +10309,7 @@ fragment LicenseFragmentBasic on License {
 	id
 	xid
 	name
+	type
 	isSpdx
 	isDeprecated
 	isOsiApproved
@@ -9904,10 +10365,12 @@ fragment ProductSearchFragment on Product {
 	... CrawlerMetaFragment
 	id
 	xid
+	platformId
 	name
 	website
 	state
 	lastUpdatedAt
+	missingSince
 	renamedTo {
 		id
 	}
@@ -9916,17 +10379,14 @@ fragment ProductSearchFragment on Product {
 	}
 	forkOf {
 		id
-		release {
-			repository {
-				url
-			}
-		}
 	}
 	forks {
 		id
 	}
 	forkCount
 	starCount
+	starGrowth
+	forkGrowth
 	tags {
 		... TagFragment
 	}
@@ -80,10 +80,14 @@ type AddComponentInput struct {
 	CreatedAt time.Time       `json:"createdAt"`
 	Releases  []*ComponentRef `json:"releases,omitempty"`
 	// Indicates if this release is the latest release of the component.
-	IsLatest           bool           `json:"isLatest"`
-	Repository         RepositoryRef  `json:"repository"`
-	License            *LicenseRef    `json:"license,omitempty"`
-	AdditionalLicenses []*LicenseRef  `json:"additionalLicenses,omitempty"`
+	IsLatest           bool          `json:"isLatest"`
+	Repository         RepositoryRef `json:"repository"`
+	License            *LicenseRef   `json:"license,omitempty"`
+	AdditionalLicenses []*LicenseRef `json:"additionalLicenses,omitempty"`
+	// The SPDX license expression the license and the additional licenses were derived from, e.g. `CERN-OHL-S-2.0 AND CC-BY-4.0`.
+	LicenseExpression *string `json:"licenseExpression,omitempty"`
+	// Indicates if the licenses of the component and its parts likely conflict with each other.
+	HasLicenseConflict *bool          `json:"hasLicenseConflict,omitempty"`
 	Licensor           UserOrGroupRef `json:"licensor"`
 	// The language in which the documentation is written.
 	DocumentationLanguage string `json:"documentationLanguage"`
@@ -139,6 +143,30 @@ type AddDatabasePayload struct {
 	NumUids  *int64      `json:"numUids"`
 }
 
+type AddExceptionInput struct {
+	// The SPDX ID of the exception.
+	Xid string `json:"xid"`
+	// The full name of the exception.
+	Name string `json:"name"`
+	// The full text of the exception.
+	Text *string `json:"text,omitempty"`
+	// The full text of the exception formatted as HTML.
+	TextHTML *string `json:"textHTML,omitempty"`
+	// The reference URL of the exception with more information.
+	ReferenceURL *string `json:"referenceURL,omitempty"`
+	// The details URL of the exception with information in machine readable format.
+	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the exception was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
+	// Indicates whether the exception identifier is deprecated and should no longer be used.
+	IsDeprecated bool `json:"isDeprecated"`
+}
+
+type AddExceptionPayload struct {
+	Exception []*Exception `json:"exception"`
+	NumUids   *int64       `json:"numUids"`
+}
+
 type AddFileInput struct {
 	DiscoveredAt  time.Time     `json:"discoveredAt"`
 	LastIndexedAt time.Time     `json:"lastIndexedAt"`
@@ -228,6 +256,8 @@ type AddLicenseInput struct {
 	ReferenceURL *string `json:"referenceURL,omitempty"`
 	// The details URL of the license with information in machine readable format.
 	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the license was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
 	// The type (strength) of the license.
 	Type LicenseType `json:"type"`
 	// Indicates whether the license is a standard license.
@@ -290,6 +320,8 @@ type AddProductInput struct {
 	// - `wikifactory.com/@aisbergg/foobar/-`
 	// - `oshwa.org/-/-/us000000.html`
 	Xid string `json:"xid"`
+	// The stable identifier of the product on its platform in the format: `domain.tld/project-id/file-path`. Unlike the Xid, it doesn't change, if the product is renamed or transferred to another owner.
+	PlatformID *string `json:"platformId,omitempty"`
 	// The name of the product.
 	Name string `json:"name"`
 	// The short description of the product.
@@ -305,19 +337,26 @@ type AddProductInput struct {
 	// Indicates if the product is still actively developed or not.
 	State ProductState `json:"state"`
 	// The date and time the product was last upated. This doesn't necessarily mean that a new release was created.
-	LastUpdatedAt *time.Time      `json:"lastUpdatedAt,omitempty"`
-	Release       ComponentRef    `json:"release"`
-	Releases      []*ComponentRef `json:"releases"`
-	RenamedTo     *ProductRef     `json:"renamedTo,omitempty"`
-	RenamedFrom   *ProductRef     `json:"renamedFrom,omitempty"`
-	ForkOf        *ProductRef     `json:"forkOf,omitempty"`
-	Forks         []*ProductRef   `json:"forks,omitempty"`
+	LastUpdatedAt *time.Time `json:"lastUpdatedAt,omitempty"`
+	MissingSince  *time.Time `json:"missingSince,omitempty"`
+	// The warnings found by the validation of the product as JSON encoded list of findings. Warnings don't prevent a product from being indexed.
+	ValidationWarnings *string         `json:"validationWarnings,omitempty"`
+	Release            ComponentRef    `json:"release"`
+	Releases           []*ComponentRef `json:"releases"`
+	RenamedTo          *ProductRef     `json:"renamedTo,omitempty"`
+	RenamedFrom        *ProductRef     `json:"renamedFrom,omitempty"`
+	ForkOf             *ProductRef     `json:"forkOf,omitempty"`
+	Forks              []*ProductRef   `json:"forks,omitempty"`
 	// The number of forks of the product. It might be higher than the number of indexed forks, because not all forks might satisfy the conditions for being indexed.
 	ForkCount *int64 `json:"forkCount,omitempty"`
 	// The number of people starring the product.
-	StarCount *int64       `json:"starCount,omitempty"`
-	Tags      []*TagRef    `json:"tags,omitempty"`
-	Category  *CategoryRef `json:"category,omitempty"`
+	StarCount *int64 `json:"starCount,omitempty"`
+	// The growth of the star count within the growth window. It is used to rank trending products.
+	StarGrowth *int64 `json:"starGrowth,omitempty"`
+	// The growth of the fork count within the growth window.
+	ForkGrowth *int64       `json:"forkGrowth,omitempty"`
+	Tags       []*TagRef    `json:"tags,omitempty"`
+	Category   *CategoryRef `json:"category,omitempty"`
 }
 
 type AddProductPayload struct {
@@ -611,6 +650,10 @@ type Component struct {
 	License *License `json:"license"`
 	// The additional licenses used for the documentation and other assets, if any.
 	AdditionalLicenses []*License `json:"additionalLicenses"`
+	// The SPDX license expression the license and the additional licenses were derived from, e.g. `CERN-OHL-S-2.0 AND CC-BY-4.0`.
+	LicenseExpression *string `json:"licenseExpression"`
+	// Indicates if the licenses of the component and its parts likely conflict with each other.
+	HasLicenseConflict *bool `json:"hasLicenseConflict"`
 	// The license holder of the component.
 	Licensor UserOrGroup `json:"licensor"`
 	// The language in which the documentation is written.
@@ -700,6 +743,8 @@ type ComponentAggregateResult struct {
 	VersionMax               *string    `json:"versionMax"`
 	CreatedAtMin             *time.Time `json:"createdAtMin"`
 	CreatedAtMax             *time.Time `json:"createdAtMax"`
+	LicenseExpressionMin     *string    `json:"licenseExpressionMin"`
+	LicenseExpressionMax     *string    `json:"licenseExpressionMax"`
 	DocumentationLanguageMin *string    `json:"documentationLanguageMin"`
 	DocumentationLanguageMax *string    `json:"documentationLanguageMax"`
 	AttestationMin           *string    `json:"attestationMin"`
@@ -726,6 +771,7 @@ type ComponentFilter struct {
 	Version               *StringTermFilter                                       `json:"version,omitempty"`
 	CreatedAt             *DateTimeFilter                                         `json:"createdAt,omitempty"`
 	IsLatest              *bool                                                   `json:"isLatest,omitempty"`
+	HasLicenseConflict    *bool                                                   `json:"hasLicenseConflict,omitempty"`
 	DocumentationLanguage *StringHashFilter                                       `json:"documentationLanguage,omitempty"`
 	Attestation           *StringHashFilter                                       `json:"attestation,omitempty"`
 	Publication           *StringHashFilter                                       `json:"publication,omitempty"`
@@ -763,10 +809,14 @@ type ComponentPatch struct {
 	CreatedAt *time.Time      `json:"createdAt,omitempty"`
 	Releases  []*ComponentRef `json:"releases,omitempty"`
 	// Indicates if this release is the latest release of the component.
-	IsLatest           *bool           `json:"isLatest,omitempty"`
-	Repository         *RepositoryRef  `json:"repository,omitempty"`
-	License            *LicenseRef     `json:"license,omitempty"`
-	AdditionalLicenses []*LicenseRef   `json:"additionalLicenses,omitempty"`
+	IsLatest           *bool          `json:"isLatest,omitempty"`
+	Repository         *RepositoryRef `json:"repository,omitempty"`
+	License            *LicenseRef    `json:"license,omitempty"`
+	AdditionalLicenses []*LicenseRef  `json:"additionalLicenses,omitempty"`
+	// The SPDX license expression the license and the additional licenses were derived from, e.g. `CERN-OHL-S-2.0 AND CC-BY-4.0`.
+	LicenseExpression *string `json:"licenseExpression,omitempty"`
+	// Indicates if the licenses of the component and its parts likely conflict with each other.
+	HasLicenseConflict *bool           `json:"hasLicenseConflict,omitempty"`
 	Licensor           *UserOrGroupRef `json:"licensor,omitempty"`
 	// The language in which the documentation is written.
 	DocumentationLanguage *string `json:"documentationLanguage,omitempty"`
@@ -827,10 +877,14 @@ type ComponentRef struct {
 	CreatedAt *time.Time      `json:"createdAt,omitempty"`
 	Releases  []*ComponentRef `json:"releases,omitempty"`
 	// Indicates if this release is the latest release of the component.
-	IsLatest           *bool           `json:"isLatest,omitempty"`
-	Repository         *RepositoryRef  `json:"repository,omitempty"`
-	License            *LicenseRef     `json:"license,omitempty"`
-	AdditionalLicenses []*LicenseRef   `json:"additionalLicenses,omitempty"`
+	IsLatest           *bool          `json:"isLatest,omitempty"`
+	Repository         *RepositoryRef `json:"repository,omitempty"`
+	License            *LicenseRef    `json:"license,omitempty"`
+	AdditionalLicenses []*LicenseRef  `json:"additionalLicenses,omitempty"`
+	// The SPDX license expression the license and the additional licenses were derived from, e.g. `CERN-OHL-S-2.0 AND CC-BY-4.0`.
+	LicenseExpression *string `json:"licenseExpression,omitempty"`
+	// Indicates if the licenses of the component and its parts likely conflict with each other.
+	HasLicenseConflict *bool           `json:"hasLicenseConflict,omitempty"`
 	Licensor           *UserOrGroupRef `json:"licensor,omitempty"`
 	// The language in which the documentation is written.
 	DocumentationLanguage *string `json:"documentationLanguage,omitempty"`
@@ -1004,6 +1058,12 @@ type DeleteDatabasePayload struct {
 	NumUids  *int64      `json:"numUids"`
 }
 
+type DeleteExceptionPayload struct {
+	Exception []*Exception `json:"exception"`
+	Msg       *string      `json:"msg"`
+	NumUids   *int64       `json:"numUids"`
+}
+
 type DeleteFilePayload struct {
 	File    []*File `json:"file"`
 	Msg     *string `json:"msg"`
@@ -1122,6 +1182,104 @@ type DgraphDefault struct {
 	Value *string `json:"value,omitempty"`
 }
 
+// A license exception grants additional permissions to a license, e.g. to link against non-free software. It is
+// attached to a license in a SPDX license expression using `WITH`, e.g. `GPL-2.0-only WITH Classpath-exception-2.0`.
+type Exception struct {
+	ID string `json:"id"`
+	// The SPDX ID of the exception.
+	Xid string `json:"xid"`
+	// The full name of the exception.
+	Name string `json:"name"`
+	// The full text of the exception.
+	Text *string `json:"text"`
+	// The full text of the exception formatted as HTML.
+	TextHTML *string `json:"textHTML"`
+	// The reference URL of the exception with more information.
+	ReferenceURL *string `json:"referenceURL"`
+	// The details URL of the exception with information in machine readable format.
+	DetailsURL *string `json:"detailsURL"`
+	// The version of the SPDX license list in which the exception was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion"`
+	// Indicates whether the exception identifier is deprecated and should no longer be used.
+	IsDeprecated bool `json:"isDeprecated"`
+}
+
+func (Exception) IsNode() {}
+
+type ExceptionAggregateResult struct {
+	Count                 *int64  `json:"count"`
+	XidMin                *string `json:"xidMin"`
+	XidMax                *string `json:"xidMax"`
+	NameMin               *string `json:"nameMin"`
+	NameMax               *string `json:"nameMax"`
+	TextMin               *string `json:"textMin"`
+	TextMax               *string `json:"textMax"`
+	TextHTMLMin           *string `json:"textHTMLMin"`
+	TextHTMLMax           *string `json:"textHTMLMax"`
+	ReferenceURLMin       *string `json:"referenceURLMin"`
+	ReferenceURLMax       *string `json:"referenceURLMax"`
+	DetailsURLMin         *string `json:"detailsURLMin"`
+	DetailsURLMax         *string `json:"detailsURLMax"`
+	LicenseListVersionMin *string `json:"licenseListVersionMin"`
+	LicenseListVersionMax *string `json:"licenseListVersionMax"`
+}
+
+type ExceptionFilter struct {
+	ID           []string                                            `json:"id,omitempty"`
+	Xid          *StringHashFilterStringRegExpFilterStringTermFilter `json:"xid,omitempty"`
+	Name         *StringRegExpFilterStringTermFilter                 `json:"name,omitempty"`
+	IsDeprecated *bool                                               `json:"isDeprecated,omitempty"`
+	Has          []*ExceptionHasFilter                               `json:"has,omitempty"`
+	And          []*ExceptionFilter                                  `json:"and,omitempty"`
+	Or           []*ExceptionFilter                                  `json:"or,omitempty"`
+	Not          *ExceptionFilter                                    `json:"not,omitempty"`
+}
+
+type ExceptionOrder struct {
+	Asc  *ExceptionOrderable `json:"asc,omitempty"`
+	Desc *ExceptionOrderable `json:"desc,omitempty"`
+	Then *ExceptionOrder     `json:"then,omitempty"`
+}
+
+type ExceptionPatch struct {
+	// The SPDX ID of the exception.
+	Xid *string `json:"xid,omitempty"`
+	// The full name of the exception.
+	Name *string `json:"name,omitempty"`
+	// The full text of the exception.
+	Text *string `json:"text,omitempty"`
+	// The full text of the exception formatted as HTML.
+	TextHTML *string `json:"textHTML,omitempty"`
+	// The reference URL of the exception with more information.
+	ReferenceURL *string `json:"referenceURL,omitempty"`
+	// The details URL of the exception with information in machine readable format.
+	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the exception was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
+	// Indicates whether the exception identifier is deprecated and should no longer be used.
+	IsDeprecated *bool `json:"isDeprecated,omitempty"`
+}
+
+type ExceptionRef struct {
+	ID *string `json:"id,omitempty"`
+	// The SPDX ID of the exception.
+	Xid *string `json:"xid,omitempty"`
+	// The full name of the exception.
+	Name *string `json:"name,omitempty"`
+	// The full text of the exception.
+	Text *string `json:"text,omitempty"`
+	// The full text of the exception formatted as HTML.
+	TextHTML *string `json:"textHTML,omitempty"`
+	// The reference URL of the exception with more information.
+	ReferenceURL *string `json:"referenceURL,omitempty"`
+	// The details URL of the exception with information in machine readable format.
+	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the exception was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
+	// Indicates whether the exception identifier is deprecated and should no longer be used.
+	IsDeprecated *bool `json:"isDeprecated,omitempty"`
+}
+
 type File struct {
 	DiscoveredAt  time.Time  `json:"discoveredAt"`
 	LastIndexedAt time.Time  `json:"lastIndexedAt"`
@@ -1522,6 +1680,8 @@ type License struct {
 	ReferenceURL *string `json:"referenceURL"`
 	// The details URL of the license with information in machine readable format.
 	DetailsURL *string `json:"detailsURL"`
+	// The version of the SPDX license list in which the license was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion"`
 	// The type (strength) of the license.
 	Type LicenseType `json:"type"`
 	// Indicates whether the license is a standard license.
@@ -1539,19 +1699,21 @@ type License struct {
 func (License) IsNode() {}
 
 type LicenseAggregateResult struct {
-	Count           *int64  `json:"count"`
-	XidMin          *string `json:"xidMin"`
-	XidMax          *string `json:"xidMax"`
-	NameMin         *string `json:"nameMin"`
-	NameMax         *string `json:"nameMax"`
-	TextMin         *string `json:"textMin"`
-	TextMax         *string `json:"textMax"`
-	TextHTMLMin     *string `json:"textHTMLMin"`
-	TextHTMLMax     *string `json:"textHTMLMax"`
-	ReferenceURLMin *string `json:"referenceURLMin"`
-	ReferenceURLMax *string `json:"referenceURLMax"`
-	DetailsURLMin   *string `json:"detailsURLMin"`
-	DetailsURLMax   *string `json:"detailsURLMax"`
+	Count                 *int64  `json:"count"`
+	XidMin                *string `json:"xidMin"`
+	XidMax                *string `json:"xidMax"`
+	NameMin               *string `json:"nameMin"`
+	NameMax               *string `json:"nameMax"`
+	TextMin               *string `json:"textMin"`
+	TextMax               *string `json:"textMax"`
+	TextHTMLMin           *string `json:"textHTMLMin"`
+	TextHTMLMax           *string `json:"textHTMLMax"`
+	ReferenceURLMin       *string `json:"referenceURLMin"`
+	ReferenceURLMax       *string `json:"referenceURLMax"`
+	DetailsURLMin         *string `json:"detailsURLMin"`
+	DetailsURLMax         *string `json:"detailsURLMax"`
+	LicenseListVersionMin *string `json:"licenseListVersionMin"`
+	LicenseListVersionMax *string `json:"licenseListVersionMax"`
 }
 
 type LicenseFilter struct {
@@ -1588,6 +1750,8 @@ type LicensePatch struct {
 	ReferenceURL *string `json:"referenceURL,omitempty"`
 	// The details URL of the license with information in machine readable format.
 	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the license was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
 	// The type (strength) of the license.
 	Type *LicenseType `json:"type,omitempty"`
 	// Indicates whether the license is a standard license.
@@ -1616,6 +1780,8 @@ type LicenseRef struct {
 	ReferenceURL *string `json:"referenceURL,omitempty"`
 	// The details URL of the license with information in machine readable format.
 	DetailsURL *string `json:"detailsURL,omitempty"`
+	// The version of the SPDX license list in which the license was last added or changed.
+	LicenseListVersion *string `json:"licenseListVersion,omitempty"`
 	// The type (strength) of the license.
 	Type *LicenseType `json:"type,omitempty"`
 	// Indicates whether the license is a standard license.
@@ -1856,6 +2022,8 @@ type Product struct {
 	// - `wikifactory.com/@aisbergg/foobar/-`
 	// - `oshwa.org/-/-/us000000.html`
 	Xid string `json:"xid"`
+	// The stable identifier of the product on its platform in the format: `domain.tld/project-id/file-path`. Unlike the Xid, it doesn't change, if the product is renamed or transferred to another owner.
+	PlatformID *string `json:"platformId"`
 	// The name of the product.
 	Name string `json:"name"`
 	// The short description of the product.
@@ -1874,6 +2042,10 @@ type Product struct {
 	State ProductState `json:"state"`
 	// The date and time the product was last upated. This doesn't necessarily mean that a new release was created.
 	LastUpdatedAt *time.Time `json:"lastUpdatedAt"`
+	// The date and time the product was found to be missing on its platform. It is only meaningful, if the product is in state MISSING.
+	MissingSince *time.Time `json:"missingSince"`
+	// The warnings found by the validation of the product as JSON encoded list of findings. Warnings don't prevent a product from being indexed.
+	ValidationWarnings *string `json:"validationWarnings"`
 	// The latest release of the product.
 	Release Component `json:"release"`
 	// A list of all releases of the product.
@@ -1890,6 +2062,10 @@ type Product struct {
 	ForkCount *int64 `json:"forkCount"`
 	// The number of people starring the product.
 	StarCount *int64 `json:"starCount"`
+	// The growth of the star count within the growth window. It is used to rank trending products.
+	StarGrowth *int64 `json:"starGrowth"`
+	// The growth of the fork count within the growth window.
+	ForkGrowth *int64 `json:"forkGrowth"`
 	// A list of all tags associated with the product.
 	Tags []*Tag `json:"tags"`
 	// The category of the product.
@@ -1910,6 +2086,8 @@ type ProductAggregateResult struct {
 	LastIndexedAtMax         *time.Time `json:"lastIndexedAtMax"`
 	XidMin                   *string    `json:"xidMin"`
 	XidMax                   *string    `json:"xidMax"`
+	PlatformIDMin            *string    `json:"platformIdMin"`
+	PlatformIDMax            *string    `json:"platformIdMax"`
 	NameMin                  *string    `json:"nameMin"`
 	NameMax                  *string    `json:"nameMax"`
 	DescriptionMin           *string    `json:"descriptionMin"`
@@ -1921,7 +2099,11 @@ type ProductAggregateResult struct {
 	WebsiteMin               *string    `json:"websiteMin"`
 	WebsiteMax               *string    `json:"websiteMax"`
 	LastUpdatedAtMin         *time.Time `json:"lastUpdatedAtMin"`
+	MissingSinceMin          *time.Time `json:"missingSinceMin"`
 	LastUpdatedAtMax         *time.Time `json:"lastUpdatedAtMax"`
+	MissingSinceMax          *time.Time `json:"missingSinceMax"`
+	ValidationWarningsMin    *string    `json:"validationWarningsMin"`
+	ValidationWarningsMax    *string    `json:"validationWarningsMax"`
 	ForkCountMin             *int64     `json:"forkCountMin"`
 	ForkCountMax             *int64     `json:"forkCountMax"`
 	ForkCountSum             *int64     `json:"forkCountSum"`
@@ -1930,6 +2112,14 @@ type ProductAggregateResult struct {
 	StarCountMax             *int64     `json:"starCountMax"`
 	StarCountSum             *int64     `json:"starCountSum"`
 	StarCountAvg             *float64   `json:"starCountAvg"`
+	StarGrowthMin            *int64     `json:"starGrowthMin"`
+	StarGrowthMax            *int64     `json:"starGrowthMax"`
+	StarGrowthSum            *int64     `json:"starGrowthSum"`
+	StarGrowthAvg            *float64   `json:"starGrowthAvg"`
+	ForkGrowthMin            *int64     `json:"forkGrowthMin"`
+	ForkGrowthMax            *int64     `json:"forkGrowthMax"`
+	ForkGrowthSum            *int64     `json:"forkGrowthSum"`
+	ForkGrowthAvg            *float64   `json:"forkGrowthAvg"`
 }
 
 type ProductFilter struct {
@@ -1937,6 +2127,7 @@ type ProductFilter struct {
 	LastIndexedAt         *DateTimeFilter                                         `json:"lastIndexedAt,omitempty"`
 	ID                    []string                                                `json:"id,omitempty"`
 	Xid                   *StringHashFilter                                       `json:"xid,omitempty"`
+	PlatformID            *StringHashFilter                                       `json:"platformId,omitempty"`
 	Name                  *StringFullTextFilterStringHashFilterStringRegExpFilter `json:"name,omitempty"`
 	Description           *StringFullTextFilterStringRegExpFilter                 `json:"description,omitempty"`
 	DocumentationLanguage *StringRegExpFilter                                     `json:"documentationLanguage,omitempty"`
@@ -1944,8 +2135,11 @@ type ProductFilter struct {
 	Website               *StringFullTextFilterStringRegExpFilter                 `json:"website,omitempty"`
 	State                 *ProductStateHash                                       `json:"state,omitempty"`
 	LastUpdatedAt         *DateTimeFilter                                         `json:"lastUpdatedAt,omitempty"`
+	MissingSince          *DateTimeFilter                                         `json:"missingSince,omitempty"`
 	ForkCount             *IntFilter                                              `json:"forkCount,omitempty"`
 	StarCount             *IntFilter                                              `json:"starCount,omitempty"`
+	StarGrowth            *IntFilter                                              `json:"starGrowth,omitempty"`
+	ForkGrowth            *IntFilter                                              `json:"forkGrowth,omitempty"`
 	Has                   []*ProductHasFilter                                     `json:"has,omitempty"`
 	And                   []*ProductFilter                                        `json:"and,omitempty"`
 	Or                    []*ProductFilter                                        `json:"or,omitempty"`
@@ -1967,6 +2161,8 @@ type ProductPatch struct {
 	// - `wikifactory.com/@aisbergg/foobar/-`
 	// - `oshwa.org/-/-/us000000.html`
 	Xid *string `json:"xid,omitempty"`
+	// The stable identifier of the product on its platform in the format: `domain.tld/project-id/file-path`. Unlike the Xid, it doesn't change, if the product is renamed or transferred to another owner.
+	PlatformID *string `json:"platformId,omitempty"`
 	// The name of the product.
 	Name *string `json:"name,omitempty"`
 	// The short description of the product.
@@ -1982,19 +2178,26 @@ type ProductPatch struct {
 	// Indicates if the product is still actively developed or not.
 	State *ProductState `json:"state,omitempty"`
 	// The date and time the product was last upated. This doesn't necessarily mean that a new release was created.
-	LastUpdatedAt *time.Time      `json:"lastUpdatedAt,omitempty"`
-	Release       *ComponentRef   `json:"release,omitempty"`
-	Releases      []*ComponentRef `json:"releases,omitempty"`
-	RenamedTo     *ProductRef     `json:"renamedTo,omitempty"`
-	RenamedFrom   *ProductRef     `json:"renamedFrom,omitempty"`
-	ForkOf        *ProductRef     `json:"forkOf,omitempty"`
-	Forks         []*ProductRef   `json:"forks,omitempty"`
+	LastUpdatedAt *time.Time `json:"lastUpdatedAt,omitempty"`
+	MissingSince  *time.Time `json:"missingSince,omitempty"`
+	// The warnings found by the validation of the product as JSON encoded list of findings. Warnings don't prevent a product from being indexed.
+	ValidationWarnings *string         `json:"validationWarnings,omitempty"`
+	Release            *ComponentRef   `json:"release,omitempty"`
+	Releases           []*ComponentRef `json:"releases,omitempty"`
+	RenamedTo          *ProductRef     `json:"renamedTo,omitempty"`
+	RenamedFrom        *ProductRef     `json:"renamedFrom,omitempty"`
+	ForkOf             *ProductRef     `json:"forkOf,omitempty"`
+	Forks              []*ProductRef   `json:"forks,omitempty"`
 	// The number of forks of the product. It might be higher than the number of indexed forks, because not all forks might satisfy the conditions for being indexed.
 	ForkCount *int64 `json:"forkCount,omitempty"`
 	// The number of people starring the product.
-	StarCount *int64       `json:"starCount,omitempty"`
-	Tags      []*TagRef    `json:"tags,omitempty"`
-	Category  *CategoryRef `json:"category,omitempty"`
+	StarCount *int64 `json:"starCount,omitempty"`
+	// The growth of the star count within the growth window. It is used to rank trending products.
+	StarGrowth *int64 `json:"starGrowth,omitempty"`
+	// The growth of the fork count within the growth window.
+	ForkGrowth *int64       `json:"forkGrowth,omitempty"`
+	Tags       []*TagRef    `json:"tags,omitempty"`
+	Category   *CategoryRef `json:"category,omitempty"`
 }
 
 type ProductRef struct {
@@ -2007,6 +2210,8 @@ type ProductRef struct {
 	// - `wikifactory.com/@aisbergg/foobar/-`
 	// - `oshwa.org/-/-/us000000.html`
 	Xid *string `json:"xid,omitempty"`
+	// The stable identifier of the product on its platform in the format: `domain.tld/project-id/file-path`. Unlike the Xid, it doesn't change, if the product is renamed or transferred to another owner.
+	PlatformID *string `json:"platformId,omitempty"`
 	// The name of the product.
 	Name *string `json:"name,omitempty"`
 	// The short description of the product.
@@ -2022,19 +2227,26 @@ type ProductRef struct {
 	// Indicates if the product is still actively developed or not.
 	State *ProductState `json:"state,omitempty"`
 	// The date and time the product was last upated. This doesn't necessarily mean that a new release was created.
-	LastUpdatedAt *time.Time      `json:"lastUpdatedAt,omitempty"`
-	Release       *ComponentRef   `json:"release,omitempty"`
-	Releases      []*ComponentRef `json:"releases,omitempty"`
-	RenamedTo     *ProductRef     `json:"renamedTo,omitempty"`
-	RenamedFrom   *ProductRef     `json:"renamedFrom,omitempty"`
-	ForkOf        *ProductRef     `json:"forkOf,omitempty"`
-	Forks         []*ProductRef   `json:"forks,omitempty"`
+	LastUpdatedAt *time.Time `json:"lastUpdatedAt,omitempty"`
+	MissingSince  *time.Time `json:"missingSince,omitempty"`
+	// The warnings found by the validation of the product as JSON encoded list of findings. Warnings don't prevent a product from being indexed.
+	ValidationWarnings *string         `json:"validationWarnings,omitempty"`
+	Release            *ComponentRef   `json:"release,omitempty"`
+	Releases           []*ComponentRef `json:"releases,omitempty"`
+	RenamedTo          *ProductRef     `json:"renamedTo,omitempty"`
+	RenamedFrom        *ProductRef     `json:"renamedFrom,omitempty"`
+	ForkOf             *ProductRef     `json:"forkOf,omitempty"`
+	Forks              []*ProductRef   `json:"forks,omitempty"`
 	// The number of forks of the product. It might be higher than the number of indexed forks, because not all forks might satisfy the conditions for being indexed.
 	ForkCount *int64 `json:"forkCount,omitempty"`
 	// The number of people starring the product.
-	StarCount *int64       `json:"starCount,omitempty"`
-	Tags      []*TagRef    `json:"tags,omitempty"`
-	Category  *CategoryRef `json:"category,omitempty"`
+	StarCount *int64 `json:"starCount,omitempty"`
+	// The growth of the star count within the growth window. It is used to rank trending products.
+	StarGrowth *int64 `json:"starGrowth,omitempty"`
+	// The growth of the fork count within the growth window.
+	ForkGrowth *int64       `json:"forkGrowth,omitempty"`
+	Tags       []*TagRef    `json:"tags,omitempty"`
+	Category   *CategoryRef `json:"category,omitempty"`
 }
 
 type ProductStateHash struct {
@@ -2570,6 +2782,17 @@ type UpdateDatabasePayload struct {
 	NumUids  *int64      `json:"numUids"`
 }
 
+type UpdateExceptionInput struct {
+	Filter ExceptionFilter `json:"filter"`
+	Set    *ExceptionPatch `json:"set,omitempty"`
+	Remove *ExceptionPatch `json:"remove,omitempty"`
+}
+
+type UpdateExceptionPayload struct {
+	Exception []*Exception `json:"exception"`
+	NumUids   *int64       `json:"numUids"`
+}
+
 type UpdateFileInput struct {
 	Filter FileFilter `json:"filter"`
 	Set    *FilePatch `json:"set,omitempty"`
@@ -3122,6 +3345,8 @@ const (
 	ComponentHasFilterRepository                  ComponentHasFilter = "repository"
 	ComponentHasFilterLicense                     ComponentHasFilter = "license"
 	ComponentHasFilterAdditionalLicenses          ComponentHasFilter = "additionalLicenses"
+	ComponentHasFilterLicenseExpression           ComponentHasFilter = "licenseExpression"
+	ComponentHasFilterHasLicenseConflict          ComponentHasFilter = "hasLicenseConflict"
 	ComponentHasFilterLicensor                    ComponentHasFilter = "licensor"
 	ComponentHasFilterDocumentationLanguage       ComponentHasFilter = "documentationLanguage"
 	ComponentHasFilterTechnologyReadinessLevel    ComponentHasFilter = "technologyReadinessLevel"
@@ -3167,6 +3392,8 @@ var AllComponentHasFilter = []ComponentHasFilter{
 	ComponentHasFilterRepository,
 	ComponentHasFilterLicense,
 	ComponentHasFilterAdditionalLicenses,
+	ComponentHasFilterLicenseExpression,
+	ComponentHasFilterHasLicenseConflict,
 	ComponentHasFilterLicensor,
 	ComponentHasFilterDocumentationLanguage,
 	ComponentHasFilterTechnologyReadinessLevel,
@@ -3200,7 +3427,7 @@ var AllComponentHasFilter = []ComponentHasFilter{
 
 func (e ComponentHasFilter) IsValid() bool {
 	switch e {
-	case ComponentHasFilterDiscoveredAt, ComponentHasFilterLastIndexedAt, ComponentHasFilterDataSource, ComponentHasFilterXid, ComponentHasFilterName, ComponentHasFilterDescription, ComponentHasFilterVersion, ComponentHasFilterCreatedAt, ComponentHasFilterReleases, ComponentHasFilterIsLatest, ComponentHasFilterRepository, ComponentHasFilterLicense, ComponentHasFilterAdditionalLicenses, ComponentHasFilterLicensor, ComponentHasFilterDocumentationLanguage, ComponentHasFilterTechnologyReadinessLevel, ComponentHasFilterDocumentationReadinessLevel, ComponentHasFilterAttestation, ComponentHasFilterPublication, ComponentHasFilterIssues, ComponentHasFilterCompliesWith, ComponentHasFilterCpcPatentClass, ComponentHasFilterTsdc, ComponentHasFilterComponents, ComponentHasFilterSoftware, ComponentHasFilterImage, ComponentHasFilterReadme, ComponentHasFilterContributionGuide, ComponentHasFilterBom, ComponentHasFilterManufacturingInstructions, ComponentHasFilterUserManual, ComponentHasFilterProduct, ComponentHasFilterUsedIn, ComponentHasFilterSource, ComponentHasFilterExport, ComponentHasFilterAuxiliary, ComponentHasFilterOrganization, ComponentHasFilterMass, ComponentHasFilterOuterDimensions, ComponentHasFilterMaterial, ComponentHasFilterManufacturingProcess, ComponentHasFilterProductionMetadata:
+	case ComponentHasFilterDiscoveredAt, ComponentHasFilterLastIndexedAt, ComponentHasFilterDataSource, ComponentHasFilterXid, ComponentHasFilterName, ComponentHasFilterDescription, ComponentHasFilterVersion, ComponentHasFilterCreatedAt, ComponentHasFilterReleases, ComponentHasFilterIsLatest, ComponentHasFilterRepository, ComponentHasFilterLicense, ComponentHasFilterAdditionalLicenses, ComponentHasFilterLicenseExpression, ComponentHasFilterHasLicenseConflict, ComponentHasFilterLicensor, ComponentHasFilterDocumentationLanguage, ComponentHasFilterTechnologyReadinessLevel, ComponentHasFilterDocumentationReadinessLevel, ComponentHasFilterAttestation, ComponentHasFilterPublication, ComponentHasFilterIssues, ComponentHasFilterCompliesWith, ComponentHasFilterCpcPatentClass, ComponentHasFilterTsdc, ComponentHasFilterComponents, ComponentHasFilterSoftware, ComponentHasFilterImage, ComponentHasFilterReadme, ComponentHasFilterContributionGuide, ComponentHasFilterBom, ComponentHasFilterManufacturingInstructions, ComponentHasFilterUserManual, ComponentHasFilterProduct, ComponentHasFilterUsedIn, ComponentHasFilterSource, ComponentHasFilterExport, ComponentHasFilterAuxiliary, ComponentHasFilterOrganization, ComponentHasFilterMass, ComponentHasFilterOuterDimensions, ComponentHasFilterMaterial, ComponentHasFilterManufacturingProcess, ComponentHasFilterProductionMetadata:
 		return true
 	}
 	return false
@@ -3237,6 +3464,7 @@ const (
 	ComponentOrderableDescription           ComponentOrderable = "description"
 	ComponentOrderableVersion               ComponentOrderable = "version"
 	ComponentOrderableCreatedAt             ComponentOrderable = "createdAt"
+	ComponentOrderableLicenseExpression     ComponentOrderable = "licenseExpression"
 	ComponentOrderableDocumentationLanguage ComponentOrderable = "documentationLanguage"
 	ComponentOrderableAttestation           ComponentOrderable = "attestation"
 	ComponentOrderablePublication           ComponentOrderable = "publication"
@@ -3253,6 +3481,7 @@ var AllComponentOrderable = []ComponentOrderable{
 	ComponentOrderableDescription,
 	ComponentOrderableVersion,
 	ComponentOrderableCreatedAt,
+	ComponentOrderableLicenseExpression,
 	ComponentOrderableDocumentationLanguage,
 	ComponentOrderableAttestation,
 	ComponentOrderablePublication,
@@ -3263,7 +3492,7 @@ var AllComponentOrderable = []ComponentOrderable{
 
 func (e ComponentOrderable) IsValid() bool {
 	switch e {
-	case ComponentOrderableDiscoveredAt, ComponentOrderableLastIndexedAt, ComponentOrderableXid, ComponentOrderableName, ComponentOrderableDescription, ComponentOrderableVersion, ComponentOrderableCreatedAt, ComponentOrderableDocumentationLanguage, ComponentOrderableAttestation, ComponentOrderablePublication, ComponentOrderableIssues, ComponentOrderableCpcPatentClass, ComponentOrderableMass:
+	case ComponentOrderableDiscoveredAt, ComponentOrderableLastIndexedAt, ComponentOrderableXid, ComponentOrderableName, ComponentOrderableDescription, ComponentOrderableVersion, ComponentOrderableCreatedAt, ComponentOrderableLicenseExpression, ComponentOrderableDocumentationLanguage, ComponentOrderableAttestation, ComponentOrderablePublication, ComponentOrderableIssues, ComponentOrderableCpcPatentClass, ComponentOrderableMass:
 		return true
 	}
 	return false
@@ -3569,6 +3798,110 @@ func (e DocumentationReadinessLevel) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ExceptionHasFilter string
+
+const (
+	ExceptionHasFilterXid                ExceptionHasFilter = "xid"
+	ExceptionHasFilterName               ExceptionHasFilter = "name"
+	ExceptionHasFilterText               ExceptionHasFilter = "text"
+	ExceptionHasFilterTextHTML           ExceptionHasFilter = "textHTML"
+	ExceptionHasFilterReferenceURL       ExceptionHasFilter = "referenceURL"
+	ExceptionHasFilterDetailsURL         ExceptionHasFilter = "detailsURL"
+	ExceptionHasFilterLicenseListVersion ExceptionHasFilter = "licenseListVersion"
+	ExceptionHasFilterIsDeprecated       ExceptionHasFilter = "isDeprecated"
+)
+
+var AllExceptionHasFilter = []ExceptionHasFilter{
+	ExceptionHasFilterXid,
+	ExceptionHasFilterName,
+	ExceptionHasFilterText,
+	ExceptionHasFilterTextHTML,
+	ExceptionHasFilterReferenceURL,
+	ExceptionHasFilterDetailsURL,
+	ExceptionHasFilterLicenseListVersion,
+	ExceptionHasFilterIsDeprecated,
+}
+
+func (e ExceptionHasFilter) IsValid() bool {
+	switch e {
+	case ExceptionHasFilterXid, ExceptionHasFilterName, ExceptionHasFilterText, ExceptionHasFilterTextHTML, ExceptionHasFilterReferenceURL, ExceptionHasFilterDetailsURL, ExceptionHasFilterLicenseListVersion, ExceptionHasFilterIsDeprecated:
+		return true
+	}
+	return false
+}
+
+func (e ExceptionHasFilter) String() string {
+	return string(e)
+}
+
+func (e *ExceptionHasFilter) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExceptionHasFilter(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExceptionHasFilter", str)
+	}
+	return nil
+}
+
+func (e ExceptionHasFilter) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExceptionOrderable string
+
+const (
+	ExceptionOrderableXid                ExceptionOrderable = "xid"
+	ExceptionOrderableName               ExceptionOrderable = "name"
+	ExceptionOrderableText               ExceptionOrderable = "text"
+	ExceptionOrderableTextHTML           ExceptionOrderable = "textHTML"
+	ExceptionOrderableReferenceURL       ExceptionOrderable = "referenceURL"
+	ExceptionOrderableDetailsURL         ExceptionOrderable = "detailsURL"
+	ExceptionOrderableLicenseListVersion ExceptionOrderable = "licenseListVersion"
+)
+
+var AllExceptionOrderable = []ExceptionOrderable{
+	ExceptionOrderableXid,
+	ExceptionOrderableName,
+	ExceptionOrderableText,
+	ExceptionOrderableTextHTML,
+	ExceptionOrderableReferenceURL,
+	ExceptionOrderableDetailsURL,
+	ExceptionOrderableLicenseListVersion,
+}
+
+func (e ExceptionOrderable) IsValid() bool {
+	switch e {
+	case ExceptionOrderableXid, ExceptionOrderableName, ExceptionOrderableText, ExceptionOrderableTextHTML, ExceptionOrderableReferenceURL, ExceptionOrderableDetailsURL, ExceptionOrderableLicenseListVersion:
+		return true
+	}
+	return false
+}
+
+func (e ExceptionOrderable) String() string {
+	return string(e)
+}
+
+func (e *ExceptionOrderable) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExceptionOrderable(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExceptionOrderable", str)
+	}
+	return nil
+}
+
+func (e ExceptionOrderable) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type FileHasFilter string
 
 const (
@@ -4075,18 +4408,19 @@ func (e KeyValueOrderable) MarshalGQL(w io.Writer) {
 type LicenseHasFilter string
 
 const (
-	LicenseHasFilterXid           LicenseHasFilter = "xid"
-	LicenseHasFilterName          LicenseHasFilter = "name"
-	LicenseHasFilterText          LicenseHasFilter = "text"
-	LicenseHasFilterTextHTML      LicenseHasFilter = "textHTML"
-	LicenseHasFilterReferenceURL  LicenseHasFilter = "referenceURL"
-	LicenseHasFilterDetailsURL    LicenseHasFilter = "detailsURL"
-	LicenseHasFilterType          LicenseHasFilter = "type"
-	LicenseHasFilterIsSpdx        LicenseHasFilter = "isSpdx"
-	LicenseHasFilterIsDeprecated  LicenseHasFilter = "isDeprecated"
-	LicenseHasFilterIsOsiApproved LicenseHasFilter = "isOsiApproved"
-	LicenseHasFilterIsFsfLibre    LicenseHasFilter = "isFsfLibre"
-	LicenseHasFilterIsBlocked     LicenseHasFilter = "isBlocked"
+	LicenseHasFilterXid                LicenseHasFilter = "xid"
+	LicenseHasFilterName               LicenseHasFilter = "name"
+	LicenseHasFilterText               LicenseHasFilter = "text"
+	LicenseHasFilterTextHTML           LicenseHasFilter = "textHTML"
+	LicenseHasFilterReferenceURL       LicenseHasFilter = "referenceURL"
+	LicenseHasFilterDetailsURL         LicenseHasFilter = "detailsURL"
+	LicenseHasFilterLicenseListVersion LicenseHasFilter = "licenseListVersion"
+	LicenseHasFilterType               LicenseHasFilter = "type"
+	LicenseHasFilterIsSpdx             LicenseHasFilter = "isSpdx"
+	LicenseHasFilterIsDeprecated       LicenseHasFilter = "isDeprecated"
+	LicenseHasFilterIsOsiApproved      LicenseHasFilter = "isOsiApproved"
+	LicenseHasFilterIsFsfLibre         LicenseHasFilter = "isFsfLibre"
+	LicenseHasFilterIsBlocked          LicenseHasFilter = "isBlocked"
 )
 
 var AllLicenseHasFilter = []LicenseHasFilter{
@@ -4096,6 +4430,7 @@ var AllLicenseHasFilter = []LicenseHasFilter{
 	LicenseHasFilterTextHTML,
 	LicenseHasFilterReferenceURL,
 	LicenseHasFilterDetailsURL,
+	LicenseHasFilterLicenseListVersion,
 	LicenseHasFilterType,
 	LicenseHasFilterIsSpdx,
 	LicenseHasFilterIsDeprecated,
@@ -4106,7 +4441,7 @@ var AllLicenseHasFilter = []LicenseHasFilter{
 
 func (e LicenseHasFilter) IsValid() bool {
 	switch e {
-	case LicenseHasFilterXid, LicenseHasFilterName, LicenseHasFilterText, LicenseHasFilterTextHTML, LicenseHasFilterReferenceURL, LicenseHasFilterDetailsURL, LicenseHasFilterType, LicenseHasFilterIsSpdx, LicenseHasFilterIsDeprecated, LicenseHasFilterIsOsiApproved, LicenseHasFilterIsFsfLibre, LicenseHasFilterIsBlocked:
+	case LicenseHasFilterXid, LicenseHasFilterName, LicenseHasFilterText, LicenseHasFilterTextHTML, LicenseHasFilterReferenceURL, LicenseHasFilterDetailsURL, LicenseHasFilterLicenseListVersion, LicenseHasFilterType, LicenseHasFilterIsSpdx, LicenseHasFilterIsDeprecated, LicenseHasFilterIsOsiApproved, LicenseHasFilterIsFsfLibre, LicenseHasFilterIsBlocked:
 		return true
 	}
 	return false
@@ -4136,12 +4471,13 @@ func (e LicenseHasFilter) MarshalGQL(w io.Writer) {
 type LicenseOrderable string
 
 const (
-	LicenseOrderableXid          LicenseOrderable = "xid"
-	LicenseOrderableName         LicenseOrderable = "name"
-	LicenseOrderableText         LicenseOrderable = "text"
-	LicenseOrderableTextHTML     LicenseOrderable = "textHTML"
-	LicenseOrderableReferenceURL LicenseOrderable = "referenceURL"
-	LicenseOrderableDetailsURL   LicenseOrderable = "detailsURL"
+	LicenseOrderableXid                LicenseOrderable = "xid"
+	LicenseOrderableName               LicenseOrderable = "name"
+	LicenseOrderableText               LicenseOrderable = "text"
+	LicenseOrderableTextHTML           LicenseOrderable = "textHTML"
+	LicenseOrderableReferenceURL       LicenseOrderable = "referenceURL"
+	LicenseOrderableDetailsURL         LicenseOrderable = "detailsURL"
+	LicenseOrderableLicenseListVersion LicenseOrderable = "licenseListVersion"
 )
 
 var AllLicenseOrderable = []LicenseOrderable{
@@ -4151,11 +4487,12 @@ var AllLicenseOrderable = []LicenseOrderable{
 	LicenseOrderableTextHTML,
 	LicenseOrderableReferenceURL,
 	LicenseOrderableDetailsURL,
+	LicenseOrderableLicenseListVersion,
 }
 
 func (e LicenseOrderable) IsValid() bool {
 	switch e {
-	case LicenseOrderableXid, LicenseOrderableName, LicenseOrderableText, LicenseOrderableTextHTML, LicenseOrderableReferenceURL, LicenseOrderableDetailsURL:
+	case LicenseOrderableXid, LicenseOrderableName, LicenseOrderableText, LicenseOrderableTextHTML, LicenseOrderableReferenceURL, LicenseOrderableDetailsURL, LicenseOrderableLicenseListVersion:
 		return true
 	}
 	return false
@@ -4563,6 +4900,7 @@ const (
 	ProductHasFilterLastIndexedAt         ProductHasFilter = "lastIndexedAt"
 	ProductHasFilterDataSource            ProductHasFilter = "dataSource"
 	ProductHasFilterXid                   ProductHasFilter = "xid"
+	ProductHasFilterPlatformID            ProductHasFilter = "platformId"
 	ProductHasFilterName                  ProductHasFilter = "name"
 	ProductHasFilterDescription           ProductHasFilter = "description"
 	ProductHasFilterDocumentationLanguage ProductHasFilter = "documentationLanguage"
@@ -4572,6 +4910,8 @@ const (
 	ProductHasFilterWebsite               ProductHasFilter = "website"
 	ProductHasFilterState                 ProductHasFilter = "state"
 	ProductHasFilterLastUpdatedAt         ProductHasFilter = "lastUpdatedAt"
+	ProductHasFilterMissingSince          ProductHasFilter = "missingSince"
+	ProductHasFilterValidationWarnings    ProductHasFilter = "validationWarnings"
 	ProductHasFilterRelease               ProductHasFilter = "release"
 	ProductHasFilterReleases              ProductHasFilter = "releases"
 	ProductHasFilterRenamedTo             ProductHasFilter = "renamedTo"
@@ -4580,6 +4920,8 @@ const (
 	ProductHasFilterForks                 ProductHasFilter = "forks"
 	ProductHasFilterForkCount             ProductHasFilter = "forkCount"
 	ProductHasFilterStarCount             ProductHasFilter = "starCount"
+	ProductHasFilterStarGrowth            ProductHasFilter = "starGrowth"
+	ProductHasFilterForkGrowth            ProductHasFilter = "forkGrowth"
 	ProductHasFilterTags                  ProductHasFilter = "tags"
 	ProductHasFilterCategory              ProductHasFilter = "category"
 )
@@ -4589,6 +4931,7 @@ var AllProductHasFilter = []ProductHasFilter{
 	ProductHasFilterLastIndexedAt,
 	ProductHasFilterDataSource,
 	ProductHasFilterXid,
+	ProductHasFilterPlatformID,
 	ProductHasFilterName,
 	ProductHasFilterDescription,
 	ProductHasFilterDocumentationLanguage,
@@ -4598,6 +4941,8 @@ var AllProductHasFilter = []ProductHasFilter{
 	ProductHasFilterWebsite,
 	ProductHasFilterState,
 	ProductHasFilterLastUpdatedAt,
+	ProductHasFilterMissingSince,
+	ProductHasFilterValidationWarnings,
 	ProductHasFilterRelease,
 	ProductHasFilterReleases,
 	ProductHasFilterRenamedTo,
@@ -4606,13 +4951,15 @@ var AllProductHasFilter = []ProductHasFilter{
 	ProductHasFilterForks,
 	ProductHasFilterForkCount,
 	ProductHasFilterStarCount,
+	ProductHasFilterStarGrowth,
+	ProductHasFilterForkGrowth,
 	ProductHasFilterTags,
 	ProductHasFilterCategory,
 }
 
 func (e ProductHasFilter) IsValid() bool {
 	switch e {
-	case ProductHasFilterDiscoveredAt, ProductHasFilterLastIndexedAt, ProductHasFilterDataSource, ProductHasFilterXid, ProductHasFilterName, ProductHasFilterDescription, ProductHasFilterDocumentationLanguage, ProductHasFilterVersion, ProductHasFilterLicense, ProductHasFilterLicensor, ProductHasFilterWebsite, ProductHasFilterState, ProductHasFilterLastUpdatedAt, ProductHasFilterRelease, ProductHasFilterReleases, ProductHasFilterRenamedTo, ProductHasFilterRenamedFrom, ProductHasFilterForkOf, ProductHasFilterForks, ProductHasFilterForkCount, ProductHasFilterStarCount, ProductHasFilterTags, ProductHasFilterCategory:
+	case ProductHasFilterDiscoveredAt, ProductHasFilterLastIndexedAt, ProductHasFilterDataSource, ProductHasFilterXid, ProductHasFilterPlatformID, ProductHasFilterName, ProductHasFilterDescription, ProductHasFilterDocumentationLanguage, ProductHasFilterVersion, ProductHasFilterLicense, ProductHasFilterLicensor, ProductHasFilterWebsite, ProductHasFilterState, ProductHasFilterLastUpdatedAt, ProductHasFilterMissingSince, ProductHasFilterValidationWarnings, ProductHasFilterRelease, ProductHasFilterReleases, ProductHasFilterRenamedTo, ProductHasFilterRenamedFrom, ProductHasFilterForkOf, ProductHasFilterForks, ProductHasFilterForkCount, ProductHasFilterStarCount, ProductHasFilterStarGrowth, ProductHasFilterForkGrowth, ProductHasFilterTags, ProductHasFilterCategory:
 		return true
 	}
 	return false
@@ -4645,33 +4992,43 @@ const (
 	ProductOrderableDiscoveredAt          ProductOrderable = "discoveredAt"
 	ProductOrderableLastIndexedAt         ProductOrderable = "lastIndexedAt"
 	ProductOrderableXid                   ProductOrderable = "xid"
+	ProductOrderablePlatformID            ProductOrderable = "platformId"
 	ProductOrderableName                  ProductOrderable = "name"
 	ProductOrderableDescription           ProductOrderable = "description"
 	ProductOrderableDocumentationLanguage ProductOrderable = "documentationLanguage"
 	ProductOrderableVersion               ProductOrderable = "version"
 	ProductOrderableWebsite               ProductOrderable = "website"
 	ProductOrderableLastUpdatedAt         ProductOrderable = "lastUpdatedAt"
+	ProductOrderableMissingSince          ProductOrderable = "missingSince"
+	ProductOrderableValidationWarnings    ProductOrderable = "validationWarnings"
 	ProductOrderableForkCount             ProductOrderable = "forkCount"
 	ProductOrderableStarCount             ProductOrderable = "starCount"
+	ProductOrderableStarGrowth            ProductOrderable = "starGrowth"
+	ProductOrderableForkGrowth            ProductOrderable = "forkGrowth"
 )
 
 var AllProductOrderable = []ProductOrderable{
 	ProductOrderableDiscoveredAt,
 	ProductOrderableLastIndexedAt,
 	ProductOrderableXid,
+	ProductOrderablePlatformID,
 	ProductOrderableName,
 	ProductOrderableDescription,
 	ProductOrderableDocumentationLanguage,
 	ProductOrderableVersion,
 	ProductOrderableWebsite,
 	ProductOrderableLastUpdatedAt,
+	ProductOrderableMissingSince,
+	ProductOrderableValidationWarnings,
 	ProductOrderableForkCount,
 	ProductOrderableStarCount,
+	ProductOrderableStarGrowth,
+	ProductOrderableForkGrowth,
 }
 
 func (e ProductOrderable) IsValid() bool {
 	switch e {
-	case ProductOrderableDiscoveredAt, ProductOrderableLastIndexedAt, ProductOrderableXid, ProductOrderableName, ProductOrderableDescription, ProductOrderableDocumentationLanguage, ProductOrderableVersion, ProductOrderableWebsite, ProductOrderableLastUpdatedAt, ProductOrderableForkCount, ProductOrderableStarCount:
+	case ProductOrderableDiscoveredAt, ProductOrderableLastIndexedAt, ProductOrderableXid, ProductOrderablePlatformID, ProductOrderableName, ProductOrderableDescription, ProductOrderableDocumentationLanguage, ProductOrderableVersion, ProductOrderableWebsite, ProductOrderableLastUpdatedAt, ProductOrderableMissingSince, ProductOrderableValidationWarnings, ProductOrderableForkCount, ProductOrderableStarCount, ProductOrderableStarGrowth, ProductOrderableForkGrowth:
 		return true
 	}
 	return false
@@ -4714,6 +5071,8 @@ const (
 	ProductStateDeprecated ProductState = "DEPRECATED"
 	// Product source doesn't exists anymore, but its entry is still in the database.
 	ProductStateMissing ProductState = "MISSING"
+	// Product is only known as the origin of a fork and has not been indexed yet. Apart from its identity and data source, it has no information.
+	ProductStatePlaceholder ProductState = "PLACEHOLDER"
 )
 
 var AllProductState = []ProductState{
@@ -4723,11 +5082,12 @@ var AllProductState = []ProductState{
 	ProductStateArchived,
 	ProductStateDeprecated,
 	ProductStateMissing,
+	ProductStatePlaceholder,
 }
 
 func (e ProductState) IsValid() bool {
 	switch e {
-	case ProductStateUndetermined, ProductStateActive, ProductStateInactive, ProductStateArchived, ProductStateDeprecated, ProductStateMissing:
+	case ProductStateUndetermined, ProductStateActive, ProductStateInactive, ProductStateArchived, ProductStateDeprecated, ProductStateMissing, ProductStatePlaceholder:
 		return true
 	}
 	return false
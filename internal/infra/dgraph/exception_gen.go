@@ -0,0 +1,246 @@
+// Code generated by codegen, DO NOT EDIT.
+
+package dgraph
+
+import (
+	"context"
+
+	"losh/internal/core/product/models"
+	"losh/internal/infra/dgraph/dgclient"
+	"losh/internal/lib/net/request"
+)
+
+// make sure the struct implements the interface
+var _ ExceptionRepository = (*DgraphRepository)(nil)
+
+// ExceptionRepository is an interface for getting and saving `Exception` objects to a repository.
+type ExceptionRepository interface {
+	GetException(ctx context.Context, id, xid *string) (*models.Exception, error)
+	GetExceptions(ctx context.Context, filter *dgclient.ExceptionFilter, order *dgclient.ExceptionOrder, first *int64, offset *int64) ([]*models.Exception, int64, error)
+	GetAllExceptions(ctx context.Context) ([]*models.Exception, int64, error)
+	CreateException(ctx context.Context, input *models.Exception) error
+	CreateExceptions(ctx context.Context, input []*models.Exception) error
+	UpdateException(ctx context.Context, input *models.Exception) error
+	DeleteException(ctx context.Context, id, xid *string) error
+	DeleteAllExceptions(ctx context.Context) error
+}
+
+var (
+	errGetExceptionStr    = "failed to get exception(s)"
+	errSaveExceptionStr   = "failed to save exception(s)"
+	errDeleteExceptionStr = "failed to delete exception(s)"
+)
+
+// GetException returns a `Exception` object by its ID.
+func (dr *DgraphRepository) GetException(ctx context.Context, id, xid *string) (*models.Exception, error) {
+	var rspData interface{}
+	if id != nil {
+		dr.log.Debugw("get Exception", "id", *id)
+		rsp, err := dr.client.GetExceptionByID(ctx, *id)
+		if err != nil {
+			return nil, WrapRepoError(err, errGetExceptionStr).Add("exceptionId", id)
+		}
+		rspData = rsp.GetException
+	} else if xid != nil {
+		dr.log.Debugw("get Exception", "xid", *xid)
+		rsp, err := dr.client.GetExceptionByXid(ctx, *xid)
+		if err != nil {
+			return nil, WrapRepoError(err, errGetExceptionStr).Add("exceptionXid", xid)
+		}
+		rspData = rsp.GetException
+	} else {
+		panic("must specify id or xid")
+	}
+
+	if rspData == nil {
+		return nil, nil
+	}
+	ret := &models.Exception{}
+	if err := dr.copier.CopyTo(rspData, ret); err != nil {
+		panic(err)
+	}
+	return ret, nil
+}
+
+// GetExceptionID returns the ID of an existing `Exception` object.
+func (dr *DgraphRepository) GetExceptionID(ctx context.Context, xid *string) (*string, error) {
+	if xid != nil {
+		dr.log.Debugw("get Exception", "xid", *xid)
+		rsp, err := dr.client.GetExceptionID(ctx, *xid)
+		if err != nil {
+			return nil, WrapRepoError(err, errGetExceptionStr).Add("exceptionXid", xid)
+		}
+		if rsp.GetException == nil {
+			return nil, nil
+		}
+		return &rsp.GetException.ID, nil
+	}
+
+	panic("must specify xid")
+}
+
+// GetExceptions returns a list of `Exception` objects matching the filter criteria.
+func (dr *DgraphRepository) GetExceptions(ctx context.Context, filter *dgclient.ExceptionFilter, order *dgclient.ExceptionOrder, first *int64, offset *int64) ([]*models.Exception, int64, error) {
+	dr.log.Debugw("get Exceptions")
+	rsp, err := dr.client.GetExceptions(ctx, filter, order, first, offset)
+	if err != nil {
+		return nil, 0, WrapRepoError(err, errGetExceptionStr)
+	}
+	ret := make([]*models.Exception, 0, len(rsp.QueryException))
+	if err = dr.copier.CopyTo(rsp.QueryException, &ret); err != nil {
+		panic(err)
+	}
+	return ret, *rsp.AggregateException.Count, nil
+}
+
+// GetAllExceptions returns a list of all `Exception` objects.
+func (dr *DgraphRepository) GetAllExceptions(ctx context.Context) ([]*models.Exception, int64, error) {
+	return dr.GetExceptions(ctx, nil, nil, nil, nil)
+}
+
+// GetExceptionWithCustomQuery returns a `Exception` object by its ID.
+// The given query controls the amount of information to be returned.
+func (dr *DgraphRepository) GetExceptionWithCustomQuery(ctx context.Context, operationName, query string, id, xid *string) (*models.Exception, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: operationName,
+		Query:         query,
+		Variables: map[string]interface{}{
+			"id":  id,
+			"xid": xid,
+		},
+	}
+	rsp := struct {
+		Exception *models.Exception "json:\"getException\" graphql:\"getException\""
+	}{}
+	dr.log.Debugw("get Exception with custom query")
+	if err := dr.requester.Do(req, &rsp); err != nil {
+		return nil, WrapRepoError(err, errGetExceptionStr)
+	}
+	return rsp.Exception, nil
+}
+
+// GetExceptionsWithCustomQuery returns a list of `Exception` objects matching the filter criteria.
+// The given query controls the amount of information to be returned.
+func (dr *DgraphRepository) GetExceptionsWithCustomQuery(ctx context.Context, operationName, query string, filter *dgclient.ExceptionFilter, order *dgclient.ExceptionOrder, first *int64, offset *int64) ([]*models.Exception, error) {
+	req := request.GraphQLRequest{
+		Ctx:           ctx,
+		OperationName: operationName,
+		Query:         query,
+		Variables: map[string]interface{}{
+			"filter": filter,
+			"order":  order,
+			"first":  first,
+			"offset": offset,
+		},
+	}
+	rsp := struct {
+		Exceptions []*models.Exception "json:\"queryException\" graphql:\"queryException\""
+	}{}
+	dr.log.Debugw("get Exceptions with custom query")
+	if err := dr.requester.Do(req, &rsp); err != nil {
+		return nil, WrapRepoError(err, errGetExceptionStr)
+	}
+	return rsp.Exceptions, nil
+}
+
+// GetAllExceptionsWithCustomQuery returns a list of all `Exception` objects.
+func (dr *DgraphRepository) GetAllExceptionsWithCustomQuery(ctx context.Context, operationName, query string) ([]*models.Exception, error) {
+	return dr.GetExceptionsWithCustomQuery(ctx, operationName, query, nil, nil, nil, nil)
+}
+
+// CreateException creates a new `Exception` object.
+// After successful creation the ID field of the input will be populated with
+// the ID assigned by the DB.
+func (dr *DgraphRepository) CreateException(ctx context.Context, input *models.Exception) error {
+	dr.log.Debugw("create Exception", []interface{}{"xid", *input.Xid}...)
+	inputData := dgclient.AddExceptionInput{}
+	dr.copyORMStruct(input, &inputData)
+	rsp, err := dr.client.CreateExceptions(ctx, []*dgclient.AddExceptionInput{&inputData})
+	if err != nil {
+		return WrapRepoError(err, "failed to create exception").
+			Add("exceptionId", input.ID).Add("exceptionXid", input.Xid)
+	}
+	// save ID from response
+	input.ID = &rsp.AddException.Exception[0].ID
+	return nil
+}
+
+// CreateExceptions creates new `Exception` objects.
+// After successful creation the ID field of the input will be populated with
+// the ID assigned by the DB.
+func (dr *DgraphRepository) CreateExceptions(ctx context.Context, input []*models.Exception) error {
+	inputData := make([]*dgclient.AddExceptionInput, 0, len(input))
+	for _, v := range input {
+		iv := &dgclient.AddExceptionInput{}
+		dr.copyORMStruct(v, iv)
+		inputData = append(inputData, iv)
+	}
+
+	dr.log.Debugw("create Exceptions")
+	rsp, err := dr.client.CreateExceptions(ctx, inputData)
+	if err != nil {
+		return WrapRepoError(err, "failed to create exceptions")
+	}
+
+	// save ID from response
+	for i, v := range input {
+		v.ID = &rsp.AddException.Exception[i].ID
+	}
+
+	return nil
+}
+
+// UpdateException updates an existing `Exception` object.
+func (dr *DgraphRepository) UpdateException(ctx context.Context, input *models.Exception) error {
+	dr.log.Debugw("update Exception", []interface{}{"id", *input.ID, "xid", *input.Xid}...)
+	if *input.ID == "" {
+		return WrapRepoError(nil, "missing ID").Add("exceptionXid", input.Xid)
+	}
+	patch := &dgclient.ExceptionPatch{}
+	dr.copyORMStruct(input, patch)
+	patch.Xid = nil
+	inputData := dgclient.UpdateExceptionInput{
+		Filter: dgclient.ExceptionFilter{
+			ID: []string{*input.ID},
+		},
+		Set: patch,
+	}
+	_, err := dr.client.UpdateExceptions(ctx, inputData)
+	if err != nil {
+		return WrapRepoError(err, "failed to update exception").
+			Add("exceptionId", *input.ID).Add("exceptionXid", input.Xid)
+	}
+	return nil
+}
+
+// DeleteException deletes a `Exception` object.
+func (dr *DgraphRepository) DeleteException(ctx context.Context, id, xid *string) error {
+	delFilter := dgclient.ExceptionFilter{}
+	if id != nil && xid != nil {
+		return NewRepoError("must specify either id or xid")
+	}
+	if id != nil {
+		delFilter.ID = []string{*id}
+	}
+	if xid != nil {
+		delFilter.Xid = &dgclient.StringHashFilterStringRegExpFilterStringTermFilter{Eq: xid}
+	}
+
+	dr.log.Debugw("delete Exception")
+	if _, err := dr.client.DeleteExceptions(ctx, delFilter); err != nil {
+		return WrapRepoError(err, errDeleteExceptionStr).
+			Add("exceptionId", id).Add("exceptionXid", xid)
+	}
+	return nil
+}
+
+// DeleteAllExceptions deletes all `Exception` objects.
+func (dr *DgraphRepository) DeleteAllExceptions(ctx context.Context) error {
+	delFilter := dgclient.ExceptionFilter{}
+	dr.log.Debugw("delete all Exception")
+	if _, err := dr.client.DeleteExceptions(ctx, delFilter); err != nil {
+		return WrapRepoError(err, errDeleteExceptionStr)
+	}
+	return nil
+}
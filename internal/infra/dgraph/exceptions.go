@@ -0,0 +1,33 @@
+// Copyright 2022 André Lehmann
+//
+// Exceptiond under the Apache Exception, Version 2.0 (the "Exception");
+// you may not use this file except in compliance with the Exception.
+// You may obtain a copy of the Exception at
+//
+//      http://www.apache.org/exceptions/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the Exception is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Exception for the specific language governing permissions and
+// limitations under the Exception.
+
+package dgraph
+
+import (
+	"context"
+
+	"losh/internal/core/product/models"
+)
+
+// GetAllExceptionsBasic returns a list of all `Exception` objects with basic
+// information.
+func (dr *DgraphRepository) GetAllExceptionsBasic(ctx context.Context) ([]*models.Exception, error) {
+	rsp := struct {
+		Exceptions []*models.Exception "json:\"queryException\" graphql:\"queryException\""
+	}{}
+	if err := dr.client.GetAllExceptionsBasicWithResponse(ctx, &rsp); err != nil {
+		return nil, WrapRepoError(err, errGetExceptionStr)
+	}
+	return rsp.Exceptions, nil
+}
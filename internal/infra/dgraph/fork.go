@@ -0,0 +1,197 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+const errCreateProductPlaceholderStr = "failed to create product placeholder"
+
+// forkTreeFields are the fields of the products in a fork tree.
+const forkTreeFields = `
+	uid
+	Product.xid
+	Product.name
+	Product.state
+	Product.starCount
+	Product.dataSource: CrawlerMeta.dataSource {
+		uid
+		Repository.url
+	}`
+
+// maxForksPerProduct is the maximum number of forks retrieved per product in
+// a fork tree. The most popular forks are returned first.
+const maxForksPerProduct = 20
+
+// dqlProductPlaceholder is the DQL representation of a product placeholder.
+type dqlProductPlaceholder struct {
+	UID           string            `json:"uid"`
+	Type          []string          `json:"dgraph.type"`
+	DiscoveredAt  time.Time         `json:"CrawlerMeta.discoveredAt"`
+	LastIndexedAt time.Time         `json:"CrawlerMeta.lastIndexedAt"`
+	DataSource    map[string]string `json:"CrawlerMeta.dataSource"`
+	Xid           string            `json:"Product.xid"`
+	Name          string            `json:"Product.name"`
+	State         string            `json:"Product.state"`
+}
+
+// CreateProductPlaceholder creates a placeholder for a product, that is only
+// known as the origin of a fork. A placeholder lacks most of the mandatory
+// fields of a product and is therefore created using DQL. Nothing is created,
+// if a product with the same Xid already exists. The ID field of the input will
+// be populated with the ID of the new or existing product.
+func (dr *DgraphRepository) CreateProductPlaceholder(ctx context.Context, input *models.Product) error {
+	dr.log.Debugw("create Product placeholder", []interface{}{"xid", *input.Xid}...)
+	if input.DataSource == nil || input.DataSource.ID == nil {
+		return NewRepoError("missing data source ID").Add("productXid", input.Xid)
+	}
+	setJSON, err := json.Marshal(dqlProductPlaceholder{
+		UID:           "_:placeholder",
+		Type:          []string{"Product", "Node", "CrawlerMeta"},
+		DiscoveredAt:  input.DiscoveredAt.UTC(),
+		LastIndexedAt: input.LastIndexedAt.UTC(),
+		DataSource:    map[string]string{"uid": *input.DataSource.ID},
+		Xid:           *input.Xid,
+		Name:          *input.Name,
+		State:         string(*input.State),
+	})
+	if err != nil {
+		return WrapRepoError(err, errCreateProductPlaceholderStr).Add("productXid", input.Xid)
+	}
+
+	req := &api.Request{
+		Query: `query q($xid: string) {
+			p as var(func: eq(Product.xid, $xid))
+			q(func: uid(p)) {uid}
+		}`,
+		Vars:      map[string]string{"$xid": *input.Xid},
+		Mutations: []*api.Mutation{{Cond: `@if(eq(len(p), 0))`, SetJson: setJSON}},
+		CommitNow: true,
+	}
+	rsp, err := dr.dgraphClient.NewTxn().Do(ctx, req)
+	if err != nil {
+		return WrapRepoError(err, errCreateProductPlaceholderStr).Add("productXid", input.Xid)
+	}
+
+	// save ID of the new or existing product
+	if uid, ok := rsp.Uids["placeholder"]; ok {
+		input.ID = &uid
+		return nil
+	}
+	var rspData struct {
+		Q []struct {
+			UID string `json:"uid"`
+		} `json:"q"`
+	}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil || len(rspData.Q) == 0 {
+		return WrapRepoError(err, errCreateProductPlaceholderStr).Add("productXid", input.Xid)
+	}
+	input.ID = &rspData.Q[0].UID
+	return nil
+}
+
+// GetProductForkTree returns the product with the given ID together with its
+// lineage and forks. The lineage is available through the `ForkOf` field up to
+// the given depth and the forks through the `Forks` field down to the given
+// depth. Only the basic information of the products is returned. It returns
+// nil, if the product doesn't exist.
+func (dr *DgraphRepository) GetProductForkTree(ctx context.Context, id string, depth int) (*models.Product, error) {
+	dr.log.Debugw("get Product fork tree", "id", id)
+
+	query := `query q($id: string) {
+	q(func: uid($id)) @filter(type(Product)) {` + forkTreeFields +
+		nestedForkTreeSelection("Product.forkOf", "", depth) +
+		nestedForkTreeSelection("Product.forks", "(orderdesc: Product.starCount, first: "+strconv.Itoa(maxForksPerProduct)+")", depth) + `
+	}
+}`
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$id": id})
+	if err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+
+	var rspData map[string]interface{}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+	rawRes, _ := rspData["q"].([]interface{})
+	if len(rawRes) == 0 {
+		return nil, nil
+	}
+	ret := make([]*models.Product, 0, 1)
+	if err = dr.dqlCopier.CopyTo(rawRes, &ret); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+	return ret[0], nil
+}
+
+const productPlaceholderQuery = `query q($id: string) {
+	q(func: uid($id)) @filter(type(Product) AND eq(Product.state, "PLACEHOLDER")) {` + forkTreeFields + `
+	}
+}`
+
+// GetProductPlaceholder returns the product placeholder with the given ID. It
+// returns nil, if the ID doesn't belong to a placeholder. Placeholders lack the
+// mandatory fields of a product and therefore can't be retrieved using GraphQL.
+func (dr *DgraphRepository) GetProductPlaceholder(ctx context.Context, id string) (*models.Product, error) {
+	dr.log.Debugw("get Product placeholder", "id", id)
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, productPlaceholderQuery, map[string]string{"$id": id})
+	if err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+
+	var rspData map[string]interface{}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+	rawRes, _ := rspData["q"].([]interface{})
+	if len(rawRes) == 0 {
+		return nil, nil
+	}
+	ret := make([]*models.Product, 0, 1)
+	if err = dr.dqlCopier.CopyTo(rawRes, &ret); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productId", id)
+	}
+	return ret[0], nil
+}
+
+// nestedForkTreeSelection creates a selection of the given predicate, that is
+// nested up to the given depth.
+func nestedForkTreeSelection(predicate, params string, depth int) string {
+	var b strings.Builder
+	for i := 0; i < depth; i++ {
+		b.WriteString("\n")
+		b.WriteString(predicate)
+		b.WriteString(" ")
+		if params != "" {
+			b.WriteString(params)
+			b.WriteString(" ")
+		}
+		b.WriteString("{")
+		b.WriteString(forkTreeFields)
+	}
+	for i := 0; i < depth; i++ {
+		b.WriteString("\n}")
+	}
+	return b.String()
+}
@@ -16,7 +16,13 @@ package dgraph
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
+	"losh/internal/core/product/models"
 	"losh/internal/infra/dgraph/dgclient"
 )
 
@@ -54,6 +60,8 @@ func (dr *DgraphRepository) GetNode(ctx context.Context, id string) (interface{}
 		node, err = dr.GetHost(ctx, &id, nil)
 	case "License":
 		node, err = dr.GetLicense(ctx, &id, nil)
+	case "Exception":
+		node, err = dr.GetException(ctx, &id, nil)
 	case "ManufacturingProcess":
 		node, err = dr.GetManufacturingProcess(ctx, &id)
 	case "Material":
@@ -63,6 +71,15 @@ func (dr *DgraphRepository) GetNode(ctx context.Context, id string) (interface{}
 	case "OpenSCADDimensions":
 		node, err = dr.GetOpenSCADDimensions(ctx, &id)
 	case "Product":
+		// placeholders can't be retrieved using GraphQL
+		var placeholder *models.Product
+		placeholder, err = dr.GetProductPlaceholder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if placeholder != nil {
+			return placeholder, nil
+		}
 		node, err = dr.GetProduct(ctx, &id, nil)
 	case "Repository":
 		node, err = dr.GetRepository(ctx, &id, nil)
@@ -113,3 +130,262 @@ func (dr *DgraphRepository) DeleteNode(ctx context.Context, id *string) error {
 func (dr *DgraphRepository) DeleteAllNodes(ctx context.Context) error {
 	return dr.DeleteNode(ctx, nil)
 }
+
+// ResolveNodeIDs looks up the IDs of all nodes of the set, that have no ID yet,
+// by their alternative IDs. The nodes are grouped by their type and alternative
+// ID and resolved in a single query. The IDs of the found nodes are set, others
+// are left untouched.
+func (dr *DgraphRepository) ResolveNodeIDs(ctx context.Context, nodes *models.NodeSet) error {
+	// group nodes by type and alternative ID
+	type nodeGroup struct {
+		typ   string
+		pred  string
+		nodes map[string][]models.Node
+	}
+	groups := []*nodeGroup{}
+	groupsByType := map[string]*nodeGroup{}
+	nodes.Range(func(node models.Node) bool {
+		altID := node.GetAltID()
+		if node.GetID() != nil || altID == nil {
+			return true
+		}
+		typ := reflect.Indirect(reflect.ValueOf(node)).Type().Name()
+		grp, ok := groupsByType[typ]
+		if !ok {
+			pred := altIDPredicate(node)
+			if pred == "" {
+				return true
+			}
+			grp = &nodeGroup{typ: typ, pred: pred, nodes: map[string][]models.Node{}}
+			groups = append(groups, grp)
+			groupsByType[typ] = grp
+		}
+		grp.nodes[*altID] = append(grp.nodes[*altID], node)
+		return true
+	})
+	if len(groups) == 0 {
+		return nil
+	}
+	dr.log.Debugw("resolve Node IDs", "types", len(groups))
+
+	// one query block per alternative ID; the IDs are passed as variables
+	type altIDBlock struct {
+		grp   *nodeGroup
+		altID string
+	}
+	blocks := []altIDBlock{}
+	varDecls := []string{}
+	vars := map[string]string{}
+	queryBlocks := []string{}
+	for _, grp := range groups {
+		altIDs := make([]string, 0, len(grp.nodes))
+		for altID := range grp.nodes {
+			altIDs = append(altIDs, altID)
+		}
+		sort.Strings(altIDs)
+		for _, altID := range altIDs {
+			i := strconv.Itoa(len(blocks))
+			x := "$x" + i
+			varDecls = append(varDecls, x+": string")
+			vars[x] = altID
+			queryBlocks = append(queryBlocks, "q"+i+"(func: eq("+grp.pred+", "+x+"), first: 1) @filter(type("+grp.typ+")) {uid}")
+			blocks = append(blocks, altIDBlock{grp: grp, altID: altID})
+		}
+	}
+	query := "query q(" + strings.Join(varDecls, ", ") + ") {\n\t" + strings.Join(queryBlocks, "\n\t") + "\n}"
+
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return WrapRepoError(err, errGetNodeStr).Add("types", len(groups))
+	}
+	var rspData map[string][]struct {
+		UID string `json:"uid"`
+	}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return WrapRepoError(err, errGetNodeStr).Add("types", len(groups))
+	}
+	for i, blk := range blocks {
+		res := rspData["q"+strconv.Itoa(i)]
+		if len(res) == 0 {
+			continue
+		}
+		for _, node := range blk.grp.nodes[blk.altID] {
+			if err = setNodeID(node, res[0].UID); err != nil {
+				return WrapRepoError(err, errGetNodeStr).Add("nodeType", blk.grp.typ)
+			}
+		}
+	}
+	return nil
+}
+
+// GetStoredNodes returns the stored versions of the given nodes in a single
+// query, keyed by their IDs. Only the scalar predicates and the IDs of the sub
+// nodes are loaded, which is sufficient to compare the nodes with their stored
+// versions. Nodes without an ID or that don't exist anymore are omitted.
+func (dr *DgraphRepository) GetStoredNodes(ctx context.Context, nodes []models.Node) (map[string]models.Node, error) {
+	stored := make(map[string]models.Node, len(nodes))
+	selections := map[reflect.Type]string{}
+	varDecls := []string{}
+	vars := map[string]string{}
+	blocks := []string{}
+	queried := []models.Node{}
+	for _, node := range nodes {
+		id := node.GetID()
+		if id == nil {
+			continue
+		}
+		ndeTyp := reflect.Indirect(reflect.ValueOf(node)).Type()
+		sel, ok := selections[ndeTyp]
+		if !ok {
+			sel = storedNodeSelection(ndeTyp)
+			selections[ndeTyp] = sel
+		}
+		i := strconv.Itoa(len(queried))
+		varDecls = append(varDecls, "$u"+i+": string")
+		vars["$u"+i] = *id
+		blocks = append(blocks, "q"+i+"(func: uid($u"+i+")) @filter(type("+ndeTyp.Name()+")) {"+sel+"}")
+		queried = append(queried, node)
+	}
+	if len(queried) == 0 {
+		return stored, nil
+	}
+	dr.log.Debugw("get stored Nodes", "count", len(queried))
+
+	query := "query q(" + strings.Join(varDecls, ", ") + ") {\n\t" + strings.Join(blocks, "\n\t") + "\n}"
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return nil, WrapRepoError(err, errGetNodeStr).Add("count", len(queried))
+	}
+	var rspData map[string][]map[string]json.RawMessage
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetNodeStr).Add("count", len(queried))
+	}
+	for i, node := range queried {
+		res := rspData["q"+strconv.Itoa(i)]
+		if len(res) == 0 {
+			continue
+		}
+		storedNode, err := decodeStoredNode(node, res[0])
+		if err != nil {
+			return nil, WrapRepoError(err, errGetNodeStr).Add("nodeId", *node.GetID())
+		}
+		stored[*node.GetID()] = storedNode
+	}
+	return stored, nil
+}
+
+// storedNodeSelection returns the DQL selection of the scalar predicates and
+// the IDs of the sub nodes of the given node type.
+func storedNodeSelection(ndeTyp reflect.Type) string {
+	sel := []string{"uid"}
+	for i := 0; i < ndeTyp.NumField(); i++ {
+		fld := ndeTyp.Field(i)
+		pred := storedPredicate(fld.Tag.Get("dql"))
+		if pred == "" || pred == "uid" {
+			continue
+		}
+		if isNodeFieldType(fld.Type) {
+			sel = append(sel, pred+" {uid}")
+		} else {
+			sel = append(sel, pred)
+		}
+	}
+	return strings.Join(sel, " ")
+}
+
+// isNodeFieldType returns true, if the field refers to sub nodes.
+func isNodeFieldType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Interface || typ.Implements(models.NodeType)
+}
+
+// decodeStoredNode decodes the stored predicates into a new node of the same
+// type as the given node. Sub nodes are represented by nodes, that only have
+// their ID set. The concrete types of sub nodes of interface type are taken
+// from the given node.
+func decodeStoredNode(node models.Node, res map[string]json.RawMessage) (models.Node, error) {
+	ndeVal := reflect.Indirect(reflect.ValueOf(node))
+	ndeTyp := ndeVal.Type()
+	strVal := reflect.New(ndeTyp)
+	strValInd := strVal.Elem()
+	for i := 0; i < ndeTyp.NumField(); i++ {
+		fld := ndeTyp.Field(i)
+		pred := storedPredicate(fld.Tag.Get("dql"))
+		raw, ok := res[pred]
+		if pred == "" || !ok {
+			continue
+		}
+		if !isNodeFieldType(fld.Type) {
+			ptr := reflect.New(fld.Type)
+			if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+				return nil, err
+			}
+			strValInd.Field(i).Set(ptr.Elem())
+			continue
+		}
+
+		var refs []struct {
+			UID string `json:"uid"`
+		}
+		if err := json.Unmarshal(raw, &refs); err != nil {
+			// single edges are returned as object
+			refs = refs[:0]
+			var ref struct {
+				UID string `json:"uid"`
+			}
+			if err = json.Unmarshal(raw, &ref); err != nil {
+				return nil, err
+			}
+			refs = append(refs, ref)
+		}
+		fldVal := ndeVal.Field(i)
+		if fld.Type.Kind() == reflect.Slice {
+			elemTyp := subNodeType(fld.Type.Elem(), fldVal)
+			if elemTyp == nil {
+				continue
+			}
+			slice := reflect.MakeSlice(fld.Type, 0, len(refs))
+			for _, ref := range refs {
+				slice = reflect.Append(slice, newNodeRef(elemTyp, ref.UID))
+			}
+			strValInd.Field(i).Set(slice)
+		} else if len(refs) > 0 {
+			elemTyp := subNodeType(fld.Type, fldVal)
+			if elemTyp == nil {
+				continue
+			}
+			strValInd.Field(i).Set(newNodeRef(elemTyp, refs[0].UID))
+		}
+	}
+	return strVal.Interface().(models.Node), nil
+}
+
+// subNodeType returns the concrete pointer type of the sub nodes of a field. For
+// fields of interface type, the type is taken from the given field value. It
+// returns nil, if the type cannot be determined.
+func subNodeType(typ reflect.Type, fldVal reflect.Value) reflect.Type {
+	if typ.Kind() != reflect.Interface {
+		return typ
+	}
+	if fldVal.Kind() == reflect.Slice {
+		for j := 0; j < fldVal.Len(); j++ {
+			if elem := fldVal.Index(j); !elem.IsNil() {
+				return elem.Elem().Type()
+			}
+		}
+		return nil
+	}
+	if fldVal.IsNil() {
+		return nil
+	}
+	return fldVal.Elem().Type()
+}
+
+// newNodeRef creates a node of the given pointer type, that only has its ID set.
+func newNodeRef(ptrTyp reflect.Type, uid string) reflect.Value {
+	ref := reflect.New(ptrTyp.Elem())
+	setNodeID(ref.Interface().(models.Node), uid)
+	return ref
+}
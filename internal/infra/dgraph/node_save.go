@@ -0,0 +1,477 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/internal/core/product/models"
+	"losh/internal/lib/util/reflectutil"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+// inverseEdge describes the inverse of an edge, which is maintained by the
+// GraphQL API (`@hasInverse`), but must be set manually when using DQL.
+type inverseEdge struct {
+	predicate string
+	isList    bool
+}
+
+// inverseEdges maps the predicates to their inverse predicates.
+var inverseEdges = map[string]inverseEdge{
+	"Product.releases":       {"Component.product", false},
+	"Component.product":      {"Product.releases", true},
+	"Product.renamedTo":      {"Product.renamedFrom", false},
+	"Product.renamedFrom":    {"Product.renamedTo", false},
+	"Product.forks":          {"Product.forkOf", false},
+	"Product.forkOf":         {"Product.forks", true},
+	"Product.licensor":       {"UserOrGroup.products", true},
+	"Product.category":       {"Category.products", true},
+	"Component.releases":     {"Component.releases", true},
+	"Component.components":   {"Component.components", true},
+	"Component.tsdc":         {"TechnologySpecificDocumentationCriteria.components", true},
+	"Component.compliesWith": {"TechnicalStandard.components", true},
+	"TechnologySpecificDocumentationCriteria.components": {"Component.tsdc", false},
+	"TechnicalStandard.components":                       {"Component.compliesWith", false},
+	"UserOrGroup.memberOf":                               {"Group.members", true},
+	"UserOrGroup.products":                               {"Product.licensor", false},
+	"Group.members":                                      {"UserOrGroup.memberOf", true},
+	"Category.parent":                                    {"Category.children", true},
+	"Category.children":                                  {"Category.parent", false},
+	"Category.products":                                  {"Product.category", false},
+	"Tag.aliases":                                        {"Tag.aliases", true},
+	"Tag.related":                                        {"Tag.related", true},
+	"ProductRevision.product":                            {"Product.revisions", true},
+	"PopularitySnapshot.product":                         {"Product.popularity", true},
+}
+
+// nodeUpsert builds a single DQL upsert request for a set of nodes.
+type nodeUpsert struct {
+	// refs holds the DQL references of the nodes, which are either the UID, a
+	// blank node or a `uid(var)` reference to a node resolved by its
+	// alternative ID.
+	refs map[models.Node]string
+	// objects holds the JSON objects to set for each node in the order of the
+	// node set.
+	objects map[models.Node]map[string]interface{}
+	order   []models.Node
+	// inverse holds the inverse edges to set for each node.
+	inverse map[models.Node]map[string]interface{}
+	// conds holds the conditions for objects, that must only be set, if the
+	// node doesn't exist yet.
+	conds map[models.Node]string
+	// deletes holds the JSON objects of the predicates to delete.
+	deletes []map[string]interface{}
+
+	// altRefs holds the references by predicate and alternative ID, so that
+	// distinct objects of the same node share a reference.
+	altRefs map[string]string
+
+	queryBlocks []string
+	queryVars   map[string]string
+	varDecls    []string
+
+	// err holds the first error, that occurred while adding the objects.
+	err error
+}
+
+// SaveNodes creates or updates all nodes of the set within a single upsert
+// transaction. Nodes without an ID are resolved by their alternative ID (e.g.
+// the xid) inside the transaction and created, if they don't exist yet.
+// Licenses and exceptions are never written, only referenced, and existing
+// products are never overwritten by placeholders. The time since when the
+// recovered products with the given IDs were missing is removed within the same
+// transaction. The IDs of the saved nodes are set afterwards. Either all nodes
+// are saved or none.
+func (dr *DgraphRepository) SaveNodes(ctx context.Context, nodes *models.NodeSet, recovered []string) error {
+	dr.log.Debugw("save Nodes", "count", nodes.Len())
+	if nodes.Len() == 0 && len(recovered) == 0 {
+		return nil
+	}
+
+	up := newNodeUpsert(nodes, recovered)
+	req, err := up.request()
+	if err != nil {
+		return WrapRepoError(err, errSaveNodeStr).Add("count", nodes.Len())
+	}
+	if len(req.Mutations) == 0 {
+		return nil
+	}
+	rsp, err := dr.dgraphClient.NewTxn().Do(ctx, req)
+	if err != nil {
+		return WrapRepoError(err, errSaveNodeStr).Add("count", nodes.Len())
+	}
+
+	// set IDs of the new and resolved nodes
+	var rspData map[string][]struct {
+		UID string `json:"uid"`
+	}
+	if len(rsp.Json) > 0 {
+		if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+			return WrapRepoError(err, errSaveNodeStr).Add("count", nodes.Len())
+		}
+	}
+	var idErr error
+	nodes.Range(func(node models.Node) bool {
+		ref, ok := up.refs[node]
+		if !ok || node.GetID() != nil {
+			return true
+		}
+		var uid string
+		switch {
+		case strings.HasPrefix(ref, "_:"):
+			uid = rsp.Uids[ref[2:]]
+		case strings.HasPrefix(ref, "uid("):
+			uid = rsp.Uids[ref]
+			if uid == "" {
+				// the query block name equals the variable name with a 'q' prefix
+				if res := rspData["q"+ref[4:len(ref)-1]]; len(res) > 0 {
+					uid = res[0].UID
+				}
+			}
+		}
+		if uid == "" {
+			idErr = NewRepoError("missing UID of saved node").Add("nodeType", reflect.TypeOf(node).String())
+			return false
+		}
+		if err := setNodeID(node, uid); err != nil {
+			idErr = WrapRepoError(err, errSaveNodeStr)
+			return false
+		}
+		return true
+	})
+	return idErr
+}
+
+// newNodeUpsert creates the upsert for the given nodes and recovered products.
+func newNodeUpsert(nodes *models.NodeSet, recovered []string) *nodeUpsert {
+	up := &nodeUpsert{
+		refs:      make(map[models.Node]string, nodes.Len()),
+		objects:   make(map[models.Node]map[string]interface{}, nodes.Len()),
+		inverse:   make(map[models.Node]map[string]interface{}),
+		conds:     make(map[models.Node]string),
+		altRefs:   make(map[string]string),
+		queryVars: make(map[string]string),
+	}
+	nodes.Range(func(node models.Node) bool {
+		up.addRef(node)
+		return true
+	})
+	nodes.Range(func(node models.Node) bool {
+		up.addObject(node)
+		return true
+	})
+	up.clearMissingSince(recovered)
+	return up
+}
+
+// addRef determines how the node is referenced within the upsert request.
+func (up *nodeUpsert) addRef(node models.Node) {
+	if id := node.GetID(); id != nil {
+		up.refs[node] = *id
+		return
+	}
+	if isReferenceOnly(node) {
+		// cannot be referenced without being created
+		return
+	}
+	i := len(up.refs)
+	if altID := node.GetAltID(); altID != nil {
+		if pred := altIDPredicate(node); pred != "" {
+			key := pred + "\x00" + *altID
+			if ref, ok := up.altRefs[key]; ok {
+				up.refs[node] = ref
+				return
+			}
+			v := "v" + strconv.Itoa(i)
+			x := "$x" + strconv.Itoa(i)
+			up.varDecls = append(up.varDecls, x+": string")
+			up.queryVars[x] = *altID
+			up.queryBlocks = append(up.queryBlocks, "q"+strconv.Itoa(i)+"(func: eq("+pred+", "+x+"), first: 1) {"+v+" as uid}")
+			up.refs[node] = "uid(" + v + ")"
+			up.altRefs[key] = up.refs[node]
+			return
+		}
+	}
+	up.refs[node] = "_:n" + strconv.Itoa(i)
+}
+
+// ref returns the reference of the node. Nodes, that are not part of the set,
+// are referenced by their ID.
+func (up *nodeUpsert) ref(node models.Node) (string, bool) {
+	if ref, ok := up.refs[node]; ok {
+		return ref, true
+	}
+	if id := node.GetID(); id != nil {
+		return *id, true
+	}
+	return "", false
+}
+
+// addObject adds the JSON object of the node and the inverse edges of its
+// edges to the request.
+func (up *nodeUpsert) addObject(node models.Node) {
+	ref, ok := up.refs[node]
+	if !ok || isReferenceOnly(node) {
+		return
+	}
+	if prd, ok := node.(*models.Product); ok && prd.IsPlaceholder() {
+		// existing products are never overwritten by placeholders
+		if prd.ID != nil {
+			return
+		}
+		if strings.HasPrefix(ref, "uid(") {
+			up.conds[node] = "@if(eq(len(" + ref[4:len(ref)-1] + "), 0))"
+		}
+	}
+
+	obj := map[string]interface{}{"uid": ref, "dgraph.type": dqlTypes(node)}
+	up.objects[node] = obj
+	up.order = append(up.order, node)
+
+	ndeVal := reflect.Indirect(reflect.ValueOf(node))
+	ndeTyp := ndeVal.Type()
+	for i := 0; i < ndeTyp.NumField(); i++ {
+		fld := ndeTyp.Field(i)
+		pred := fld.Tag.Get("dql")
+		if pred == "" || pred == "uid" || !fld.IsExported() {
+			continue
+		}
+		pred = storedPredicate(pred)
+		fldVal := ndeVal.Field(i)
+		fldValInd := reflectutil.Indirect(fldVal)
+
+		switch fldValInd.Kind() {
+		case reflect.Invalid:
+			continue
+
+		case reflect.Slice:
+			if !fldValInd.Type().Elem().Implements(models.NodeType) {
+				if fldValInd.Len() == 0 {
+					continue
+				}
+				if fldValInd.Type().Elem().Kind() == reflect.Struct {
+					// lists of structs (e.g. the changes of a revision) are
+					// stored as JSON strings
+					value, err := json.Marshal(fldValInd.Interface())
+					if err != nil {
+						up.err = err
+						return
+					}
+					obj[pred] = string(value)
+					continue
+				}
+				obj[pred] = fldValInd.Interface()
+				continue
+			}
+			edges := make([]map[string]string, 0, fldValInd.Len())
+			for j := 0; j < fldValInd.Len(); j++ {
+				if sub, ok := models.AssertNode(fldValInd.Index(j).Interface()); ok {
+					if subRef, ok := up.ref(sub); ok {
+						edges = append(edges, map[string]string{"uid": subRef})
+						up.addInverse(sub, pred, ref)
+					}
+				}
+			}
+			if len(edges) > 0 {
+				obj[pred] = edges
+			}
+
+		case reflect.Struct:
+			if fldValInd.Type() == timeType {
+				obj[pred] = fldValInd.Interface().(time.Time).UTC()
+				continue
+			}
+			if sub, ok := models.AssertNode(fldVal.Interface()); ok {
+				if subRef, ok := up.ref(sub); ok {
+					obj[pred] = map[string]string{"uid": subRef}
+					up.addInverse(sub, pred, ref)
+				}
+			}
+
+		default:
+			// scalars and enums
+			obj[pred] = fldValInd.Interface()
+		}
+	}
+}
+
+// addInverse adds the inverse of the edge `pred` pointing to the given node.
+func (up *nodeUpsert) addInverse(node models.Node, pred, fromRef string) {
+	inv, ok := inverseEdges[pred]
+	if !ok {
+		return
+	}
+	ref, ok := up.ref(node)
+	if !ok {
+		return
+	}
+	obj, ok := up.inverse[node]
+	if !ok {
+		obj = map[string]interface{}{"uid": ref}
+		up.inverse[node] = obj
+	}
+	if !inv.isList {
+		obj[inv.predicate] = map[string]string{"uid": fromRef}
+		return
+	}
+	edges, _ := obj[inv.predicate].([]map[string]string)
+	for _, e := range edges {
+		if e["uid"] == fromRef {
+			return
+		}
+	}
+	obj[inv.predicate] = append(edges, map[string]string{"uid": fromRef})
+}
+
+// clearMissingSince removes the time since when the products with the given
+// IDs were missing.
+func (up *nodeUpsert) clearMissingSince(ids []string) {
+	for _, id := range ids {
+		up.deletes = append(up.deletes, map[string]interface{}{"uid": id, "Product.missingSince": nil})
+	}
+}
+
+// request returns the upsert request. Objects without a condition are combined
+// into a single mutation.
+func (up *nodeUpsert) request() (*api.Request, error) {
+	if up.err != nil {
+		return nil, up.err
+	}
+	req := &api.Request{CommitNow: true}
+	if len(up.queryBlocks) > 0 {
+		req.Query = "query q(" + strings.Join(up.varDecls, ", ") + ") {\n\t" + strings.Join(up.queryBlocks, "\n\t") + "\n}"
+		req.Vars = up.queryVars
+	}
+
+	unconditional := make([]map[string]interface{}, 0, len(up.objects)+len(up.inverse))
+	for _, node := range up.order {
+		obj := up.objects[node]
+		cond, ok := up.conds[node]
+		if !ok {
+			// merge the inverse edges into the object of the node
+			for k, v := range up.inverse[node] {
+				obj[k] = v
+			}
+			delete(up.inverse, node)
+			unconditional = append(unconditional, obj)
+			continue
+		}
+		setJSON, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		req.Mutations = append(req.Mutations, &api.Mutation{Cond: cond, SetJson: setJSON})
+	}
+	// inverse edges of nodes, that are not written or only written
+	// conditionally, are set regardless of the condition
+	up.rangeInverse(func(obj map[string]interface{}) {
+		unconditional = append(unconditional, obj)
+	})
+	if len(unconditional) > 0 {
+		setJSON, err := json.Marshal(unconditional)
+		if err != nil {
+			return nil, err
+		}
+		req.Mutations = append(req.Mutations, &api.Mutation{SetJson: setJSON})
+	}
+	if len(up.deletes) > 0 {
+		delJSON, err := json.Marshal(up.deletes)
+		if err != nil {
+			return nil, err
+		}
+		req.Mutations = append(req.Mutations, &api.Mutation{DeleteJson: delJSON})
+	}
+	return req, nil
+}
+
+// rangeInverse calls fn for the remaining inverse edges in a stable order.
+func (up *nodeUpsert) rangeInverse(fn func(obj map[string]interface{})) {
+	refs := make([]string, 0, len(up.inverse))
+	byRef := make(map[string]map[string]interface{}, len(up.inverse))
+	for _, obj := range up.inverse {
+		ref := obj["uid"].(string)
+		refs = append(refs, ref)
+		byRef[ref] = obj
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		fn(byRef[ref])
+	}
+}
+
+// isReferenceOnly returns true for nodes, that are maintained separately and
+// are therefore only referenced, but never written when saving nodes.
+func isReferenceOnly(node models.Node) bool {
+	switch node.(type) {
+	case *models.License, *models.Exception:
+		return true
+	}
+	return false
+}
+
+// altIDPredicate returns the DQL predicate of the alternative ID of the node.
+func altIDPredicate(node models.Node) string {
+	ndeTyp := reflect.Indirect(reflect.ValueOf(node)).Type()
+	for i := 0; i < ndeTyp.NumField(); i++ {
+		fld := ndeTyp.Field(i)
+		if fld.Tag.Get("altID") == "true" {
+			return fld.Tag.Get("dql")
+		}
+	}
+	return ""
+}
+
+// dqlTypes returns the DQL types of the node including its interfaces.
+func dqlTypes(node models.Node) []string {
+	types := []string{reflect.Indirect(reflect.ValueOf(node)).Type().Name(), "Node"}
+	switch node.(type) {
+	case models.CrawlerMeta:
+		types = append(types, "CrawlerMeta")
+	case models.UserOrGroup:
+		types = append(types, "UserOrGroup")
+	}
+	return types
+}
+
+// setNodeID sets the ID field of the node.
+func setNodeID(node models.Node, uid string) error {
+	ndeVal := reflect.Indirect(reflect.ValueOf(node))
+	ndeTyp := ndeVal.Type()
+	for i := 0; i < ndeTyp.NumField(); i++ {
+		if ndeTyp.Field(i).Tag.Get("id") == "true" {
+			ndeVal.Field(i).Set(reflect.ValueOf(&uid))
+			return nil
+		}
+	}
+	return NewRepoError("node has no ID field").Add("nodeType", ndeTyp.Name())
+}
+
+// storedPredicate returns the predicate, under which the value of a field with
+// the given DQL tag is stored. The data source is defined by the `CrawlerMeta`
+// interface and therefore stored as `CrawlerMeta.dataSource` for all types.
+func storedPredicate(pred string) string {
+	if strings.HasSuffix(pred, ".dataSource") {
+		return "CrawlerMeta.dataSource"
+	}
+	return pred
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+// decodeObjects decodes the JSON objects of the mutation keyed by their UID
+// reference.
+func decodeObjects(t *testing.T, data []byte) map[string]map[string]interface{} {
+	t.Helper()
+	var objs []map[string]interface{}
+	if err := json.Unmarshal(data, &objs); err != nil {
+		t.Fatalf("failed to decode mutation %s: %v", data, err)
+	}
+	byRef := make(map[string]map[string]interface{}, len(objs))
+	for _, obj := range objs {
+		byRef[obj["uid"].(string)] = obj
+	}
+	return byRef
+}
+
+func edgeRefs(value interface{}) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return []string{v["uid"].(string)}
+	case []interface{}:
+		refs := make([]string, 0, len(v))
+		for _, e := range v {
+			refs = append(refs, e.(map[string]interface{})["uid"].(string))
+		}
+		return refs
+	}
+	return nil
+}
+
+func TestNodeUpsertRequest(t *testing.T) {
+	now := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	prdXid, cmpXid, name, stars := "github.com/a/b", "github.com/a/b/main/okh.toml", "b", int64(42)
+	cmp := &models.Component{Xid: &cmpXid, Name: &name}
+	origin := models.NewProductPlaceholder("github.com/x/b", "b", nil, now)
+	prd := &models.Product{Xid: &prdXid, Name: &name, Release: cmp, Releases: []*models.Component{cmp}, ForkOf: origin, StarCount: &stars}
+	existingID := "0x5"
+	rev := &models.ProductRevision{
+		Product:   &models.Product{ID: &existingID},
+		CreatedAt: &now,
+		Changes:   []models.RevisionChange{{Field: "name", Old: "a", New: "b"}},
+	}
+	snp := &models.PopularitySnapshot{Product: prd, CreatedAt: &now, StarCount: &stars}
+
+	nodes := models.NewNodeSet()
+	for _, n := range []models.Node{prd, cmp, origin, rev, snp} {
+		nodes.AddBack(n)
+	}
+	req, err := newNodeUpsert(nodes, []string{"0x9"}).request()
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	// nodes without an ID are resolved by their alternative ID
+	if !strings.Contains(req.Query, "$x0: string") ||
+		!strings.Contains(req.Query, "q0(func: eq(Product.xid, $x0), first: 1) {v0 as uid}") ||
+		!strings.Contains(req.Query, "q1(func: eq(Component.xid, $x1), first: 1) {v1 as uid}") {
+		t.Errorf("unexpected query:\n%s", req.Query)
+	}
+	if req.Vars["$x0"] != prdXid || req.Vars["$x1"] != cmpXid {
+		t.Errorf("unexpected vars: %v", req.Vars)
+	}
+
+	var cond, set, del *api.Mutation
+	for _, mu := range req.Mutations {
+		switch {
+		case mu.Cond != "":
+			cond = mu
+		case len(mu.DeleteJson) > 0:
+			del = mu
+		default:
+			set = mu
+		}
+	}
+	if len(req.Mutations) != 3 || cond == nil || set == nil || del == nil {
+		t.Fatalf("unexpected mutations: %v", req.Mutations)
+	}
+
+	// placeholders are only created, if the product doesn't exist yet
+	if cond.Cond != "@if(eq(len(v2), 0))" {
+		t.Errorf("Cond = %q, want %q", cond.Cond, "@if(eq(len(v2), 0))")
+	}
+	var placeholder map[string]interface{}
+	if err := json.Unmarshal(cond.SetJson, &placeholder); err != nil {
+		t.Fatal(err)
+	}
+	if placeholder["uid"] != "uid(v2)" {
+		t.Errorf("conditional object = %v, want placeholder", placeholder)
+	}
+
+	objs := decodeObjects(t, set.SetJson)
+	tests := []struct {
+		ref  string
+		pred string
+		want []string
+	}{
+		{"uid(v0)", "Product.release", []string{"uid(v1)"}},
+		{"uid(v0)", "Product.forkOf", []string{"uid(v2)"}},
+		{"uid(v0)", "Product.popularity", []string{"_:n4"}},
+		// inverse edges
+		{"uid(v1)", "Component.product", []string{"uid(v0)"}},
+		{"uid(v2)", "Product.forks", []string{"uid(v0)"}},
+		{"0x5", "Product.revisions", []string{"_:n3"}},
+		{"_:n3", "ProductRevision.product", []string{"0x5"}},
+		{"_:n4", "PopularitySnapshot.product", []string{"uid(v0)"}},
+	}
+	for _, tt := range tests {
+		obj, ok := objs[tt.ref]
+		if !ok {
+			t.Errorf("missing object %s", tt.ref)
+			continue
+		}
+		if got := edgeRefs(obj[tt.pred]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.%s = %v, want %v", tt.ref, tt.pred, got, tt.want)
+		}
+	}
+
+	// the changes of a revision are stored as JSON string
+	if got := objs["_:n3"]["ProductRevision.changes"]; got != `[{"field":"name","old":"a","new":"b"}]` {
+		t.Errorf("ProductRevision.changes = %v", got)
+	}
+	if got := objs["_:n4"]["dgraph.type"]; !reflect.DeepEqual(got, []interface{}{"PopularitySnapshot", "Node"}) {
+		t.Errorf("dgraph.type = %v", got)
+	}
+
+	// recovered products are not missing anymore
+	if string(del.DeleteJson) != `[{"Product.missingSince":null,"uid":"0x9"}]` {
+		t.Errorf("DeleteJson = %s", del.DeleteJson)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"losh/internal/core/product/models"
+)
+
+func TestStoredNodeSelectionDataSource(t *testing.T) {
+	for _, node := range []models.Node{&models.Product{}, &models.Component{}, &models.File{}, &models.Software{}} {
+		typ := reflect.Indirect(reflect.ValueOf(node)).Type()
+		sel := storedNodeSelection(typ)
+		if !strings.Contains(sel, "CrawlerMeta.dataSource {uid}") {
+			t.Errorf("selection of %s lacks CrawlerMeta.dataSource: %s", typ.Name(), sel)
+		}
+		if strings.Contains(sel, typ.Name()+".dataSource") {
+			t.Errorf("selection of %s contains %s.dataSource: %s", typ.Name(), typ.Name(), sel)
+		}
+	}
+}
+
+func TestDecodeStoredNode(t *testing.T) {
+	name := "Motor Driver"
+	updated := &models.Product{
+		Name:       &name,
+		DataSource: &models.Repository{},
+		Tags:       []*models.Tag{{}},
+	}
+	var res map[string]json.RawMessage
+	err := json.Unmarshal([]byte(`{
+		"uid": "0x1",
+		"Product.name": "Motor Driver",
+		"CrawlerMeta.dataSource": {"uid": "0x2"},
+		"Product.tags": [{"uid": "0x3"}, {"uid": "0x4"}]
+	}`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := decodeStoredNode(updated, res)
+	if err != nil {
+		t.Fatalf("decodeStoredNode failed: %v", err)
+	}
+	prd := stored.(*models.Product)
+	if prd.Name == nil || *prd.Name != name {
+		t.Errorf("Name = %v, want %q", prd.Name, name)
+	}
+	if prd.DataSource == nil || prd.DataSource.ID == nil || *prd.DataSource.ID != "0x2" {
+		t.Errorf("DataSource = %+v, want ID 0x2", prd.DataSource)
+	}
+	if len(prd.Tags) != 2 || *prd.Tags[0].ID != "0x3" || *prd.Tags[1].ID != "0x4" {
+		t.Errorf("Tags = %+v, want IDs 0x3 and 0x4", prd.Tags)
+	}
+
+	// the stored data source is not reported as a change
+	updated.DataSource.ID = prd.DataSource.ID
+	for _, c := range models.DiffNode(stored, updated).Changes {
+		if c.Field == "DataSource" {
+			t.Errorf("unexpected change of the data source: %+v", c)
+		}
+	}
+}
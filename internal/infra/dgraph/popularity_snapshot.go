@@ -0,0 +1,265 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+const (
+	errGetPopularitySnapshotStr  = "failed to get popularity snapshot(s)"
+	errSavePopularitySnapshotStr = "failed to save popularity snapshot(s)"
+)
+
+// popularitySnapshotsSelection selects the snapshots of a product, that were
+// taken since `$since`, oldest first.
+const popularitySnapshotsSelection = `snapshots: Product.popularity (orderasc: PopularitySnapshot.createdAt) @filter(ge(PopularitySnapshot.createdAt, $since)) {
+			uid
+			PopularitySnapshot.createdAt
+			PopularitySnapshot.starCount
+			PopularitySnapshot.forkCount
+		}`
+
+const productsPopularityQuery = `
+query q($since: string, $first: int, $offset: int) {
+	q(func: has(Product.popularity), first: $first, offset: $offset) @filter(type(Product)) {
+		uid
+		Product.starCount
+		Product.forkCount
+		Product.starGrowth
+		Product.forkGrowth
+		` + popularitySnapshotsSelection + `
+	}
+}`
+
+// popularitySnapshotsResult is the result of a query of the snapshots of a
+// product.
+type popularitySnapshotsResult struct {
+	UID        string `json:"uid"`
+	StarCount  *int64 `json:"Product.starCount"`
+	ForkCount  *int64 `json:"Product.forkCount"`
+	StarGrowth *int64 `json:"Product.starGrowth"`
+	ForkGrowth *int64 `json:"Product.forkGrowth"`
+	Snapshots  []struct {
+		UID       string    `json:"uid"`
+		CreatedAt time.Time `json:"PopularitySnapshot.createdAt"`
+		StarCount *int64    `json:"PopularitySnapshot.starCount"`
+		ForkCount *int64    `json:"PopularitySnapshot.forkCount"`
+	} `json:"snapshots"`
+}
+
+// toModels converts the queried snapshots into models.
+func (r popularitySnapshotsResult) toModels() []*models.PopularitySnapshot {
+	productID := r.UID
+	snapshots := make([]*models.PopularitySnapshot, 0, len(r.Snapshots))
+	for _, s := range r.Snapshots {
+		s := s
+		snapshots = append(snapshots, &models.PopularitySnapshot{
+			ID:        &s.UID,
+			Product:   &models.Product{ID: &productID},
+			CreatedAt: &s.CreatedAt,
+			StarCount: s.StarCount,
+			ForkCount: s.ForkCount,
+		})
+	}
+	return snapshots
+}
+
+// GetPopularitySnapshots returns the popularity snapshots of the products with
+// the given IDs, that were taken since the given time, oldest first. The
+// snapshots are keyed by the product ID. All products are queried at once.
+func (dr *DgraphRepository) GetPopularitySnapshots(ctx context.Context, productIDs []string, since time.Time) (map[string][]*models.PopularitySnapshot, error) {
+	dr.log.Debugw("get PopularitySnapshots", "count", len(productIDs))
+	snapshots := make(map[string][]*models.PopularitySnapshot, len(productIDs))
+	if len(productIDs) == 0 {
+		return snapshots, nil
+	}
+
+	varDecls := []string{"$since: string"}
+	vars := map[string]string{"$since": since.UTC().Format(time.RFC3339)}
+	blocks := make([]string, 0, len(productIDs))
+	for i, id := range productIDs {
+		i := strconv.Itoa(i)
+		varDecls = append(varDecls, "$u"+i+": string")
+		vars["$u"+i] = id
+		blocks = append(blocks, "q"+i+"(func: uid($u"+i+")) @filter(type(Product)) {\n\t\tuid\n\t\t"+popularitySnapshotsSelection+"\n\t}")
+	}
+	query := "query q(" + strings.Join(varDecls, ", ") + ") {\n\t" + strings.Join(blocks, "\n\t") + "\n}"
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return nil, WrapRepoError(err, errGetPopularitySnapshotStr).Add("count", len(productIDs))
+	}
+	var rspData map[string][]popularitySnapshotsResult
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetPopularitySnapshotStr).Add("count", len(productIDs))
+	}
+	for i, id := range productIDs {
+		snapshots[id] = []*models.PopularitySnapshot{}
+		if res := rspData["q"+strconv.Itoa(i)]; len(res) > 0 {
+			snapshots[id] = res[0].toModels()
+		}
+	}
+	return snapshots, nil
+}
+
+// GetProductsPopularity returns a page of the products, that have popularity
+// snapshots, with their current counts and growth. It also returns the
+// snapshots, that were taken since the given time, keyed by the product ID.
+func (dr *DgraphRepository) GetProductsPopularity(ctx context.Context, since time.Time, first, offset int64) ([]*models.Product, map[string][]*models.PopularitySnapshot, error) {
+	dr.log.Debugw("get Products popularity", "first", first, "offset", offset)
+	vars := map[string]string{
+		"$since":  since.UTC().Format(time.RFC3339),
+		"$first":  strconv.FormatInt(first, 10),
+		"$offset": strconv.FormatInt(offset, 10),
+	}
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, productsPopularityQuery, vars)
+	if err != nil {
+		return nil, nil, WrapRepoError(err, errGetPopularitySnapshotStr).Add("first", first).Add("offset", offset)
+	}
+	var rspData struct {
+		Q []popularitySnapshotsResult `json:"q"`
+	}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, nil, WrapRepoError(err, errGetPopularitySnapshotStr).Add("first", first).Add("offset", offset)
+	}
+
+	products := make([]*models.Product, 0, len(rspData.Q))
+	snapshots := make(map[string][]*models.PopularitySnapshot, len(rspData.Q))
+	for _, res := range rspData.Q {
+		res := res
+		products = append(products, &models.Product{
+			ID:         &res.UID,
+			StarCount:  res.StarCount,
+			ForkCount:  res.ForkCount,
+			StarGrowth: res.StarGrowth,
+			ForkGrowth: res.ForkGrowth,
+		})
+		snapshots[res.UID] = res.toModels()
+	}
+	return products, snapshots, nil
+}
+
+// UpdateProductsGrowth sets the star and fork growth of the given products.
+// Growth values, that are nil, are deleted.
+func (dr *DgraphRepository) UpdateProductsGrowth(ctx context.Context, products []*models.Product) error {
+	dr.log.Debugw("update Products growth", "count", len(products))
+	if len(products) == 0 {
+		return nil
+	}
+
+	setObjs := []map[string]interface{}{}
+	delObjs := []map[string]interface{}{}
+	for i, prd := range products {
+		if prd.ID == nil {
+			return NewRepoError("missing product ID").Add("index", i)
+		}
+		setObj := map[string]interface{}{}
+		delObj := map[string]interface{}{}
+		for pred, val := range map[string]*int64{
+			"Product.starGrowth": prd.StarGrowth,
+			"Product.forkGrowth": prd.ForkGrowth,
+		} {
+			if val != nil {
+				setObj[pred] = *val
+			} else {
+				delObj[pred] = nil
+			}
+		}
+		if len(setObj) > 0 {
+			setObj["uid"] = *prd.ID
+			setObjs = append(setObjs, setObj)
+		}
+		if len(delObj) > 0 {
+			delObj["uid"] = *prd.ID
+			delObjs = append(delObjs, delObj)
+		}
+	}
+
+	mu := &api.Mutation{CommitNow: true}
+	var err error
+	if len(setObjs) > 0 {
+		if mu.SetJson, err = json.Marshal(setObjs); err != nil {
+			return WrapRepoError(err, errSaveProductStr).Add("count", len(products))
+		}
+	}
+	if len(delObjs) > 0 {
+		if mu.DeleteJson, err = json.Marshal(delObjs); err != nil {
+			return WrapRepoError(err, errSaveProductStr).Add("count", len(products))
+		}
+	}
+	if _, err = dr.dgraphClient.NewTxn().Mutate(ctx, mu); err != nil {
+		return WrapRepoError(err, errSaveProductStr).Add("count", len(products))
+	}
+	return nil
+}
+
+// CreatePopularitySnapshots creates the given snapshots and links them to their
+// products.
+func (dr *DgraphRepository) CreatePopularitySnapshots(ctx context.Context, snapshots []*models.PopularitySnapshot) error {
+	dr.log.Debugw("create PopularitySnapshots", "count", len(snapshots))
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	objs := make([]map[string]interface{}, 0, len(snapshots))
+	for i, snp := range snapshots {
+		if snp.Product == nil || snp.Product.ID == nil {
+			return NewRepoError("missing product ID").Add("index", i)
+		}
+		blankNode := "_:s" + strconv.Itoa(i)
+		obj := map[string]interface{}{
+			"uid":         blankNode,
+			"dgraph.type": []string{"PopularitySnapshot", "Node"},
+			// the inverse edge is maintained manually
+			"PopularitySnapshot.product": map[string]interface{}{
+				"uid":                *snp.Product.ID,
+				"Product.popularity": []map[string]string{{"uid": blankNode}},
+			},
+			"PopularitySnapshot.createdAt": snp.CreatedAt.UTC(),
+		}
+		if snp.StarCount != nil {
+			obj["PopularitySnapshot.starCount"] = *snp.StarCount
+		}
+		if snp.ForkCount != nil {
+			obj["PopularitySnapshot.forkCount"] = *snp.ForkCount
+		}
+		objs = append(objs, obj)
+	}
+
+	setJSON, err := json.Marshal(objs)
+	if err != nil {
+		return WrapRepoError(err, errSavePopularitySnapshotStr).Add("count", len(snapshots))
+	}
+	rsp, err := dr.dgraphClient.NewTxn().Mutate(ctx, &api.Mutation{SetJson: setJSON, CommitNow: true})
+	if err != nil {
+		return WrapRepoError(err, errSavePopularitySnapshotStr).Add("count", len(snapshots))
+	}
+
+	// save IDs of the new snapshots
+	for i, snp := range snapshots {
+		if uid, ok := rsp.Uids["s"+strconv.Itoa(i)]; ok {
+			snp.ID = &uid
+		}
+	}
+	return nil
+}
@@ -29,9 +29,15 @@ import (
 	"losh/web/core/search/parser"
 
 	"github.com/aisbergg/go-errors/pkg/errors"
+	"github.com/dgraph-io/dgo/v200/protos/api"
 	"github.com/golang-module/carbon/v2"
 )
 
+// listedProductsFilter excludes placeholders for the origins of forks, that
+// haven't been indexed yet, and renamed products from the search results.
+const listedProductsFilter = `@filter(NOT eq(Product.state, "PLACEHOLDER") AND NOT has(Product.renamedTo))`
+
+// selectQueryFragment selects the found products.
 const selectQueryFragment = `
 q(func: uid(%s), first: $first, offset: $offset, %s) {
 	uid
@@ -45,15 +51,17 @@ q(func: uid(%s), first: $first, offset: $offset, %s) {
 	Product.renamedTo {uid}
 	Product.renamedFrom {uid}
 	Product.forkOf {
-		uid Product.release {
-			Component.repository {
-				Repository.url
-			}
+		uid
+		Product.xid
+		Product.dataSource: CrawlerMeta.dataSource {
+			Repository.url
 		}
 	}
 	Product.forks {uid}
 	Product.forkCount
 	Product.starCount
+	Product.starGrowth
+	Product.forkGrowth
 	Product.releases {uid}
 	Product.tags {
 		uid
@@ -95,6 +103,7 @@ q(func: uid(%s), first: $first, offset: $offset, %s) {
 		Component.createdAt
 		Component.releases {uid}
 		Component.isLatest
+		Component.hasLicenseConflict
 		Component.documentationLanguage
 		Component.technologyReadinessLevel
 		Component.documentationReadinessLevel
@@ -316,16 +325,210 @@ func (dr *DgraphRepository) SearchProducts(ctx context.Context, query *parser.Qu
 	return ret, rsp.Metrics.NumUids["Product.xid"], nil
 }
 
+const productsIndexedBeforeQuery = `query q($domain: string, $before: string, $first: int, $offset: int) {
+	p as var(func: type(Product)) @filter(lt(CrawlerMeta.lastIndexedAt, $before) AND NOT has(Product.renamedTo)) @cascade {
+		CrawlerMeta.dataSource {
+			Repository.host @filter(eq(Host.domain, $domain)) {
+				uid
+			}
+		}
+	}
+	q(func: uid(p), orderasc: CrawlerMeta.lastIndexedAt, first: $first, offset: $offset) {
+		uid
+		Product.xid
+		CrawlerMeta.discoveredAt
+		CrawlerMeta.lastIndexedAt
+		Product.state
+		Product.lastUpdatedAt
+		Product.missingSince
+		Product.dataSource: CrawlerMeta.dataSource {
+			uid
+			Repository.url
+		}
+		Product.releases {
+			uid
+			Component.version
+		}
+	}
+}`
+
+// GetProductsIndexedBefore returns the products originating from the given
+// host, that were last indexed before the given time. Renamed products are
+// omitted. Only the information required to re-index the products is
+// returned.
+func (dr *DgraphRepository) GetProductsIndexedBefore(ctx context.Context, hostDomain string, indexedBefore time.Time, first, offset int64) ([]*productmodels.Product, error) {
+	dr.log.Debugw("get Products indexed before", "hostDomain", hostDomain, "indexedBefore", indexedBefore)
+
+	vars := map[string]string{
+		"$domain": hostDomain,
+		"$before": indexedBefore.UTC().Format(time.RFC3339),
+		"$first":  strconv.FormatInt(first, 10),
+		"$offset": strconv.FormatInt(offset, 10),
+	}
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, productsIndexedBeforeQuery, vars)
+	if err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("hostDomain", hostDomain)
+	}
+
+	var rspData map[string]interface{}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("hostDomain", hostDomain)
+	}
+	rawRes, _ := rspData["q"].([]interface{})
+	ret := make([]*productmodels.Product, 0, len(rawRes))
+	if len(rawRes) == 0 {
+		return ret, nil
+	}
+	if err = dr.dqlCopier.CopyTo(rawRes, &ret); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("hostDomain", hostDomain)
+	}
+	return ret, nil
+}
+
+const renamedProductQuery = `query q($platformId: string, $xid: string) {
+	q(func: eq(Product.platformId, $platformId), first: 1) @filter(NOT eq(Product.xid, $xid) AND NOT has(Product.renamedTo)) {
+		uid
+		Product.xid
+		Product.platformId
+		CrawlerMeta.discoveredAt
+	}
+}`
+
+// GetRenamedProduct returns the product with the given platform ID, but a
+// different Xid, which is the same product before it was renamed or
+// transferred. Products, that are already linked to their new identity, are
+// ignored. It returns nil, if there is no such product.
+func (dr *DgraphRepository) GetRenamedProduct(ctx context.Context, platformID, xid string) (*productmodels.Product, error) {
+	dr.log.Debugw("get renamed Product", "platformId", platformID, "xid", xid)
+
+	vars := map[string]string{
+		"$platformId": platformID,
+		"$xid":        xid,
+	}
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, renamedProductQuery, vars)
+	if err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productPlatformId", platformID)
+	}
+
+	var rspData map[string]interface{}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productPlatformId", platformID)
+	}
+	rawRes, _ := rspData["q"].([]interface{})
+	if len(rawRes) == 0 {
+		return nil, nil
+	}
+	ret := make([]*productmodels.Product, 0, 1)
+	if err = dr.dqlCopier.CopyTo(rawRes, &ret); err != nil {
+		return nil, WrapRepoError(err, errGetProductStr).Add("productPlatformId", platformID)
+	}
+	return ret[0], nil
+}
+
+// ClearProductsMissingSince removes the time since when the products with the
+// given IDs were missing.
+func (dr *DgraphRepository) ClearProductsMissingSince(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	dr.log.Debugw("clear Products missingSince", "count", len(ids))
+	objs := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		objs = append(objs, map[string]interface{}{"uid": id, "Product.missingSince": nil})
+	}
+	delJSON, err := json.Marshal(objs)
+	if err != nil {
+		return WrapRepoError(err, errSaveProductStr).Add("count", len(ids))
+	}
+	if _, err = dr.dgraphClient.NewTxn().Mutate(ctx, &api.Mutation{DeleteJson: delJSON, CommitNow: true}); err != nil {
+		return WrapRepoError(err, errSaveProductStr).Add("count", len(ids))
+	}
+	return nil
+}
+
+// maxComponentDepth is the maximum nesting depth of the sub-components, that
+// are deleted together with a product.
+const maxComponentDepth = 10
+
+// DeleteProductSubgraph deletes the nodes, that belong exclusively to the
+// product with the given ID: its releases and their sub-components together
+// with the software, dimensions, materials, manufacturing processes and
+// production metadata of the components, as well as the revisions and
+// popularity snapshots of the product. The product itself is kept. Files and
+// repositories are identified by their path and might be referenced by other
+// products of the same repository, therefore they are kept as well.
+func (dr *DgraphRepository) DeleteProductSubgraph(ctx context.Context, id string) error {
+	dr.log.Debugw("delete Product subgraph", "id", id)
+	req := &api.Request{
+		Query:     productSubgraphQuery(maxComponentDepth),
+		Vars:      map[string]string{"$id": id},
+		Mutations: []*api.Mutation{{DelNquads: []byte(productSubgraphDelNquads)}},
+		CommitNow: true,
+	}
+	if _, err := dr.dgraphClient.NewTxn().Do(ctx, req); err != nil {
+		return WrapRepoError(err, errDeleteProductStr).Add("productId", id)
+	}
+	return nil
+}
+
+// productSubgraphDelNquads deletes all nodes selected by the
+// `productSubgraphQuery`.
+const productSubgraphDelNquads = `
+	uid(rev) * * .
+	uid(pop) * * .
+	uid(cmp) * * .
+	uid(sw) * * .
+	uid(dim) * * .
+	uid(mat) * * .
+	uid(mp) * * .
+	uid(kv) * * .
+	uid(kvv) * * .
+`
+
+// productSubgraphQuery creates a query, that selects the nodes belonging
+// exclusively to the product `$id`. Sub-components are selected down to the
+// given depth.
+func productSubgraphQuery(depth int) string {
+	var b strings.Builder
+	b.WriteString(`query q($id: string) {
+	var(func: uid($id)) @filter(type(Product)) {
+		rev as Product.revisions
+		pop as Product.popularity
+		c0 as Product.releases`)
+	cmpVars := []string{"c0"}
+	for i := 1; i <= depth; i++ {
+		cmpVar := "c" + strconv.Itoa(i)
+		cmpVars = append(cmpVars, cmpVar)
+		b.WriteString(" {\n" + strings.Repeat("\t", i+2) + cmpVar + " as Component.components")
+	}
+	for i := depth; i > 0; i-- {
+		b.WriteString("\n" + strings.Repeat("\t", i+1) + "}")
+	}
+	b.WriteString(`
+	}
+	cmp as var(func: uid(` + strings.Join(cmpVars, ", ") + `)) @filter(type(Component)) {
+		sw as Component.software
+		dim as Component.outerDimensions
+		mat as Component.material
+		mp as Component.manufacturingProcess
+		kv as Component.productionMetadata {
+			kvv as KeyValue.value
+		}
+	}
+}`)
+	return b.String()
+}
+
 // createDQLQuery creates a DQL query from a search query.
 //
 // I first tried to use github.com/fenos/dqlx to programmatically build a query. It was cumbersome but the actual deal breaker was its bugginess. Therefore I crafted a query manually.
 func createDQLQuery(query *parser.Query, order searchmodels.OrderBy, pagination searchmodels.Pagination) (q string, v map[string]string) {
 	encoder := newEncoder()
 	lastVar := encoder.encodeQuery(query, "")
-	if lastVar == "" {
-		// if no root query was created, we need to create a query for returning all products
-		lastVar = encoder.addVariableWithFilter("", "", "")
-	}
+	// the filter is applied before ordering and selecting, so that the excluded
+	// products aren't counted either; if no root query was created, this
+	// variable holds all products
+	lastVar = encoder.addVariableWithFilter(listedProductsFilter, "", lastVar)
 	lastVar = encoder.appendOrderByVariable(order, lastVar)
 
 	ordFrg := ""
@@ -554,10 +757,13 @@ func (e *encoder) encodeOperator(opr *parser.Operator, parVar string) (curVar st
 		if !ok {
 			return
 		}
-		if o.Type != booleanHasOperator {
-			return ""
+		switch o.Type {
+		case booleanHasOperator:
+			curVar = e.appendVariable2(o.IsRootFilter, func() { e.appendBooleanHasFilter(o.Predicate, false) }, o.SelectionStart, o.SelectionEnd, parVar)
+		case booleanIsOperator:
+			// computed flags are stored as boolean predicates
+			curVar = e.appendVariable2(o.IsRootFilter, func() { e.appendBooleanIsFilter(o.Predicate, o.Value, false) }, o.SelectionStart, o.SelectionEnd, parVar)
 		}
-		curVar = e.appendVariable2(o.IsRootFilter, func() { e.appendBooleanHasFilter(o.Predicate, false) }, o.SelectionStart, o.SelectionEnd, parVar)
 		return
 	}
 
@@ -728,6 +934,18 @@ var operators = map[string]operator{
 		Predicate:      "Product.forkCount",
 		SelectionStart: "uid",
 	},
+	"stargrowth": {
+		Type:           numberIntOperator,
+		IsRootFilter:   true,
+		Predicate:      "Product.starGrowth",
+		SelectionStart: "uid",
+	},
+	"forkgrowth": {
+		Type:           numberIntOperator,
+		IsRootFilter:   true,
+		Predicate:      "Product.forkGrowth",
+		SelectionStart: "uid",
+	},
 	"discoveredat": {
 		Type:           dateTimeOperator,
 		IsRootFilter:   true,
@@ -805,6 +1023,12 @@ var operators = map[string]operator{
 		SelectionStart: `Product.release`,
 		SelectionEnd:   `{uid}`,
 	},
+	"haslicenseconflict": {
+		Type:           booleanIsOperator,
+		Predicate:      "Component.hasLicenseConflict",
+		SelectionStart: `Product.release`,
+		SelectionEnd:   `{uid}`,
+	},
 	"license": { // alias for licenseid
 		Type:           textTermExactOperator,
 		Predicate:      "License.xid",
@@ -1951,6 +2175,10 @@ func (e *encoder) appendOrderByVariable(orderBy searchmodels.OrderBy, parVar str
 		e.buf.WriteString(`order as Product.forkCount`)
 	case searchmodels.OrderByStarCount:
 		e.buf.WriteString(`order as Product.starCount`)
+	case searchmodels.OrderByStarGrowth:
+		e.buf.WriteString(`order as Product.starGrowth`)
+	case searchmodels.OrderByForkGrowth:
+		e.buf.WriteString(`order as Product.forkGrowth`)
 	case searchmodels.OrderByVersion:
 		e.buf.WriteString(`order as Product.version`)
 	case searchmodels.OrderByWebsite:
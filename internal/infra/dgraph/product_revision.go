@@ -0,0 +1,172 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"losh/internal/core/product/models"
+
+	"github.com/dgraph-io/dgo/v200/protos/api"
+)
+
+const (
+	errGetProductRevisionStr  = "failed to get product revision(s)"
+	errSaveProductRevisionStr = "failed to save product revision(s)"
+)
+
+const productRevisionsQuery = `
+query q($id: string, $first: int, $offset: int) {
+	q(func: uid($id)) @filter(type(Product)) {
+		total: count(Product.revisions)
+		revisions: Product.revisions (orderdesc: ProductRevision.createdAt, first: $first, offset: $offset) {
+			uid
+			ProductRevision.createdAt
+			ProductRevision.crawlerRunId
+			ProductRevision.changes
+			ProductRevision.component {
+				uid
+				Component.xid
+				Component.version
+			}
+		}
+	}
+}`
+
+// dqlProductRevision is the DQL representation of a product revision.
+type dqlProductRevision struct {
+	UID          string    `json:"uid"`
+	CreatedAt    time.Time `json:"ProductRevision.createdAt"`
+	CrawlerRunID *string   `json:"ProductRevision.crawlerRunId"`
+	Changes      string    `json:"ProductRevision.changes"`
+	Component    *struct {
+		UID     string  `json:"uid"`
+		Xid     *string `json:"Component.xid"`
+		Version *string `json:"Component.version"`
+	} `json:"ProductRevision.component"`
+}
+
+func (r dqlProductRevision) toModel(productID string) (*models.ProductRevision, error) {
+	rev := &models.ProductRevision{
+		ID:           &r.UID,
+		Product:      &models.Product{ID: &productID},
+		CreatedAt:    &r.CreatedAt,
+		CrawlerRunID: r.CrawlerRunID,
+		Changes:      []models.RevisionChange{},
+	}
+	if r.Component != nil {
+		rev.Component = &models.Component{ID: &r.Component.UID, Xid: r.Component.Xid, Version: r.Component.Version}
+	}
+	if err := json.Unmarshal([]byte(r.Changes), &rev.Changes); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// GetProductRevisions returns the revisions of the product with the given ID,
+// latest first, together with the total number of revisions.
+func (dr *DgraphRepository) GetProductRevisions(ctx context.Context, productID string, first, offset int64) ([]*models.ProductRevision, int64, error) {
+	dr.log.Debugw("get ProductRevisions", "productId", productID)
+	vars := map[string]string{
+		"$id":     productID,
+		"$first":  strconv.FormatInt(first, 10),
+		"$offset": strconv.FormatInt(offset, 10),
+	}
+	rsp, err := dr.dgraphClient.NewReadOnlyTxn().QueryWithVars(ctx, productRevisionsQuery, vars)
+	if err != nil {
+		return nil, 0, WrapRepoError(err, errGetProductRevisionStr).Add("productId", productID)
+	}
+	var rspData struct {
+		Q []struct {
+			Total     int64                `json:"total"`
+			Revisions []dqlProductRevision `json:"revisions"`
+		} `json:"q"`
+	}
+	if err = json.Unmarshal(rsp.Json, &rspData); err != nil {
+		return nil, 0, WrapRepoError(err, errGetProductRevisionStr).Add("productId", productID)
+	}
+	if len(rspData.Q) == 0 {
+		return []*models.ProductRevision{}, 0, nil
+	}
+
+	revisions := make([]*models.ProductRevision, 0, len(rspData.Q[0].Revisions))
+	for _, r := range rspData.Q[0].Revisions {
+		rev, err := r.toModel(productID)
+		if err != nil {
+			return nil, 0, WrapRepoError(err, errGetProductRevisionStr).Add("productId", productID)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rspData.Q[0].Total, nil
+}
+
+// CreateProductRevisions creates the given revisions and links them to their
+// products.
+func (dr *DgraphRepository) CreateProductRevisions(ctx context.Context, revisions []*models.ProductRevision) error {
+	dr.log.Debugw("create ProductRevisions", "count", len(revisions))
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	objs := make([]map[string]interface{}, 0, len(revisions))
+	for i, rev := range revisions {
+		if rev.Product == nil || rev.Product.ID == nil {
+			return NewRepoError("missing product ID").Add("index", i)
+		}
+		changes, err := json.Marshal(rev.Changes)
+		if err != nil {
+			return WrapRepoError(err, errSaveProductRevisionStr).Add("productId", *rev.Product.ID)
+		}
+		blankNode := "_:r" + strconv.Itoa(i)
+		obj := map[string]interface{}{
+			"uid":         blankNode,
+			"dgraph.type": []string{"ProductRevision", "Node"},
+			// the inverse edge is maintained manually
+			"ProductRevision.product": map[string]interface{}{
+				"uid":               *rev.Product.ID,
+				"Product.revisions": []map[string]string{{"uid": blankNode}},
+			},
+			"ProductRevision.createdAt": rev.CreatedAt.UTC(),
+			"ProductRevision.changes":   string(changes),
+		}
+		if rev.Component != nil && rev.Component.ID != nil {
+			obj["ProductRevision.component"] = map[string]string{"uid": *rev.Component.ID}
+		}
+		if rev.CrawlerRunID != nil {
+			obj["ProductRevision.crawlerRunId"] = *rev.CrawlerRunID
+		}
+		objs = append(objs, obj)
+	}
+
+	setJSON, err := json.Marshal(objs)
+	if err != nil {
+		return WrapRepoError(err, errSaveProductRevisionStr).Add("count", len(revisions))
+	}
+	rsp, err := dr.dgraphClient.NewTxn().Mutate(ctx, &api.Mutation{SetJson: setJSON, CommitNow: true})
+	if err != nil {
+		return WrapRepoError(err, errSaveProductRevisionStr).Add("count", len(revisions))
+	}
+
+	// save IDs of the new revisions
+	for i, rev := range revisions {
+		if uid, ok := rsp.Uids["r"+strconv.Itoa(i)]; ok {
+			rev.ID = &uid
+		}
+	}
+	return nil
+}
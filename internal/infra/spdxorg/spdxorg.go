@@ -17,9 +17,14 @@
 package spdxorg
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"embed"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"strings"
 
@@ -58,17 +63,84 @@ type spdxLicenseDetails struct {
 	LicenseTextHTML string `json:"licenseTextHtml"`
 }
 
-//go:embed *.json
+type spdxExceptionFile struct {
+	LicenseListVersion string          `json:"licenseListVersion"`
+	Exceptions         []spdxException `json:"exceptions"`
+}
+
+type spdxException struct {
+	LicenseExceptionID    string   `json:"licenseExceptionId"`
+	Name                  string   `json:"name"`
+	Reference             string   `json:"reference"`
+	DetailsURL            string   `json:"detailsUrl"`
+	SeeAlso               []string `json:"seeAlso"`
+	ReferenceNumber       int      `json:"referenceNumber"`
+	IsDeprecatedLicenseID bool     `json:"isDeprecatedLicenseId"`
+}
+
+type spdxExceptionDetails struct {
+	LicenseExceptionText string `json:"licenseExceptionText"`
+	ExceptionTextHTML    string `json:"exceptionTextHtml"`
+}
+
+// SnapshotFile is the name of the embedded license list snapshot. The snapshot
+// is created with the command `dev snapshot-licenses` and embedded on the next
+// build.
+const SnapshotFile = "spdx-snapshot.json.gz"
+
+//go:embed *.json*
 var assets embed.FS
 
+// Snapshot is a versioned copy of the SPDX license list including the texts of
+// all licenses and exceptions. It allows to set up the licenses on air-gapped
+// installations.
+type Snapshot struct {
+	LicenseListVersion string              `json:"licenseListVersion"`
+	Licenses           []*models.License   `json:"licenses"`
+	Exceptions         []*models.Exception `json:"exceptions"`
+}
+
+// ReadSnapshot reads a gzip compressed snapshot.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress license snapshot")
+	}
+	defer zr.Close()
+	snapshot := &Snapshot{}
+	if err = json.NewDecoder(zr).Decode(snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to parse license snapshot")
+	}
+	return snapshot, nil
+}
+
+// WriteSnapshot writes the snapshot gzip compressed.
+func WriteSnapshot(w io.Writer, snapshot *Snapshot) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return errors.Wrap(err, "failed to encode license snapshot")
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "failed to compress license snapshot")
+	}
+	return nil
+}
+
 // SpdxOrgProvider is a license provider that downloads SPDX licenses from the
-// SPDX Workgroup website.
+// SPDX license list data repository. In offline mode the licenses are taken from
+// the embedded snapshot instead.
 type SpdxOrgProvider struct {
-	// https://raw.githubusercontent.com/spdx/license-list-data/master/json/licenses.json
-	// use detailsUrl to get the license text
-	dowloader   *download.Downloader
-	licensesURL string
-	log         *zap.SugaredLogger
+	dowloader *download.Downloader
+	// baseURL is the URL of the repository, which contains the license list
+	// data in the directory `json` of each tagged version.
+	baseURL string
+	// version is the pinned version of the license list; the latest version is
+	// used, if empty.
+	version string
+	offline bool
+	// snapshots is the file system containing the license list snapshot.
+	snapshots fs.FS
+	log       *zap.SugaredLogger
 }
 
 // NewSpdxOrgProvider creates a new SpdxOrgProvider.
@@ -77,14 +149,111 @@ func NewSpdxOrgProvider(userAgent string) *SpdxOrgProvider {
 	requester := request.NewHTTPRequester(http.DefaultClient).SetLogger(log)
 	downloader := download.NewDownloaderWithRequester(requester).SetUserAgent(userAgent)
 	return &SpdxOrgProvider{
-		dowloader:   downloader,
-		licensesURL: "https://raw.githubusercontent.com/spdx/license-list-data/master/json/licenses.json",
-		log:         log,
+		dowloader: downloader,
+		baseURL:   "https://raw.githubusercontent.com/spdx/license-list-data",
+		snapshots: assets,
+		log:       log,
+	}
+}
+
+// SetVersion pins the version of the license list, e.g. `3.19`.
+func (p *SpdxOrgProvider) SetVersion(version string) *SpdxOrgProvider {
+	p.version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	return p
+}
+
+// SetOffline enables the offline mode, in which the licenses are taken from the
+// embedded snapshot.
+func (p *SpdxOrgProvider) SetOffline(offline bool) *SpdxOrgProvider {
+	p.offline = offline
+	return p
+}
+
+// SetSnapshotFS sets the file system, that contains the license list snapshot
+// named `SnapshotFile`. Defaults to the embedded snapshot.
+func (p *SpdxOrgProvider) SetSnapshotFS(fsys fs.FS) *SpdxOrgProvider {
+	p.snapshots = fsys
+	return p
+}
+
+// dataURL returns the URL of a file of the license list data of the pinned or
+// latest version.
+func (p *SpdxOrgProvider) dataURL(path string) string {
+	ref := "master"
+	if p.version != "" {
+		ref = "v" + p.version
+	}
+	return fmt.Sprintf("%s/%s/json/%s", p.baseURL, ref, path)
+}
+
+// detailsURL returns the URL to download the details of a license or exception
+// from. The details URL of the list always refers to the latest version,
+// therefore the URL is derived from the ID, if the version is pinned.
+func (p *SpdxOrgProvider) detailsURL(dir, id string, detailsURL *string) string {
+	if p.version != "" {
+		return p.dataURL(dir + "/" + id + ".json")
+	}
+	if detailsURL == nil {
+		return ""
+	}
+	return *detailsURL
+}
+
+// GetSnapshot returns the embedded snapshot of the license list.
+func (p *SpdxOrgProvider) GetSnapshot() (*Snapshot, error) {
+	content, err := fs.ReadFile(p.snapshots, SnapshotFile)
+	if err != nil {
+		return nil, errors.New("no license snapshot embedded, create one using the command `dev snapshot-licenses`")
+	}
+	snapshot, err := ReadSnapshot(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if p.version != "" && p.version != snapshot.LicenseListVersion {
+		return nil, errors.Errorf("embedded license snapshot is of version %s, but version %s is requested", snapshot.LicenseListVersion, p.version)
 	}
+	return snapshot, nil
+}
+
+// CreateSnapshot downloads the complete license list including the texts of all
+// licenses and exceptions.
+func (p *SpdxOrgProvider) CreateSnapshot(ctx context.Context) (*Snapshot, error) {
+	if p.offline {
+		return nil, errors.New("cannot create license snapshot in offline mode")
+	}
+	licenses, err := p.GetAllLicenses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(licenses) == 0 || licenses[0].LicenseListVersion == nil {
+		return nil, errors.New("license list is empty")
+	}
+	exceptions, err := p.GetAllExceptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		LicenseListVersion: *licenses[0].LicenseListVersion,
+		Licenses:           licenses,
+		Exceptions:         exceptions,
+	}, nil
 }
 
 // GetLicense returns the license with the given id.
 func (p *SpdxOrgProvider) GetLicense(ctx context.Context, _, spdxID *string) (*models.License, error) {
+	if p.offline {
+		snapshot, err := p.GetSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range snapshot.Licenses {
+			if *l.Xid == *spdxID {
+				return l, nil
+			}
+		}
+		return nil, errors.New("license not found")
+	}
+
 	p.log.Debug("downloading base license information")
 	licenses, err := p.getBaseLicenses(ctx)
 	if err != nil {
@@ -92,13 +261,14 @@ func (p *SpdxOrgProvider) GetLicense(ctx context.Context, _, spdxID *string) (*m
 	}
 
 	for _, l := range licenses {
-		if l.Xid == spdxID {
-			if l.DetailsURL != nil && *l.DetailsURL == "" {
+		if *l.Xid == *spdxID {
+			url := p.detailsURL("details", *l.Xid, l.DetailsURL)
+			if url == "" {
 				return l, nil
 			}
-			text, textHTML, err := p.getLicenseText(ctx, *l.DetailsURL)
+			text, textHTML, err := p.getLicenseText(ctx, url)
 			if err != nil {
-				return nil, errors.Wrapf(err, "failed to get license text for %s", l.Xid)
+				return nil, errors.Wrapf(err, "failed to get license text for %s", *l.Xid)
 			}
 			l.Text = &text
 			l.TextHTML = &textHTML
@@ -112,6 +282,14 @@ func (p *SpdxOrgProvider) GetLicense(ctx context.Context, _, spdxID *string) (*m
 
 // GetAllLicenses returns a list of all licenses
 func (p *SpdxOrgProvider) GetAllLicenses(ctx context.Context) ([]*models.License, error) {
+	if p.offline {
+		snapshot, err := p.GetSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Licenses, nil
+	}
+
 	p.log.Debug("downloading base license information")
 	incompleteLicenses, err := p.getBaseLicenses(ctx)
 	if err != nil {
@@ -121,11 +299,12 @@ func (p *SpdxOrgProvider) GetAllLicenses(ctx context.Context) ([]*models.License
 
 	// download license texts
 	for _, l := range incompleteLicenses {
-		if l.DetailsURL == nil || *l.DetailsURL == "" {
+		url := p.detailsURL("details", *l.Xid, l.DetailsURL)
+		if url == "" {
 			continue
 		}
 		p.log.Debugw("downloading license text", "spdxId", l.Xid)
-		text, textHTML, err := p.getLicenseText(ctx, *l.DetailsURL)
+		text, textHTML, err := p.getLicenseText(ctx, url)
 		if err != nil {
 			p.log.Errorw("failed to get license text", "spdxId", l.Xid)
 			continue
@@ -163,7 +342,7 @@ func (p *SpdxOrgProvider) getLicenseText(ctx context.Context, url string) (text
 // getLicenses returns a list of all licenses without license text.
 func (p *SpdxOrgProvider) getBaseLicenses(ctx context.Context) ([]*models.License, error) {
 	// download the license list
-	lcnt, err := p.dowloader.DownloadContent(ctx, p.licensesURL)
+	lcnt, err := p.dowloader.DownloadContent(ctx, p.dataURL("licenses.json"))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to download license list")
 	}
@@ -180,15 +359,16 @@ func (p *SpdxOrgProvider) getBaseLicenses(ctx context.Context) ([]*models.Licens
 	for i := 0; i < len(licenseFile.Licenses); i++ {
 		l := licenseFile.Licenses[i]
 		licenses = append(licenses, &models.License{
-			Xid:           &l.LicenseID,
-			Name:          &l.Name,
-			ReferenceURL:  &l.Reference,
-			DetailsURL:    &l.DetailsURL,
-			IsSpdx:        &isSPDX,
-			IsDeprecated:  &l.IsDeprecatedLicenseID,
-			IsOsiApproved: &l.IsOSIApproved,
-			IsFsfLibre:    &l.IsFSFLibre,
-			Type:          &lt,
+			Xid:                &l.LicenseID,
+			Name:               &l.Name,
+			ReferenceURL:       &l.Reference,
+			DetailsURL:         &l.DetailsURL,
+			IsSpdx:             &isSPDX,
+			IsDeprecated:       &l.IsDeprecatedLicenseID,
+			IsOsiApproved:      &l.IsOSIApproved,
+			IsFsfLibre:         &l.IsFSFLibre,
+			Type:               &lt,
+			LicenseListVersion: &licenseFile.LicenseListVersion,
 		})
 	}
 
@@ -220,3 +400,117 @@ func (p *SpdxOrgProvider) getBaseLicenses(ctx context.Context) ([]*models.Licens
 
 	return licenses, nil
 }
+
+// GetLicenseList returns all licenses and exceptions. Only the license list is
+// downloaded without the texts of the licenses, which suffices to resolve
+// license expressions. In offline mode the licenses and exceptions are taken
+// from the embedded snapshot including their texts.
+func (p *SpdxOrgProvider) GetLicenseList(ctx context.Context) ([]*models.License, []*models.Exception, error) {
+	if p.offline {
+		snapshot, err := p.GetSnapshot()
+		if err != nil {
+			return nil, nil, err
+		}
+		return snapshot.Licenses, snapshot.Exceptions, nil
+	}
+
+	p.log.Debug("downloading license list")
+	licenses, err := p.getBaseLicenses(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	exceptions, err := p.getBaseExceptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return licenses, exceptions, nil
+}
+
+// GetAllExceptions returns a list of all license exceptions.
+func (p *SpdxOrgProvider) GetAllExceptions(ctx context.Context) ([]*models.Exception, error) {
+	if p.offline {
+		snapshot, err := p.GetSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Exceptions, nil
+	}
+
+	p.log.Debug("downloading base exception information")
+	incompleteExceptions, err := p.getBaseExceptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exceptions := make([]*models.Exception, 0, len(incompleteExceptions))
+
+	// download exception texts
+	for _, e := range incompleteExceptions {
+		url := p.detailsURL("exceptions", *e.Xid, e.DetailsURL)
+		if url == "" {
+			continue
+		}
+		p.log.Debugw("downloading exception text", "spdxId", e.Xid)
+		text, textHTML, err := p.getExceptionText(ctx, url)
+		if err != nil {
+			p.log.Errorw("failed to get exception text", "spdxId", e.Xid)
+			continue
+		}
+		e.Text = &text
+		e.TextHTML = &textHTML
+		exceptions = append(exceptions, e)
+	}
+
+	return exceptions, nil
+}
+
+// getExceptionText returns the exception text for the given url.
+func (p *SpdxOrgProvider) getExceptionText(ctx context.Context, url string) (text string, textHTML string, err error) {
+	if url == "" {
+		return "", "", nil
+	}
+
+	// download the exception details file
+	detailsContent, err := p.dowloader.DownloadContent(ctx, url)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to download")
+	}
+
+	// parse exception details
+	var details spdxExceptionDetails
+	err = json.Unmarshal(detailsContent, &details)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to parse content, content was: %s", stringutil.Ellipses(strings.ReplaceAll(string(detailsContent), "\n", "\\n"), 60))
+	}
+
+	return details.LicenseExceptionText, details.ExceptionTextHTML, nil
+}
+
+// getBaseExceptions returns a list of all exceptions without exception text.
+func (p *SpdxOrgProvider) getBaseExceptions(ctx context.Context) ([]*models.Exception, error) {
+	// download the exception list
+	ecnt, err := p.dowloader.DownloadContent(ctx, p.dataURL("exceptions.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download exception list")
+	}
+
+	// parse exceptions
+	var exceptionFile spdxExceptionFile
+	err = json.Unmarshal(ecnt, &exceptionFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse exception file")
+	}
+	exceptions := make([]*models.Exception, 0, len(exceptionFile.Exceptions))
+	for i := 0; i < len(exceptionFile.Exceptions); i++ {
+		e := exceptionFile.Exceptions[i]
+		exceptions = append(exceptions, &models.Exception{
+			Xid:                &e.LicenseExceptionID,
+			Name:               &e.Name,
+			ReferenceURL:       &e.Reference,
+			DetailsURL:         &e.DetailsURL,
+			IsDeprecated:       &e.IsDeprecatedLicenseID,
+			LicenseListVersion: &exceptionFile.LicenseListVersion,
+		})
+	}
+
+	return exceptions, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spdxorg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"losh/internal/core/product/models"
+	"losh/internal/lib/log/logtest"
+)
+
+func TestMain(m *testing.M) {
+	logtest.Main(m)
+}
+
+// newOfflineProvider creates a provider in offline mode, that fails on any
+// network access.
+func newOfflineProvider(fsys fs.FS) *SpdxOrgProvider {
+	p := NewSpdxOrgProvider("test").SetOffline(true)
+	p.baseURL = "http://127.0.0.1:0"
+	if fsys != nil {
+		p.SetSnapshotFS(fsys)
+	}
+	return p
+}
+
+func snapshotFS(t *testing.T, snapshot *Snapshot) fs.FS {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := WriteSnapshot(buf, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{SnapshotFile: &fstest.MapFile{Data: buf.Bytes()}}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestOfflineProviderUsesSnapshot(t *testing.T) {
+	snapshot := &Snapshot{
+		LicenseListVersion: "3.19",
+		Licenses: []*models.License{
+			{Xid: strPtr("MIT"), Name: strPtr("MIT License"), Text: strPtr("Permission is hereby granted")},
+			{Xid: strPtr("CERN-OHL-S-2.0"), Name: strPtr("CERN Open Hardware Licence Version 2 - Strongly Reciprocal")},
+		},
+		Exceptions: []*models.Exception{
+			{Xid: strPtr("Classpath-exception-2.0"), Name: strPtr("Classpath exception 2.0")},
+		},
+	}
+	p := newOfflineProvider(snapshotFS(t, snapshot))
+	ctx := context.Background()
+
+	licenses, err := p.GetAllLicenses(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLicenses() error = %v", err)
+	}
+	if len(licenses) != 2 {
+		t.Errorf("GetAllLicenses() returned %d licenses, want 2", len(licenses))
+	}
+
+	license, err := p.GetLicense(ctx, nil, strPtr("MIT"))
+	if err != nil {
+		t.Fatalf("GetLicense() error = %v", err)
+	}
+	if license.Text == nil || *license.Text != "Permission is hereby granted" {
+		t.Errorf("GetLicense() returned license without text")
+	}
+	if _, err = p.GetLicense(ctx, nil, strPtr("GPL-3.0-only")); err == nil {
+		t.Errorf("GetLicense() of unknown license succeeded")
+	}
+
+	exceptions, err := p.GetAllExceptions(ctx)
+	if err != nil {
+		t.Fatalf("GetAllExceptions() error = %v", err)
+	}
+	if len(exceptions) != 1 {
+		t.Errorf("GetAllExceptions() returned %d exceptions, want 1", len(exceptions))
+	}
+
+	licenses, exceptions, err = p.GetLicenseList(ctx)
+	if err != nil {
+		t.Fatalf("GetLicenseList() error = %v", err)
+	}
+	if len(licenses) != 2 || len(exceptions) != 1 {
+		t.Errorf("GetLicenseList() returned %d licenses and %d exceptions, want 2 and 1", len(licenses), len(exceptions))
+	}
+}
+
+func TestOfflineProviderChecksVersion(t *testing.T) {
+	fsys := snapshotFS(t, &Snapshot{LicenseListVersion: "3.19"})
+	if _, err := newOfflineProvider(fsys).SetVersion("3.18").GetSnapshot(); err == nil {
+		t.Errorf("GetSnapshot() succeeded for a mismatching version")
+	}
+	if _, err := newOfflineProvider(fsys).SetVersion("v3.19").GetSnapshot(); err != nil {
+		t.Errorf("GetSnapshot() error = %v", err)
+	}
+}
+
+func TestEmbeddedSnapshot(t *testing.T) {
+	if _, err := assets.ReadFile(SnapshotFile); errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("no license snapshot embedded; create one using `dev snapshot-licenses internal/infra/spdxorg/%s`", SnapshotFile)
+	}
+
+	p := newOfflineProvider(nil)
+	snapshot, err := p.GetSnapshot()
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	if snapshot.LicenseListVersion == "" {
+		t.Errorf("snapshot has no license list version")
+	}
+	if len(snapshot.Licenses) == 0 || len(snapshot.Exceptions) == 0 {
+		t.Fatalf("snapshot contains %d licenses and %d exceptions", len(snapshot.Licenses), len(snapshot.Exceptions))
+	}
+	license, err := p.GetLicense(context.Background(), nil, strPtr("MIT"))
+	if err != nil {
+		t.Fatalf("GetLicense() error = %v", err)
+	}
+	if license.Text == nil || *license.Text == "" {
+		t.Errorf("embedded license MIT has no text")
+	}
+}
@@ -12,14 +12,26 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package util
+// Package logtest provides utilities for tests, that make use of the
+// application logger.
+package logtest
 
-import "regexp"
+import (
+	"os"
+	"testing"
 
-// var _manifest_name_pattern = r"^okh([_\-\t ].+)*$"
-var manifestNamePattern = regexp.MustCompile(`^okh([_\-\t ].+)*$`)
+	"losh/internal/lib/log"
+)
 
-// IsAcceptedManifestFileName returns true if the given file name matches an accepted manifest name.
-func IsAcceptedManifestFileName(path string) bool {
-	return manifestNamePattern.MatchString(path)
+// Main initializes the application logger with the default configuration and
+// runs the tests. It is meant to be called from `TestMain`:
+//
+//	func TestMain(m *testing.M) {
+//		logtest.Main(m)
+//	}
+func Main(m *testing.M) {
+	if err := log.Initialize(log.DefaultConfig()); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
 }
@@ -33,6 +33,9 @@ import (
 	"github.com/aisbergg/go-errors/pkg/errors"
 )
 
+// ErrNotFound indicates that the requested resource does not exist.
+var ErrNotFound = errors.New("resource not found")
+
 // ErrTooLarge indicates that the download content is too large.
 type ErrTooLarge struct {
 	Size  unit.ByteSize
@@ -179,6 +182,10 @@ func (r *Downloader) download(ctx context.Context, urlStr string, maxSize unit.B
 	if err != nil {
 		return errors.Wrap(err, "failed to create request")
 	}
+	req.Header = copyHeaders(r.headers)
+	if r.userAgent != "" {
+		req.Header.Set(n.HdrUserAgentKey, r.userAgent)
+	}
 	resp, err = r.requester.Do(req)
 	if err != nil {
 		return errors.Wrap(err, "request failed")
@@ -194,6 +201,9 @@ func (r *Downloader) download(ctx context.Context, urlStr string, maxSize unit.B
 		}
 	}
 	if !successful {
+		if resp.StatusCode == http.StatusNotFound {
+			return errors.Wrapf(ErrNotFound, "request failed with status code %d", resp.StatusCode)
+		}
 		return errors.Errorf("request failed with status code %d", resp.StatusCode)
 	}
 
@@ -17,10 +17,15 @@ package ratelimit
 import (
 	"context"
 	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
 	"time"
 )
 
-// RateLimiter applies a rate limit by waiting if necessary.
+// RateLimiter applies a rate limit by waiting if necessary. Implementations
+// must be safe for concurrent use, because a rate limiter is usually shared by
+// all requests to a platform.
 type RateLimiter interface {
 	// Update updates the rate limit after each request. It can make use of
 	// information from the response to calculate the next time limit.
@@ -40,8 +45,10 @@ type RateLimiter interface {
 
 // TimedeltaRateLimiter is a `RateLimiter` that ensures that requests are at least
 // `timedelta` time apart from each other. It adds a random amount of jitter to
-// the wait time.
+// the wait time. Each call of `WaitTime` reserves the next free time slot, so
+// that concurrent requests are spaced apart as well.
 type TimedeltaRateLimiter struct {
+	mutex     sync.Mutex
 	lastTime  time.Time
 	timedelta time.Duration
 	maxJitter time.Duration
@@ -63,21 +70,31 @@ func NewTimedeltaRateLimiter(timedelta time.Duration, jitterPercent uint64) *Tim
 
 // Update implements `RateLimiter`.
 func (rl *TimedeltaRateLimiter) Update(_ interface{}) {
-	rl.lastTime = time.Now().UTC()
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	// don't move reserved time slots forward
+	if now := time.Now().UTC(); now.After(rl.lastTime) {
+		rl.lastTime = now
+	}
 }
 
 // WaitTime implements `RateLimiter`.
 func (rl *TimedeltaRateLimiter) WaitTime() time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
 	jitter := time.Duration(0)
 	if rl.maxJitter > 0 {
 		jitter = time.Duration(rand.Int63n(int64(rl.maxJitter)))
 	}
 
-	wait := rl.timedelta + jitter - time.Now().UTC().Sub(rl.lastTime)
-	if wait > 0 {
-		return wait
+	// reserve the next time slot
+	now := time.Now().UTC()
+	next := rl.lastTime.Add(rl.timedelta + jitter)
+	if next.Before(now) {
+		next = now
 	}
-	return 0
+	rl.lastTime = next
+	return next.Sub(now)
 }
 
 // Apply implements RateLimiter.
@@ -90,41 +107,139 @@ func (rl *TimedeltaRateLimiter) Apply(ctx context.Context) {
 // -----------------------------------------------------------------------------
 
 // NumRequestsRateLimiter is a `RateLimiter` that limits the number of requests.
+// After `numRequests` requests, it waits for `waitTime` before the next batch
+// of requests is sent. Each call of `WaitTime` reserves a slot in the current
+// or next batch, so that concurrent requests are counted as well.
 type NumRequestsRateLimiter struct {
-	numRequests       uint64
-	requestsRemaining uint64
-	waitTime          time.Duration
+	mutex       sync.Mutex
+	numRequests uint64
+	waitTime    time.Duration
+	// reserved is the number of reserved slots in the current batch
+	reserved uint64
+	// batchStart is the time at which the current batch may start
+	batchStart time.Time
 }
 
 // NewNumRequestsRateLimiter creates a new `NumRequestsRateLimiter`.
 func NewNumRequestsRateLimiter(numRequests uint64, waitTime time.Duration) *NumRequestsRateLimiter {
 	return &NumRequestsRateLimiter{
-		numRequests:       numRequests,
-		requestsRemaining: numRequests,
-		waitTime:          waitTime,
+		numRequests: numRequests,
+		waitTime:    waitTime,
+	}
+}
+
+// Update implements `RateLimiter`. The slots are reserved by `WaitTime`,
+// therefore nothing needs to be updated.
+func (rl *NumRequestsRateLimiter) Update(resp interface{}) {}
+
+// WaitTime implements `RateLimiter`.
+func (rl *NumRequestsRateLimiter) WaitTime() time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := time.Now().UTC()
+	if rl.reserved >= rl.numRequests {
+		// the batch is exhausted, the next one starts after the wait time
+		if rl.batchStart.Before(now) {
+			rl.batchStart = now
+		}
+		rl.batchStart = rl.batchStart.Add(rl.waitTime)
+		rl.reserved = 0
+	}
+
+	// reserve a slot in the current batch
+	rl.reserved++
+	if wait := rl.batchStart.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Apply implements `RateLimiter`.
+func (rl *NumRequestsRateLimiter) Apply(ctx context.Context) {
+	SleepWithContext(ctx, rl.WaitTime())
+}
+
+// -----------------------------------------------------------------------------
+// HeaderRateLimiter
+// -----------------------------------------------------------------------------
+
+// HeaderRateLimiter is a `RateLimiter` that uses the rate limit information
+// reported by the server in the response headers (e.g. `X-RateLimit-Remaining`
+// and `X-RateLimit-Reset`). When the number of remaining requests drops to
+// zero, it waits until the reported reset time.
+type HeaderRateLimiter struct {
+	mutex        sync.Mutex
+	remainingKey string
+	resetKey     string
+	remaining    int64
+	resetTime    time.Time
+}
+
+// NewHeaderRateLimiter creates a new `HeaderRateLimiter` that reads the
+// remaining requests and the reset time (Unix epoch seconds) from the given
+// header keys.
+func NewHeaderRateLimiter(remainingKey, resetKey string) *HeaderRateLimiter {
+	return &HeaderRateLimiter{
+		remainingKey: http.CanonicalHeaderKey(remainingKey),
+		resetKey:     http.CanonicalHeaderKey(resetKey),
+		remaining:    -1,
 	}
 }
 
 // Update implements `RateLimiter`.
-func (rl *NumRequestsRateLimiter) Update(resp interface{}) {
-	if rl.requestsRemaining > 0 {
-		rl.requestsRemaining--
-	} else {
-		// reset the counter
-		rl.requestsRemaining = rl.numRequests
+func (rl *HeaderRateLimiter) Update(resp interface{}) {
+	var header http.Header
+	switch r := resp.(type) {
+	case *http.Response:
+		if r != nil {
+			header = r.Header
+		}
+	case interface{ Header() http.Header }:
+		if r != nil {
+			header = r.Header()
+		}
+	}
+	if header == nil {
+		return
+	}
+
+	remainingStr := header.Get(rl.remainingKey)
+	resetStr := header.Get(rl.resetKey)
+	if remainingStr == "" || resetStr == "" {
+		return
+	}
+	remaining, err := strconv.ParseInt(remainingStr, 10, 64)
+	if err != nil {
+		return
 	}
+	reset, err := strconv.ParseInt(resetStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.remaining = remaining
+	rl.resetTime = time.Unix(reset, 0).UTC()
 }
 
 // WaitTime implements `RateLimiter`.
-func (rl *NumRequestsRateLimiter) WaitTime() time.Duration {
-	if rl.requestsRemaining > 0 {
+func (rl *HeaderRateLimiter) WaitTime() time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	if rl.remaining != 0 {
 		return 0
 	}
-	return rl.waitTime
+	wait := rl.resetTime.Sub(time.Now().UTC())
+	if wait > 0 {
+		// add a small buffer to compensate clock differences
+		return wait + 1*time.Second
+	}
+	return 0
 }
 
 // Apply implements `RateLimiter`.
-func (rl *NumRequestsRateLimiter) Apply(ctx context.Context) {
+func (rl *HeaderRateLimiter) Apply(ctx context.Context) {
 	SleepWithContext(ctx, rl.WaitTime())
 }
 
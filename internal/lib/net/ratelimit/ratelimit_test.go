@@ -0,0 +1,78 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrentWaitTimes returns the wait times of the given number of concurrent
+// requests, shortest first.
+func concurrentWaitTimes(rl RateLimiter, num int) []time.Duration {
+	waits := make([]time.Duration, num)
+	var wg sync.WaitGroup
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			waits[i] = rl.WaitTime()
+		}(i)
+	}
+	wg.Wait()
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	return waits
+}
+
+// roughly reports whether the wait time matches the expected one, allowing for
+// the time passed during the test.
+func roughly(got, want time.Duration) bool {
+	return got <= want && got > want-time.Second
+}
+
+func TestNumRequestsRateLimiterConcurrent(t *testing.T) {
+	rl := NewNumRequestsRateLimiter(2, time.Minute)
+	waits := concurrentWaitTimes(rl, 5)
+
+	// each batch of two requests is a minute apart
+	want := []time.Duration{0, 0, time.Minute, time.Minute, 2 * time.Minute}
+	for i := range want {
+		if !roughly(waits[i], want[i]) {
+			t.Errorf("wait times = %v, want %v", waits, want)
+			break
+		}
+	}
+
+	// responses don't release the reserved slots
+	rl.Update(nil)
+	if wait := rl.WaitTime(); !roughly(wait, 2*time.Minute) {
+		t.Errorf("WaitTime() = %s, want 2m0s", wait)
+	}
+}
+
+func TestTimedeltaRateLimiterConcurrent(t *testing.T) {
+	rl := NewTimedeltaRateLimiter(time.Minute, 0)
+	waits := concurrentWaitTimes(rl, 3)
+
+	want := []time.Duration{0, time.Minute, 2 * time.Minute}
+	for i := range want {
+		if !roughly(waits[i], want[i]) {
+			t.Errorf("wait times = %v, want %v", waits, want)
+			break
+		}
+	}
+}
@@ -16,6 +16,7 @@ package request
 
 import (
 	"context"
+	"fmt"
 	gourl "net/url"
 	"time"
 
@@ -213,6 +214,23 @@ func (r *RESTRequester) Do(req RESTRequest) (resp *resty.Response, err error) {
 			}
 		}
 
+		// on HTTP error status -> let the backoff decide whether to retry
+		if err == nil && resp != nil && resp.IsError() {
+			retry := r.retryCalcWaitTimeFromResponseFunc != nil
+			for _, c := range r.retryOnCodes {
+				if c == resp.StatusCode() {
+					retry = true
+					break
+				}
+			}
+			if retry {
+				return &httpRetryableError{
+					err:  fmt.Errorf("request failed with status code %d", resp.StatusCode()),
+					resp: resp.RawResponse,
+				}
+			}
+		}
+
 		// stop on success
 		return nil
 	}
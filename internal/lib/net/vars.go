@@ -23,6 +23,9 @@ var (
 	HdrContentLengthKey       = http.CanonicalHeaderKey("Content-Length")
 	HdrContentTypeKey         = http.CanonicalHeaderKey("Content-Type")
 	HdrLocationKey            = http.CanonicalHeaderKey("Location")
+	HdrRateLimitRemainingKey  = http.CanonicalHeaderKey("RateLimit-Remaining")
+	HdrRateLimitResetKey      = http.CanonicalHeaderKey("RateLimit-Reset")
+	HdrRetryAfterKey          = http.CanonicalHeaderKey("Retry-After")
 	HdrUserAgentKey           = http.CanonicalHeaderKey("User-Agent")
 	HdrXRateLimitRemainingKey = http.CanonicalHeaderKey("X-RateLimit-Remaining")
 	HdrXRateLimitResetKey     = http.CanonicalHeaderKey("X-RateLimit-Reset")
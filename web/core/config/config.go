@@ -23,20 +23,22 @@ import (
 )
 
 type Config struct {
-	Debug     DebugConfig     `json:"debug"`
-	Log       log.Config      `json:"log"`
-	AccessLog AccessLogConfig `json:"accessLog"`
-	Server    ServerConfig    `json:"server"`
-	Database  dgraph.Config   `json:"database"`
+	Debug      DebugConfig      `json:"debug"`
+	Log        log.Config       `json:"log"`
+	AccessLog  AccessLogConfig  `json:"accessLog"`
+	Server     ServerConfig     `json:"server"`
+	Database   dgraph.Config    `json:"database"`
+	Popularity PopularityConfig `json:"popularity"`
 }
 
 func DefaultConfig() Config {
 	return Config{
-		Debug:     DefaultDebugConfig(),
-		Log:       log.DefaultConfig(),
-		AccessLog: DefaultAccessLogConfig(),
-		Server:    DefaultServerConfig(),
-		Database:  dgraph.DefaultConfig(),
+		Debug:      DefaultDebugConfig(),
+		Log:        log.DefaultConfig(),
+		AccessLog:  DefaultAccessLogConfig(),
+		Server:     DefaultServerConfig(),
+		Database:   dgraph.DefaultConfig(),
+		Popularity: DefaultPopularityConfig(),
 	}
 }
 
@@ -160,3 +162,18 @@ func DefaultTLSConfig() TLSConfig {
 		PreferServerCipherSuites: false,
 	}
 }
+
+// PopularityConfig contains the configuration of the growth of the star and
+// fork counts shown on the details page of a product.
+type PopularityConfig struct {
+	// GrowthWindows are the time windows over which the growth is computed.
+	// The first window should match the one of the crawler, which is used to
+	// rank trending products.
+	GrowthWindows []time.Duration `json:"growthWindows"`
+}
+
+func DefaultPopularityConfig() PopularityConfig {
+	return PopularityConfig{
+		GrowthWindows: []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour},
+	}
+}
@@ -124,8 +124,8 @@ func dumpCSVorTSV(results *dgraph.PaginatedList[*models.Product], t searchmodels
 			tags = append(tags, *tag.Name)
 		}
 		var forkOf string
-		if prd.ForkOf != nil {
-			forkOf = *prd.ForkOf.Release.Repository.URL
+		if prd.ForkOf != nil && prd.ForkOf.DataSource != nil {
+			forkOf = *prd.ForkOf.DataSource.URL
 		}
 		var category string
 		if prd.Category != nil {
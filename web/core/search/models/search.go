@@ -50,6 +50,8 @@ const (
 	OrderByState
 	OrderByForkCount
 	OrderByStarCount
+	OrderByStarGrowth
+	OrderByForkGrowth
 	OrderByVersion
 	OrderByWebsite
 	OrderByCreatedAt
@@ -121,6 +123,11 @@ func OrderByFromStr(s string, descending bool) OrderBy {
 		orderBy.Field = OrderByForkCount
 	case "starcount":
 		orderBy.Field = OrderByStarCount
+	case "stargrowth", "trending":
+		// trending products are those with the highest star growth
+		orderBy.Field = OrderByStarGrowth
+	case "forkgrowth":
+		orderBy.Field = OrderByForkGrowth
 	case "version":
 		orderBy.Field = OrderByVersion
 	case "website":
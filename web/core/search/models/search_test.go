@@ -0,0 +1,55 @@
+// Copyright 2022 André Lehmann
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "testing"
+
+func TestOrderByFromStr(t *testing.T) {
+	tests := []struct {
+		str        string
+		descending bool
+		want       OrderBy
+	}{
+		{"name", false, OrderBy{Field: OrderByName}},
+		{"language", false, OrderBy{Field: OrderByDocumentationLanguage}},
+		{"stargrowth", true, OrderBy{Field: OrderByStarGrowth, Descending: true}},
+		{"trending", true, OrderBy{Field: OrderByStarGrowth, Descending: true}},
+		{"Trending", false, OrderBy{Field: OrderByStarGrowth}},
+		{"forkgrowth", true, OrderBy{Field: OrderByForkGrowth, Descending: true}},
+		{"unknown", false, OrderBy{Field: OrderByName}},
+	}
+	for _, tt := range tests {
+		if got := OrderByFromStr(tt.str, tt.descending); got != tt.want {
+			t.Errorf("OrderByFromStr(%q, %v) = %+v, want %+v", tt.str, tt.descending, got, tt.want)
+		}
+	}
+}
+
+func TestOrderByFromCombinedStr(t *testing.T) {
+	tests := []struct {
+		str  string
+		want OrderBy
+	}{
+		{"trendingdsc", OrderBy{Field: OrderByStarGrowth, Descending: true}},
+		{"trendingasc", OrderBy{Field: OrderByStarGrowth}},
+		{"starcountdsc", OrderBy{Field: OrderByStarCount, Descending: true}},
+		{"", OrderBy{Field: OrderByName}},
+	}
+	for _, tt := range tests {
+		if got := OrderByFromCombinedStr(tt.str); got != tt.want {
+			t.Errorf("OrderByFromCombinedStr(%q) = %+v, want %+v", tt.str, got, tt.want)
+		}
+	}
+}
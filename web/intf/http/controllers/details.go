@@ -17,6 +17,8 @@ package controllers
 import (
 	"context"
 	gourl "net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"losh/internal/core/product/models"
@@ -30,6 +32,18 @@ import (
 
 var dbTimeout = 30 * time.Second
 
+// forkTreeDepth is the number of generations of origins and forks shown in the
+// fork tree of a product.
+const forkTreeDepth = 3
+
+const (
+	// historyLimit is the number of revisions shown in the history of a
+	// product and returned by default as JSON.
+	historyLimit = 50
+	// maxHistoryLimit is the maximum number of revisions returned as JSON.
+	maxHistoryLimit = 1000
+)
+
 // DetailsController is the controller for the resource details page at '/details/:id'.
 type DetailsController struct {
 	Controller
@@ -49,6 +63,7 @@ func NewDetailsController(db *dgraph.DgraphRepository, prdSvc *services.Service,
 // Register registers the controller with the given router.
 func (c DetailsController) Register(router fiber.Router) {
 	router.Get("/details/:id", c.Handle)
+	router.Get("/details/:id/history", c.HandleHistory)
 }
 
 // Handle handles the request for the resource details page.
@@ -78,13 +93,23 @@ func (c DetailsController) Handle(ctx *fiber.Ctx) error {
 	tplNme := ""
 	switch data.(type) {
 	case *models.Product:
+		// redirect to the new identity of a renamed product
+		prd := data.(*models.Product)
+		// placeholders are only known as the origin of a fork and have no
+		// details
+		if prd.IsPlaceholder() {
+			return fiber.ErrNotFound
+		}
+		if prd.RenamedTo != nil && prd.RenamedTo.ID != nil {
+			return ctx.Redirect("/details/"+strings.TrimPrefix(*prd.RenamedTo.ID, "0x"), fiber.StatusMovedPermanently)
+		}
+
 		queryParams := parseDetailsQueryParams(ctx).(DetailsQueryParams)
 		reqInfo.QueryParams = queryParams
 		tplBnd["req"] = reqInfo
 		tplNme = "details-product.html"
 
 		// select specific release version
-		prd := data.(*models.Product)
 		var selectedRelease *models.Component
 		for _, r := range prd.Releases {
 			if *r.Version == queryParams.Version {
@@ -97,6 +122,12 @@ func (c DetailsController) Handle(ctx *fiber.Ctx) error {
 		}
 		page["release"] = selectedRelease
 
+		// analyze the licenses of the release and its parts
+		page["licenseConflicts"], err = c.prdSvc.GetLicenseConflicts(svcCtx, selectedRelease)
+		if err != nil {
+			return newControllerError(err, reqInfo, "failed to render details page")
+		}
+
 		// get list of images
 		images := make([]*models.File, 0)
 		if selectedRelease.Image != nil {
@@ -109,9 +140,35 @@ func (c DetailsController) Handle(ctx *fiber.Ctx) error {
 		}
 		page["images"] = images
 
+		// get lineage and forks of the product
+		forkTree, err := c.prdSvc.GetProductForkTree(svcCtx, params.ID, forkTreeDepth)
+		if err != nil {
+			return newControllerError(err, reqInfo, "failed to render details page")
+		}
+		page["forkTree"] = flattenForkTree(forkTree)
+
+		// get the latest changes of the product
+		revisions, _, err := c.prdSvc.GetProductRevisions(svcCtx, params.ID, historyLimit, 0)
+		if err != nil {
+			return newControllerError(err, reqInfo, "failed to render details page")
+		}
+		page["history"] = newHistory(revisions)
+
+		// get the growth of the star and fork counts
+		growths, err := c.prdSvc.GetProductGrowth(svcCtx, prd)
+		if err != nil {
+			return newControllerError(err, reqInfo, "failed to render details page")
+		}
+		page["growth"] = newGrowth(growths)
+
 	case *models.License:
 		tplNme = "details-license.html"
 
+	case *models.Exception:
+		// exceptions share the template with licenses
+		tplNme = "details-license.html"
+		page["isException"] = true
+
 	case *models.User, *models.Group:
 		queryParams := parseSearchQueryParams(ctx).(SearchQueryParams)
 		tplBnd["req"].(*RequestInfo).QueryParams = queryParams
@@ -146,6 +203,152 @@ func (c DetailsController) Handle(ctx *fiber.Ctx) error {
 	return nil
 }
 
+// HandleHistory handles the request for the history of a product as JSON. The
+// revisions are returned latest first and can be paged using the query
+// parameters `first` and `offset`.
+func (c DetailsController) HandleHistory(ctx *fiber.Ctx) error {
+	reqInfo, _ := c.preprocessRequest(ctx, nil, parseDetailsParams)
+	params := reqInfo.Params.(DetailsParams)
+	if params.ID == "" {
+		return fiber.ErrNotFound
+	}
+	queryParams := struct {
+		First  int `query:"first"`
+		Offset int `query:"offset"`
+	}{First: historyLimit}
+	if err := ctx.QueryParser(&queryParams); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if queryParams.First < 1 || queryParams.First > maxHistoryLimit || queryParams.Offset < 0 {
+		return fiber.ErrBadRequest
+	}
+
+	svcCtx, cancel := context.WithTimeout(ctx.Context(), dbTimeout)
+	defer cancel()
+	revisions, total, err := c.prdSvc.GetProductRevisions(svcCtx, params.ID, int64(queryParams.First), int64(queryParams.Offset))
+	if err != nil {
+		return newControllerError(err, reqInfo, "failed to get product history")
+	}
+	return ctx.JSON(fiber.Map{
+		"total":     total,
+		"revisions": newHistory(revisions),
+	})
+}
+
+// HistoryEntry is a revision in the history of a product.
+type HistoryEntry struct {
+	ID           string                  `json:"id"`
+	CreatedAt    time.Time               `json:"createdAt"`
+	CrawlerRunID string                  `json:"crawlerRunId,omitempty"`
+	ComponentID  string                  `json:"componentId,omitempty"`
+	Version      string                  `json:"version,omitempty"`
+	Changes      []models.RevisionChange `json:"changes"`
+}
+
+// newHistory converts the revisions of a product into history entries.
+func newHistory(revisions []*models.ProductRevision) []HistoryEntry {
+	entries := make([]HistoryEntry, 0, len(revisions))
+	for _, r := range revisions {
+		entry := HistoryEntry{
+			ID:        *r.ID,
+			CreatedAt: *r.CreatedAt,
+			Changes:   r.Changes,
+		}
+		if r.CrawlerRunID != nil {
+			entry.CrawlerRunID = *r.CrawlerRunID
+		}
+		if r.Component != nil {
+			if r.Component.ID != nil {
+				entry.ComponentID = *r.Component.ID
+			}
+			if r.Component.Version != nil {
+				entry.Version = *r.Component.Version
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// GrowthEntry is the growth of the star and fork counts of a product within a
+// time window.
+type GrowthEntry struct {
+	// Window is the human readable time window, e.g. `7 days`.
+	Window     string
+	StarGrowth *int64
+	ForkGrowth *int64
+}
+
+// newGrowth converts the growth of a product into growth entries. Windows
+// without any known growth are omitted.
+func newGrowth(growths []services.PopularityGrowth) []GrowthEntry {
+	entries := make([]GrowthEntry, 0, len(growths))
+	for _, g := range growths {
+		if g.StarGrowth == nil && g.ForkGrowth == nil {
+			continue
+		}
+		entries = append(entries, GrowthEntry{
+			Window:     formatGrowthWindow(g.Window),
+			StarGrowth: g.StarGrowth,
+			ForkGrowth: g.ForkGrowth,
+		})
+	}
+	return entries
+}
+
+// formatGrowthWindow formats the time window in days or, if it isn't a
+// multiple of a day, in hours.
+func formatGrowthWindow(window time.Duration) string {
+	day := 24 * time.Hour
+	num, unit := int64(window/time.Hour), "hour"
+	if window%day == 0 {
+		num, unit = int64(window/day), "day"
+	}
+	if num != 1 {
+		unit += "s"
+	}
+	return strconv.FormatInt(num, 10) + " " + unit
+}
+
+// ForkTreeEntry is a product in the flattened fork tree of a product.
+type ForkTreeEntry struct {
+	Product   *models.Product
+	Depth     int
+	IsCurrent bool
+}
+
+// flattenForkTree flattens the fork tree of the given product into a list,
+// which starts with the oldest origin followed by the younger generations. The
+// forks of a product directly follow the product itself.
+func flattenForkTree(product *models.Product) []ForkTreeEntry {
+	if product == nil {
+		return nil
+	}
+
+	// lineage from the oldest origin to the product itself
+	lineage := []*models.Product{}
+	for origin := product.ForkOf; origin != nil; origin = origin.ForkOf {
+		lineage = append([]*models.Product{origin}, lineage...)
+	}
+	entries := make([]ForkTreeEntry, 0, len(lineage)+1+len(product.Forks))
+	for i, origin := range lineage {
+		entries = append(entries, ForkTreeEntry{Product: origin, Depth: i})
+	}
+	entries = append(entries, ForkTreeEntry{Product: product, Depth: len(lineage), IsCurrent: true})
+
+	// forks in depth first order
+	var appendForks func(prd *models.Product, depth int)
+	appendForks = func(prd *models.Product, depth int) {
+		for _, fork := range prd.Forks {
+			entries = append(entries, ForkTreeEntry{Product: fork, Depth: depth})
+			appendForks(fork, depth+1)
+		}
+	}
+	appendForks(product, len(lineage)+1)
+
+	return entries
+}
+
 func parseDetailsParams(ctx *fiber.Ctx) interface{} {
 	params := DetailsParams{}
 
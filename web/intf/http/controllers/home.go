@@ -15,19 +15,38 @@
 package controllers
 
 import (
+	"context"
+
+	"losh/internal/infra/dgraph"
+	"losh/web/core/search"
+	searchmodels "losh/web/core/search/models"
 	"losh/web/intf/http/controllers/binding"
 
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// trendingQuery selects the products shown in the trending block.
+	trendingQuery = "stargrowth:>0"
+	// trendingLimit is the number of products shown in the trending block.
+	trendingLimit = 6
 )
 
 // HomeController is the controller for the homepage at '/'.
 type HomeController struct {
 	Controller
+	searchService *search.Service
+	log           *zap.SugaredLogger
 }
 
 // NewHomeController creates a new HomeController.
-func NewHomeController(tplBndPrv binding.TemplateBindingProvider) HomeController {
-	return HomeController{Controller: Controller{tplBndPrv: tplBndPrv}}
+func NewHomeController(db *dgraph.DgraphRepository, log *zap.SugaredLogger, tplBndPrv binding.TemplateBindingProvider, debug bool) HomeController {
+	return HomeController{
+		Controller:    Controller{tplBndPrv: tplBndPrv},
+		searchService: search.NewService(db, debug),
+		log:           log,
+	}
 }
 
 func (c HomeController) Register(router fiber.Router) {
@@ -36,5 +55,23 @@ func (c HomeController) Register(router fiber.Router) {
 
 func (c HomeController) Handle(ctx *fiber.Ctx) error {
 	_, tplBnd := c.preprocessRequest(ctx, nil, nil)
+
+	// products with the highest star growth
+	svcCtx, cancel := context.WithTimeout(ctx.Context(), dbTimeout)
+	defer cancel()
+	trending, err := c.searchService.Search(
+		svcCtx,
+		trendingQuery,
+		searchmodels.OrderBy{Field: searchmodels.OrderByStarGrowth, Descending: true},
+		searchmodels.Pagination{First: trendingLimit},
+	)
+	if err != nil {
+		// the trending block is optional, the page is rendered without it
+		c.log.Errorw("failed to get trending products", "error", err)
+	} else {
+		page := tplBnd["page"].(map[string]interface{})
+		page["trending"] = trending.Items
+	}
+
 	return ctx.Render("home", tplBnd)
 }
@@ -263,6 +263,9 @@ func extractValueAndType(node models.Node) (value, typ string) {
 	case *models.Component:
 		value = *t.Name
 		typ = "Component"
+	case *models.Exception:
+		value = *t.Xid
+		typ = "Exception"
 	case *models.File:
 		value = *t.Path
 		typ = "File"
@@ -94,7 +94,7 @@ func (s *Server) Shutdown() error {
 // NewServer creates a new server instance.
 func NewServer(config *config.Config, db *dgraph.DgraphRepository) (*Server, error) {
 	log := log.NewLogger(logSelector)
-	prdSvc := services.NewService(db)
+	prdSvc := services.NewService(db).SetPopularityWindows(0, config.Popularity.GrowthWindows)
 	tplBndPrv := binding.NewTemplateBindingProvider(config)
 	fiberConfig, err := createFiberConfig(config, log, tplBndPrv)
 	if err != nil {
@@ -195,7 +195,7 @@ Disallow: /details
 	// web routes
 	tplBndPrv := binding.NewTemplateBindingProvider(s.config)
 	web := s.Group("")
-	controllers.NewHomeController(tplBndPrv).Register(web)
+	controllers.NewHomeController(s.db, s.log, tplBndPrv, s.config.Debug.Enabled).Register(web)
 	controllers.NewSearchController(s.db, tplBndPrv, s.config.Debug.Enabled).Register(web)
 	controllers.NewDetailsController(s.db, s.prdSvc, tplBndPrv, s.config.Debug.Enabled).Register(web)
 	controllers.NewAboutController(tplBndPrv).Register(web)